@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -9,7 +10,9 @@ import (
 	"github.com/wal-g/wal-g/cmd/common/st"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/internal/statistics"
+	"github.com/wal-g/wal-g/internal/tracing"
 )
 
 const usageTemplate = `Usage:{{if .Runnable}}
@@ -77,6 +80,20 @@ func Init(cmd *cobra.Command, dbName string) {
 		var err error
 		p, err = internal.Profile()
 		tracelog.ErrorLogger.FatalOnError(err)
+
+		shutdownTracing, err := tracing.Configure()
+		tracelog.ErrorLogger.FatalOnError(err)
+		if tracing.Enabled() {
+			endRootSpan := tracing.StartRoot(cmd.CommandPath())
+			stopTracing = func(err error) {
+				stopTracing = nil
+				endRootSpan(err)
+				shutdownTracing()
+			}
+		}
+
+		err = notify.Configure(dbName)
+		tracelog.ErrorLogger.FatalOnError(err)
 	}
 	cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
 		if persistentPostRun != nil {
@@ -85,10 +102,15 @@ func Init(cmd *cobra.Command, dbName string) {
 
 		// metrics hook
 		statistics.PushMetrics()
+		notify.Wait()
 
 		if p != nil {
 			p.Stop()
 		}
+
+		if stopTracing != nil {
+			stopTracing(nil)
+		}
 	}
 
 	// Don't run PersistentPreRun when shell autocompleting
@@ -101,6 +123,30 @@ func Init(cmd *cobra.Command, dbName string) {
 	}
 }
 
+// stopTracing ends the span of the running command and flushes the trace spans, it is nil when tracing is off
+var stopTracing func(err error)
+
+// FatalOnError exits with the error like tracelog.ErrorLogger.FatalOnError, but waits for the webhook events
+// sent in the background and flushes the trace spans first, the root span gets the error status.
+// The commands running the traced and notified operations exit with it.
+func FatalOnError(err error) {
+	if err == nil {
+		return
+	}
+	notify.Wait()
+	if stopTracing != nil {
+		stopTracing(err)
+	}
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// FatalfOnError is FatalOnError with the formatted message
+func FatalfOnError(format string, err error) {
+	if err != nil {
+		FatalOnError(fmt.Errorf(strings.TrimSpace(format), err))
+	}
+}
+
 // setup init and usage functionality
 func initHelp(cmd *cobra.Command) {
 	cmd.SetUsageTemplate(usageTemplate)
@@ -0,0 +1,72 @@
+package st
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/multistorage/exec"
+	"github.com/wal-g/wal-g/internal/storagetools"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+const recompressShortDescription = "Convert the objects by the prefix to another compression method"
+
+// recompressCmd represents the recompress command
+var recompressCmd = &cobra.Command{
+	Use:   "recompress [prefix] --to=method",
+	Short: recompressShortDescription,
+	Long: "The command decompresses every object by the prefix compressed with another method, compresses it " +
+		"with the specified one and replaces the object, changing its extension. The backup metadata referring " +
+		"to the object names is updated accordingly.",
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		compressor, ok := compression.Compressors[recompressTo]
+		if !ok {
+			tracelog.ErrorLogger.Fatalf("unknown compression method %q, supported methods: %v",
+				recompressTo, compression.CompressingAlgorithms)
+		}
+		sourceExtension := ""
+		if recompressFrom != "" {
+			decompressor := compression.FindDecompressor(recompressFrom)
+			if decompressor == nil {
+				tracelog.ErrorLogger.Fatalf("unknown compression file extension %q", recompressFrom)
+			}
+			sourceExtension = decompressor.FileExtension()
+		}
+
+		cfg := storagetools.RecompressConfig{
+			Concurrency:     recompressConcurrency,
+			Compressor:      compressor,
+			SourceExtension: sourceExtension,
+			Crypter:         internal.ConfigureCrypter(),
+		}
+
+		err := exec.OnStorage(targetStorage, func(folder storage.Folder) error {
+			return storagetools.HandleRecompress(cmd.Context(), prefix, folder, cfg)
+		})
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+var (
+	recompressTo          string
+	recompressFrom        string
+	recompressConcurrency int
+)
+
+func init() {
+	recompressCmd.Flags().StringVar(&recompressTo, "to", "",
+		"compression method to convert the objects to")
+	recompressCmd.Flags().StringVar(&recompressFrom, "from", "",
+		"convert only the objects with this file extension (e.g. lz4)")
+	recompressCmd.Flags().IntVarP(&recompressConcurrency, "concurrency", "c", 10,
+		"number of objects to recompress concurrently")
+	_ = recompressCmd.MarkFlagRequired("to")
+	StorageToolsCmd.AddCommand(recompressCmd)
+}
@@ -0,0 +1,68 @@
+package st
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/multistorage/exec"
+	"github.com/wal-g/wal-g/internal/storagetools"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+const reencryptShortDescription = "Re-encrypt the objects by the prefix with the currently configured key"
+
+// reencryptCmd represents the reencrypt command
+var reencryptCmd = &cobra.Command{
+	Use:   "reencrypt [prefix] --old-config=path",
+	Short: reencryptShortDescription,
+	Long: "The command decrypts every encrypted object by the prefix (or of the backup specified by --backup) " +
+		"with the key from the old config, encrypts it with the currently configured key and replaces the object. " +
+		"The objects already encrypted with the current key are skipped, so the command can be restarted " +
+		"after an interruption.",
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 && reencryptBackupName != "" {
+			tracelog.ErrorLogger.Fatal("either prefix or --backup should be specified, not both")
+		}
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		cfg := storagetools.ReencryptConfig{
+			Concurrency: reencryptConcurrency,
+			OldCrypter:  internal.CrypterFromConfig(reencryptOldConfig),
+			NewCrypter:  internal.ConfigureCrypter(),
+		}
+
+		err := exec.OnStorage(targetStorage, func(folder storage.Folder) error {
+			prefix := prefix
+			if reencryptBackupName != "" {
+				var err error
+				prefix, err = storagetools.BackupDataPrefix(folder, reencryptBackupName)
+				if err != nil {
+					return err
+				}
+			}
+			return storagetools.HandleReencrypt(cmd.Context(), prefix, folder, cfg)
+		})
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+var (
+	reencryptOldConfig   string
+	reencryptBackupName  string
+	reencryptConcurrency int
+)
+
+func init() {
+	reencryptCmd.Flags().StringVar(&reencryptOldConfig, "old-config", "",
+		"path to the config file with the old encryption settings")
+	reencryptCmd.Flags().StringVar(&reencryptBackupName, "backup", "",
+		"re-encrypt the data of the backup with this name (or LATEST) instead of the prefix")
+	reencryptCmd.Flags().IntVarP(&reencryptConcurrency, "concurrency", "c", 10,
+		"number of objects to re-encrypt concurrently")
+	_ = reencryptCmd.MarkFlagRequired("old-config")
+	StorageToolsCmd.AddCommand(reencryptCmd)
+}
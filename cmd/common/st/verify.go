@@ -0,0 +1,54 @@
+package st
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/multistorage/exec"
+	"github.com/wal-g/wal-g/internal/storagetools"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+const verifyShortDescription = "Read every object by the prefix end to end and report the broken ones"
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [prefix]",
+	Short: verifyShortDescription,
+	Long: "The command downloads every object by the prefix, decrypts and decompresses objects that have " +
+		"a known compression extension, and prints a JSON report listing unreadable or truncated objects. " +
+		"It exits with a non-zero code if any broken object is found.",
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		cfg := storagetools.VerifyConfig{
+			Concurrency: verifyConcurrency,
+			RateLimit:   verifyRateLimit,
+			Crypter:     internal.ConfigureCrypter(),
+		}
+
+		err := exec.OnStorage(targetStorage, func(folder storage.Folder) error {
+			return storagetools.HandleVerify(prefix, folder, cfg, os.Stdout)
+		})
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+var (
+	verifyConcurrency int
+	verifyRateLimit   int64
+)
+
+func init() {
+	verifyCmd.Flags().IntVarP(&verifyConcurrency, "concurrency", "c", 10,
+		"number of objects to verify concurrently")
+	verifyCmd.Flags().Int64Var(&verifyRateLimit, "rate-limit", 0,
+		"max total download speed in bytes per second, 0 means no limit")
+	StorageToolsCmd.AddCommand(verifyCmd)
+}
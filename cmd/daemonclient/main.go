@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -23,7 +24,7 @@ Usage:
 {{if not .CommandUsage}}
 Arguments:
   socket	- name of unix socket to communicate with wal-g daemon
-  command	- command to send to the daemon: wal-push, wal-fetch
+  command	- command to send to the daemon: wal-push, wal-fetch, wal-prefetch, backup-push, status
   command_args	- command specific arguments
 {{end}}
 Flags:
@@ -34,6 +35,8 @@ type commandOpts struct {
 	name    string
 	msgType daemon.SocketMessageType
 	args    []string
+	// flags are the boolean command flags, which are sent to the daemon as the message arguments when set
+	flags map[string]string
 
 	options *daemon.RunOptions
 }
@@ -60,6 +63,21 @@ var (
 			msgType: daemon.WalFetchType,
 			args:    []string{"wal_name", "destination_filename"},
 		},
+		"wal-prefetch": {
+			msgType: daemon.WalPrefetchType,
+			args:    []string{"wal_name", "prefetch_location"},
+		},
+		"backup-push": {
+			msgType: daemon.BackupPushType,
+			flags: map[string]string{
+				"full":      "make full backup-push",
+				"permanent": "push permanent backup",
+				"verify":    "verify page checksums",
+			},
+		},
+		"status": {
+			msgType: daemon.StatusType,
+		},
 	}
 )
 
@@ -80,6 +98,11 @@ func parseArgs(args []string) (*commandOpts, *flag.FlagSet, error) {
 		return nil, fs, fmt.Errorf("unsupported command %v", command)
 	}
 
+	flagValues := make(map[string]*bool, len(cmd.flags))
+	for name, usage := range cmd.flags {
+		flagValues[name] = fs.Bool(name, false, usage)
+	}
+
 	cmd.name = command
 	if len(args) < 2+len(cmd.args) {
 		return cmd, fs, errCommandArguments
@@ -94,6 +117,15 @@ func parseArgs(args []string) (*commandOpts, *flag.FlagSet, error) {
 		}
 	}
 
+	flagNames := make([]string, 0, len(flagValues))
+	for name, value := range flagValues {
+		if *value {
+			flagNames = append(flagNames, name)
+		}
+	}
+	sort.Strings(flagNames)
+	opts.MessageArgs = append(opts.MessageArgs, flagNames...)
+
 	cmd.options = opts
 	return cmd, fs, nil
 }
@@ -125,6 +157,17 @@ func main() {
 		log.Fatalf("daemon socket '%v' doesn't exist or is unavailable:\n\t%v", cmd.options.SocketName, err)
 	}
 
+	if cmd.msgType.HasFramedResponse() {
+		result, err := daemon.SendStreamingCommand(cmd.options, os.Stderr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(result) > 0 {
+			fmt.Println(string(result))
+		}
+		return
+	}
+
 	response, err := daemon.SendCommand(cmd.options)
 	if err != nil {
 		if response == daemon.ArchiveNonExistenceType {
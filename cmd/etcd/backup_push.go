@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/etcd"
@@ -32,13 +33,14 @@ var backupPushCmd = &cobra.Command{
 		signalHandler := utility.NewSignalHandler(ctx, cancel, []os.Signal{syscall.SIGINT, syscall.SIGTERM})
 		defer func() { _ = signalHandler.Close() }()
 
-		uploader, err := internal.ConfigureUploader()
+		uploader, err := internal.ConfigureStreamUploader()
 		tracelog.ErrorLogger.FatalOnError(err)
 		uploader.ChangeDirectory(utility.BaseBackupPath)
 
 		backupCmd, err := internal.GetCommandSetting(conf.NameStreamCreateCmd)
 		tracelog.ErrorLogger.FatalOnError(err)
-		etcd.HandleBackupPush(uploader, backupCmd)
+		err = etcd.HandleBackupPush(uploader, backupCmd)
+		common.FatalOnError(err)
 	},
 }
 
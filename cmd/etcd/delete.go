@@ -8,6 +8,7 @@ import (
 )
 
 var confirmed = false
+var deleteGfsPolicy internal.GfsPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -38,6 +39,13 @@ var deleteEverythingCmd = &cobra.Command{
 	Run:       runDeleteEverything,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
 func runDeleteBefore(cmd *cobra.Command, args []string) {
 	storage, err := internal.ConfigureStorage()
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -68,8 +76,19 @@ func runDeleteEverything(cmd *cobra.Command, args []string) {
 	deleteHandler.DeleteEverything(confirmed)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	storage, err := internal.ConfigureStorage()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := etcd.NewEtcdDeleteHandler(storage.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy, confirmed)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd)
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteGfsCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
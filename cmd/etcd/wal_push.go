@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/etcd"
@@ -40,7 +41,7 @@ var walPushCmd = &cobra.Command{
 		tracelog.ErrorLogger.FatalOnError(err)
 
 		err = etcd.HandleWALPush(ctx, uploader, dataDir)
-		tracelog.ErrorLogger.FatalOnError(err)
+		common.FatalOnError(err)
 	},
 }
 
@@ -9,6 +9,7 @@ import (
 )
 
 var confirmed = false
+var deleteGfsPolicy internal.GfsPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -46,6 +47,13 @@ var deleteEverythingCmd = &cobra.Command{
 	Run:       runDeleteEverything,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	st, err := internal.ConfigureStorage()
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -86,10 +94,21 @@ func runDeleteRetainAfter(args []string) {
 	deleteHandler.HandleDeleteRetainAfter(args, confirmed)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	st, err := internal.ConfigureStorage()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := newFdbDeleteHandler(st.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy, confirmed)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
 	deleteRetainCmd.Flags().StringP("after", "a", "", "Set the time after which retain backups")
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd)
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteGfsCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
 
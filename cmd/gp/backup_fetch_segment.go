@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/greenplum"
@@ -62,7 +63,8 @@ var segBackupFetchCmd = &cobra.Command{
 		}
 
 		pgFetcher := postgres.GetFetcherOld(args[0], fileMask, restoreSpec, extractProv)
-		internal.HandleBackupFetch(storage.RootFolder(), targetBackupSelector, pgFetcher)
+		err = internal.HandleBackupFetch(storage.RootFolder(), targetBackupSelector, pgFetcher)
+		common.FatalOnError(err)
 	},
 }
 
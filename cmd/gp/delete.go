@@ -9,6 +9,7 @@ import (
 
 var confirmed = false
 var deleteTargetUserData = ""
+var deleteGfsPolicy internal.GfsPolicy
 
 const DeleteGarbageExamples = `  garbage           Deletes outdated WAL archives and leftover backups files from storage`
 const DeleteGarbageUse = "garbage"
@@ -48,6 +49,13 @@ var deleteTargetCmd = &cobra.Command{
 	Run:     runDeleteTarget,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
 var deleteGarbageCmd = &cobra.Command{
 	Use:     DeleteGarbageUse,
 	Example: DeleteGarbageExamples,
@@ -111,6 +119,17 @@ func runDeleteTarget(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteTarget(targetBackupSelector)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	storage, err := internal.ConfigureStorage()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	delArgs := greenplum.DeleteArgs{Confirmed: confirmed}
+	deleteHandler, err := greenplum.NewDeleteHandler(storage.RootFolder(), delArgs)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy)
+}
+
 func runDeleteGarbage(cmd *cobra.Command, args []string) {
 	storage, err := internal.ConfigureStorage()
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -128,7 +147,9 @@ func init() {
 
 	deleteTargetCmd.Flags().StringVar(
 		&deleteTargetUserData, internal.DeleteTargetUserDataFlag, "", internal.DeleteTargetUserDataDescription)
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
 
-	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
+	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd,
+		deleteGfsCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
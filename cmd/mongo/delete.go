@@ -8,6 +8,7 @@ import (
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/mongo"
 	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
+	"github.com/wal-g/wal-g/utility"
 )
 
 const (
@@ -23,6 +24,7 @@ var (
 	purgeGarbage bool
 	retainAfter  string
 	retainCount  uint
+	gfsPolicy    internal.GfsPolicy
 )
 
 // deleteCmd represents the delete command
@@ -32,6 +34,20 @@ var deleteCmd = &cobra.Command{
 	Run:   runPurge,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runPurgeGfs,
+}
+
+var deleteRetainWindowCmd = &cobra.Command{
+	Use:     internal.DeleteRetainWindowUsageExample,
+	Example: internal.DeleteRetainWindowExamples,
+	Args:    internal.DeleteRetainWindowArgsValidator,
+	Run:     runPurgeRetainWindow,
+}
+
 func runPurge(cmd *cobra.Command, args []string) {
 	opts := []mongo.PurgeOption{
 		mongo.PurgeDryRun(!confirmed),
@@ -61,6 +77,42 @@ func runPurge(cmd *cobra.Command, args []string) {
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
+func runPurgeGfs(cmd *cobra.Command, args []string) {
+	opts := []mongo.PurgeOption{
+		mongo.PurgeDryRun(!confirmed),
+		mongo.PurgeOplog(purgeOplog),
+		mongo.PurgeGarbage(purgeGarbage),
+		mongo.PurgeGfs(gfsPolicy)}
+
+	downloader, err := archive.NewStorageDownloader(archive.NewDefaultStorageSettings())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	purger, err := archive.NewStoragePurger(archive.NewDefaultStorageSettings())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	err = mongo.HandlePurge(downloader, purger, opts...)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+func runPurgeRetainWindow(cmd *cobra.Command, args []string) {
+	window, err := internal.ParseRetainWindow(args[0])
+	tracelog.ErrorLogger.FatalOnError(err)
+	opts := []mongo.PurgeOption{
+		mongo.PurgeDryRun(!confirmed),
+		mongo.PurgeOplog(purgeOplog),
+		mongo.PurgeGarbage(purgeGarbage),
+		mongo.PurgeRetainWindow(utility.TimeNowCrossPlatformUTC().Add(-window))}
+
+	downloader, err := archive.NewStorageDownloader(archive.NewDefaultStorageSettings())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	purger, err := archive.NewStoragePurger(archive.NewDefaultStorageSettings())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	err = mongo.HandlePurge(downloader, purger, opts...)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup, garbage and oplog deletion."+
@@ -70,4 +122,16 @@ func init() {
 	deleteCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 	deleteCmd.Flags().StringVar(&retainAfter, retainAfterFlag, "", "Keep backups newer")
 	deleteCmd.Flags().UintVar(&retainCount, retainCountFlag, 0, "Keep minimum count, except permanent backups")
+
+	deleteCmd.AddCommand(deleteGfsCmd)
+	deleteGfsCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup, garbage and oplog deletion")
+	deleteGfsCmd.Flags().BoolVar(&purgeOplog, purgeOplogFlag, false, "Purge oplog archives older than the oldest kept backup")
+	deleteGfsCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &gfsPolicy)
+
+	deleteCmd.AddCommand(deleteRetainWindowCmd)
+	deleteRetainWindowCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup, garbage and oplog deletion")
+	deleteRetainWindowCmd.Flags().BoolVar(&purgeOplog, purgeOplogFlag, false,
+		"Purge oplog archives older than the oldest kept backup")
+	deleteRetainWindowCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 }
@@ -12,6 +12,8 @@ import (
 const (
 	backupFetchShortDescription = "Fetch desired backup from storage"
 	targetUserDataDescription   = "Fetch storage backup which has the specified user data"
+	tablesDescription           = "Restore only the given tables (db.tbl,...) of the xtrabackup backup for the import"
+	tablesDstDescription        = "Directory to restore and prepare the tables in"
 )
 
 var (
@@ -36,10 +38,13 @@ var (
 			targetBackupSelector, err := createTargetBackupSelector(args, fetchTargetUserData)
 			tracelog.ErrorLogger.FatalOnError(err)
 
-			mysql.HandleBackupFetch(storage.RootFolder(), targetBackupSelector, restoreCmd, prepareCmd)
+			mysql.HandleBackupFetch(storage.RootFolder(), targetBackupSelector, restoreCmd, prepareCmd,
+				fetchTables, fetchTablesDst)
 		},
 	}
 	fetchTargetUserData string
+	fetchTables         []string
+	fetchTablesDst      string
 )
 
 func createTargetBackupSelector(args []string, fetchTargetUserData string) (internal.BackupSelector, error) {
@@ -57,4 +62,7 @@ func init() {
 	cmd.AddCommand(backupFetchCmd)
 	backupFetchCmd.Flags().StringVar(&fetchTargetUserData, "target-user-data",
 		"", targetUserDataDescription)
+	backupFetchCmd.Flags().StringSliceVar(&fetchTables, "tables", nil, tablesDescription)
+	backupFetchCmd.Flags().StringVar(&fetchTablesDst, "tables-dst", "", tablesDstDescription)
+	backupFetchCmd.MarkFlagsRequiredTogether("tables", "tables-dst")
 }
@@ -16,11 +16,15 @@ const skipStartTimeFlagShortDescr = "skip start time starting from begin to fetc
 const fetchUntilFlagShortDescr = "time in RFC3339 for PITR"
 const fetchUntilBinlogLastModifiedFlagShortDescr = "time in RFC3339 that is used to prevent wal-g from replaying" +
 	" binlogs that was created/modified after this time"
+const fetchUntilGTIDFlagShortDescr = "GTID of the first transaction which should not be applied"
+const fetchUntilPositionFlagShortDescr = "binlog position file:pos of the first event which should not be applied"
 
 var fetchBackupName string
 var fetchUntilTS string
 var fetchUntilBinlogLastModifiedTS string
 var skipStartTime bool
+var fetchUntilGTID string
+var fetchUntilPosition string
 
 // binlogPushCmd represents the cron command
 var binlogFetchCmd = &cobra.Command{
@@ -30,7 +34,8 @@ var binlogFetchCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		storage, err := internal.ConfigureStorage()
 		tracelog.ErrorLogger.FatalOnError(err)
-		mysql.HandleBinlogFetch(storage.RootFolder(), fetchBackupName, fetchUntilTS, fetchUntilBinlogLastModifiedTS, skipStartTime)
+		mysql.HandleBinlogFetch(storage.RootFolder(), fetchBackupName, fetchUntilTS, fetchUntilBinlogLastModifiedTS, skipStartTime,
+			fetchUntilGTID, fetchUntilPosition)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.MysqlBinlogDstSetting] = true
@@ -50,5 +55,7 @@ func init() {
 		"",
 		fetchUntilBinlogLastModifiedFlagShortDescr)
 	binlogFetchCmd.PersistentFlags().BoolVar(&skipStartTime, "skip-start-time", false, skipStartTimeFlagShortDescr)
+	binlogFetchCmd.PersistentFlags().StringVar(&fetchUntilGTID, "until-gtid", "", fetchUntilGTIDFlagShortDescr)
+	binlogFetchCmd.PersistentFlags().StringVar(&fetchUntilPosition, "until-position", "", fetchUntilPositionFlagShortDescr)
 	cmd.AddCommand(binlogFetchCmd)
 }
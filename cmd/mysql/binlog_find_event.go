@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/mysql"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	findEventShortDescription    = "Find the archived binlog statements matching the pattern to locate the PITR stop point"
+	findEventSinceFlagShortDescr = "backup name starting from which you want to search binlogs"
+	findEventUntilFlagShortDescr = "time in RFC3339 until which you want to search binlogs"
+	findEventPatternShortDescr   = "regular expression the statement should match"
+)
+
+var (
+	findEventBackupName string
+	findEventUntilTS    string
+	findEventPattern    string
+	findEventPretty     bool
+	findEventJSON       bool
+
+	findEventCmd = &cobra.Command{
+		Use:   "binlog-find-event",
+		Short: findEventShortDescription,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			storage, err := internal.ConfigureStorage()
+			tracelog.ErrorLogger.FatalOnError(err)
+			mysql.HandleBinlogFindEvent(storage.RootFolder(), findEventBackupName, findEventUntilTS, findEventPattern,
+				findEventPretty, findEventJSON)
+		},
+	}
+)
+
+func init() {
+	findEventCmd.Flags().StringVar(&findEventBackupName, "since", "LATEST", findEventSinceFlagShortDescr)
+	findEventCmd.Flags().StringVar(&findEventUntilTS, "until",
+		utility.TimeNowCrossPlatformUTC().Format(time.RFC3339), findEventUntilFlagShortDescr)
+	findEventCmd.Flags().StringVar(&findEventPattern, "pattern", "", findEventPatternShortDescr)
+	findEventCmd.Flags().BoolVar(&findEventPretty, PrettyFlag, false, "Prints more readable output")
+	findEventCmd.Flags().BoolVar(&findEventJSON, JSONFlag, false, "Prints output in json format")
+	_ = findEventCmd.MarkFlagRequired("pattern")
+	cmd.AddCommand(findEventCmd)
+}
@@ -15,10 +15,14 @@ const replaySinceFlagShortDescr = "backup name starting from which you want to f
 const replayUntilFlagShortDescr = "time in RFC3339 for PITR"
 const replayUntilBinlogLastModifiedFlagShortDescr = "time in RFC3339 that is used to prevent wal-g from replaying" +
 	" binlogs that was created/modified after this time"
+const replayUntilGTIDFlagShortDescr = "GTID of the first transaction which should not be replayed"
+const replayUntilPositionFlagShortDescr = "binlog position file:pos of the first event which should not be replayed"
 
 var replayBackupName string
 var replayUntilTS string
 var replayUntilBinlogLastModifiedTS string
+var replayUntilGTID string
+var replayUntilPosition string
 
 var binlogReplayCmd = &cobra.Command{
 	Use:   "binlog-replay",
@@ -27,7 +31,8 @@ var binlogReplayCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		storage, err := internal.ConfigureStorage()
 		tracelog.ErrorLogger.FatalOnError(err)
-		mysql.HandleBinlogReplay(storage.RootFolder(), replayBackupName, replayUntilTS, replayUntilBinlogLastModifiedTS)
+		mysql.HandleBinlogReplay(storage.RootFolder(), replayBackupName, replayUntilTS, replayUntilBinlogLastModifiedTS,
+			replayUntilGTID, replayUntilPosition)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.MysqlBinlogReplayCmd] = true
@@ -42,5 +47,7 @@ func init() {
 		utility.TimeNowCrossPlatformUTC().Format(time.RFC3339), replayUntilFlagShortDescr)
 	binlogReplayCmd.PersistentFlags().StringVar(&replayUntilBinlogLastModifiedTS, "until-binlog-last-modified-time",
 		"", replayUntilBinlogLastModifiedFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().StringVar(&replayUntilGTID, "until-gtid", "", replayUntilGTIDFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().StringVar(&replayUntilPosition, "until-position", "", replayUntilPositionFlagShortDescr)
 	cmd.AddCommand(binlogReplayCmd)
 }
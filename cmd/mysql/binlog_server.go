@@ -15,10 +15,12 @@ const (
 	binlogServerShortDescription = "Create server for backup slaves"
 	binlogSinceFlagShortDescr    = "backup name starting from which you want to use binlogs"
 	untilFlagShortDescr          = "time in RFC3339 for PITR"
+	followFlagShortDescr         = "keep streaming the newly uploaded binlogs instead of stopping at until"
 )
 
 var untilTS string
 var BinlogBackupName string
+var followBinlogs bool
 
 var (
 	binlogServerCmd = &cobra.Command{
@@ -36,7 +38,7 @@ var (
 			tracelog.ErrorLogger.FatalOnError(err)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			mysql.HandleBinlogServer(BinlogBackupName, untilTS)
+			mysql.HandleBinlogServer(BinlogBackupName, untilTS, followBinlogs)
 		},
 	}
 )
@@ -47,5 +49,6 @@ func init() {
 		"until",
 		utility.TimeNowCrossPlatformUTC().Format(time.RFC3339),
 		untilFlagShortDescr)
+	binlogServerCmd.Flags().BoolVar(&followBinlogs, "follow", false, followFlagShortDescr)
 	cmd.AddCommand(binlogServerCmd)
 }
@@ -8,6 +8,7 @@ import (
 )
 
 var confirmed = false
+var deleteGfsPolicy internal.GfsPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -45,6 +46,20 @@ var deleteTargetCmd = &cobra.Command{
 	Run:     runDeleteTarget,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
+var deleteRetainWindowCmd = &cobra.Command{
+	Use:     internal.DeleteRetainWindowUsageExample,
+	Example: internal.DeleteRetainWindowExamples,
+	Args:    internal.DeleteRetainWindowArgsValidator,
+	Run:     runDeleteRetainWindow,
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	storage, err := internal.ConfigureStorage()
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -97,8 +112,31 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteRetain(args, confirmed)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	storage, err := internal.ConfigureStorage()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := mysql.NewDeleteHandler(storage.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy, confirmed)
+}
+
+func runDeleteRetainWindow(cmd *cobra.Command, args []string) {
+	storage, err := internal.ConfigureStorage()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := mysql.NewDeleteHandler(storage.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	err = deleteHandler.HandleDeleteRetainWindow(args, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteTargetCmd)
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteTargetCmd, deleteGfsCmd,
+		deleteRetainWindowCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
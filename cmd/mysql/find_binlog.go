@@ -18,7 +18,10 @@ var (
 		Use:   "binlog-find",
 		Short: findBinlogShortDescription,
 		PreRun: func(cmd *cobra.Command, args []string) {
-			conf.RequiredSettings[conf.MysqlDatasourceNameSetting] = true
+			// the binlogs archived with the index are looked up without connecting to MySQL
+			if findGtid == "" {
+				conf.RequiredSettings[conf.MysqlDatasourceNameSetting] = true
+			}
 			err := internal.AssertRequiredSettingsSet()
 			tracelog.ErrorLogger.FatalOnError(err)
 		},
@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/mysql"
@@ -57,7 +58,7 @@ var (
 				userData = viper.GetString(conf.SentinelUserDataSetting)
 			}
 
-			mysql.HandleBackupPush(
+			err = mysql.HandleBackupPush(
 				folder,
 				uploader,
 				backupCmd,
@@ -66,6 +67,7 @@ var (
 				userData,
 				mysql.NewRegularDeltaBackupConfigurator(folder, deltaBaseSelector),
 			)
+			common.FatalOnError(err)
 		},
 	}
 	fullBackup        = true
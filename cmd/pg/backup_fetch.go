@@ -2,10 +2,12 @@ package pg
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/postgres"
@@ -25,6 +27,9 @@ For information about pattern syntax view: https://golang.org/pkg/path/filepath/
 	restoreOnlyDescription        = `[Experimental] Downloads only databases or tables specified by passed names.
 Separate parameters with comma. Use 'database' or 'database/namespace.table' as a parameter ('public' namespace can be omitted).  
 Sets reverse delta unpack & skip redundant tars options automatically. Always downloads system databases and tables.`
+	recoveryTargetTimeDescription = "Fetch the newest backup preceding the time (RFC3339) and configure the recovery to it"
+	recoveryTargetLSNDescription  = "Fetch the newest backup preceding the LSN and configure the recovery to it"
+	recoveryTargetXidDescription  = "Fetch the newest backup and configure the recovery to the transaction ID"
 )
 
 var fileMask string
@@ -33,9 +38,13 @@ var reverseDeltaUnpack bool
 var skipRedundantTars bool
 var fetchTargetUserData string
 var partialRestoreArgs []string
+var recoveryTargetTime string
+var recoveryTargetLSN string
+var recoveryTargetXid string
 
 var backupFetchCmd = &cobra.Command{
-	Use:   "backup-fetch destination_directory [backup_name | --target-user-data <data>]",
+	Use: "backup-fetch destination_directory [backup_name | --target-user-data <data>] " +
+		"[--recovery-target-time <time> | --recovery-target-lsn <lsn> | --recovery-target-xid <xid>]",
 	Short: backupFetchShortDescription, // TODO : improve description
 	Args:  cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -44,8 +53,15 @@ var backupFetchCmd = &cobra.Command{
 		if fetchTargetUserData == "" {
 			fetchTargetUserData = viper.GetString(conf.FetchTargetUserDataSetting)
 		}
-		targetBackupSelector, err := createTargetFetchBackupSelector(cmd, args, fetchTargetUserData)
+		recoveryTarget, err := postgres.NewRecoveryTarget(recoveryTargetTime, recoveryTargetLSN, recoveryTargetXid)
 		tracelog.ErrorLogger.FatalOnError(err)
+		var targetBackupSelector internal.BackupSelector
+		if recoveryTarget == nil {
+			targetBackupSelector, err = createTargetFetchBackupSelector(cmd, args, fetchTargetUserData)
+			tracelog.ErrorLogger.FatalOnError(err)
+		} else if fetchTargetUserData != "" {
+			tracelog.ErrorLogger.Fatal("The recovery target can't be used together with the target user data")
+		}
 
 		storage, err := postgres.ConfigureMultiStorage(false)
 		tracelog.ErrorLogger.FatalOnError(err)
@@ -81,10 +97,31 @@ var backupFetchCmd = &cobra.Command{
 			pgFetcher = postgres.GetFetcherOld(args[0], fileMask, restoreSpec, extractProv)
 		}
 
-		internal.HandleBackupFetch(rootFolder, targetBackupSelector, pgFetcher)
+		if recoveryTarget != nil {
+			backupName := ""
+			if len(args) >= 2 {
+				backupName = args[1]
+			}
+			selector := postgres.NewRecoveryTargetBackupSelector(*recoveryTarget, backupName)
+			err = postgres.HandleRecoveryTargetFetch(rootFolder, selector, pgFetcher, args[0], buildRestoreCommand())
+			common.FatalOnError(err)
+			return
+		}
+		err = internal.HandleBackupFetch(rootFolder, targetBackupSelector, pgFetcher)
+		common.FatalOnError(err)
 	},
 }
 
+// buildRestoreCommand creates the restore_command fetching WAL with this WAL-G binary and config
+func buildRestoreCommand() string {
+	walgPath, err := os.Executable()
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to get the WAL-G binary path, using wal-g from PATH: %v", err)
+		walgPath = "wal-g"
+	}
+	return postgres.BuildRestoreCommand(walgPath, conf.CfgFile)
+}
+
 // create the BackupSelector to select the backup to fetch
 func createTargetFetchBackupSelector(cmd *cobra.Command,
 	args []string, targetUserData string) (internal.BackupSelector, error) {
@@ -114,6 +151,12 @@ func init() {
 		nil, restoreOnlyDescription)
 	backupFetchCmd.Flags().StringVar(&targetStorage, "target-storage",
 		"", targetStorageDescription)
+	backupFetchCmd.Flags().StringVar(&recoveryTargetTime, "recovery-target-time",
+		"", recoveryTargetTimeDescription)
+	backupFetchCmd.Flags().StringVar(&recoveryTargetLSN, "recovery-target-lsn",
+		"", recoveryTargetLSNDescription)
+	backupFetchCmd.Flags().StringVar(&recoveryTargetXid, "recovery-target-xid",
+		"", recoveryTargetXidDescription)
 
 	Cmd.AddCommand(backupFetchCmd)
 }
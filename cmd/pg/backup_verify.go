@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+)
+
+const (
+	backupVerifyShortDescription = "Verifies the page checksums of a backup in storage"
+	backupVerifyLongDescription  = "Streams every tar of the backup and the backups it is a delta from, " +
+		"validates the header and checksum of every page of the restored relation files and the pg_control file " +
+		"and prints the JSON report. Nothing is written to disk."
+)
+
+// backupVerifyCmd represents the backupVerify command
+var backupVerifyCmd = &cobra.Command{
+	Use:   "backup-verify backup_name",
+	Short: backupVerifyShortDescription,
+	Long:  backupVerifyLongDescription,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		internal.ConfigureLimiters()
+
+		storage, err := internal.ConfigureStorage()
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		backupSelector, err := internal.NewTargetBackupSelector("", args[0], postgres.NewGenericMetaFetcher())
+		tracelog.ErrorLogger.FatalOnError(err)
+		backup, err := backupSelector.Select(storage.RootFolder())
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		report, err := postgres.HandleBackupVerify(postgres.ToPgBackup(backup), os.Stdout)
+		tracelog.ErrorLogger.FatalOnError(err)
+		if report.Status != postgres.BackupVerifyStatusOk {
+			tracelog.ErrorLogger.Fatalf("Backup %s verification failed", backup.Name)
+		}
+	},
+}
+
+func init() {
+	Cmd.AddCommand(backupVerifyCmd)
+}
@@ -21,6 +21,7 @@ const afterFlag = "after"
 var confirmed = false
 var useSentinelTime = false
 var deleteTargetUserData = ""
+var deleteGfsPolicy internal.GfsPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -58,6 +59,20 @@ var deleteTargetCmd = &cobra.Command{
 	Run:     runDeleteTarget,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
+var deleteRetainWindowCmd = &cobra.Command{
+	Use:     internal.DeleteRetainWindowUsageExample,
+	Example: internal.DeleteRetainWindowExamples,
+	Args:    internal.DeleteRetainWindowArgsValidator,
+	Run:     runDeleteRetainWindow,
+}
+
 var deleteGarbageCmd = &cobra.Command{
 	Use:     DeleteGarbageUse,
 	Example: DeleteGarbageExamples,
@@ -130,6 +145,29 @@ func runDeleteTarget(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteTarget(targetBackupSelector, confirmed, findFullBackup)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	folder := configureFolder()
+
+	permanentBackups, permanentWals := postgres.GetPermanentBackupsAndWals(folder)
+
+	deleteHandler, err := postgres.NewDeleteHandler(folder, permanentBackups, permanentWals, useSentinelTime)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy, confirmed)
+}
+
+func runDeleteRetainWindow(cmd *cobra.Command, args []string) {
+	folder := configureFolder()
+
+	permanentBackups, permanentWals := postgres.GetPermanentBackupsAndWals(folder)
+
+	deleteHandler, err := postgres.NewDeleteHandler(folder, permanentBackups, permanentWals, useSentinelTime)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	err = deleteHandler.HandleDeleteRetainWindow(args, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func runDeleteGarbage(cmd *cobra.Command, args []string) {
 	folder := configureFolder()
 
@@ -163,8 +201,10 @@ func init() {
 	deleteTargetCmd.Flags().StringVar(
 		&deleteTargetUserData, internal.DeleteTargetUserDataFlag, "", internal.DeleteTargetUserDataDescription)
 	deleteRetainCmd.Flags().StringP(afterFlag, "a", "", "Set the time after which retain backups")
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
 
-	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
+	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd,
+		deleteGfsCmd, deleteRetainWindowCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 	deleteCmd.PersistentFlags().BoolVar(&useSentinelTime, UseSentinelTimeFlag, false, UseSentinelTimeDescription)
 }
@@ -0,0 +1,53 @@
+package pg
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/internal/exporter"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	exporterShortDescription = "Serve backup and WAL archive metrics for Prometheus"
+	exporterLongDescription  = "Periodically lists the storage and serves the backup freshness, " +
+		"WAL archive lag and storage usage metrics on HTTP_LISTEN"
+)
+
+var (
+	// exporterCmd represents the exporter command
+	exporterCmd = &cobra.Command{
+		Use:   "exporter",
+		Short: exporterShortDescription,
+		Long:  exporterLongDescription,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := conf.ConfigureAndRunDefaultWebServer()
+			tracelog.ErrorLogger.FatalOnError(err)
+			storage, err := internal.ConfigureStorage()
+			tracelog.ErrorLogger.FatalOnError(err)
+
+			source := exporter.Source{
+				Database:    "postgres",
+				ArchivePath: utility.WalPath,
+				MetaFetcher: postgres.NewGenericMetaFetcher(),
+			}
+			exporter.HandleExporter(storage.RootFolder(), source, exporterScrapeInterval, exporterUsageInterval)
+		},
+	}
+	exporterScrapeInterval time.Duration
+	exporterUsageInterval  time.Duration
+)
+
+func init() {
+	Cmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().DurationVar(&exporterScrapeInterval, "scrape-interval", time.Minute,
+		"Interval between the storage scrapes")
+	exporterCmd.Flags().DurationVar(&exporterUsageInterval, "usage-interval", time.Hour,
+		"Interval between the storage usage computations, which list the whole storage. 0 disables the storage usage")
+}
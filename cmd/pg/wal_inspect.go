@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	walInspectUsage            = "wal-inspect segment|first_segment-last_segment"
+	walInspectShortDescription = "Decode the WAL segments from storage like pg_waldump"
+	walInspectLongDescription  = "Fetches the WAL segments from storage and prints their records or, with --stats, " +
+		"the WAL volume aggregated by the resource managers and the relations. No running Postgres is required."
+)
+
+var (
+	// walInspectCmd represents the walInspect command
+	walInspectCmd = &cobra.Command{
+		Use:   walInspectUsage,
+		Short: walInspectShortDescription,
+		Long:  walInspectLongDescription,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			timeline, first, last, err := postgres.ParseWalInspectRange(args[0])
+			tracelog.ErrorLogger.FatalOnError(err)
+			inspectArgs := postgres.WalInspectArguments{
+				Timeline:     timeline,
+				FirstSegment: first,
+				LastSegment:  last,
+				Stats:        walInspectStats,
+				Rmgr:         walInspectRmgr,
+				TopRelations: walInspectTopRelations,
+			}
+			if walInspectRelation != "" {
+				inspectArgs.Relation, err = postgres.ParseRelFileNode(walInspectRelation)
+				tracelog.ErrorLogger.FatalOnError(err)
+			}
+
+			storage, err := internal.ConfigureStorage()
+			tracelog.ErrorLogger.FatalOnError(err)
+			outputType := postgres.WalInspectTextOutput
+			if walInspectJSON {
+				outputType = postgres.WalInspectJSONOutput
+			}
+			outputWriter := postgres.NewWalInspectOutputWriter(outputType, os.Stdout)
+
+			err = postgres.HandleWalInspect(storage.RootFolder().GetSubFolder(utility.WalPath), inspectArgs, outputWriter)
+			tracelog.ErrorLogger.FatalOnError(err)
+		},
+	}
+	walInspectStats        bool
+	walInspectJSON         bool
+	walInspectRmgr         string
+	walInspectRelation     string
+	walInspectTopRelations int
+)
+
+func init() {
+	Cmd.AddCommand(walInspectCmd)
+	walInspectCmd.Flags().BoolVar(&walInspectStats, "stats", false,
+		"Show the statistics by the resource managers and the relations instead of the records")
+	walInspectCmd.Flags().BoolVar(&walInspectJSON, useJSONOutputFlag, false, useJSONOutputDescription)
+	walInspectCmd.Flags().StringVar(&walInspectRmgr, "rmgr", "",
+		"Only inspect the records of the resource manager, e.g. Heap")
+	walInspectCmd.Flags().StringVar(&walInspectRelation, "relation", "",
+		"Only inspect the records referring to the relation in the tablespace/database/relation form")
+	walInspectCmd.Flags().IntVar(&walInspectTopRelations, "top", 20,
+		"Number of the relations with the largest WAL volume in the statistics, 0 to show all")
+}
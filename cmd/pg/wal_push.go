@@ -3,6 +3,7 @@ package pg
 import (
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal/databases/postgres"
 )
 
@@ -21,7 +22,7 @@ var walPushCmd = &cobra.Command{
 		tracelog.ErrorLogger.FatalOnError(err)
 
 		err = postgres.HandleWALPush(cmd.Context(), walUploader, args[0])
-		tracelog.ErrorLogger.FatalOnError(err)
+		common.FatalOnError(err)
 	},
 }
 
@@ -22,6 +22,7 @@ var (
 	purgeGarbage bool
 	retainAfter  string
 	retainCount  uint
+	gfsPolicy    internal.GfsPolicy
 )
 
 // deleteCmd represents the delete command
@@ -31,6 +32,13 @@ var deleteCmd = &cobra.Command{
 	Run:   runDelete,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
 func runDelete(cmd *cobra.Command, args []string) {
 	opts := []redis.PurgeOption{
 		redis.PurgeDryRun(!confirmed),
@@ -54,10 +62,26 @@ func runDelete(cmd *cobra.Command, args []string) {
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	opts := []redis.PurgeOption{
+		redis.PurgeDryRun(!confirmed),
+		redis.PurgeGarbage(purgeGarbage),
+		redis.PurgeGfs(gfsPolicy),
+	}
+
+	err := redis.HandlePurge(utility.BaseBackupPath, opts...)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup and garbage deletion")
 	deleteCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Delete garbage in backup folder")
 	deleteCmd.Flags().StringVar(&retainAfter, retainAfterFlag, "", "Keep backups newer")
 	deleteCmd.Flags().UintVar(&retainCount, retainCountFlag, 0, "Keep minimum count, except permanent backups")
+
+	deleteCmd.AddCommand(deleteGfsCmd)
+	deleteGfsCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup and garbage deletion")
+	deleteGfsCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Delete garbage in backup folder")
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &gfsPolicy)
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/redis"
@@ -41,7 +42,7 @@ var backupFetchCmd = &cobra.Command{
 		restoreCmd.Stderr = os.Stderr
 
 		err = redis.HandleBackupFetch(ctx, storage.RootFolder(), args[0], restoreCmd)
-		tracelog.ErrorLogger.FatalOnError(err)
+		common.FatalOnError(err)
 	},
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/cmd/common"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/redis"
@@ -37,7 +38,7 @@ var backupPushCmd = &cobra.Command{
 		signalHandler := utility.NewSignalHandler(ctx, cancel, []os.Signal{syscall.SIGINT, syscall.SIGTERM})
 		defer func() { _ = signalHandler.Close() }()
 
-		uploader, err := internal.ConfigureUploader()
+		uploader, err := internal.ConfigureStreamUploader()
 		tracelog.ErrorLogger.FatalOnError(err)
 
 		// Configure folder
@@ -54,7 +55,7 @@ var backupPushCmd = &cobra.Command{
 		metaConstructor := archive.NewBackupRedisMetaConstructor(ctx, uploader.Folder(), permanent)
 
 		err = redis.HandleBackupPush(uploader, backupCmd, metaConstructor)
-		tracelog.ErrorLogger.FatalfOnError("Redis backup creation failed: %v", err)
+		common.FatalfOnError("Redis backup creation failed: %v", err)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.NameStreamCreateCmd] = true
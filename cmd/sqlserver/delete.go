@@ -9,6 +9,7 @@ import (
 )
 
 var confirmed = false
+var deleteGfsPolicy internal.GfsPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -39,6 +40,13 @@ var deleteEverythingCmd = &cobra.Command{
 	Run:       runDeleteEverything,
 }
 
+var deleteGfsCmd = &cobra.Command{
+	Use:     internal.DeleteGfsUsageExample,
+	Example: internal.DeleteGfsExamples,
+	Args:    internal.DeleteGfsArgsValidator,
+	Run:     runDeleteGfs,
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	deleteHandler, err := newSQLServerDeleteHandler()
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -60,9 +68,17 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteRetain(args, confirmed)
 }
 
+func runDeleteGfs(cmd *cobra.Command, args []string) {
+	deleteHandler, err := newSQLServerDeleteHandler()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteGfs(deleteGfsPolicy, confirmed)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd)
+	internal.AddDeleteGfsFlags(deleteGfsCmd, &deleteGfsPolicy)
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteGfsCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
@@ -26,17 +27,23 @@ func (err BackupNonExistenceError) Error() string {
 }
 
 // GetBackupToCommandFetcher returns function that copies all bytes from backup to cmd's stdin
-func GetBackupToCommandFetcher(cmd *exec.Cmd) func(folder storage.Folder, backup Backup) {
-	return func(folder storage.Folder, backup Backup) {
+func GetBackupToCommandFetcher(cmd *exec.Cmd) Fetcher {
+	return func(folder storage.Folder, backup Backup) error {
 		stdin, err := cmd.StdinPipe()
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
 		stderr := &bytes.Buffer{}
 		cmd.Stderr = stderr
 		err = cmd.Start()
-		tracelog.ErrorLogger.FatalfOnError("Failed to start restore command: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to start restore command: %w", err)
+		}
 
 		fetcher, err := GetBackupStreamFetcher(backup)
-		tracelog.ErrorLogger.FatalfOnError("Failed to detect backup format: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to detect backup format: %w", err)
+		}
 
 		err = fetcher(backup, stdin)
 
@@ -50,12 +57,19 @@ func GetBackupToCommandFetcher(cmd *exec.Cmd) func(folder storage.Folder, backup
 			}
 			err = cmdErr
 		}
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
+		return nil
 	}
 }
 
 // StreamBackupToCommandStdin downloads and decompresses backup stream to cmd stdin.
 func StreamBackupToCommandStdin(cmd *exec.Cmd, backup Backup) error {
+	fetcher, err := GetBackupStreamFetcher(backup)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect backup format")
+	}
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("failed to fetch backup: %v", err)
@@ -65,7 +79,7 @@ func StreamBackupToCommandStdin(cmd *exec.Cmd, backup Backup) error {
 	if err != nil {
 		return fmt.Errorf("failed to start command: %v", err)
 	}
-	err = DownloadAndDecompressStream(backup, stdin)
+	err = fetcher(backup, stdin)
 	if err != nil {
 		return errors.Wrap(err, "failed to download and decompress stream")
 	}
@@ -79,14 +93,22 @@ func StreamBackupToCommandStdin(cmd *exec.Cmd, backup Backup) error {
 	return nil
 }
 
-type Fetcher func(rootFolder storage.Folder, backup Backup)
+type Fetcher func(rootFolder storage.Folder, backup Backup) error
 
 // TODO : unit tests
 // HandleBackupFetch is invoked to perform wal-g backup-fetch
-func HandleBackupFetch(folder storage.Folder, targetBackupSelector BackupSelector, fetcher Fetcher) {
+func HandleBackupFetch(folder storage.Folder, targetBackupSelector BackupSelector, fetcher Fetcher) (err error) {
 	backup, err := targetBackupSelector.Select(folder)
-	tracelog.ErrorLogger.FatalfOnError("Failed to select backup: %v\n", err)
+	if err != nil {
+		return fmt.Errorf("failed to select backup: %w", err)
+	}
 	tracelog.DebugLogger.Printf("HandleBackupFetch(%s)\n", backup.Name)
 
-	fetcher(folder, backup)
+	notification, err := notify.Start(notify.BackupFetch, notify.WithBackupName(backup.Name))
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
+	return fetcher(folder, backup)
 }
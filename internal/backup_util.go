@@ -149,6 +149,9 @@ func GetGarbageFromPrefix(folders []storage.Folder, nonGarbage []BackupTime) []s
 	}
 	for _, folder := range folders {
 		backupName := utility.StripPrefixName(folder.GetPath())
+		if IsDedupChunkStore(backupName) {
+			continue
+		}
 		if _, ok := keyFilter[backupName]; ok {
 			continue
 		}
@@ -238,27 +241,29 @@ func SplitPurgingBackups(backups []TimedBackup,
 
 // DeleteGarbage purges given garbage keys
 func DeleteGarbage(folder storage.Folder, garbage []string) error {
-	var keys []string
+	groups := make([][]string, 0, len(garbage))
 	for _, prefix := range garbage {
 		garbageObjects, err := storage.ListFolderRecursively(folder.GetSubFolder(prefix))
 		if err != nil {
 			return err
 		}
+		keys := make([]string, 0, len(garbageObjects))
 		for _, obj := range garbageObjects {
 			keys = append(keys, path.Join(prefix, obj.GetName()))
 		}
+		groups = append(groups, keys)
 	}
-	tracelog.DebugLogger.Printf("Garbage keys will be deleted: %+v\n", keys)
-	return folder.DeleteObjects(keys)
+	tracelog.DebugLogger.Printf("Garbage keys will be deleted: %+v\n", groups)
+	return DeleteObjectGroups(folder, groups)
 }
 
 // DeleteBackups purges given backups files
 // TODO: extract BackupLayout abstraction and provide DataPath(), SentinelPath(), Exists() methods
 func DeleteBackups(folder storage.Folder, backups []string) error {
-	keys := make([]string, 0, len(backups)*2)
+	groups := make([][]string, 0, len(backups))
 	for i := range backups {
 		backupName := backups[i]
-		keys = append(keys, SentinelNameFromBackup(backupName))
+		keys := []string{SentinelNameFromBackup(backupName)}
 
 		dataObjects, err := storage.ListFolderRecursively(folder.GetSubFolder(backupName))
 		if err != nil {
@@ -267,11 +272,9 @@ func DeleteBackups(folder storage.Folder, backups []string) error {
 		for _, obj := range dataObjects {
 			keys = append(keys, path.Join(backupName, obj.GetName()))
 		}
+		groups = append(groups, keys)
 	}
 
-	tracelog.DebugLogger.Printf("Backup keys will be deleted: %+v\n", keys)
-	if err := folder.DeleteObjects(keys); err != nil {
-		return err
-	}
-	return nil
+	tracelog.DebugLogger.Printf("Backup keys will be deleted: %+v\n", groups)
+	return DeleteObjectGroups(folder, groups)
 }
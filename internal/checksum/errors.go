@@ -0,0 +1,27 @@
+package checksum
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+)
+
+// ObjectCorruptedError is returned when the content of an object read from the storage
+// doesn't match the checksum stored on upload
+type ObjectCorruptedError struct {
+	error
+	ObjectPath string
+}
+
+func NewObjectCorruptedError(objectPath, expected, actual string) ObjectCorruptedError {
+	return ObjectCorruptedError{
+		error: errors.Errorf("object '%s' is corrupted: expected sha256 checksum %s, got %s",
+			objectPath, expected, actual),
+		ObjectPath: objectPath,
+	}
+}
+
+func (err ObjectCorruptedError) Error() string {
+	return fmt.Sprintf(tracelog.GetErrorFormatter(), err.error)
+}
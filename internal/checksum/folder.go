@@ -0,0 +1,169 @@
+package checksum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// SidecarSuffix is appended to an object name to get the name of the object storing its checksum
+const SidecarSuffix = ".sha256"
+
+// Folder computes the SHA-256 checksum of every uploaded object and stores it in a sidecar object
+// next to it. When an object is read till EOF, its content is verified against the stored checksum.
+// Objects uploaded without a checksum (e.g. before the wrapper was enabled) are read without verification.
+type Folder struct {
+	storage.Folder
+}
+
+func NewFolder(folder storage.Folder) *Folder {
+	return &Folder{Folder: folder}
+}
+
+func SidecarName(objectRelativePath string) string {
+	return objectRelativePath + SidecarSuffix
+}
+
+func IsSidecarName(objectRelativePath string) bool {
+	return strings.HasSuffix(objectRelativePath, SidecarSuffix)
+}
+
+func (folder *Folder) GetSubFolder(subFolderRelativePath string) storage.Folder {
+	return NewFolder(folder.Folder.GetSubFolder(subFolderRelativePath))
+}
+
+// ListFolder lists the folder hiding the checksum sidecars
+func (folder *Folder) ListFolder() (objects []storage.Object, subFolders []storage.Folder, err error) {
+	allObjects, allSubFolders, err := folder.Folder.ListFolder()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, object := range allObjects {
+		if IsSidecarName(object.GetName()) {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	for _, subFolder := range allSubFolders {
+		subFolders = append(subFolders, NewFolder(subFolder))
+	}
+	return objects, subFolders, nil
+}
+
+func (folder *Folder) DeleteObjects(objectRelativePaths []string) error {
+	paths := make([]string, 0, 2*len(objectRelativePaths))
+	for _, objectPath := range objectRelativePaths {
+		paths = append(paths, objectPath, SidecarName(objectPath))
+	}
+	return folder.Folder.DeleteObjects(paths)
+}
+
+func (folder *Folder) ReadObject(objectRelativePath string) (io.ReadCloser, error) {
+	expected, err := folder.readChecksum(objectRelativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	readCloser, err := folder.Folder.ReadObject(objectRelativePath)
+	if err != nil {
+		return nil, err
+	}
+	if expected == "" {
+		tracelog.DebugLogger.Printf("No checksum stored for %q, skipping verification", objectRelativePath)
+		return readCloser, nil
+	}
+	return newVerifyingReader(readCloser, objectRelativePath, expected), nil
+}
+
+func (folder *Folder) PutObject(name string, content io.Reader) error {
+	return folder.PutObjectWithContext(context.Background(), name, content)
+}
+
+// PutObjectWithContext uploads the object and then its checksum. The checksum of the overwritten object
+// is deleted first, so it doesn't fail the reads of the new one if the new checksum fails to be stored.
+func (folder *Folder) PutObjectWithContext(ctx context.Context, name string, content io.Reader) error {
+	err := folder.Folder.DeleteObjects([]string{SidecarName(name)})
+	if err != nil {
+		return fmt.Errorf("delete the old checksum of %q: %w", name, err)
+	}
+
+	calculator := CreateCalculator()
+	err = folder.Folder.PutObjectWithContext(ctx, name, CreateReaderWithChecksum(content, calculator))
+	if err != nil {
+		return err
+	}
+
+	err = folder.Folder.PutObjectWithContext(ctx, SidecarName(name), strings.NewReader(calculator.Checksum()))
+	if err != nil {
+		return fmt.Errorf("upload checksum of %q: %w", name, err)
+	}
+	return nil
+}
+
+func (folder *Folder) CopyObject(srcPath string, dstPath string) error {
+	err := folder.Folder.CopyObject(srcPath, dstPath)
+	if err != nil {
+		return err
+	}
+
+	err = folder.Folder.CopyObject(SidecarName(srcPath), SidecarName(dstPath))
+	if _, ok := err.(storage.ObjectNotFoundError); ok {
+		// the source object has no checksum, so the copy doesn't have it too
+		return folder.Folder.DeleteObjects([]string{SidecarName(dstPath)})
+	}
+	if err != nil {
+		return fmt.Errorf("copy checksum of %q: %w", srcPath, err)
+	}
+	return nil
+}
+
+// readChecksum returns the stored checksum of the object or an empty string if there is no one
+func (folder *Folder) readChecksum(objectRelativePath string) (string, error) {
+	sidecar, err := folder.Folder.ReadObject(SidecarName(objectRelativePath))
+	if _, ok := err.(storage.ObjectNotFoundError); ok {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read checksum of %q: %w", objectRelativePath, err)
+	}
+	defer sidecar.Close()
+
+	checksum, err := io.ReadAll(sidecar)
+	if err != nil {
+		return "", fmt.Errorf("read checksum of %q: %w", objectRelativePath, err)
+	}
+	return strings.TrimSpace(string(checksum)), nil
+}
+
+type verifyingReader struct {
+	io.ReadCloser
+	calculator *Calculator
+	objectPath string
+	expected   string
+}
+
+func newVerifyingReader(readCloser io.ReadCloser, objectPath, expected string) *verifyingReader {
+	return &verifyingReader{
+		ReadCloser: readCloser,
+		calculator: CreateCalculator(),
+		objectPath: objectPath,
+		expected:   expected,
+	}
+}
+
+func (reader *verifyingReader) Read(p []byte) (n int, err error) {
+	n, err = reader.ReadCloser.Read(p)
+	reader.calculator.AddData(p[:n])
+	if errors.Is(err, io.EOF) {
+		actual := reader.calculator.Checksum()
+		if actual != reader.expected {
+			return n, NewObjectCorruptedError(reader.objectPath, reader.expected, actual)
+		}
+	}
+	return n, err
+}
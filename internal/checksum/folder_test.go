@@ -0,0 +1,126 @@
+package checksum_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+func TestChecksumFolder(t *testing.T) {
+	storage.RunFolderTest(checksum.NewFolder(memory.NewFolder("in_memory/", memory.NewKVS())), t)
+}
+
+func TestChecksumFolder_StoresSidecar(t *testing.T) {
+	baseFolder := memory.NewFolder("in_memory/", memory.NewKVS())
+	folder := checksum.NewFolder(baseFolder)
+
+	err := folder.PutObject("sub/object", strings.NewReader("data"))
+	require.NoError(t, err)
+
+	exists, err := baseFolder.Exists("sub/object" + checksum.SidecarSuffix)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	objects, err := storage.ListFolderRecursively(folder)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "sub/object", objects[0].GetName())
+
+	err = folder.DeleteObjects([]string{"sub/object"})
+	require.NoError(t, err)
+	exists, err = baseFolder.Exists("sub/object" + checksum.SidecarSuffix)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestChecksumFolder_DetectsCorruption(t *testing.T) {
+	baseFolder := memory.NewFolder("in_memory/", memory.NewKVS())
+	folder := checksum.NewFolder(baseFolder)
+
+	err := folder.PutObject("object", strings.NewReader("original data"))
+	require.NoError(t, err)
+	err = baseFolder.PutObject("object", strings.NewReader("corrupted data"))
+	require.NoError(t, err)
+
+	reader, err := folder.ReadObject("object")
+	require.NoError(t, err)
+	_, err = io.ReadAll(reader)
+	var corruptedErr checksum.ObjectCorruptedError
+	assert.ErrorAs(t, err, &corruptedErr)
+	assert.Equal(t, "object", corruptedErr.ObjectPath)
+}
+
+func TestChecksumFolder_ReadsObjectWithoutChecksum(t *testing.T) {
+	baseFolder := memory.NewFolder("in_memory/", memory.NewKVS())
+	folder := checksum.NewFolder(baseFolder)
+
+	err := baseFolder.PutObject("legacy", bytes.NewBufferString("data"))
+	require.NoError(t, err)
+
+	reader, err := folder.ReadObject("legacy")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+func TestChecksumFolder_CopyObject(t *testing.T) {
+	baseFolder := memory.NewFolder("in_memory/", memory.NewKVS())
+	folder := checksum.NewFolder(baseFolder)
+
+	err := folder.PutObject("src", strings.NewReader("data"))
+	require.NoError(t, err)
+	err = folder.CopyObject("src", "dst")
+	require.NoError(t, err)
+
+	exists, err := baseFolder.Exists("dst" + checksum.SidecarSuffix)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	reader, err := folder.ReadObject("dst")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+// failingSidecarFolder fails the uploads of the checksum sidecars
+type failingSidecarFolder struct {
+	storage.Folder
+}
+
+func (folder *failingSidecarFolder) PutObjectWithContext(ctx context.Context, name string, content io.Reader) error {
+	if checksum.IsSidecarName(name) {
+		return errors.New("sidecar upload failed")
+	}
+	return folder.Folder.PutObjectWithContext(ctx, name, content)
+}
+
+func TestChecksumFolder_OverwriteDropsOldChecksum(t *testing.T) {
+	baseFolder := memory.NewFolder("in_memory/", memory.NewKVS())
+	err := checksum.NewFolder(baseFolder).PutObject("object", strings.NewReader("original data"))
+	require.NoError(t, err)
+
+	folder := checksum.NewFolder(&failingSidecarFolder{Folder: baseFolder})
+	err = folder.PutObject("object", strings.NewReader("new data"))
+	assert.Error(t, err)
+
+	exists, err := baseFolder.Exists("object" + checksum.SidecarSuffix)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	reader, err := folder.ReadObject("object")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "new data", string(data))
+}
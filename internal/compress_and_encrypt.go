@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"io"
 
@@ -8,7 +9,9 @@ import (
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/tracing"
 	"github.com/wal-g/wal-g/utility"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CompressAndEncryptError is used to catch specific errors from CompressAndEncrypt
@@ -28,6 +31,13 @@ func (err CompressAndEncryptError) Error() string {
 // CompressAndEncrypt compresses input to a pipe reader. Output must be used or
 // pipe will block.
 func CompressAndEncrypt(source io.Reader, compressor compression.Compressor, crypter crypto.Crypter) io.Reader {
+	return CompressAndEncryptNamed(source, compressor, crypter, "")
+}
+
+// CompressAndEncryptNamed is CompressAndEncrypt which names the stream for the adaptive compression,
+// so the level chosen for it is recorded
+func CompressAndEncryptNamed(source io.Reader, compressor compression.Compressor, crypter crypto.Crypter,
+	name string) io.Reader {
 	compressedReader, dstWriter := io.Pipe()
 
 	var writeCloser io.WriteCloser = dstWriter
@@ -43,13 +53,17 @@ func CompressAndEncrypt(source io.Reader, compressor compression.Compressor, cry
 	var compressedWriter io.WriteCloser
 	if compressor != nil {
 		writeIgnorer := &utility.EmptyWriteIgnorer{Writer: writeCloser}
-		compressedWriter = compressor.NewWriter(writeIgnorer)
+		compressedWriter = newNamedCompressedWriter(compressor, writeIgnorer, name)
 	} else {
 		compressedWriter = writeCloser
 	}
 
 	go func() {
-		_, err := utility.FastCopy(compressedWriter, source)
+		_, span := tracing.Start(context.Background(), "compress_and_encrypt",
+			compressAndEncryptAttributes(compressor, crypter)...)
+		written, err := utility.FastCopy(compressedWriter, source)
+		span.SetAttributes(tracing.Bytes(written))
+		tracing.End(span, err)
 
 		if err != nil {
 			e := newCompressingPipeWriterError("CompressAndEncrypt: compression failed")
@@ -74,3 +88,37 @@ func CompressAndEncrypt(source io.Reader, compressor compression.Compressor, cry
 	}()
 	return compressedReader
 }
+
+func newNamedCompressedWriter(compressor compression.Compressor, writer io.Writer, name string) io.WriteCloser {
+	if adaptive, ok := compressor.(compression.AdaptiveCompressor); ok && name != "" {
+		return adaptive.NewNamedWriter(writer, name)
+	}
+	return compressor.NewWriter(writer)
+}
+
+// AdaptiveCompressionLevels returns the levels chosen by the adaptive compression for the named streams
+// since the last ResetAdaptiveCompressionLevels, nil for the other compression methods
+func AdaptiveCompressionLevels(uploader Uploader) map[string]string {
+	if adaptive, ok := uploader.Compression().(compression.AdaptiveCompressor); ok {
+		return adaptive.Levels()
+	}
+	return nil
+}
+
+// ResetAdaptiveCompressionLevels is called when a backup starts, so its metadata lists only the levels of its streams
+func ResetAdaptiveCompressionLevels(uploader Uploader) {
+	if adaptive, ok := uploader.Compression().(compression.AdaptiveCompressor); ok {
+		adaptive.ResetLevels()
+	}
+}
+
+func compressAndEncryptAttributes(compressor compression.Compressor, crypter crypto.Crypter) []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	if compressor != nil {
+		attributes = append(attributes, tracing.CompressionMethod(compressor.FileExtension()))
+	}
+	if crypter != nil {
+		attributes = append(attributes, tracing.Encryption(crypter.Name()))
+	}
+	return attributes
+}
@@ -2,6 +2,7 @@ package compression
 
 import (
 	"io"
+	"time"
 )
 
 type Compressor interface {
@@ -9,6 +10,21 @@ type Compressor interface {
 	FileExtension() string
 }
 
+// AdaptiveCompressor is implemented by the compressors which choose the compression level of each stream on the fly
+type AdaptiveCompressor interface {
+	Compressor
+	// NewNamedWriter is NewWriter which remembers the level chosen for the named stream
+	NewNamedWriter(writer io.Writer, name string) io.WriteCloser
+	// ReportUpload feeds the upload statistics: the size of the uploaded compressed data
+	// and the time the upload took without waiting for the data
+	ReportUpload(size int64, elapsed time.Duration)
+	// Levels returns the compression levels chosen for the named streams
+	Levels() map[string]string
+	// ResetLevels forgets the levels of the named streams, it is called when a new backup starts
+	// because the compressor is shared by all the backups made by the process
+	ResetLevels()
+}
+
 type Decompressor interface {
 	Decompress(src io.Reader) (io.ReadCloser, error)
 	FileExtension() string
@@ -0,0 +1,48 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// magicNumbers are the leading bytes of the compressed data by the file extension.
+// The lzma and brotli data doesn't have any.
+var magicNumbers = map[string][]byte{
+	"lz4": {0x04, 0x22, 0x4d, 0x18},
+	"zst": {0x28, 0xb5, 0x2f, 0xfd},
+	"gz":  {0x1f, 0x8b},
+	"lzo": {0x89, 0x4c, 0x5a, 0x4f, 0x00, 0x0d, 0x0a, 0x1a, 0x0a},
+}
+
+const maxMagicNumberLen = 9
+
+// DetectDecompressor chooses the decompressor by the magic number of the data. The file extension is trusted
+// when the data has no magic number of a known format, or when the format of the extension has no magic number
+// at all (so the matching one might be accidental). The returned reader must be used instead of src, since
+// the beginning of the data is already read from it.
+func DetectDecompressor(src io.Reader, fileExtension string) (Decompressor, io.Reader, error) {
+	bufferedSrc := bufio.NewReader(src)
+	header, err := bufferedSrc.Peek(maxMagicNumberLen)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	byExtension := FindDecompressor(fileExtension)
+	if byExtension != nil {
+		if _, hasMagic := magicNumbers[byExtension.FileExtension()]; !hasMagic {
+			return byExtension, bufferedSrc, nil
+		}
+	}
+	for _, decompressor := range Decompressors {
+		magic, ok := magicNumbers[decompressor.FileExtension()]
+		if ok && bytes.HasPrefix(header, magic) {
+			return decompressor, bufferedSrc, nil
+		}
+	}
+	if byExtension != nil {
+		return byExtension, bufferedSrc, nil
+	}
+	return nil, nil, fmt.Errorf("can't detect the compression method of the data with extension %q", fileExtension)
+}
@@ -0,0 +1,59 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/compression/lzma"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
+)
+
+func compress(t *testing.T, compressor Compressor, data []byte) []byte {
+	var compressed bytes.Buffer
+	writer := compressor.NewWriter(&compressed)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return compressed.Bytes()
+}
+
+func TestDetectDecompressor(t *testing.T) {
+	data := bytes.Repeat([]byte("wal-g"), 100)
+
+	tests := []struct {
+		name              string
+		compressor        Compressor
+		fileExtension     string
+		expectedExtension string
+	}{
+		{"matching extension", lz4.Compressor{}, "lz4", "lz4"},
+		{"wrong extension", zstd.Compressor{}, "lz4", "zst"},
+		{"unknown extension", lz4.Compressor{}, "tar", "lz4"},
+		{"no magic number", lzma.Compressor{}, "lzma", "lzma"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compressed := compress(t, test.compressor, data)
+
+			decompressor, reader, err := DetectDecompressor(bytes.NewReader(compressed), test.fileExtension)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedExtension, decompressor.FileExtension())
+
+			decompressed, err := decompressor.Decompress(reader)
+			require.NoError(t, err)
+			content, err := io.ReadAll(decompressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, content)
+		})
+	}
+}
+
+func TestDetectDecompressor_Unknown(t *testing.T) {
+	_, _, err := DetectDecompressor(bytes.NewReader([]byte("plain text")), "txt")
+
+	assert.Error(t, err)
+}
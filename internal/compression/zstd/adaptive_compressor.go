@@ -0,0 +1,187 @@
+package zstd
+
+import (
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/limiters"
+)
+
+const AdaptiveAlgorithmName = "adaptive"
+
+const (
+	// adaptiveMinSampleSize is the minimal amount of the compressed and the uploaded data which
+	// the throughput is measured on, the level is kept until both samples are collected
+	adaptiveMinSampleSize = 1 << 20
+	// adaptiveThroughputRatio is how many times one of the compression and upload has to be slower
+	// than the other one to move the level, it keeps the level stable when they are balanced
+	adaptiveThroughputRatio = 2
+)
+
+// throughputSample is the amount of data processed and the time it took
+type throughputSample struct {
+	size    int64
+	elapsed time.Duration
+}
+
+func (sample throughputSample) rate() float64 {
+	if sample.elapsed <= 0 {
+		return math.Inf(1)
+	}
+	return float64(sample.size) / sample.elapsed.Seconds()
+}
+
+// AdaptiveCompressor is the zstd compressor which chooses the compression level of each stream
+// on the fly. It compares the compression throughput, measured without the time the compressed data
+// waits for the upload, with the upload throughput reported by the uploader and capped by the network
+// limiter. When a new stream starts, the level is raised if the upload is the bottleneck
+// and lowered if the compression is.
+type AdaptiveCompressor struct {
+	mutex      sync.Mutex
+	level      zstd.EncoderLevel
+	levels     map[string]string
+	compressed throughputSample
+	uploaded   throughputSample
+}
+
+func NewAdaptiveCompressor() *AdaptiveCompressor {
+	return &AdaptiveCompressor{level: zstd.SpeedDefault, levels: map[string]string{}}
+}
+
+func (compressor *AdaptiveCompressor) NewWriter(writer io.Writer) io.WriteCloser {
+	return compressor.NewNamedWriter(writer, "")
+}
+
+// NewNamedWriter is NewWriter which remembers the level chosen for the named stream
+func (compressor *AdaptiveCompressor) NewNamedWriter(writer io.Writer, name string) io.WriteCloser {
+	compressor.mutex.Lock()
+	compressor.adjustLevel()
+	level := compressor.level
+	if name != "" {
+		compressor.levels[name] = level.String()
+	}
+	compressor.mutex.Unlock()
+
+	dst := &timedWriter{Writer: writer}
+	// the synchronous encoding makes the time spent in Write the compression time plus the upload wait
+	zw, err := zstd.NewWriter(dst, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		panic(err)
+	}
+	return &adaptiveWriter{encoder: zw, dst: dst, compressor: compressor}
+}
+
+// ReportUpload adds the uploaded compressed data and the time the upload took to the upload throughput
+func (compressor *AdaptiveCompressor) ReportUpload(size int64, elapsed time.Duration) {
+	compressor.mutex.Lock()
+	defer compressor.mutex.Unlock()
+	compressor.uploaded.size += size
+	compressor.uploaded.elapsed += elapsed
+}
+
+// Levels returns the compression levels chosen for the named streams
+func (compressor *AdaptiveCompressor) Levels() map[string]string {
+	compressor.mutex.Lock()
+	defer compressor.mutex.Unlock()
+	levels := make(map[string]string, len(compressor.levels))
+	for name, level := range compressor.levels {
+		levels[name] = level
+	}
+	return levels
+}
+
+// ResetLevels forgets the levels of the named streams, the current level is kept for the next streams
+func (compressor *AdaptiveCompressor) ResetLevels() {
+	compressor.mutex.Lock()
+	defer compressor.mutex.Unlock()
+	compressor.levels = map[string]string{}
+}
+
+func (compressor *AdaptiveCompressor) FileExtension() string {
+	return FileExtension
+}
+
+func (compressor *AdaptiveCompressor) addCompressed(size int64, elapsed time.Duration) {
+	compressor.mutex.Lock()
+	defer compressor.mutex.Unlock()
+	compressor.compressed.size += size
+	compressor.compressed.elapsed += elapsed
+}
+
+// adjustLevel moves the level by the compression and upload throughput since the previous move,
+// the caller must hold the mutex
+func (compressor *AdaptiveCompressor) adjustLevel() {
+	if compressor.compressed.size < adaptiveMinSampleSize {
+		return
+	}
+	uploadRate := math.Inf(1)
+	if compressor.uploaded.size >= adaptiveMinSampleSize {
+		uploadRate = compressor.uploaded.rate()
+	}
+	if limiters.NetworkLimiter != nil {
+		uploadRate = math.Min(uploadRate, float64(limiters.NetworkLimiter.Limit()))
+	}
+	if math.IsInf(uploadRate, 1) {
+		// neither the upload statistics nor the network limit are known yet
+		return
+	}
+	compressionRate := compressor.compressed.rate()
+
+	prevLevel := compressor.level
+	switch {
+	case compressionRate > uploadRate*adaptiveThroughputRatio && compressor.level < zstd.SpeedBestCompression:
+		compressor.level++
+	case uploadRate > compressionRate*adaptiveThroughputRatio && compressor.level > zstd.SpeedFastest:
+		compressor.level--
+	}
+	if compressor.level != prevLevel {
+		tracelog.DebugLogger.Printf("Compression throughput is %.0f B/s, upload throughput is %.0f B/s, "+
+			"switching zstd level from %s to %s", compressionRate, uploadRate, prevLevel, compressor.level)
+	}
+	compressor.compressed = throughputSample{}
+	compressor.uploaded = throughputSample{}
+}
+
+type adaptiveWriter struct {
+	encoder    *zstd.Encoder
+	dst        *timedWriter
+	compressor *AdaptiveCompressor
+	elapsed    time.Duration
+}
+
+func (writer *adaptiveWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := writer.encoder.Write(p)
+	writer.elapsed += time.Since(start)
+	return n, err
+}
+
+func (writer *adaptiveWriter) Close() error {
+	start := time.Now()
+	err := writer.encoder.Close()
+	writer.elapsed += time.Since(start)
+	if err != nil {
+		return err
+	}
+	writer.compressor.addCompressed(writer.dst.size, writer.elapsed-writer.dst.elapsed)
+	return nil
+}
+
+// timedWriter measures the size written to the underlying writer and the time spent waiting for it
+type timedWriter struct {
+	io.Writer
+	size    int64
+	elapsed time.Duration
+}
+
+func (writer *timedWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := writer.Writer.Write(p)
+	writer.elapsed += time.Since(start)
+	writer.size += int64(n)
+	return n, err
+}
@@ -0,0 +1,94 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/limiters"
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptiveCompressor_AdjustLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         zstd.EncoderLevel
+		compressed    throughputSample
+		uploaded      throughputSample
+		expectedLevel zstd.EncoderLevel
+	}{
+		{"upload is the bottleneck", zstd.SpeedDefault,
+			throughputSample{10 << 20, time.Second}, throughputSample{10 << 20, 3 * time.Second}, zstd.SpeedBetterCompression},
+		{"compression is the bottleneck", zstd.SpeedDefault,
+			throughputSample{10 << 20, 3 * time.Second}, throughputSample{10 << 20, time.Second}, zstd.SpeedFastest},
+		{"balanced", zstd.SpeedDefault,
+			throughputSample{10 << 20, time.Second}, throughputSample{10 << 20, time.Second}, zstd.SpeedDefault},
+		{"highest level", zstd.SpeedBestCompression,
+			throughputSample{10 << 20, time.Second}, throughputSample{10 << 20, 3 * time.Second}, zstd.SpeedBestCompression},
+		{"lowest level", zstd.SpeedFastest,
+			throughputSample{10 << 20, 3 * time.Second}, throughputSample{10 << 20, time.Second}, zstd.SpeedFastest},
+		{"too little compressed", zstd.SpeedDefault,
+			throughputSample{1 << 10, time.Second}, throughputSample{10 << 20, 3 * time.Second}, zstd.SpeedDefault},
+		{"nothing uploaded", zstd.SpeedDefault,
+			throughputSample{10 << 20, time.Second}, throughputSample{}, zstd.SpeedDefault},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compressor := NewAdaptiveCompressor()
+			compressor.level = test.level
+			compressor.compressed = test.compressed
+			compressor.uploaded = test.uploaded
+
+			compressor.adjustLevel()
+
+			assert.Equal(t, test.expectedLevel, compressor.level)
+		})
+	}
+}
+
+func TestAdaptiveCompressor_NetworkLimiter(t *testing.T) {
+	limiters.NetworkLimiter = rate.NewLimiter(rate.Limit(1<<20), 1<<20)
+	defer func() { limiters.NetworkLimiter = nil }()
+
+	// the upload is throttled to 1 MiB/s before any upload is reported
+	compressor := NewAdaptiveCompressor()
+	compressor.compressed = throughputSample{10 << 20, time.Second}
+	compressor.adjustLevel()
+	assert.Equal(t, zstd.SpeedBetterCompression, compressor.level)
+	assert.Equal(t, throughputSample{}, compressor.compressed)
+}
+
+func TestAdaptiveCompressor_SlowUpload(t *testing.T) {
+	data := make([]byte, 4<<20)
+	rand.New(rand.NewSource(0)).Read(data)
+	compressor := NewAdaptiveCompressor()
+
+	var compressed bytes.Buffer
+	writer := compressor.NewNamedWriter(&compressed, "part_001.tar.zst")
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	compressor.ReportUpload(int64(compressed.Len()), time.Hour)
+
+	writer = compressor.NewNamedWriter(io.Discard, "part_002.tar.zst")
+	require.NoError(t, writer.Close())
+	assert.Equal(t, map[string]string{
+		"part_001.tar.zst": zstd.SpeedDefault.String(),
+		"part_002.tar.zst": zstd.SpeedBetterCompression.String(),
+	}, compressor.Levels())
+
+	compressor.ResetLevels()
+	assert.Empty(t, compressor.Levels())
+	assert.Equal(t, zstd.SpeedBetterCompression, compressor.level)
+
+	reader, err := Decompressor{}.Decompress(&compressed)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+}
@@ -8,4 +8,6 @@ func init() {
 	Decompressors = append(Decompressors, zstd.Decompressor{})
 	Compressors[zstd.AlgorithmName] = zstd.Compressor{}
 	CompressingAlgorithms = append(CompressingAlgorithms, zstd.AlgorithmName)
+	Compressors[zstd.AdaptiveAlgorithmName] = zstd.NewAdaptiveCompressor()
+	CompressingAlgorithms = append(CompressingAlgorithms, zstd.AdaptiveAlgorithmName)
 }
@@ -41,6 +41,7 @@ const (
 	DeltaOriginSetting            = "WALG_DELTA_ORIGIN"
 	CompressionMethodSetting      = "WALG_COMPRESSION_METHOD"
 	StoragePrefixSetting          = "WALG_STORAGE_PREFIX"
+	StoreObjectChecksumsSetting   = "WALG_STORE_OBJECT_CHECKSUMS"
 	DiskRateLimitSetting          = "WALG_DISK_RATE_LIMIT"
 	NetworkRateLimitSetting       = "WALG_NETWORK_RATE_LIMIT"
 	UseWalDeltaSetting            = "WALG_USE_WAL_DELTA"
@@ -63,6 +64,10 @@ const (
 	LibsodiumKeySetting           = "WALG_LIBSODIUM_KEY"
 	LibsodiumKeyPathSetting       = "WALG_LIBSODIUM_KEY_PATH"
 	LibsodiumKeyTransform         = "WALG_LIBSODIUM_KEY_TRANSFORM"
+	AgeRecipientsSetting          = "WALG_AGE_RECIPIENTS"
+	AgeRecipientsPathSetting      = "WALG_AGE_RECIPIENTS_PATH"
+	AgeIdentitySetting            = "WALG_AGE_IDENTITY"
+	AgeIdentityPathSetting        = "WALG_AGE_IDENTITY_PATH"
 	GpgKeyIDSetting               = "GPG_KEY_ID"
 	PgpKeySetting                 = "WALG_PGP_KEY"
 	PgpKeyPathSetting             = "WALG_PGP_KEY_PATH"
@@ -74,6 +79,17 @@ const (
 	PgpEnvelopeYcEndpointSetting  = "WALG_ENVELOPE_PGP_YC_ENDPOINT"
 	PgpEnvelopeCacheExpiration    = "WALG_ENVELOPE_CACHE_EXPIRATION"
 
+	PgpEnvelopeVaultAddressSetting      = "WALG_ENVELOPE_PGP_VAULT_ADDR"
+	PgpEnvelopeVaultNamespaceSetting    = "WALG_ENVELOPE_PGP_VAULT_NAMESPACE"
+	PgpEnvelopeVaultCACertSetting       = "WALG_ENVELOPE_PGP_VAULT_CACERT"
+	PgpEnvelopeVaultTimeoutSetting      = "WALG_ENVELOPE_PGP_VAULT_TIMEOUT"
+	PgpEnvelopeVaultTokenSetting        = "WALG_ENVELOPE_PGP_VAULT_TOKEN"
+	PgpEnvelopeVaultRoleIDSetting       = "WALG_ENVELOPE_PGP_VAULT_ROLE_ID"
+	PgpEnvelopeVaultSecretIDSetting     = "WALG_ENVELOPE_PGP_VAULT_SECRET_ID"
+	PgpEnvelopeVaultAppRoleMountSetting = "WALG_ENVELOPE_PGP_VAULT_APPROLE_MOUNT"
+	PgpEnvelopeVaultTransitMountSetting = "WALG_ENVELOPE_PGP_VAULT_TRANSIT_MOUNT"
+	PgpEnvelopeVaultKeyNameSetting      = "WALG_ENVELOPE_PGP_VAULT_KEY_NAME"
+
 	PgDataSetting                          = "PGDATA"
 	UserSetting                            = "USER" // TODO : do something with it
 	PgPortSetting                          = "PGPORT"
@@ -95,6 +111,8 @@ const (
 	StreamSplitterPartitions               = "WALG_STREAM_SPLITTER_PARTITIONS"
 	StreamSplitterBlockSize                = "WALG_STREAM_SPLITTER_BLOCK_SIZE"
 	StreamSplitterMaxFileSize              = "WALG_STREAM_SPLITTER_MAX_FILE_SIZE"
+	StreamDedupSetting                     = "WALG_STREAM_DEDUP"
+	StreamDedupAvgChunkSizeSetting         = "WALG_STREAM_DEDUP_AVG_CHUNK_SIZE"
 	StatsdAddressSetting                   = "WALG_STATSD_ADDRESS"
 	StatsdExtraTagsSetting                 = "WALG_STATSD_EXTRA_TAGS"
 	PgAliveCheckInterval                   = "WALG_ALIVE_CHECK_INTERVAL"
@@ -117,6 +135,19 @@ const (
 	ProfileMode          = "PROFILE_MODE"
 	ProfilePath          = "PROFILE_PATH"
 
+	TracingExporterSetting     = "WALG_TRACING_EXPORTER"
+	TracingOTLPEndpointSetting = "WALG_TRACING_OTLP_ENDPOINT"
+	TracingOTLPInsecureSetting = "WALG_TRACING_OTLP_INSECURE"
+	TracingFileSetting         = "WALG_TRACING_FILE"
+
+	WebhookURLsSetting     = "WALG_WEBHOOK_URLS"
+	WebhookSecretSetting   = "WALG_WEBHOOK_SECRET"
+	WebhookRetriesSetting  = "WALG_WEBHOOK_RETRIES"
+	WebhookTimeoutSetting  = "WALG_WEBHOOK_TIMEOUT"
+	PreHookCommandSetting  = "WALG_PRE_HOOK_COMMAND"
+	PostHookCommandSetting = "WALG_POST_HOOK_COMMAND"
+	HookOperationsSetting  = "WALG_HOOK_OPERATIONS"
+
 	MongoDBProvider                  = "MONGODB_PROVIDER"
 	MongoDBPath                      = "MONGODB_PATH"
 	MongoDBUriSetting                = "MONGODB_URI"
@@ -148,6 +179,8 @@ const (
 	MysqlBinlogServerPassword      = "WALG_MYSQL_BINLOG_SERVER_PASSWORD"
 	MysqlBinlogServerID            = "WALG_MYSQL_BINLOG_SERVER_ID"
 	MysqlBinlogServerReplicaSource = "WALG_MYSQL_BINLOG_SERVER_REPLICA_SOURCE"
+	MysqlBinlogServerPollInterval  = "WALG_MYSQL_BINLOG_SERVER_POLL_INTERVAL"
+	MysqlBinlogServerHeartbeat     = "WALG_MYSQL_BINLOG_SERVER_HEARTBEAT_INTERVAL"
 	MysqlBackupDownloadMaxRetry    = "WALG_BACKUP_DOWNLOAD_MAX_RETRY"
 	MysqlIncrementalBackupDst      = "WALG_MYSQL_INCREMENTAL_BACKUP_DST"
 	// Deprecated: unused
@@ -212,6 +245,11 @@ const (
 	SSHUsername       = "SSH_USERNAME"
 	SSHPrivateKeyPath = "SSH_PRIVATE_KEY_PATH"
 
+	WebDAVUsername   = "WEBDAV_USERNAME"
+	WebDAVPassword   = "WEBDAV_PASSWORD"
+	WebDAVCACertFile = "WEBDAV_CA_CERT_FILE"
+	WebDAVTimeout    = "WEBDAV_TIMEOUT"
+
 	SystemdNotifySocket = "NOTIFY_SOCKET"
 )
 
@@ -247,6 +285,16 @@ var (
 		PgFailoverStoragesCheckTimeout: "30s",
 		PgFailoverStorageCacheLifetime: "15m",
 		PgpEnvelopeCacheExpiration:     "0",
+		StoreObjectChecksumsSetting:    "false",
+		StreamDedupSetting:             "false",
+		StreamDedupAvgChunkSizeSetting: "1048576",
+		WebhookRetriesSetting:          "3",
+		WebhookTimeoutSetting:          "10s",
+		HookOperationsSetting:          "backup-push,backup-fetch,delete",
+
+		PgpEnvelopeVaultTimeoutSetting:      "30s",
+		PgpEnvelopeVaultAppRoleMountSetting: "approle",
+		PgpEnvelopeVaultTransitMountSetting: "transit",
 	}
 
 	MongoDefaultSettings = map[string]string{
@@ -261,9 +309,11 @@ var (
 	}
 
 	MysqlDefaultSettings = map[string]string{
-		StreamSplitterBlockSize:     "1048576",
-		MysqlBackupDownloadMaxRetry: "1",
-		MysqlIncrementalBackupDst:   "/tmp",
+		StreamSplitterBlockSize:       "1048576",
+		MysqlBackupDownloadMaxRetry:   "1",
+		MysqlIncrementalBackupDst:     "/tmp",
+		MysqlBinlogServerPollInterval: "10s",
+		MysqlBinlogServerHeartbeat:    "30s",
 	}
 
 	SQLServerDefaultSettings = map[string]string{
@@ -306,6 +356,7 @@ var (
 		DeltaOriginSetting:            true,
 		CompressionMethodSetting:      true,
 		StoragePrefixSetting:          true,
+		StoreObjectChecksumsSetting:   true,
 		DiskRateLimitSetting:          true,
 		NetworkRateLimitSetting:       true,
 		UseWalDeltaSetting:            true,
@@ -326,6 +377,10 @@ var (
 		LibsodiumKeySetting:           true,
 		LibsodiumKeyPathSetting:       true,
 		LibsodiumKeyTransform:         true,
+		AgeRecipientsSetting:          true,
+		AgeRecipientsPathSetting:      true,
+		AgeIdentitySetting:            true,
+		AgeIdentityPathSetting:        true,
 		TotalBgUploadedLimit:          true,
 		NameStreamCreateCmd:           true,
 		NameStreamRestoreCmd:          true,
@@ -345,10 +400,37 @@ var (
 		StatsdAddressSetting:          true,
 		StatsdExtraTagsSetting:        true,
 
+		StreamDedupSetting:             true,
+		StreamDedupAvgChunkSizeSetting: true,
+
+		PgpEnvelopeVaultAddressSetting:      true,
+		PgpEnvelopeVaultNamespaceSetting:    true,
+		PgpEnvelopeVaultCACertSetting:       true,
+		PgpEnvelopeVaultTimeoutSetting:      true,
+		PgpEnvelopeVaultTokenSetting:        true,
+		PgpEnvelopeVaultRoleIDSetting:       true,
+		PgpEnvelopeVaultSecretIDSetting:     true,
+		PgpEnvelopeVaultAppRoleMountSetting: true,
+		PgpEnvelopeVaultTransitMountSetting: true,
+		PgpEnvelopeVaultKeyNameSetting:      true,
+
 		ProfileSamplingRatio: true,
 		ProfileMode:          true,
 		ProfilePath:          true,
 
+		TracingExporterSetting:     true,
+		TracingOTLPEndpointSetting: true,
+		TracingOTLPInsecureSetting: true,
+		TracingFileSetting:         true,
+
+		WebhookURLsSetting:     true,
+		WebhookSecretSetting:   true,
+		WebhookRetriesSetting:  true,
+		WebhookTimeoutSetting:  true,
+		PreHookCommandSetting:  true,
+		PostHookCommandSetting: true,
+		HookOperationsSetting:  true,
+
 		// Swift
 		"WALG_SWIFT_PREFIX": true,
 		SwiftOsAuthURL:      true,
@@ -390,6 +472,10 @@ var (
 		"WALG_S3_RANGE_MAX_RETRIES":   true,
 		"WALG_S3_MAX_RETRIES":         true,
 
+		"WALG_S3_OBJECT_LOCK_MODE":       true,
+		"WALG_S3_OBJECT_LOCK_RETENTION":  true,
+		"WALG_S3_OBJECT_LOCK_LEGAL_HOLD": true,
+
 		// Azure
 		"WALG_AZ_PREFIX":         true,
 		AzureStorageAccount:      true,
@@ -414,6 +500,13 @@ var (
 		SSHUsername:       true,
 		SSHPrivateKeyPath: true,
 
+		// WebDAV
+		"WALG_WEBDAV_PREFIX": true,
+		WebDAVUsername:       true,
+		WebDAVPassword:       true,
+		WebDAVCACertFile:     true,
+		WebDAVTimeout:        true,
+
 		//File
 		"WALG_FILE_PREFIX": true,
 
@@ -502,6 +595,8 @@ var (
 		MysqlBinlogServerPassword:      true,
 		MysqlBinlogServerID:            true,
 		MysqlBinlogServerReplicaSource: true,
+		MysqlBinlogServerPollInterval:  true,
+		MysqlBinlogServerHeartbeat:     true,
 		MysqlBackupDownloadMaxRetry:    true,
 		MysqlIncrementalBackupDst:      true,
 	}
@@ -541,6 +636,7 @@ var (
 		AzureStorageSasToken:         true,
 		GoogleApplicationCredentials: true,
 		LibsodiumKeySetting:          true,
+		AgeIdentitySetting:           true,
 		PgPasswordSetting:            true,
 		PgpKeyPassphraseSetting:      true,
 		PgpKeySetting:                true,
@@ -549,6 +645,10 @@ var (
 		SQLServerConnectionString:    true,
 		SSHPassword:                  true,
 		SwiftOsPassword:              true,
+		WebDAVPassword:               true,
+
+		PgpEnvelopeVaultTokenSetting:    true,
+		PgpEnvelopeVaultSecretIDSetting: true,
 	}
 
 	complexSettings = map[string]bool{
@@ -906,10 +1006,14 @@ func GetFetchRetries() int {
 }
 
 func GetDurationSetting(setting string) (time.Duration, error) {
-	intervalStr, ok := GetSetting(setting)
-	if !ok {
+	return GetDurationSettingFrom(setting, viper.GetViper())
+}
+
+func GetDurationSettingFrom(setting string, config *viper.Viper) (time.Duration, error) {
+	if !config.IsSet(setting) {
 		return 0, NewUnsetRequiredSettingError(setting)
 	}
+	intervalStr := config.GetString(setting)
 	interval, err := time.ParseDuration(intervalStr)
 	if err != nil {
 		return 0, fmt.Errorf("duration expected for %s setting but given '%s': %w", setting, intervalStr, err)
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -59,3 +60,17 @@ func resetToDefaults() {
 	config.InitConfig()
 	config.Configure()
 }
+
+func TestGetDurationSettingFrom(t *testing.T) {
+	specificConfig := viper.New()
+	config.SetDefaultValues(specificConfig)
+	specificConfig.Set(config.PgpEnvelopeVaultTimeoutSetting, "5s")
+
+	actual, err := config.GetDurationSettingFrom(config.PgpEnvelopeVaultTimeoutSetting, specificConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, actual)
+
+	// the global config is not read
+	_, err = config.GetDurationSettingFrom(config.PgpEnvelopeVaultTimeoutSetting, viper.New())
+	assert.Error(t, err)
+}
@@ -17,15 +17,21 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
 	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/crypto/age"
 	"github.com/wal-g/wal-g/internal/crypto/awskms"
+	"github.com/wal-g/wal-g/internal/crypto/envelope"
 	cachenvlpr "github.com/wal-g/wal-g/internal/crypto/envelope/enveloper/cached"
+	vaultenvlpr "github.com/wal-g/wal-g/internal/crypto/envelope/enveloper/vault"
 	yckmsenvlpr "github.com/wal-g/wal-g/internal/crypto/envelope/enveloper/yckms"
 	envopenpgp "github.com/wal-g/wal-g/internal/crypto/envelope/openpgp"
 	"github.com/wal-g/wal-g/internal/crypto/openpgp"
+	"github.com/wal-g/wal-g/internal/dedup"
 	"github.com/wal-g/wal-g/internal/fsutil"
 	"github.com/wal-g/wal-g/internal/limiters"
+	"github.com/wal-g/wal-g/internal/tracing"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"golang.org/x/time/rate"
 )
@@ -109,6 +115,16 @@ func ConfigureStorage() (storage.HashableStorage, error) {
 			return NewLimitedFolder(prevFolder, limiters.NetworkLimiter)
 		})
 	}
+	if viper.GetBool(conf.StoreObjectChecksumsSetting) {
+		rootWraps = append(rootWraps, func(prevFolder storage.Folder) (newFolder storage.Folder) {
+			return checksum.NewFolder(prevFolder)
+		})
+	}
+	if tracing.Enabled() {
+		rootWraps = append(rootWraps, func(prevFolder storage.Folder) (newFolder storage.Folder) {
+			return tracing.NewFolder(prevFolder)
+		})
+	}
 	rootWraps = append(rootWraps, ConfigureStoragePrefix)
 
 	st, err := ConfigureStorageForSpecificConfig(viper.GetViper(), rootWraps...)
@@ -245,20 +261,56 @@ func ConfigureUploaderWithoutCompressor() (Uploader, error) {
 	return uploader, err
 }
 
+// ConfigureSplitUploader configures the uploader for the stream backups which may be split into partitions.
+// The deduplication of WALG_STREAM_DEDUP applies only to the single stream, so it can't be combined with the splitter.
 func ConfigureSplitUploader() (Uploader, error) {
+	var partitions = viper.GetInt(conf.StreamSplitterPartitions)
+	var blockSize = viper.GetSizeInBytes(conf.StreamSplitterBlockSize)
+	var maxFileSize = viper.GetInt(conf.StreamSplitterMaxFileSize)
+	dedupEnabled := viper.GetBool(conf.StreamDedupSetting)
+	if dedupEnabled && (partitions > 1 || maxFileSize > 0) {
+		return nil, fmt.Errorf("%s can't be combined with %s and %s",
+			conf.StreamDedupSetting, conf.StreamSplitterPartitions, conf.StreamSplitterMaxFileSize)
+	}
+
 	uploader, err := ConfigureUploader()
 	if err != nil {
 		return nil, err
 	}
-
-	var partitions = viper.GetInt(conf.StreamSplitterPartitions)
-	var blockSize = viper.GetSizeInBytes(conf.StreamSplitterBlockSize)
-	var maxFileSize = viper.GetInt(conf.StreamSplitterMaxFileSize)
+	if dedupEnabled {
+		return configureDedupUploader(uploader)
+	}
 
 	splitStreamUploader := NewSplitStreamUploader(uploader, partitions, int(blockSize), maxFileSize)
 	return splitStreamUploader, nil
 }
 
+// ConfigureStreamUploader configures the uploader for the stream backups,
+// which deduplicates the streams if WALG_STREAM_DEDUP is enabled.
+func ConfigureStreamUploader() (Uploader, error) {
+	uploader, err := ConfigureUploader()
+	if err != nil {
+		return nil, err
+	}
+	if viper.GetBool(conf.StreamDedupSetting) {
+		return configureDedupUploader(uploader)
+	}
+	return uploader, nil
+}
+
+func configureDedupUploader(uploader Uploader) (Uploader, error) {
+	avgChunkSize := viper.GetSizeInBytes(conf.StreamDedupAvgChunkSizeSetting)
+	if avgChunkSize < dedup.MinAvgChunkSize || avgChunkSize > dedup.MaxAvgChunkSize {
+		return nil, fmt.Errorf("%s must be between %d and %d bytes",
+			conf.StreamDedupAvgChunkSizeSetting, dedup.MinAvgChunkSize, dedup.MaxAvgChunkSize)
+	}
+	concurrency, err := conf.GetMaxUploadConcurrency()
+	if err != nil {
+		return nil, err
+	}
+	return NewDedupStreamUploader(uploader, int(avgChunkSize), concurrency), nil
+}
+
 func ConfigureCrypter() crypto.Crypter {
 	crypter, err := ConfigureCrypterForSpecificConfig(viper.GetViper())
 	if err != nil {
@@ -297,6 +349,9 @@ func ConfigureCrypterForSpecificConfig(config *viper.Viper) (crypto.Crypter, err
 	isEnvelopePgpKey := envelopePgpKey || envelopePgpKeyPath
 	isLibsodium := libsodiumKey || libsodiumKeyPath
 
+	isAge := config.IsSet(conf.AgeRecipientsSetting) || config.IsSet(conf.AgeRecipientsPathSetting) ||
+		config.IsSet(conf.AgeIdentitySetting) || config.IsSet(conf.AgeIdentityPathSetting)
+
 	if isPgpKey && isEnvelopePgpKey {
 		return nil, errors.New("there is no way to configure plain gpg and envelope gpg at the same time, please choose one")
 	}
@@ -312,6 +367,8 @@ func ConfigureCrypterForSpecificConfig(config *viper.Viper) (crypto.Crypter, err
 		return yckms.YcCrypterFromKeyIDAndCredential(config.GetString(conf.YcKmsKeyIDSetting), config.GetString(conf.YcSaKeyFileSetting)), nil
 	case isLibsodium:
 		return configureLibsodiumCrypter(config)
+	case isAge:
+		return configureAgeCrypter(config), nil
 	default:
 		return nil, nil
 	}
@@ -339,15 +396,7 @@ func configurePgpCrypter(config *viper.Viper) (crypto.Crypter, error) {
 }
 
 func configureEnvelopePgpCrypter(config *viper.Viper) (crypto.Crypter, error) {
-	if !config.IsSet(conf.PgpEnvelopeYcKmsKeyIDSetting) {
-		return nil, errors.New("yandex cloud KMS key for client-side encryption and decryption must be configured")
-	}
-
-	yckmsEnveloper, err := yckmsenvlpr.EnveloperFromKeyIDAndCredential(
-		config.GetString(conf.PgpEnvelopeYcKmsKeyIDSetting),
-		config.GetString(conf.PgpEnvelopeYcSaKeyFileSetting),
-		config.GetString(conf.PgpEnvelopeYcEndpointSetting),
-	)
+	kmsEnveloper, err := configureEnveloper(config)
 	if err != nil {
 		return nil, err
 	}
@@ -355,7 +404,7 @@ func configureEnvelopePgpCrypter(config *viper.Viper) (crypto.Crypter, error) {
 	if err != nil {
 		return nil, err
 	}
-	enveloper := cachenvlpr.EnveloperWithCache(yckmsEnveloper, expiration)
+	enveloper := cachenvlpr.EnveloperWithCache(kmsEnveloper, expiration)
 
 	if config.IsSet(conf.PgpEnvelopKeyPathSetting) {
 		return envopenpgp.CrypterFromKeyPath(viper.GetString(conf.PgpEnvelopKeyPathSetting), enveloper), nil
@@ -366,6 +415,47 @@ func configureEnvelopePgpCrypter(config *viper.Viper) (crypto.Crypter, error) {
 	return nil, errors.New("there is no any supported envelope gpg crypter configuration")
 }
 
+func configureEnveloper(config *viper.Viper) (envelope.Enveloper, error) {
+	if config.IsSet(conf.PgpEnvelopeVaultKeyNameSetting) {
+		timeout, err := conf.GetDurationSettingFrom(conf.PgpEnvelopeVaultTimeoutSetting, config)
+		if err != nil {
+			return nil, err
+		}
+		return vaultenvlpr.EnveloperFromConfig(vaultenvlpr.Config{
+			Address:      config.GetString(conf.PgpEnvelopeVaultAddressSetting),
+			Namespace:    config.GetString(conf.PgpEnvelopeVaultNamespaceSetting),
+			CACertFile:   config.GetString(conf.PgpEnvelopeVaultCACertSetting),
+			Timeout:      timeout,
+			TransitMount: config.GetString(conf.PgpEnvelopeVaultTransitMountSetting),
+			KeyName:      config.GetString(conf.PgpEnvelopeVaultKeyNameSetting),
+			Token:        config.GetString(conf.PgpEnvelopeVaultTokenSetting),
+			RoleID:       config.GetString(conf.PgpEnvelopeVaultRoleIDSetting),
+			SecretID:     config.GetString(conf.PgpEnvelopeVaultSecretIDSetting),
+			AppRoleMount: config.GetString(conf.PgpEnvelopeVaultAppRoleMountSetting),
+		})
+	}
+
+	if !config.IsSet(conf.PgpEnvelopeYcKmsKeyIDSetting) {
+		return nil, errors.New("yandex cloud KMS key or vault transit key for client-side encryption and decryption must be configured")
+	}
+	return yckmsenvlpr.EnveloperFromKeyIDAndCredential(
+		config.GetString(conf.PgpEnvelopeYcKmsKeyIDSetting),
+		config.GetString(conf.PgpEnvelopeYcSaKeyFileSetting),
+		config.GetString(conf.PgpEnvelopeYcEndpointSetting),
+	)
+}
+
+// recipients are enough to push backups and identities are enough to fetch them,
+// so each host may be given only the keys it needs
+func configureAgeCrypter(config *viper.Viper) crypto.Crypter {
+	return age.NewCrypter(
+		config.GetString(conf.AgeRecipientsSetting),
+		config.GetString(conf.AgeRecipientsPathSetting),
+		config.GetString(conf.AgeIdentitySetting),
+		config.GetString(conf.AgeIdentityPathSetting),
+	)
+}
+
 // TODO : unit tests
 func GetDeltaConfig() (maxDeltas int, fromFull bool) {
 	maxDeltas = viper.GetInt(conf.DeltaMaxStepsSetting)
@@ -542,6 +632,16 @@ func ConfigureFailoverStorages() (failovers map[string]storage.HashableStorage,
 				return NewLimitedFolder(prevFolder, limiters.NetworkLimiter)
 			})
 		}
+		if viper.GetBool(conf.StoreObjectChecksumsSetting) {
+			rootWraps = append(rootWraps, func(prevFolder storage.Folder) (newFolder storage.Folder) {
+				return checksum.NewFolder(prevFolder)
+			})
+		}
+		if tracing.Enabled() {
+			rootWraps = append(rootWraps, func(prevFolder storage.Folder) (newFolder storage.Folder) {
+				return tracing.NewFolder(prevFolder)
+			})
+		}
 		rootWraps = append(rootWraps, ConfigureStoragePrefix)
 
 		st, err := ConfigureStorageForSpecificConfig(cfg, rootWraps...)
@@ -172,3 +172,22 @@ func resetToDefaults() {
 	config.InitConfig()
 	config.Configure()
 }
+
+func TestConfigureSplitUploader_Dedup(t *testing.T) {
+	viper.Set("WALG_FILE_PREFIX", t.TempDir())
+	viper.Set(config.StreamDedupSetting, true)
+	defer resetToDefaults()
+
+	uploader, err := internal.ConfigureSplitUploader()
+	assert.NoError(t, err)
+	assert.IsType(t, &internal.DedupStreamUploader{}, uploader)
+
+	viper.Set(config.StreamSplitterPartitions, 4)
+	_, err = internal.ConfigureSplitUploader()
+	assert.Error(t, err)
+
+	viper.Set(config.StreamSplitterPartitions, 1)
+	viper.Set(config.StreamSplitterMaxFileSize, 1<<30)
+	_, err = internal.ConfigureSplitUploader()
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package age
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/ioextensions"
+)
+
+// Crypter encrypts the data for one or more X25519 recipients (public keys)
+// and decrypts it with any of the matching identities (private keys).
+// The hosts that only push backups need the recipients,
+// the identities are needed to fetch them.
+type Crypter struct {
+	Recipients     string
+	RecipientsPath string
+
+	Identities     string
+	IdentitiesPath string
+
+	recipients []age.Recipient
+	identities []age.Identity
+
+	mutex sync.RWMutex
+}
+
+func (crypter *Crypter) Name() string {
+	return "Age/Crypter"
+}
+
+// NewCrypter creates Crypter from the inline keys or the key files in the age format:
+// one key per line, lines starting with '#' are comments. The inline recipients
+// may be also separated by commas.
+func NewCrypter(recipients, recipientsPath, identities, identitiesPath string) crypto.Crypter {
+	return &Crypter{
+		Recipients:     recipients,
+		RecipientsPath: recipientsPath,
+		Identities:     identities,
+		IdentitiesPath: identitiesPath,
+	}
+}
+
+// Encrypt creates encryption writer from ordinary writer
+func (crypter *Crypter) Encrypt(writer io.Writer) (io.WriteCloser, error) {
+	recipients, err := crypter.loadRecipients()
+	if err != nil {
+		return nil, err
+	}
+
+	// We use buffered writer because encryption starts writing header immediately,
+	// which can be inappropriate for further usage with blocking writers.
+	bufferedWriter := bufio.NewWriter(writer)
+	encryptedWriter, err := age.Encrypt(bufferedWriter, recipients...)
+	if err != nil {
+		return nil, errors.Wrap(err, "age encryption error")
+	}
+
+	return ioextensions.NewOnCloseFlusher(encryptedWriter, bufferedWriter), nil
+}
+
+// Decrypt creates decrypted reader from ordinary reader
+func (crypter *Crypter) Decrypt(reader io.Reader) (io.Reader, error) {
+	identities, err := crypter.loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	decryptedReader, err := age.Decrypt(reader, identities...)
+	if err != nil {
+		return nil, errors.Wrap(err, "age decryption error")
+	}
+	return decryptedReader, nil
+}
+
+func (crypter *Crypter) loadRecipients() ([]age.Recipient, error) {
+	crypter.mutex.RLock()
+	if crypter.recipients != nil {
+		crypter.mutex.RUnlock()
+		return crypter.recipients, nil
+	}
+	crypter.mutex.RUnlock()
+
+	crypter.mutex.Lock()
+	defer crypter.mutex.Unlock()
+	if crypter.recipients != nil { // already loaded
+		return crypter.recipients, nil
+	}
+
+	keys, err := readKeys(splitInlineRecipients(crypter.Recipients), crypter.RecipientsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read age recipients")
+	}
+	if keys == nil {
+		return nil, errors.New("age recipients are not configured, encryption is not possible")
+	}
+	recipients, err := age.ParseRecipients(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse age recipients")
+	}
+
+	crypter.recipients = recipients
+	return recipients, nil
+}
+
+func (crypter *Crypter) loadIdentities() ([]age.Identity, error) {
+	crypter.mutex.RLock()
+	if crypter.identities != nil {
+		crypter.mutex.RUnlock()
+		return crypter.identities, nil
+	}
+	crypter.mutex.RUnlock()
+
+	crypter.mutex.Lock()
+	defer crypter.mutex.Unlock()
+	if crypter.identities != nil { // already loaded
+		return crypter.identities, nil
+	}
+
+	keys, err := readKeys(strings.ReplaceAll(crypter.Identities, `\n`, "\n"), crypter.IdentitiesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read age identities")
+	}
+	if keys == nil {
+		return nil, errors.New("age identities are not configured, decryption is not possible")
+	}
+	identities, err := age.ParseIdentities(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse age identities")
+	}
+
+	crypter.identities = identities
+	return identities, nil
+}
+
+// readKeys returns the inline keys if they are set, or the content of the key file otherwise
+func readKeys(inline, path string) (io.Reader, error) {
+	if inline != "" {
+		return strings.NewReader(inline), nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(string(content)), nil
+}
+
+// splitInlineRecipients puts the comma-separated recipients on separate lines
+func splitInlineRecipients(recipients string) string {
+	lines := strings.Split(recipients, ",")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
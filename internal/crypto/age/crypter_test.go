@@ -0,0 +1,84 @@
+package age
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/crypto"
+)
+
+const someSecret = "so very secret thingy"
+
+func generateIdentity(t *testing.T) *age.X25519Identity {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func encrypt(t *testing.T, crypter crypto.Crypter) []byte {
+	buf := new(bytes.Buffer)
+	encrypt, err := crypter.Encrypt(buf)
+	require.NoError(t, err)
+	_, err = encrypt.Write([]byte(someSecret))
+	require.NoError(t, err)
+	require.NoError(t, encrypt.Close())
+	return buf.Bytes()
+}
+
+func decrypt(t *testing.T, crypter crypto.Crypter, encrypted []byte) string {
+	decrypt, err := crypter.Decrypt(bytes.NewReader(encrypted))
+	require.NoError(t, err)
+	decrypted, err := io.ReadAll(decrypt)
+	require.NoError(t, err)
+	return string(decrypted)
+}
+
+func TestEncryptionCycle_MultipleRecipients(t *testing.T) {
+	first, second := generateIdentity(t), generateIdentity(t)
+	recipients := first.Recipient().String() + ", " + second.Recipient().String()
+
+	encrypted := encrypt(t, NewCrypter(recipients, "", "", ""))
+
+	assert.NotContains(t, string(encrypted), someSecret)
+	assert.Equal(t, someSecret, decrypt(t, NewCrypter("", "", first.String(), ""), encrypted))
+	assert.Equal(t, someSecret, decrypt(t, NewCrypter("", "", second.String(), ""), encrypted))
+}
+
+func TestEncryptionCycle_KeyFiles(t *testing.T) {
+	identity := generateIdentity(t)
+	dir := t.TempDir()
+	recipientsPath := filepath.Join(dir, "recipients.txt")
+	identitiesPath := filepath.Join(dir, "key.txt")
+	require.NoError(t, os.WriteFile(recipientsPath,
+		[]byte("# backup operators\n"+identity.Recipient().String()+"\n"), 0600))
+	require.NoError(t, os.WriteFile(identitiesPath,
+		[]byte("# created: 2023-11-01T00:00:00Z\n"+identity.String()+"\n"), 0600))
+	crypter := NewCrypter("", recipientsPath, "", identitiesPath)
+
+	assert.Equal(t, someSecret, decrypt(t, crypter, encrypt(t, crypter)))
+}
+
+func TestDecrypt_WrongIdentity(t *testing.T) {
+	encrypted := encrypt(t, NewCrypter(generateIdentity(t).Recipient().String(), "", "", ""))
+
+	_, err := NewCrypter("", "", generateIdentity(t).String(), "").Decrypt(bytes.NewReader(encrypted))
+
+	assert.Error(t, err)
+}
+
+func TestCrypter_NotConfiguredKeys(t *testing.T) {
+	identity := generateIdentity(t)
+
+	_, err := NewCrypter("", "", identity.String(), "").Encrypt(new(bytes.Buffer))
+	assert.Error(t, err)
+
+	_, err = NewCrypter(identity.Recipient().String(), "", "", "").Decrypt(strings.NewReader(""))
+	assert.Error(t, err)
+}
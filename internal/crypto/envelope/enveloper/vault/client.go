@@ -0,0 +1,193 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+)
+
+const (
+	tokenHeader     = "X-Vault-Token"
+	namespaceHeader = "X-Vault-Namespace"
+
+	// the AppRole token is renewed by a new login when it's close to the expiration
+	tokenRenewMargin = 10 * time.Second
+)
+
+// client is a minimal Vault HTTP API client which authenticates with a static token or with AppRole
+type client struct {
+	config     Config
+	httpClient *http.Client
+
+	mutex          sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+}
+
+type apiError struct {
+	Errors []string `json:"errors"`
+}
+
+type loginResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+func newClient(config Config) (*client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CACertFile != "" {
+		caCert, err := os.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't read vault CA certificate")
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf("no certificates found in %s", config.CACertFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12}
+	}
+
+	c := &client{
+		config:     config,
+		httpClient: &http.Client{Transport: transport, Timeout: config.Timeout},
+	}
+	if !c.usesAppRole() {
+		c.token = config.Token
+	}
+	return c, nil
+}
+
+// write sends a POST request with the JSON body to the API path (without the /v1/ prefix)
+// and decodes the JSON response into the result
+func (c *client) write(path string, body, result interface{}) error {
+	token, err := c.getToken()
+	if err != nil {
+		return err
+	}
+	err = c.do(path, token, body, result)
+	if err == nil || !c.usesAppRole() || !isPermissionDenied(err) {
+		return err
+	}
+
+	// the token might be revoked before its lease expired, so try to log in again once
+	tracelog.DebugLogger.Printf("Vault denied the request to %s, logging in again", path)
+	c.resetToken(token)
+	token, err = c.getToken()
+	if err != nil {
+		return err
+	}
+	return c.do(path, token, body, result)
+}
+
+func (c *client) getToken() (string, error) {
+	if !c.usesAppRole() {
+		return c.token, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token != "" && (c.tokenExpiresAt.IsZero() || time.Now().Add(tokenRenewMargin).Before(c.tokenExpiresAt)) {
+		return c.token, nil
+	}
+
+	var response loginResponse
+	loginPath := fmt.Sprintf("auth/%s/login", c.config.AppRoleMount)
+	err := c.do(loginPath, "", map[string]string{
+		"role_id":   c.config.RoleID,
+		"secret_id": c.config.SecretID,
+	}, &response)
+	if err != nil {
+		return "", errors.Wrap(err, "vault AppRole login failed")
+	}
+	if response.Auth.ClientToken == "" {
+		return "", errors.New("vault AppRole login returned no token")
+	}
+
+	c.token = response.Auth.ClientToken
+	c.tokenExpiresAt = time.Time{}
+	if response.Auth.LeaseDuration > 0 {
+		c.tokenExpiresAt = time.Now().Add(time.Duration(response.Auth.LeaseDuration) * time.Second)
+	}
+	return c.token, nil
+}
+
+func (c *client) resetToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+func (c *client) usesAppRole() bool {
+	return c.config.RoleID != ""
+}
+
+func (c *client) do(path, token string, body, result interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(c.config.Address, "/") + "/v1/" + path
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set(tokenHeader, token)
+	}
+	if c.config.Namespace != "" {
+		request.Header.Set(namespaceHeader, c.config.Namespace)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "vault request to %s failed", path)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrapf(err, "can't read vault response from %s", path)
+	}
+	if response.StatusCode != http.StatusOK {
+		return newResponseError(path, response.StatusCode, responseBody)
+	}
+	return errors.Wrapf(json.Unmarshal(responseBody, result), "can't parse vault response from %s", path)
+}
+
+type responseError struct {
+	path       string
+	statusCode int
+	messages   []string
+}
+
+func newResponseError(path string, statusCode int, body []byte) responseError {
+	var apiErr apiError
+	_ = json.Unmarshal(body, &apiErr)
+	return responseError{path: path, statusCode: statusCode, messages: apiErr.Errors}
+}
+
+func (err responseError) Error() string {
+	return fmt.Sprintf("vault request to %s failed with status %d: %s",
+		err.path, err.statusCode, strings.Join(err.messages, "; "))
+}
+
+func isPermissionDenied(err error) bool {
+	var responseErr responseError
+	return errors.As(err, &responseErr) && responseErr.statusCode == http.StatusForbidden
+}
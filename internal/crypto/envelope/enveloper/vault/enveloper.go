@@ -0,0 +1,197 @@
+package vault
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+
+	"github.com/wal-g/wal-g/internal/crypto/envelope"
+)
+
+const (
+	magic              = "envelope-vault-transit"
+	schemeVersion byte = 1
+	sizeofInt32        = 4
+
+	DefaultTransitMount = "transit"
+	DefaultAppRoleMount = "approle"
+	DefaultTimeout      = 30 * time.Second
+)
+
+// Config describes the Vault server, the Transit key and the credentials used to access it.
+// Either Token or RoleID and SecretID (AppRole auth) must be set.
+type Config struct {
+	Address      string
+	Namespace    string
+	CACertFile   string
+	Timeout      time.Duration
+	TransitMount string
+	KeyName      string
+
+	Token        string
+	RoleID       string
+	SecretID     string
+	AppRoleMount string
+}
+
+// Enveloper decrypts the data keys with the Vault Transit secrets engine
+type Enveloper struct {
+	keyName      string
+	transitMount string
+	client       *client
+}
+
+type transitResponse struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+}
+
+func (enveloper *Enveloper) Name() string {
+	return "vault"
+}
+
+func (enveloper *Enveloper) ReadEncryptedKey(r io.Reader) (*envelope.EncryptedKey, error) {
+	return readEncryptedKey(r)
+}
+
+// DecryptKey decrypts the key, which is the Transit ciphertext ("vault:v1:...")
+func (enveloper *Enveloper) DecryptKey(encryptedKey *envelope.EncryptedKey) ([]byte, error) {
+	var response transitResponse
+	err := enveloper.client.write(
+		fmt.Sprintf("%s/decrypt/%s", enveloper.transitMount, enveloper.keyName),
+		map[string]string{"ciphertext": string(encryptedKey.Data)},
+		&response,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(response.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't decode the key decrypted by vault")
+	}
+	return plaintext, nil
+}
+
+// EncryptKey encrypts the key with the Transit key, the result can be decrypted by DecryptKey
+func (enveloper *Enveloper) EncryptKey(key []byte) (*envelope.EncryptedKey, error) {
+	var response transitResponse
+	err := enveloper.client.write(
+		fmt.Sprintf("%s/encrypt/%s", enveloper.transitMount, enveloper.keyName),
+		map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)},
+		&response,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data.Ciphertext == "" {
+		return nil, errors.New("vault returned no ciphertext")
+	}
+	return envelope.NewEncryptedKey("", []byte(response.Data.Ciphertext)), nil
+}
+
+func (enveloper *Enveloper) SerializeEncryptedKey(encryptedKey *envelope.EncryptedKey) []byte {
+	return serializeEncryptedKey(encryptedKey)
+}
+
+func serializeEncryptedKey(encryptedKey *envelope.EncryptedKey) []byte {
+	/*
+		magic value "envelope-vault-transit"
+		scheme version (current version is 1)
+		uint32 - keyID len
+		keyID ...
+		uint32 - encrypted key len
+		encrypted key ...
+	*/
+
+	result := append([]byte(magic), schemeVersion)
+
+	keyID := encryptedKey.ID()
+	result = binary.LittleEndian.AppendUint32(result, uint32(len(keyID)))
+	result = append(result, []byte(keyID)...)
+
+	result = binary.LittleEndian.AppendUint32(result, uint32(len(encryptedKey.Data)))
+	return append(result, encryptedKey.Data...)
+}
+
+func readEncryptedKey(r io.Reader) (*envelope.EncryptedKey, error) {
+	magicSchemeBytes := make([]byte, len(magic)+1)
+	_, err := io.ReadFull(r, magicSchemeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(magicSchemeBytes[0:len(magic)]) != magic {
+		return nil, errors.New("envelope vault transit: invalid encrypted header format")
+	}
+
+	if schemeVersion != magicSchemeBytes[len(magic)] {
+		return nil, errors.New("envelope vault transit: scheme version is not supported")
+	}
+
+	keyID, err := readSizedBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	tracelog.DebugLogger.Printf("Encrypted key was found: %s\n", keyID)
+
+	encryptedKey, err := readSizedBytes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope.NewEncryptedKey(string(keyID), encryptedKey), nil
+}
+
+func readSizedBytes(r io.Reader) ([]byte, error) {
+	lenBytes := make([]byte, sizeofInt32)
+	_, err := io.ReadFull(r, lenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, binary.LittleEndian.Uint32(lenBytes))
+	_, err = io.ReadFull(r, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func EnveloperFromConfig(config Config) (*Enveloper, error) {
+	if config.Address == "" {
+		return nil, errors.New("vault address must be set")
+	}
+	if config.KeyName == "" {
+		return nil, errors.New("vault transit key name must be set")
+	}
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, errors.New("either vault token or AppRole role ID and secret ID must be set")
+	}
+	if config.TransitMount == "" {
+		config.TransitMount = DefaultTransitMount
+	}
+	if config.AppRoleMount == "" {
+		config.AppRoleMount = DefaultAppRoleMount
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Enveloper{
+		keyName:      config.KeyName,
+		transitMount: config.TransitMount,
+		client:       client,
+	}, nil
+}
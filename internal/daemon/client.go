@@ -2,8 +2,8 @@ package daemon
 
 import (
 	"context"
-	"encoding/binary"
 	"fmt"
+	"io"
 	"net"
 	"time"
 )
@@ -18,23 +18,22 @@ type RunOptions struct {
 }
 
 func getMessage(messageType SocketMessageType, messageArgs []string) ([]byte, error) {
-	switch len(messageArgs) {
-	case 0:
-		return binary.BigEndian.AppendUint16(messageType.ToBytes(), uint16(3)), nil
-	case 1:
-		res := binary.BigEndian.AppendUint16(messageType.ToBytes(), uint16(len(messageArgs[0])+3))
-		return append(res, []byte(messageArgs[0])...), nil
+	switch {
+	case len(messageArgs) == 0:
+		return NewMessage(messageType, nil)
+	case len(messageArgs) == 1 && !messageType.HasFramedResponse():
+		return NewMessage(messageType, []byte(messageArgs[0]))
 	}
 
 	messageBody, err := ArgsToBytes(messageArgs...)
 	if err != nil {
 		return nil, err
 	}
-	res := binary.BigEndian.AppendUint16(messageType.ToBytes(), uint16(len(messageBody)+3))
-	return append(res, messageBody...), nil
+	return NewMessage(messageType, messageBody)
 }
 
-func SendCommand(opts *RunOptions) (SocketMessageType, error) {
+// sendMessage connects to the daemon socket and sends the command message
+func sendMessage(opts *RunOptions) (net.Conn, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), opts.DaemonSocketConnectionTimeout)
 	defer cancel()
 
@@ -42,22 +41,33 @@ func SendCommand(opts *RunOptions) (SocketMessageType, error) {
 	daemonAddr := net.UnixAddr{Name: opts.SocketName, Net: "unix"}
 	socketConnection, err := dialer.DialContext(ctx, "unix", daemonAddr.String())
 	if err != nil {
-		return ErrorType, fmt.Errorf("unix socket dial error: %w", err)
+		return nil, fmt.Errorf("unix socket dial error: %w", err)
 	}
-	defer socketConnection.Close()
 	err = socketConnection.SetDeadline(time.Now().Add(opts.DaemonOperationTimeout))
 	if err != nil {
-		return ErrorType, fmt.Errorf("unix socket set deadline error: %w", err)
+		socketConnection.Close()
+		return nil, fmt.Errorf("unix socket set deadline error: %w", err)
 	}
 
 	msg, err := getMessage(opts.MessageType, opts.MessageArgs)
 	if err != nil {
-		return ErrorType, err
+		socketConnection.Close()
+		return nil, err
 	}
 	_, err = socketConnection.Write(msg)
 	if err != nil {
-		return ErrorType, fmt.Errorf("unix socket write error: %w", err)
+		socketConnection.Close()
+		return nil, fmt.Errorf("unix socket write error: %w", err)
 	}
+	return socketConnection, nil
+}
+
+func SendCommand(opts *RunOptions) (SocketMessageType, error) {
+	socketConnection, err := sendMessage(opts)
+	if err != nil {
+		return ErrorType, err
+	}
+	defer socketConnection.Close()
 
 	resp := make([]byte, 512)
 	n, err := socketConnection.Read(resp)
@@ -73,3 +83,41 @@ func SendCommand(opts *RunOptions) (SocketMessageType, error) {
 	}
 	return OkType, nil
 }
+
+// SendStreamingCommand sends the command which is answered with the framed messages,
+// writes the progress messages to the progress writer and returns the body of the result message.
+// The operation timeout is applied to the wait for every next message, so the long operations
+// are not interrupted while they report the progress.
+func SendStreamingCommand(opts *RunOptions, progress io.Writer) ([]byte, error) {
+	socketConnection, err := sendMessage(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer socketConnection.Close()
+
+	for {
+		messageType, messageBody, err := ReadMessage(socketConnection)
+		if err != nil {
+			return nil, fmt.Errorf("unix socket read error: %w", err)
+		}
+		switch messageType {
+		case ProgressType:
+			_, err = fmt.Fprintln(progress, string(messageBody))
+			if err != nil {
+				return nil, err
+			}
+			err = socketConnection.SetDeadline(time.Now().Add(opts.DaemonOperationTimeout))
+			if err != nil {
+				return nil, fmt.Errorf("unix socket set deadline error: %w", err)
+			}
+		case OkType:
+			return messageBody, nil
+		case ErrorType:
+			return nil, fmt.Errorf("daemon command run error [message type: %v, args: %v]: %s",
+				string(opts.MessageType), opts.MessageArgs, string(messageBody))
+		default:
+			return nil, fmt.Errorf("unexpected daemon response [message type: %v, args: %v, daemon response: %v]",
+				string(opts.MessageType), opts.MessageArgs, string(messageType))
+		}
+	}
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 )
 
@@ -16,6 +17,12 @@ const (
 
 	WalPushType  SocketMessageType = 'F'
 	WalFetchType SocketMessageType = 'f'
+
+	BackupPushType  SocketMessageType = 'B'
+	WalPrefetchType SocketMessageType = 'p'
+	StatusType      SocketMessageType = 'S'
+	// ProgressType is sent by the daemon while the long operation is running
+	ProgressType SocketMessageType = 'P'
 )
 
 var (
@@ -30,6 +37,47 @@ func (msg SocketMessageType) IsEqual(value byte) bool {
 	return byte(msg) == value
 }
 
+// HasFramedResponse reports whether the daemon answers the message type with the framed messages:
+// any number of ProgressType messages followed by OkType or ErrorType message with the result.
+// The legacy message types are answered with a single byte.
+func (msg SocketMessageType) HasFramedResponse() bool {
+	switch msg {
+	case BackupPushType, WalPrefetchType, StatusType:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewMessage frames the message body: the message type, the uint16 total message length and the body
+func NewMessage(messageType SocketMessageType, body []byte) ([]byte, error) {
+	if len(body) > math.MaxUint16-3 {
+		return nil, fmt.Errorf("unsupported message size")
+	}
+	res := binary.BigEndian.AppendUint16(messageType.ToBytes(), uint16(len(body)+3))
+	return append(res, body...), nil
+}
+
+// ReadMessage reads the framed message
+func ReadMessage(r io.Reader) (SocketMessageType, []byte, error) {
+	messageParameters := make([]byte, 3)
+	_, err := io.ReadFull(r, messageParameters)
+	if err != nil {
+		return ErrorType, nil, fmt.Errorf("failed to read params: %w", err)
+	}
+	messageType := SocketMessageType(messageParameters[0])
+	messageLength := binary.BigEndian.Uint16(messageParameters[1:3])
+	if messageLength < 3 {
+		return ErrorType, nil, fmt.Errorf("incorrect message length: %d", messageLength)
+	}
+	messageBody := make([]byte, messageLength-3)
+	_, err = io.ReadFull(r, messageBody)
+	if err != nil {
+		return ErrorType, nil, fmt.Errorf("failed to read msg body: %w", err)
+	}
+	return messageType, messageBody, nil
+}
+
 func ArgsToBytes(args ...string) ([]byte, error) {
 	argsLen := len(args)
 	if argsLen > 255 {
@@ -50,6 +98,9 @@ func ArgsToBytes(args ...string) ([]byte, error) {
 }
 
 func BytesToArgs(body []byte) ([]string, error) {
+	if len(body) == 0 {
+		return []string{}, nil
+	}
 	argsCount := int(body[0])
 	res := make([]string, 0, argsCount)
 	idx := 1
@@ -1,9 +1,15 @@
 package daemon
 
 import (
+	"bytes"
 	"fmt"
-	"github.com/stretchr/testify/assert"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDaemon_MessageBodyArrayConversion(t *testing.T) {
@@ -25,3 +31,70 @@ func TestDaemon_MessageBodyArrayConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestDaemon_FramedMessage(t *testing.T) {
+	message, err := getMessage(WalPrefetchType, []string{"000000010000000000000001", "pg_wal"})
+	assert.NoError(t, err)
+
+	messageType, messageBody, err := ReadMessage(bytes.NewReader(message))
+	assert.NoError(t, err)
+	assert.Equal(t, WalPrefetchType, messageType)
+	args, err := BytesToArgs(messageBody)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"000000010000000000000001", "pg_wal"}, args)
+
+	message, err = getMessage(BackupPushType, nil)
+	assert.NoError(t, err)
+	messageType, messageBody, err = ReadMessage(bytes.NewReader(message))
+	assert.NoError(t, err)
+	assert.Equal(t, BackupPushType, messageType)
+	args, err = BytesToArgs(messageBody)
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+
+	_, _, err = ReadMessage(bytes.NewReader([]byte{byte(OkType), 0, 2}))
+	assert.Error(t, err)
+	_, _, err = ReadMessage(bytes.NewReader(message[:2]))
+	assert.Error(t, err)
+}
+
+func TestDaemon_SendStreamingCommand(t *testing.T) {
+	socketName := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", socketName)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	responses := [][]byte{
+		mustNewMessage(t, ProgressType, []byte("in progress")),
+		mustNewMessage(t, OkType, []byte("base_000000010000000000000002")),
+	}
+	go func() {
+		c, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		_, _, _ = ReadMessage(c)
+		for _, response := range responses {
+			_, _ = c.Write(response)
+		}
+	}()
+
+	opts := &RunOptions{
+		SocketName:                    socketName,
+		MessageType:                   BackupPushType,
+		DaemonOperationTimeout:        time.Second,
+		DaemonSocketConnectionTimeout: time.Second,
+	}
+	var progress bytes.Buffer
+	result, err := SendStreamingCommand(opts, &progress)
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", string(result))
+	assert.Equal(t, "in progress\n", progress.String())
+}
+
+func mustNewMessage(t *testing.T, messageType SocketMessageType, body []byte) []byte {
+	message, err := NewMessage(messageType, body)
+	require.NoError(t, err)
+	return message
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os/exec"
 
+	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
@@ -12,5 +13,6 @@ func HandleBackupFetch(ctx context.Context,
 	folder storage.Folder,
 	targetBackupSelector internal.BackupSelector,
 	restoreCmd *exec.Cmd) {
-	internal.HandleBackupFetch(folder, targetBackupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
+	err := internal.HandleBackupFetch(folder, targetBackupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
+	tracelog.ErrorLogger.FatalOnError(err)
 }
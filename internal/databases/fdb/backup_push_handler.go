@@ -2,11 +2,13 @@ package fdb
 
 import (
 	"context"
+	"fmt"
 	"os/exec"
 	"time"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -15,23 +17,33 @@ type streamSentinelDto struct {
 	StartLocalTime time.Time
 }
 
-func HandleBackupPush(uploader internal.Uploader, backupCmd *exec.Cmd) {
+func HandleBackupPush(uploader internal.Uploader, backupCmd *exec.Cmd) (err error) {
 	timeStart := utility.TimeNowCrossPlatformLocal()
 
+	notification, err := notify.Start(notify.BackupPush)
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
-	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to start backup create command: %w", err)
+	}
 
 	fileName, err := uploader.PushStream(context.Background(), stdout)
-	tracelog.ErrorLogger.FatalfOnError("failed to push backup: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to push backup: %w", err)
+	}
+	notification.SetBackupName(fileName)
 
 	err = backupCmd.Wait()
 	if err != nil {
 		tracelog.ErrorLogger.Printf("Backup command output:\n%s", stderr.String())
-		tracelog.ErrorLogger.Fatalf("backup create command failed: %v", err)
+		return fmt.Errorf("backup create command failed: %w", err)
 	}
 
 	sentinel := streamSentinelDto{StartLocalTime: timeStart}
 
-	err = internal.UploadSentinel(uploader, &sentinel, fileName)
-	tracelog.ErrorLogger.FatalOnError(err)
+	return internal.UploadSentinel(uploader, &sentinel, fileName)
 }
@@ -263,21 +263,27 @@ func (fh *FetchHandler) buildFetchCommand(contentID int) string {
 
 func NewGreenplumBackupFetcher(restoreCfgPath string, inPlaceRestore bool, logsDir string,
 	fetchContentIds []int, mode BackupFetchMode, restorePoint string, partialRestoreArgs []string,
-) func(folder storage.Folder, backup internal.Backup) {
-	return func(folder storage.Folder, backup internal.Backup) {
+) internal.Fetcher {
+	return func(folder storage.Folder, backup internal.Backup) error {
 		tracelog.InfoLogger.Printf("Starting backup-fetch for %s", backup.Name)
 		if restorePoint != "" {
-			tracelog.ErrorLogger.FatalOnError(ValidateMatch(folder, backup.Name, restorePoint))
+			err := ValidateMatch(folder, backup.Name, restorePoint)
+			if err != nil {
+				return err
+			}
 		}
 		var sentinel BackupSentinelDto
 		err := backup.FetchSentinel(&sentinel)
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return err
+		}
 
 		segCfgMaker, err := NewSegConfigMaker(restoreCfgPath, inPlaceRestore)
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return err
+		}
 
-		err = NewFetchHandler(backup, sentinel, segCfgMaker, logsDir, fetchContentIds, mode, restorePoint, partialRestoreArgs).Fetch()
-		tracelog.ErrorLogger.FatalOnError(err)
+		return NewFetchHandler(backup, sentinel, segCfgMaker, logsDir, fetchContentIds, mode, restorePoint, partialRestoreArgs).Fetch()
 	}
 }
 
@@ -118,6 +118,42 @@ func (h *DeleteHandler) HandleDeleteEverything(args []string) {
 	h.DeleteHandler.HandleDeleteEverything(args, h.permanentBackups, h.args.Confirmed)
 }
 
+func (h *DeleteHandler) HandleDeleteGfs(policy internal.GfsPolicy) {
+	decisions := h.FindGfsRetention(policy)
+	err := internal.WriteGfsDecisions(decisions, os.Stdout)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	targets, oldestKept, err := h.FindGfsTargets(decisions)
+	tracelog.ErrorLogger.FatalOnError(err)
+	if len(targets) == 0 {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		return
+	}
+
+	tracelog.InfoLogger.Println("Deleting the segments backups...")
+	for _, target := range targets {
+		if oldestKept != nil && target.GetLastModified().Before(oldestKept.GetLastModified()) {
+			// the older backups are deleted along with the segments WAL below
+			continue
+		}
+		err = h.dispatchDeleteCmd(target, SegDeleteTarget)
+		if err != nil {
+			tracelog.ErrorLogger.Fatalf("Failed to delete the segments backups: %v", err)
+		}
+	}
+	if oldestKept != nil {
+		err = h.dispatchDeleteCmd(oldestKept, SegDeleteBefore)
+		if err != nil {
+			tracelog.ErrorLogger.Fatalf("Failed to delete the segments backups: %v", err)
+		}
+	}
+	tracelog.InfoLogger.Printf("Finished deleting the segments backups")
+
+	folderFilter := func(name string) bool { return strings.HasPrefix(name, utility.BaseBackupPath) }
+	err = h.DeleteHandler.DeleteGfs(decisions, h.args.Confirmed, folderFilter)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func (h *DeleteHandler) DeleteBeforeTarget(target internal.BackupObject) error {
 	tracelog.InfoLogger.Println("Deleting the segments backups...")
 	err := h.dispatchDeleteCmd(target, SegDeleteBefore)
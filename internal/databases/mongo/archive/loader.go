@@ -335,6 +335,8 @@ func (su *StorageUploader) UploadBackup(stream io.Reader, cmd internal.ErrWaiter
 	if err != nil {
 		return fmt.Errorf("can not init meta provider: %+v", err)
 	}
+	// the sentinel must list only the levels of this backup's streams
+	internal.ResetAdaptiveCompressionLevels(su.Uploader)
 	backupName, err := su.PushStream(context.Background(), stream)
 	if err != nil {
 		return fmt.Errorf("can not push stream: %+v", err)
@@ -349,6 +351,9 @@ func (su *StorageUploader) UploadBackup(stream io.Reader, cmd internal.ErrWaiter
 	}
 
 	backupSentinel := metaConstructor.MetaInfo()
+	if backup, ok := backupSentinel.(*models.Backup); ok {
+		backup.CompressionLevels = internal.AdaptiveCompressionLevels(su.Uploader)
+	}
 	if err := internal.UploadSentinel(su.Uploader, backupSentinel, backupName); err != nil {
 		return fmt.Errorf("can not upload sentinel: %+v", err)
 	}
@@ -376,7 +381,10 @@ func NewStoragePurger(opts StorageSettings) (*StoragePurger, error) {
 // TODO: extract BackupLayout abstraction and provide DataPath(), SentinelPath(), Exists() methods
 func (sp *StoragePurger) DeleteBackups(backups []*models.Backup) error {
 	backupNames := BackupNamesFromBackups(backups)
-	return internal.DeleteBackups(sp.backupsFolder, backupNames)
+	if err := internal.DeleteBackups(sp.backupsFolder, backupNames); err != nil {
+		return err
+	}
+	return internal.DeleteUnreferencedDedupChunks(sp.backupsFolder, true)
 }
 
 // DeleteGarbage purges given garbage keys
@@ -386,12 +394,12 @@ func (sp *StoragePurger) DeleteGarbage(garbage []string) error {
 
 // DeleteOplogArchives purges given oplogs files
 func (sp *StoragePurger) DeleteOplogArchives(archives []models.Archive) error {
-	oplogKeys := make([]string, 0, len(archives))
+	oplogKeys := make([][]string, 0, len(archives))
 	for _, arch := range archives {
-		oplogKeys = append(oplogKeys, arch.Filename())
+		oplogKeys = append(oplogKeys, []string{arch.Filename()})
 	}
 	tracelog.DebugLogger.Printf("Oplog keys will be deleted: %+v\n", oplogKeys)
-	return sp.oplogsFolder.DeleteObjects(oplogKeys)
+	return internal.DeleteObjectGroups(sp.oplogsFolder, oplogKeys)
 }
 
 func isOplogForSpecificNode(fileName, node string) bool {
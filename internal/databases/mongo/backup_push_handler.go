@@ -6,13 +6,21 @@ import (
 
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/utility"
 )
 
 // HandleBackupPush starts backup procedure.
 func HandleBackupPush(uploader archive.Uploader,
 	metaConstructor internal.MetaConstructor,
-	backupCmd *exec.Cmd) error {
+	backupCmd *exec.Cmd) (err error) {
+	notification, err := notify.Start(notify.BackupPush)
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
 	if err := metaConstructor.Init(); err != nil {
 		return fmt.Errorf("can not initiate meta provider: %+v", err)
 	}
@@ -22,5 +30,13 @@ func HandleBackupPush(uploader archive.Uploader,
 		return fmt.Errorf("can not start backup command: %+v", err)
 	}
 
-	return uploader.UploadBackup(stdout, backupCmd, metaConstructor)
+	err = uploader.UploadBackup(stdout, backupCmd, metaConstructor)
+	if err != nil {
+		return err
+	}
+	if backup, ok := metaConstructor.MetaInfo().(*models.Backup); ok {
+		notification.SetBackupName(backup.BackupName)
+		notification.SetSizes(backup.CompressedSize, backup.UncompressedSize)
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// GenericMetaFetcher fetches the generic metadata of mongodb backups
+type GenericMetaFetcher struct{}
+
+func NewGenericMetaFetcher() GenericMetaFetcher {
+	return GenericMetaFetcher{}
+}
+
+func (mf GenericMetaFetcher) Fetch(backupName string, backupFolder storage.Folder) (internal.GenericMetadata, error) {
+	backup, err := DownloadSentinel(backupFolder, backupName)
+	if err != nil {
+		return internal.GenericMetadata{}, err
+	}
+
+	return internal.GenericMetadata{
+		BackupName:       backup.BackupName,
+		UncompressedSize: backup.UncompressedSize,
+		CompressedSize:   backup.CompressedSize,
+		Hostname:         backup.Hostname,
+		StartTime:        backup.StartLocalTime,
+		FinishTime:       backup.FinishLocalTime,
+		IsPermanent:      backup.Permanent,
+		IncrementDetails: &internal.NopIncrementDetailsFetcher{},
+		UserData:         backup.UserData,
+	}, nil
+}
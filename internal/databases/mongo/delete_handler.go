@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/wal-g/tracelog"
@@ -12,6 +13,9 @@ import (
 type PurgeSettings struct {
 	retainCount  *int
 	retainAfter  *time.Time
+	gfsPolicy    *internal.GfsPolicy
+	windowStart  *time.Time
+	retainSince  *time.Time
 	purgeOplog   bool
 	purgeGarbage bool
 	dryRun       bool
@@ -33,6 +37,20 @@ func PurgeRetainCount(retainCount int) PurgeOption {
 	}
 }
 
+// PurgeGfs ...
+func PurgeGfs(policy internal.GfsPolicy) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.gfsPolicy = &policy
+	}
+}
+
+// PurgeRetainWindow keeps everything needed to restore to any point after windowStart
+func PurgeRetainWindow(windowStart time.Time) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.windowStart = &windowStart
+	}
+}
+
 // PurgeOplog ...
 func PurgeOplog(purgeOplog bool) PurgeOption {
 	return func(args *PurgeSettings) {
@@ -66,11 +84,22 @@ func HandlePurge(downloader archive.Downloader, purger archive.Purger, setters .
 		return err
 	}
 
-	_, _, err = HandleBackupsPurge(backupTimes, downloader, purger, opts)
+	_, retain, err := HandleBackupsPurge(backupTimes, downloader, purger, opts)
 	if err != nil {
 		return err
 	}
 
+	if (opts.gfsPolicy != nil || opts.windowStart != nil) && len(retain) > 0 {
+		// the oplog is needed since the oldest kept backup
+		retainAfter := retain[0].FinishLocalTime
+		for _, backup := range retain {
+			if backup.FinishLocalTime.Before(retainAfter) {
+				retainAfter = backup.FinishLocalTime
+			}
+		}
+		opts.retainAfter = &retainAfter
+	}
+
 	if opts.purgeOplog {
 		// TODO: fix error if retainBackups is empty
 		if err := HandleOplogPurge(downloader, purger, opts.retainAfter, opts.dryRun); err != nil {
@@ -105,10 +134,22 @@ func HandleBackupsPurge(backupTimes []internal.BackupTime,
 		return nil, nil, err
 	}
 
+	if opts.windowStart != nil {
+		target := findRetainWindowTarget(backups, *opts.windowStart)
+		if target == nil {
+			tracelog.InfoLogger.Printf("No backup finished before %s, nothing to delete", opts.windowStart.Format(time.RFC3339))
+			return nil, backups, nil
+		}
+		if err := checkOplogCoverage(downloader, target); err != nil {
+			return nil, nil, fmt.Errorf("the retention window is not covered, nothing is deleted: %w", err)
+		}
+		opts.retainSince = &target.StartLocalTime
+	}
+
 	timedBackups := archive.MongoModelToTimedBackup(backups)
 
 	internal.SortTimedBackup(timedBackups)
-	purgeBackups, retainBackups, err := internal.SplitPurgingBackups(timedBackups, opts.retainCount, opts.retainAfter)
+	purgeBackups, retainBackups, err := splitPurgingBackups(timedBackups, opts)
 
 	if err != nil {
 		return nil, nil, err
@@ -126,3 +167,50 @@ func HandleBackupsPurge(backupTimes []internal.BackupTime,
 	}
 	return purge, retain, nil
 }
+
+// findRetainWindowTarget returns the newest backup finished before the window start, it is needed to restore
+// to any point of the window. Returns nil if there is no such backup.
+func findRetainWindowTarget(backups []*models.Backup, windowStart time.Time) *models.Backup {
+	var target *models.Backup
+	for _, backup := range backups {
+		if backup.FinishLocalTime.After(windowStart) {
+			continue
+		}
+		if target == nil || backup.FinishLocalTime.After(target.FinishLocalTime) {
+			target = backup
+		}
+	}
+	return target
+}
+
+// checkOplogCoverage checks that the oplog archives are continuous since the start of the backup
+// up to the latest archive in storage
+func checkOplogCoverage(downloader archive.Downloader, backup *models.Backup) error {
+	archives, err := downloader.ListOplogArchives()
+	if err != nil {
+		return fmt.Errorf("can not load oplog archives: %w", err)
+	}
+	lastTS, err := downloader.LastKnownArchiveTS()
+	if err != nil {
+		return fmt.Errorf("can not fetch the last known oplog archive timestamp: %w", err)
+	}
+	if _, err := archive.SequenceBetweenTS(archives, backup.MongoMeta.Before.LastMajTS, lastTS); err != nil {
+		return fmt.Errorf("oplog since backup %s is not continuous: %w", backup.BackupName, err)
+	}
+	return nil
+}
+
+func splitPurgingBackups(backups []internal.TimedBackup, opts PurgeSettings) (purge, retain map[string]bool, err error) {
+	if opts.retainSince != nil {
+		purge, retain = make(map[string]bool), make(map[string]bool)
+		for _, backup := range backups {
+			if backup.IsPermanent() || !backup.StartTime().Before(*opts.retainSince) {
+				retain[backup.Name()] = true
+			} else {
+				purge[backup.Name()] = true
+			}
+		}
+		return purge, retain, nil
+	}
+	return internal.SplitPurgingTimedBackups(backups, opts.retainCount, opts.retainAfter, opts.gfsPolicy)
+}
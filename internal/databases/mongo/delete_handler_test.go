@@ -0,0 +1,93 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	mocks "github.com/wal-g/wal-g/internal/databases/mongo/archive/mocks"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+)
+
+func retainWindowBackups() []*models.Backup {
+	return []*models.Backup{
+		{
+			BackupName:     "stream_3",
+			StartLocalTime: time.Unix(800, 0), FinishLocalTime: time.Unix(900, 0),
+			MongoMeta: models.MongoMeta{Before: models.NodeMeta{LastMajTS: models.Timestamp{TS: 800}}},
+		},
+		{
+			BackupName:     "stream_2",
+			StartLocalTime: time.Unix(600, 0), FinishLocalTime: time.Unix(700, 0),
+			MongoMeta: models.MongoMeta{Before: models.NodeMeta{LastMajTS: models.Timestamp{TS: 600}}},
+		},
+		{
+			BackupName:     "stream_1",
+			StartLocalTime: time.Unix(300, 0), FinishLocalTime: time.Unix(400, 0),
+			MongoMeta: models.MongoMeta{Before: models.NodeMeta{LastMajTS: models.Timestamp{TS: 300}}},
+		},
+	}
+}
+
+func retainWindowDownloader(archives []models.Archive) *mocks.Downloader {
+	dl := &mocks.Downloader{}
+	dl.On("LoadBackups", mock.Anything).Return(retainWindowBackups(), nil).Once()
+	dl.On("ListOplogArchives").Return(archives, nil)
+	dl.On("LastKnownArchiveTS").Return(archives[len(archives)-1].End, nil)
+	return dl
+}
+
+func TestHandleBackupsPurge_RetainWindow(t *testing.T) {
+	archives := []models.Archive{
+		{Start: models.Timestamp{TS: 200}, End: models.Timestamp{TS: 500}, Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 500}, End: models.Timestamp{TS: 650}, Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 650}, End: models.Timestamp{TS: 950}, Type: models.ArchiveTypeOplog},
+	}
+	// stream_2 started before the window start but finished in the window, so stream_1 is needed
+	windowStart := time.Unix(650, 0)
+	purger := &mocks.Purger{}
+
+	purge, retain, err := HandleBackupsPurge(make([]internal.BackupTime, 3), retainWindowDownloader(archives), purger,
+		PurgeSettings{windowStart: &windowStart, dryRun: true})
+	require.NoError(t, err)
+	assert.Empty(t, purge)
+	assert.Len(t, retain, 3)
+
+	windowStart = time.Unix(750, 0)
+	purger.On("DeleteBackups", mock.MatchedBy(func(backups []*models.Backup) bool {
+		return len(backups) == 1 && backups[0].BackupName == "stream_1"
+	})).Return(nil).Once()
+
+	purge, retain, err = HandleBackupsPurge(make([]internal.BackupTime, 3), retainWindowDownloader(archives), purger,
+		PurgeSettings{windowStart: &windowStart})
+	require.NoError(t, err)
+	assert.Len(t, purge, 1)
+	assert.Len(t, retain, 2)
+	purger.AssertExpectations(t)
+}
+
+func TestHandleBackupsPurge_RetainWindowOplogGap(t *testing.T) {
+	archives := []models.Archive{
+		{Start: models.Timestamp{TS: 200}, End: models.Timestamp{TS: 500}, Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 500}, End: models.Timestamp{TS: 650}, Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 700}, End: models.Timestamp{TS: 950}, Type: models.ArchiveTypeOplog},
+	}
+	windowStart := time.Unix(750, 0)
+	purger := &mocks.Purger{}
+
+	_, _, err := HandleBackupsPurge(make([]internal.BackupTime, 3), retainWindowDownloader(archives), purger,
+		PurgeSettings{windowStart: &windowStart})
+	assert.Error(t, err)
+	purger.AssertNotCalled(t, "DeleteBackups", mock.Anything)
+}
+
+func TestFindRetainWindowTarget(t *testing.T) {
+	backups := retainWindowBackups()
+	assert.Nil(t, findRetainWindowTarget(backups, time.Unix(399, 0)))
+	assert.Equal(t, "stream_1", findRetainWindowTarget(backups, time.Unix(400, 0)).BackupName)
+	assert.Equal(t, "stream_1", findRetainWindowTarget(backups, time.Unix(650, 0)).BackupName)
+	assert.Equal(t, "stream_3", findRetainWindowTarget(backups, time.Unix(1000, 0)).BackupName)
+}
@@ -24,6 +24,8 @@ type Backup struct {
 	Permanent        bool        `json:"Permanent"`
 	UncompressedSize int64       `json:"UncompressedSize,omitempty"`
 	CompressedSize   int64       `json:"DataSize,omitempty"`
+	// CompressionLevels are the levels chosen for the stream files by the adaptive compression method
+	CompressionLevels map[string]string `json:"CompressionLevels,omitempty"`
 }
 
 func (b *Backup) Name() string {
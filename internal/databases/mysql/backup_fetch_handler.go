@@ -10,7 +10,9 @@ import (
 func HandleBackupFetch(folder storage.Folder,
 	targetBackupSelector internal.BackupSelector,
 	restoreCmd *exec.Cmd,
-	prepareCmd *exec.Cmd) {
+	prepareCmd *exec.Cmd,
+	tables []string,
+	tablesDst string) {
 	backup, err := targetBackupSelector.Select(folder)
 	tracelog.ErrorLogger.FatalfOnError("Failed to get backup: %v", err)
 
@@ -18,11 +20,24 @@ func HandleBackupFetch(folder storage.Folder,
 	err = backup.FetchSentinel(&sentinel)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch sentinel: %v", err)
 
+	if len(tables) > 0 {
+		if sentinel.Tool != WalgXtrabackupTool {
+			tracelog.ErrorLogger.Fatalf("Table-level restore is supported for xtrabackup backups only, %s was taken with %s",
+				backup.Name, sentinel.Tool)
+		}
+		err = internal.HandleBackupFetch(folder, targetBackupSelector,
+			GetXtrabackupTablesFetcher(restoreCmd, prepareCmd, tables, tablesDst))
+		tracelog.ErrorLogger.FatalOnError(err)
+		return
+	}
+
 	// we should ba able to read & restore any backup we ever created:
 	if sentinel.Tool == WalgXtrabackupTool {
-		internal.HandleBackupFetch(folder, targetBackupSelector, GetXtrabackupFetcher(restoreCmd, prepareCmd))
+		err = internal.HandleBackupFetch(folder, targetBackupSelector, GetXtrabackupFetcher(restoreCmd, prepareCmd))
+		tracelog.ErrorLogger.FatalOnError(err)
 	} else {
-		internal.HandleBackupFetch(folder, targetBackupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
+		err = internal.HandleBackupFetch(folder, targetBackupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
+		tracelog.ErrorLogger.FatalOnError(err)
 		if prepareCmd != nil {
 			err = prepareCmd.Run()
 			tracelog.ErrorLogger.FatalfOnError("failed to prepare fetched backup: %v", err)
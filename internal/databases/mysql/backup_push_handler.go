@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"fmt"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"os"
 	"os/exec"
@@ -10,6 +11,7 @@ import (
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/limiters"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -22,7 +24,13 @@ func HandleBackupPush(
 	isFullBackup bool,
 	userDataRaw string,
 	deltaBackupConfigurator DeltaBackupConfigurator,
-) {
+) (err error) {
+	notification, err := notify.Start(notify.BackupPush)
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = ""
@@ -30,41 +38,61 @@ func HandleBackupPush(
 	}
 
 	db, err := getMySQLConnection()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 	defer utility.LoggedClose(db, "")
 
 	version, err := getMySQLVersion(db)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 
 	flavor, err := getMySQLFlavor(db)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 
 	serverUUID, err := getServerUUID(db, flavor)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 
 	gtidStart, err := getMySQLGTIDExecuted(db, flavor)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 
 	binlogStart, err := getLastUploadedBinlogBeforeGTID(folder, gtidStart, flavor)
-	tracelog.ErrorLogger.FatalfOnError("failed to get last uploaded binlog: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to get last uploaded binlog: %w", err)
+	}
 	timeStart := utility.TimeNowCrossPlatformLocal()
 
 	var backupName string
 	var prevBackupInfo PrevBackupInfo
 	var incrementCount int
 	var xtrabackupInfo XtrabackupInfo
+	// the sentinel must list only the levels of this backup streams
+	internal.ResetAdaptiveCompressionLevels(uploader)
 	if isXtrabackup(backupCmd) {
 		prevBackupInfo, incrementCount, err = deltaBackupConfigurator.Configure(isFullBackup, hostname, serverUUID, version)
-		tracelog.ErrorLogger.FatalfOnError("failed to get previous backup for delta backup: %v", err)
+		if err != nil {
+			return fmt.Errorf("failed to get previous backup for delta backup: %w", err)
+		}
 
 		backupName, xtrabackupInfo, err = handleXtrabackupBackup(uploader, backupCmd, isFullBackup, &prevBackupInfo)
 	} else {
 		backupName, err = handleRegularBackup(uploader, backupCmd)
 	}
-	tracelog.ErrorLogger.FatalfOnError("backup create command failed: %v", err)
+	if err != nil {
+		return fmt.Errorf("backup create command failed: %w", err)
+	}
 
 	binlogEnd, err := getLastUploadedBinlog(folder)
-	tracelog.ErrorLogger.FatalfOnError("failed to get last uploaded binlog (after): %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to get last uploaded binlog (after): %w", err)
+	}
 	timeStop := utility.TimeNowCrossPlatformLocal()
 
 	uploadedSize, err := uploader.UploadedDataSize()
@@ -78,10 +106,12 @@ func HandleBackupPush(
 	}
 
 	userData, err := internal.UnmarshalSentinelUserData(userDataRaw)
-	tracelog.ErrorLogger.FatalfOnError("Failed to unmarshal the provided UserData: %s", err)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the provided UserData: %w", err)
+	}
 
 	var incrementFrom *string
-	if (prevBackupInfo != PrevBackupInfo{}) {
+	if prevBackupInfo.name != "" {
 		incrementFrom = &prevBackupInfo.name
 	}
 
@@ -109,19 +139,30 @@ func HandleBackupPush(
 		IncrementFrom:     incrementFrom,
 		IncrementFullName: prevBackupInfo.fullBackupName,
 		IncrementCount:    &incrementCount,
+		CompressionLevels: internal.AdaptiveCompressionLevels(uploader),
 	}
 	tracelog.InfoLogger.Printf("Backup sentinel: %s", sentinel.String())
 
+	notification.SetBackupName(backupName)
 	err = internal.UploadSentinel(uploader, &sentinel, backupName)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
+
+	notification.SetSizes(uploadedSize, rawSize)
+	return nil
 }
 
 func handleRegularBackup(uploader internal.Uploader, backupCmd *exec.Cmd) (backupName string, err error) {
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
-	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
+	if err != nil {
+		return "", fmt.Errorf("failed to start backup create command: %w", err)
+	}
 
 	backupName, err = uploader.PushStream(context.Background(), limiters.NewDiskLimitReader(stdout))
-	tracelog.ErrorLogger.FatalfOnError("failed to push backup: %v", err)
+	if err != nil {
+		return "", fmt.Errorf("failed to push backup: %w", err)
+	}
 
 	err = backupCmd.Wait()
 	if err != nil {
@@ -137,21 +178,27 @@ func handleXtrabackupBackup(
 	prevBackupInfo *PrevBackupInfo,
 ) (backupName string, backupInfo XtrabackupInfo, err error) {
 	if prevBackupInfo == nil {
-		tracelog.ErrorLogger.Fatalf("PrevBackupInfo is null")
+		return "", backupInfo, fmt.Errorf("PrevBackupInfo is null")
 	}
 
 	tmpDirRoot := "/tmp" // There is no Percona XtraBackup for Windows (c) @PeterZaitsev
 	xtrabackupExtraDirectory, err := prepareTemporaryDirectory(tmpDirRoot)
-	tracelog.ErrorLogger.FatalfOnError("failed to prepare tmp directory for diff-backup: %v", err)
+	if err != nil {
+		return "", backupInfo, fmt.Errorf("failed to prepare tmp directory for diff-backup: %w", err)
+	}
 
 	enrichBackupArgs(backupCmd, xtrabackupExtraDirectory, isFullBackup, prevBackupInfo)
 	tracelog.InfoLogger.Printf("Command to execute: %v", strings.Join(backupCmd.Args, " "))
 
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
-	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
+	if err != nil {
+		return "", backupInfo, fmt.Errorf("failed to start backup create command: %w", err)
+	}
 
 	backupName, err = uploader.PushStream(context.Background(), limiters.NewDiskLimitReader(stdout))
-	tracelog.ErrorLogger.FatalfOnError("failed to push backup: %v", err)
+	if err != nil {
+		return "", backupInfo, fmt.Errorf("failed to push backup: %w", err)
+	}
 
 	err = backupCmd.Wait()
 	if err != nil {
@@ -43,7 +43,8 @@ func (ih *indexHandler) createIndexFile() error {
 	return nil
 }
 
-func HandleBinlogFetch(folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string, skipStartTime bool) {
+func HandleBinlogFetch(folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string, skipStartTime bool,
+	untilGTID string, untilPosition string) {
 	dstDir, err := internal.GetLogsDstSettings(conf.MysqlBinlogDstSetting)
 	tracelog.ErrorLogger.FatalOnError(err)
 	var startTS, endTS, endBinlogTS time.Time
@@ -53,12 +54,18 @@ func HandleBinlogFetch(folder storage.Folder, backupName string, untilTS string,
 		startTS, endTS, endBinlogTS, err = getTimestamps(folder, backupName, untilTS, untilBinlogLastModifiedTS)
 	}
 	tracelog.ErrorLogger.FatalOnError(err)
+	stopPoint, err := newBinlogStopPoint(untilGTID, untilPosition)
+	tracelog.ErrorLogger.FatalOnError(err)
 
 	handler := newIndexHandler(dstDir)
 
 	tracelog.InfoLogger.Printf("Fetching binlogs since %s until %s", startTS, endTS)
-	err = fetchLogs(folder, dstDir, startTS, endTS, endBinlogTS, handler)
+	err = fetchLogs(folder, dstDir, startTS, endTS, endBinlogTS, stopPoint, handler)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch binlogs: %v", err)
+	if stopPoint != nil {
+		tracelog.InfoLogger.Printf("Stop applying the binlog %s at position %d: mysqlbinlog --stop-position=%d",
+			stopPoint.binlogName, stopPoint.position, stopPoint.position)
+	}
 
 	err = handler.createIndexFile()
 	tracelog.ErrorLogger.FatalfOnError("Failed to create binlog index file: %v", err)
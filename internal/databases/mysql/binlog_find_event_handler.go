@@ -0,0 +1,175 @@
+package mysql
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/printlist"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// FoundBinlogEvent is the archived binlog statement matching the searched pattern.
+// Position is the start of the transaction containing the statement, so it can be passed to --until-position.
+type FoundBinlogEvent struct {
+	Binlog    string    `json:"binlog"`
+	Position  uint32    `json:"position"`
+	Time      time.Time `json:"time"`
+	GTID      string    `json:"gtid,omitempty"`
+	Statement string    `json:"statement"`
+}
+
+func (e *FoundBinlogEvent) PrintableFields() []printlist.TableField {
+	prettyTime := internal.PrettyFormatTime(e.Time)
+	return []printlist.TableField{
+		{
+			Name:       "binlog",
+			PrettyName: "Binlog",
+			Value:      e.Binlog,
+		},
+		{
+			Name:       "position",
+			PrettyName: "Position",
+			Value:      strconv.FormatUint(uint64(e.Position), 10),
+		},
+		{
+			Name:        "time",
+			PrettyName:  "Time",
+			Value:       internal.FormatTime(e.Time),
+			PrettyValue: &prettyTime,
+		},
+		{
+			Name:       "gtid",
+			PrettyName: "GTID",
+			Value:      e.GTID,
+		},
+		{
+			Name:       "statement",
+			PrettyName: "Statement",
+			Value:      e.Statement,
+		},
+	}
+}
+
+type eventFinder struct {
+	pattern *regexp.Regexp
+	events  []FoundBinlogEvent
+}
+
+func newEventFinder(pattern *regexp.Regexp) *eventFinder {
+	return &eventFinder{pattern: pattern}
+}
+
+func (ef *eventFinder) handleBinlog(binlogPath string) error {
+	defer os.Remove(binlogPath)
+	binlogName := path.Base(binlogPath)
+	tracelog.InfoLogger.Printf("searching %s ...", binlogName)
+
+	// transactionPosition is the start of the current transaction, zero outside the transactions
+	var transactionPosition uint32
+	var gtid string
+	inTransaction := false
+	endTransaction := func() {
+		transactionPosition = 0
+		gtid = ""
+		inTransaction = false
+	}
+	parser := replication.NewBinlogParser()
+	parser.SetFlavor(mysql.MySQLFlavor)
+	parser.SetVerifyChecksum(false)
+	err := parser.ParseFile(binlogPath, 0, func(e *replication.BinlogEvent) error {
+		position := e.Header.LogPos - e.Header.EventSize
+		var statement []byte
+		switch event := e.Event.(type) {
+		case *replication.GTIDEvent:
+			sid, err := uuid.FromBytes(event.SID)
+			if err != nil {
+				return err
+			}
+			transactionPosition = position
+			gtid = fmt.Sprintf("%s:%d", sid, event.GNO)
+			return nil
+		case *replication.MariadbGTIDEvent:
+			transactionPosition = position
+			gtid = event.GTID.String()
+			return nil
+		case *replication.XIDEvent:
+			endTransaction()
+			return nil
+		case *replication.QueryEvent:
+			switch strings.ToUpper(strings.TrimSpace(string(event.Query))) {
+			case "BEGIN":
+				// the binlogs written without GTIDs start the transaction here
+				if transactionPosition == 0 {
+					transactionPosition = position
+				}
+				inTransaction = true
+				return nil
+			case "COMMIT", "ROLLBACK":
+				endTransaction()
+				return nil
+			}
+			statement = event.Query
+		case *replication.RowsQueryEvent:
+			statement = event.Query
+		default:
+			if e.Header.EventType == replication.ANONYMOUS_GTID_EVENT {
+				transactionPosition = position
+				gtid = ""
+			}
+			return nil
+		}
+		if transactionPosition == 0 {
+			// the statement outside BEGIN is the transaction itself, e.g. DDL
+			transactionPosition = position
+		}
+		if ef.pattern.Match(statement) {
+			ef.events = append(ef.events, FoundBinlogEvent{
+				Binlog:    binlogName,
+				Position:  transactionPosition,
+				Time:      time.Unix(int64(e.Header.Timestamp), 0).UTC(),
+				GTID:      gtid,
+				Statement: string(statement),
+			})
+		}
+		if !inTransaction {
+			endTransaction()
+		}
+		return nil
+	})
+	return errors.Wrapf(err, "failed to parse binlog %s", binlogName)
+}
+
+// HandleBinlogFindEvent searches the binlogs archived since the backup for the statements matching the pattern
+func HandleBinlogFindEvent(folder storage.Folder, backupName string, untilTS string, pattern string, pretty, json bool) {
+	re, err := regexp.Compile(pattern)
+	tracelog.ErrorLogger.FatalfOnError("Failed to compile the pattern: %v", err)
+	startTS, endTS, endBinlogTS, err := getTimestamps(folder, backupName, untilTS, "")
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	dstDir, err := os.MkdirTemp("", "binlog-find-event")
+	tracelog.ErrorLogger.FatalOnError(err)
+	defer os.RemoveAll(dstDir)
+
+	finder := newEventFinder(re)
+	tracelog.InfoLogger.Printf("Searching binlogs since %s until %s", startTS, endTS)
+	err = fetchLogs(folder, dstDir, startTS, endTS, endBinlogTS, nil, finder)
+	tracelog.ErrorLogger.FatalfOnError("Failed to search binlogs: %v", err)
+
+	printableEntities := make([]printlist.Entity, len(finder.events))
+	for i := range finder.events {
+		printableEntities[i] = &finder.events[i]
+	}
+	err = printlist.List(printableEntities, os.Stdout, pretty, json)
+	tracelog.ErrorLogger.FatalfOnError("Print binlog events: %v", err)
+}
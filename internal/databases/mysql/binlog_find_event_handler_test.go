@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFinder(t *testing.T) {
+	binlogPath := writeTestBinlog(t, "mysql-bin.000017")
+	stopPoint, err := newBinlogStopPoint(testServerUUID+":2", "")
+	require.NoError(t, err)
+	_, err = stopPoint.locate(binlogPath, nil)
+	require.NoError(t, err)
+
+	finder := newEventFinder(regexp.MustCompile("(?i)drop table"))
+	require.NoError(t, finder.handleBinlog(binlogPath))
+
+	require.Len(t, finder.events, 1)
+	event := finder.events[0]
+	assert.Equal(t, "mysql-bin.000017", event.Binlog)
+	assert.Equal(t, testServerUUID+":2", event.GTID)
+	assert.Equal(t, "DROP TABLE t1", event.Statement)
+	assert.Equal(t, int64(1700000000), event.Time.Unix())
+	// the found position stops the replay right before the transaction
+	assert.Equal(t, stopPoint.stopPosition("mysql-bin.000017"), event.Position)
+
+	_, err = os.Stat(binlogPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestEventFinderWithoutGTID(t *testing.T) {
+	header, err := os.ReadFile(testFilenameSmall)
+	require.NoError(t, err)
+	data := header[:testBinlogHeaderSize]
+	insertPosition := uint32(len(data))
+	data = appendTestQueryEvent(data, "BEGIN")
+	data = appendTestQueryEvent(data, "INSERT INTO t1 VALUES (1)")
+	data = appendTestBinlogEvent(data, replication.XID_EVENT, make([]byte, 8))
+	dropPosition := uint32(len(data))
+	data = appendTestQueryEvent(data, "DROP TABLE t1")
+	deletePosition := uint32(len(data))
+	data = appendTestQueryEvent(data, "BEGIN")
+	data = appendTestQueryEvent(data, "DELETE FROM t2")
+	data = appendTestQueryEvent(data, "COMMIT")
+	binlogPath := filepath.Join(t.TempDir(), "mysql-bin.000018")
+	require.NoError(t, os.WriteFile(binlogPath, data, 0600))
+
+	finder := newEventFinder(regexp.MustCompile("(?i)insert|drop|delete"))
+	require.NoError(t, finder.handleBinlog(binlogPath))
+
+	require.Len(t, finder.events, 3)
+	assert.Equal(t, insertPosition, finder.events[0].Position)
+	assert.Equal(t, dropPosition, finder.events[1].Position)
+	assert.Equal(t, deletePosition, finder.events[2].Position)
+	for _, event := range finder.events {
+		assert.Empty(t, event.GTID)
+		assert.Equal(t, time.UTC, event.Time.Location())
+	}
+}
@@ -9,16 +9,20 @@ import (
 )
 
 func HandleBinlogFind(folder storage.Folder, gtid string) {
-	db, err := getMySQLConnection()
-	tracelog.ErrorLogger.FatalOnError(err)
-	defer utility.LoggedClose(db, "")
-	flavor, err := getMySQLFlavor(db)
-	tracelog.ErrorLogger.FatalOnError(err)
+	var flavor string
 	var gtidSet gomysql.GTIDSet
+	var err error
 	if gtid == "" {
+		db, err := getMySQLConnection()
+		tracelog.ErrorLogger.FatalOnError(err)
+		defer utility.LoggedClose(db, "")
+		flavor, err = getMySQLFlavor(db)
+		tracelog.ErrorLogger.FatalOnError(err)
 		gtidSet, err = getMySQLGTIDExecuted(db, flavor)
 		tracelog.ErrorLogger.FatalOnError(err)
 	} else {
+		flavor, err = getArchivedBinlogFlavor(folder)
+		tracelog.ErrorLogger.FatalOnError(err)
 		gtidSet, err = gomysql.ParseGTIDSet(flavor, gtid)
 		tracelog.ErrorLogger.FatalOnError(err)
 	}
@@ -26,3 +30,27 @@ func HandleBinlogFind(folder storage.Folder, gtid string) {
 	tracelog.ErrorLogger.FatalOnError(err)
 	tracelog.InfoLogger.Println(name)
 }
+
+// getArchivedBinlogFlavor takes the flavor from the index of the last archived binlog,
+// MySQL is asked for it only if the binlog has no index
+func getArchivedBinlogFlavor(folder storage.Folder) (string, error) {
+	lastBinlog, err := getLastUploadedBinlog(folder)
+	if err != nil {
+		return "", err
+	}
+	if lastBinlog != "" {
+		index, err := FetchBinlogIndex(folder, lastBinlog)
+		if err != nil {
+			return "", err
+		}
+		if index != nil {
+			return index.Flavor, nil
+		}
+	}
+	db, err := getMySQLConnection()
+	if err != nil {
+		return "", err
+	}
+	defer utility.LoggedClose(db, "")
+	return getMySQLFlavor(db)
+}
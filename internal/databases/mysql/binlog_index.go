@@ -0,0 +1,175 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const BinlogIndexPath = "binlog_index_" + utility.VersionStr + "/"
+
+// BinlogIndexDto describes the content of the archived binlog, so the binlogs can be looked up
+// by GTID and time without downloading them or connecting to MySQL
+type BinlogIndexDto struct {
+	Binlog         string    `json:"binlog"`
+	Flavor         string    `json:"flavor"`
+	ServerUUID     string    `json:"server_uuid,omitempty"`
+	FirstEventTime time.Time `json:"first_event_time"`
+	LastEventTime  time.Time `json:"last_event_time"`
+	// PreviousGTIDs are the GTIDs executed before the binlog
+	PreviousGTIDs string `json:"previous_gtids"`
+	// GTIDs are the GTIDs of the transactions in the binlog
+	GTIDs string `json:"gtids"`
+}
+
+func (dto *BinlogIndexDto) String() string {
+	result, _ := json.Marshal(dto)
+	return string(result)
+}
+
+func (dto *BinlogIndexDto) GetPreviousGTIDs() (mysql.GTIDSet, error) {
+	return mysql.ParseGTIDSet(dto.Flavor, dto.PreviousGTIDs)
+}
+
+func (dto *BinlogIndexDto) GetGTIDs() (mysql.GTIDSet, error) {
+	return mysql.ParseGTIDSet(dto.Flavor, dto.GTIDs)
+}
+
+// NewBinlogIndex parses the whole binlog to index its content
+func NewBinlogIndex(binlogPath, binlogName, flavor, serverUUID string) (*BinlogIndexDto, error) {
+	previousGTIDs, err := mysql.ParseGTIDSet(flavor, "")
+	if err != nil {
+		return nil, err
+	}
+	gtids, err := mysql.ParseGTIDSet(flavor, "")
+	if err != nil {
+		return nil, err
+	}
+	index := &BinlogIndexDto{Binlog: binlogName, Flavor: flavor, ServerUUID: serverUUID}
+
+	parser := replication.NewBinlogParser()
+	parser.SetFlavor(flavor)
+	parser.SetVerifyChecksum(false) // the faster, the better
+	parser.SetRawMode(true)         // choose events to parse manually
+	err = parser.ParseFile(binlogPath, 0, func(event *replication.BinlogEvent) error {
+		if event.Header.Timestamp != 0 {
+			eventTime := time.Unix(int64(event.Header.Timestamp), 0).UTC()
+			if index.FirstEventTime.IsZero() {
+				index.FirstEventTime = eventTime
+			}
+			index.LastEventTime = eventTime
+		}
+		data := event.RawData[replication.EventHeaderSize:]
+		switch event.Header.EventType {
+		case replication.PREVIOUS_GTIDS_EVENT:
+			previousGTIDsEvent := &replication.PreviousGTIDsEvent{}
+			if err := previousGTIDsEvent.Decode(data); err != nil {
+				return err
+			}
+			previousGTIDs, err = mysql.ParseMysqlGTIDSet(previousGTIDsEvent.GTIDSets)
+			return err
+		case replication.GTID_EVENT:
+			gtidEvent := &replication.GTIDEvent{}
+			if err := gtidEvent.Decode(data); err != nil {
+				return err
+			}
+			sid, err := uuid.FromBytes(gtidEvent.SID)
+			if err != nil {
+				return err
+			}
+			return gtids.Update(fmt.Sprintf("%s:%d", sid, gtidEvent.GNO))
+		case replication.MARIADB_GTID_LIST_EVENT:
+			listEvent := &replication.MariadbGTIDListEvent{}
+			if err := listEvent.Decode(data); err != nil {
+				return err
+			}
+			for i := range listEvent.GTIDs {
+				if err := previousGTIDs.Update(listEvent.GTIDs[i].String()); err != nil {
+					return err
+				}
+			}
+		case replication.MARIADB_GTID_EVENT:
+			gtidEvent := &replication.MariadbGTIDEvent{}
+			if err := gtidEvent.Decode(data); err != nil {
+				return err
+			}
+			gtidEvent.GTID.ServerID = event.Header.ServerID
+			return gtids.Update(gtidEvent.GTID.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to index binlog %s", binlogName)
+	}
+	index.PreviousGTIDs = previousGTIDs.String()
+	index.GTIDs = gtids.String()
+	return index, nil
+}
+
+func getBinlogIndexName(binlogName string) string {
+	return binlogName + ".json"
+}
+
+func UploadBinlogIndex(folder storage.Folder, index *BinlogIndexDto) error {
+	body, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return folder.GetSubFolder(BinlogIndexPath).PutObject(getBinlogIndexName(index.Binlog), bytes.NewReader(body))
+}
+
+// FetchBinlogIndex returns nil if the binlog was archived without the index
+func FetchBinlogIndex(folder storage.Folder, binlogName string) (*BinlogIndexDto, error) {
+	reader, err := folder.GetSubFolder(BinlogIndexPath).ReadObject(getBinlogIndexName(binlogName))
+	if _, ok := errors.Cause(err).(storage.ObjectNotFoundError); ok {
+		tracelog.DebugLogger.Printf("Binlog %s has no index", binlogName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var index BinlogIndexDto
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the index of binlog %s", binlogName)
+	}
+	return &index, nil
+}
+
+// getBinlogPreviousGTIDs reads the previous GTIDs from the binlog index or from the binlog itself if it has no index
+func getBinlogPreviousGTIDs(folder storage.Folder, logFile storage.Object, flavor string) (mysql.GTIDSet, error) {
+	binlog, err := newArchivedBinlog(folder, logFile)
+	if err != nil {
+		return nil, err
+	}
+	return binlog.previousGTIDs(folder, flavor)
+}
+
+// listIndexedBinlogs returns the names of the binlogs which have the index
+func listIndexedBinlogs(folder storage.Folder) (map[string]bool, error) {
+	indexes, _, err := folder.GetSubFolder(BinlogIndexPath).ListFolder()
+	if err != nil {
+		return nil, err
+	}
+	indexed := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		indexed[strings.TrimSuffix(index.GetName(), getBinlogIndexName(""))] = true
+	}
+	return indexed, nil
+}
@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestNewBinlogIndex(t *testing.T) {
+	binlogPath := writeTestBinlog(t, "mysql-bin.000017")
+	index, err := NewBinlogIndex(binlogPath, "mysql-bin.000017", mysql.MySQLFlavor, testServerUUID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mysql-bin.000017", index.Binlog)
+	assert.Equal(t, mysql.MySQLFlavor, index.Flavor)
+	assert.Equal(t, testServerUUID, index.ServerUUID)
+	assert.Equal(t, time.Unix(1566047760, 0).UTC(), index.FirstEventTime)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), index.LastEventTime)
+	assert.Equal(t, testServerUUID+":1-3", index.GTIDs)
+
+	previousGTIDs, err := GetBinlogPreviousGTIDs(binlogPath, mysql.MySQLFlavor)
+	require.NoError(t, err)
+	assert.Equal(t, previousGTIDs.String(), index.PreviousGTIDs)
+}
+
+func TestBinlogIndex_UploadFetch(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	index := &BinlogIndexDto{
+		Binlog:        "mysql-bin.000017",
+		Flavor:        mysql.MySQLFlavor,
+		PreviousGTIDs: testServerUUID + ":1-10",
+		GTIDs:         testServerUUID + ":11-20",
+	}
+	require.NoError(t, UploadBinlogIndex(folder, index))
+
+	fetched, err := FetchBinlogIndex(folder, "mysql-bin.000017")
+	require.NoError(t, err)
+	assert.Equal(t, index, fetched)
+
+	missing, err := FetchBinlogIndex(folder, "mysql-bin.000018")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// the previous GTIDs are taken from the index without reading the binlog
+	require.NoError(t, folder.GetSubFolder(BinlogPath).PutObject("mysql-bin.000017.br", &bytes.Buffer{}))
+	logFiles, _, err := folder.GetSubFolder(BinlogPath).ListFolder()
+	require.NoError(t, err)
+	require.Len(t, logFiles, 1)
+	previousGTIDs, err := getBinlogPreviousGTIDs(folder, logFiles[0], mysql.MySQLFlavor)
+	require.NoError(t, err)
+	assert.Equal(t, testServerUUID+":1-10", previousGTIDs.String())
+}
+
+func TestBinlogStopPoint_LocateGTIDByIndex(t *testing.T) {
+	stopPoint, err := newBinlogStopPoint(testServerUUID+":2", "")
+	require.NoError(t, err)
+
+	index := &BinlogIndexDto{Binlog: "mysql-bin.000017", Flavor: mysql.MySQLFlavor, GTIDs: testServerUUID + ":5-10"}
+	last, err := stopPoint.locate(writeTestBinlog(t, "mysql-bin.000017"), index)
+	require.NoError(t, err)
+	assert.False(t, last)
+
+	index.PreviousGTIDs = testServerUUID + ":1-4"
+	_, err = stopPoint.locate(writeTestBinlog(t, "mysql-bin.000017"), index)
+	assert.Error(t, err)
+}
+
+func TestGetLogsCoveringInterval_ByIndex(t *testing.T) {
+	baseTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var objectsTime time.Time
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time {
+		return objectsTime
+	})))
+	// the binlogs were copied to the storage in the reverse order, so only the indexed ones have the real times
+	for i, binlogName := range []string{"mysql-bin.000003", "mysql-bin.000002", "mysql-bin.000001"} {
+		objectsTime = baseTime.Add(time.Duration(10+i) * time.Hour)
+		require.NoError(t, folder.GetSubFolder(BinlogPath).PutObject(binlogName+".br", &bytes.Buffer{}))
+	}
+	for i, binlogName := range []string{"mysql-bin.000002", "mysql-bin.000003"} {
+		require.NoError(t, UploadBinlogIndex(folder, &BinlogIndexDto{
+			Binlog:         binlogName,
+			FirstEventTime: baseTime.Add(time.Duration(i) * time.Hour),
+			LastEventTime:  baseTime.Add(time.Duration(i+1) * time.Hour),
+		}))
+	}
+
+	names := func(binlogs []archivedBinlog) []string {
+		var result []string
+		for _, binlog := range binlogs {
+			result = append(result, binlog.GetName())
+		}
+		return result
+	}
+	binlogs, err := getLogsCoveringInterval(folder, baseTime.Add(time.Hour), true, baseTime.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mysql-bin.000002.br", "mysql-bin.000003.br", "mysql-bin.000001.br"}, names(binlogs))
+	assert.Equal(t, baseTime.Add(time.Hour), binlogs[0].Time)
+	assert.NotNil(t, binlogs[0].Index)
+	assert.Nil(t, binlogs[2].Index)
+
+	binlogs, err = getLogsCoveringInterval(folder, baseTime.Add(time.Hour), false, baseTime.Add(11*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mysql-bin.000003.br"}, names(binlogs))
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -40,6 +41,13 @@ func HandleBinlogPush(uploader internal.Uploader, untilBinlog string, checkGTIDs
 	binlogs, err := getMySQLBinlogs(db)
 	tracelog.ErrorLogger.FatalOnError(err)
 
+	flavor, err := getMySQLFlavor(db)
+	tracelog.ErrorLogger.FatalOnError(err)
+	serverUUID, err := getServerUUID(db, flavor)
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to get the server UUID for the binlog index: %v", err)
+	}
+
 	lastBinlog := lastOrDefault(binlogs, "")
 	if untilBinlog == "" || BinlogNum(untilBinlog) > BinlogNum(lastBinlog) {
 		untilBinlog = lastBinlog
@@ -62,9 +70,6 @@ func HandleBinlogPush(uploader internal.Uploader, untilBinlog string, checkGTIDs
 
 	var filter gtidFilter
 	if checkGTIDs {
-		flavor, err := getMySQLFlavor(db)
-		tracelog.ErrorLogger.FatalOnError(err)
-
 		switch flavor {
 		case mysql.MySQLFlavor:
 			gtid, _ := mysql.ParseMysqlGTIDSet(binlogSentinelDto.GTIDArchived)
@@ -121,6 +126,11 @@ func HandleBinlogPush(uploader internal.Uploader, untilBinlog string, checkGTIDs
 		// Upload binlogs:
 		err = archiveBinLog(uploader, binlogsFolder, binlog)
 		tracelog.ErrorLogger.FatalOnError(err)
+		// the index only speeds up the lookups, they fall back to reading the binlogs
+		err = archiveBinlogIndex(rootFolder, binlogsFolder, binlog, flavor, serverUUID)
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to archive the index of binlog %s: %v", binlog, err)
+		}
 
 		cache.LastArchivedBinlog = binlog
 		putCache(cache)
@@ -188,6 +198,15 @@ func archiveBinLog(uploader internal.Uploader, dataDir string, binlog string) er
 	return nil
 }
 
+func archiveBinlogIndex(rootFolder storage.Folder, dataDir, binlog, flavor, serverUUID string) error {
+	index, err := NewBinlogIndex(path.Join(dataDir, binlog), binlog, flavor, serverUUID)
+	if err != nil {
+		return err
+	}
+	tracelog.DebugLogger.Printf("Uploading binlog index: %s", index)
+	return UploadBinlogIndex(rootFolder, index)
+}
+
 func getCache() LogsCache {
 	var cache LogsCache
 	var cacheFilename string
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,30 +16,41 @@ import (
 	"github.com/wal-g/wal-g/utility"
 )
 
-const binlogFetchAhead = 2
+const (
+	binlogFetchAhead = 2
+
+	binlogStopPositionEnv = "WALG_MYSQL_BINLOG_STOP_POSITION"
+)
+
+type binlogToReplay struct {
+	path         string
+	stopPosition uint32
+}
 
 type replayHandler struct {
-	logCh chan string
-	errCh chan error
-	endTS string
+	logCh     chan binlogToReplay
+	errCh     chan error
+	endTS     string
+	stopPoint *binlogStopPoint
 }
 
-func newReplayHandler(endTS time.Time) *replayHandler {
+func newReplayHandler(endTS time.Time, stopPoint *binlogStopPoint) *replayHandler {
 	rh := new(replayHandler)
 	rh.endTS = endTS.Local().Format(TimeMysqlFormat)
-	rh.logCh = make(chan string, binlogFetchAhead)
+	rh.stopPoint = stopPoint
+	rh.logCh = make(chan binlogToReplay, binlogFetchAhead)
 	rh.errCh = make(chan error, 1)
 	go rh.replayLogs()
 	return rh
 }
 
 func (rh *replayHandler) replayLogs() {
-	for binlogPath := range rh.logCh {
-		tracelog.InfoLogger.Printf("replaying %s ...", path.Base(binlogPath))
-		err := rh.replayLog(binlogPath)
-		os.Remove(binlogPath)
+	for binlog := range rh.logCh {
+		tracelog.InfoLogger.Printf("replaying %s ...", path.Base(binlog.path))
+		err := rh.replayLog(binlog)
+		os.Remove(binlog.path)
 		if err != nil {
-			tracelog.ErrorLogger.Printf("failed to replay %s: %v", path.Base(binlogPath), err)
+			tracelog.ErrorLogger.Printf("failed to replay %s: %v", path.Base(binlog.path), err)
 			rh.errCh <- err
 			break
 		}
@@ -46,15 +58,18 @@ func (rh *replayHandler) replayLogs() {
 	close(rh.errCh)
 }
 
-func (rh *replayHandler) replayLog(binlogPath string) error {
+func (rh *replayHandler) replayLog(binlog binlogToReplay) error {
 	cmd, err := internal.GetCommandSetting(conf.MysqlBinlogReplayCmd)
 	if err != nil {
 		return err
 	}
 	env := os.Environ()
 	env = append(env,
-		fmt.Sprintf("%s=%s", "WALG_MYSQL_CURRENT_BINLOG", binlogPath),
+		fmt.Sprintf("%s=%s", "WALG_MYSQL_CURRENT_BINLOG", binlog.path),
 		fmt.Sprintf("%s=%s", "WALG_MYSQL_BINLOG_END_TS", rh.endTS))
+	if binlog.stopPosition > 0 {
+		env = append(env, fmt.Sprintf("%s=%d", binlogStopPositionEnv, binlog.stopPosition))
+	}
 	cmd.Env = env
 	return cmd.Run()
 }
@@ -65,31 +80,54 @@ func (rh *replayHandler) wait() error {
 }
 
 func (rh *replayHandler) handleBinlog(binlogPath string) error {
+	// the stop position is resolved before the binlog is handled, so it's read here rather than in the replaying goroutine
+	binlog := binlogToReplay{path: binlogPath, stopPosition: rh.stopPoint.stopPosition(path.Base(binlogPath))}
 	select {
 	case err := <-rh.errCh:
 		return err
-	case rh.logCh <- binlogPath:
+	case rh.logCh <- binlog:
 		return nil
 	}
 }
 
-func HandleBinlogReplay(folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string) {
+func HandleBinlogReplay(folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string,
+	untilGTID string, untilPosition string) {
 	dstDir, err := internal.GetLogsDstSettings(conf.MysqlBinlogDstSetting)
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	startTS, endTS, endBinlogTS, err := getTimestamps(folder, backupName, untilTS, untilBinlogLastModifiedTS)
 	tracelog.ErrorLogger.FatalOnError(err)
+	stopPoint, err := newBinlogStopPoint(untilGTID, untilPosition)
+	tracelog.ErrorLogger.FatalOnError(err)
+	err = checkReplayCommandStopPosition(stopPoint)
+	tracelog.ErrorLogger.FatalOnError(err)
 
-	handler := newReplayHandler(endTS)
+	handler := newReplayHandler(endTS, stopPoint)
 
 	tracelog.InfoLogger.Printf("Fetching binlogs since %s until %s", startTS, endTS)
-	err = fetchLogs(folder, dstDir, startTS, endTS, endBinlogTS, handler)
+	err = fetchLogs(folder, dstDir, startTS, endTS, endBinlogTS, stopPoint, handler)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch binlogs: %v", err)
 
 	err = handler.wait()
 	tracelog.ErrorLogger.FatalfOnError("Failed to apply binlogs: %v", err)
 }
 
+// checkReplayCommandStopPosition fails when the replay would ignore the stop point: wal-g doesn't run mysqlbinlog itself,
+// so the stop position reaches the replay command only through its environment.
+// The GTID stop point is resolved to the position of its transaction rather than passed to --exclude-gtids,
+// which would skip only that transaction and still apply the ones after it.
+func checkReplayCommandStopPosition(stopPoint *binlogStopPoint) error {
+	if stopPoint == nil {
+		return nil
+	}
+	command, _ := conf.GetSetting(conf.MysqlBinlogReplayCmd)
+	if !strings.Contains(command, binlogStopPositionEnv) {
+		return fmt.Errorf("%s must pass $%s to mysqlbinlog --stop-position to stop the replay at %s",
+			conf.MysqlBinlogReplayCmd, binlogStopPositionEnv, stopPoint)
+	}
+	return nil
+}
+
 func getTimestamps(folder storage.Folder, backupName, untilTS, untilBinlogLastModifiedTS string) (time.Time, time.Time, time.Time, error) {
 	backup, err := internal.GetBackupByName(backupName, utility.BaseBackupPath, folder)
 	if err != nil {
@@ -27,6 +27,9 @@ var (
 	startTS      time.Time
 	untilTS      time.Time
 	lastSentGTID string
+	// pollInterval and heartbeatInterval are set in the follow mode only
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
 )
 
 func handleEventError(err error, s *replication.BinlogStreamer) {
@@ -40,53 +43,87 @@ func handleEventError(err error, s *replication.BinlogStreamer) {
 	}
 }
 
-// see: https://dev.mysql.com/doc/dev/mysql-server/latest/classbinary__log_1_1Rotate__event.html
-func addRotateEvent(s *replication.BinlogStreamer, pos mysql.Position) error {
+// newArtificialEvent creates the event generated by the binlog server itself rather than read from the binlogs
+func newArtificialEvent(eventType replication.EventType, logPos uint32, body []byte) *replication.BinlogEvent {
 	serverID, err := conf.GetRequiredSetting(conf.MysqlBinlogServerID)
 	tracelog.ErrorLogger.FatalOnError(err)
 	ServerIDNum, err := strconv.Atoi(serverID)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	// create rotate event
-	rotateBinlogEvent := replication.BinlogEvent{}
-
-	messageBodySize := 8 + len(pos.Name) + 1
-	eventLength := replication.EventHeaderSize + messageBodySize + replication.BinlogChecksumLength
-
-	rotateBinlogEvent.RawData = make([]byte, eventLength)
+	event := replication.BinlogEvent{}
+	eventLength := replication.EventHeaderSize + len(body) + replication.BinlogChecksumLength
+	event.RawData = make([]byte, eventLength)
 	// generate header:
 	// timestamp default 4 bytes
 	binlogEventPos := 4
 	// type - 1 byte
-	rotateBinlogEvent.RawData[binlogEventPos] = byte(replication.ROTATE_EVENT)
+	event.RawData[binlogEventPos] = byte(eventType)
 	binlogEventPos++
 	// server_id- 4 bytes
-	binary.LittleEndian.PutUint32(rotateBinlogEvent.RawData[binlogEventPos:], uint32(ServerIDNum))
+	binary.LittleEndian.PutUint32(event.RawData[binlogEventPos:], uint32(ServerIDNum))
 	binlogEventPos += 4
 	// event_length - 4 bytes
-	binary.LittleEndian.PutUint32(rotateBinlogEvent.RawData[binlogEventPos:], uint32(eventLength))
+	binary.LittleEndian.PutUint32(event.RawData[binlogEventPos:], uint32(eventLength))
 	binlogEventPos += 4
 	// end_log_pos - 4 bytes
-	binary.LittleEndian.PutUint32(rotateBinlogEvent.RawData[binlogEventPos:], 0)
+	binary.LittleEndian.PutUint32(event.RawData[binlogEventPos:], logPos)
 	binlogEventPos += 4
 	// flags - 2 bytes
-	binary.LittleEndian.PutUint16(rotateBinlogEvent.RawData[binlogEventPos:], 0)
+	binary.LittleEndian.PutUint16(event.RawData[binlogEventPos:], 0)
 	binlogEventPos += 2
 
-	// set binlog event data:
-	// position - 8 bytes
-	binary.LittleEndian.PutUint64(rotateBinlogEvent.RawData[binlogEventPos:], uint64(pos.Pos))
-	binlogEventPos += 8
-	// new binlog name - zero-terminated string
-	copy(rotateBinlogEvent.RawData[binlogEventPos:], pos.Name)
-	binlogEventPos += len(pos.Name)
-	rotateBinlogEvent.RawData[binlogEventPos] = 0
-	binlogEventPos++
+	// set binlog event data
+	copy(event.RawData[binlogEventPos:], body)
+	binlogEventPos += len(body)
 
-	checksum := crc32.ChecksumIEEE(rotateBinlogEvent.RawData[0 : replication.EventHeaderSize+messageBodySize])
-	binary.LittleEndian.PutUint32(rotateBinlogEvent.RawData[binlogEventPos:], checksum)
+	checksum := crc32.ChecksumIEEE(event.RawData[0:binlogEventPos])
+	binary.LittleEndian.PutUint32(event.RawData[binlogEventPos:], checksum)
+	return &event
+}
 
-	return s.AddEventToStreamer(&rotateBinlogEvent)
+// see: https://dev.mysql.com/doc/dev/mysql-server/latest/classbinary__log_1_1Rotate__event.html
+func addRotateEvent(s *replication.BinlogStreamer, pos mysql.Position) error {
+	// position - 8 bytes, new binlog name - zero-terminated string
+	body := make([]byte, 8+len(pos.Name)+1)
+	binary.LittleEndian.PutUint64(body, uint64(pos.Pos))
+	copy(body[8:], pos.Name)
+
+	return s.AddEventToStreamer(newArtificialEvent(replication.ROTATE_EVENT, 0, body))
+}
+
+// see: https://dev.mysql.com/doc/dev/mysql-server/latest/classbinary__log_1_1Heartbeat__event.html
+func addHeartbeatEvent(s *replication.BinlogStreamer, pos mysql.Position) error {
+	// current binlog name - not zero-terminated string
+	return s.AddEventToStreamer(newArtificialEvent(replication.HEARTBEAT_EVENT, pos.Pos, []byte(pos.Name)))
+}
+
+// getNextBinlog waits for the next binlog sending the heartbeats to the replica meanwhile
+func getNextBinlog(logFilesProvider *storage.ObjectProvider, pos mysql.Position, s *replication.BinlogStreamer) (storage.Object, error) {
+	if heartbeatInterval <= 0 {
+		return logFilesProvider.GetObject()
+	}
+	type nextBinlog struct {
+		logFile storage.Object
+		err     error
+	}
+	next := make(chan nextBinlog, 1)
+	go func() {
+		logFile, err := logFilesProvider.GetObject()
+		next <- nextBinlog{logFile, err}
+	}()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case result := <-next:
+			return result.logFile, result.err
+		case <-ticker.C:
+			tracelog.DebugLogger.Printf("Sending heartbeat at %s:%d", pos.Name, pos.Pos)
+			err := addHeartbeatEvent(s, pos)
+			handleEventError(err, s)
+		}
+	}
 }
 
 func waitReplicationIsDone() error {
@@ -120,7 +157,48 @@ func waitReplicationIsDone() error {
 	}
 }
 
-func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mysql.Position, s *replication.BinlogStreamer) {
+// executedGTIDFilter skips the transactions which the replica has already executed
+type executedGTIDFilter struct {
+	executed *mysql.MysqlGTIDSet
+	skipping bool
+}
+
+func newExecutedGTIDFilter(executed *mysql.MysqlGTIDSet) *executedGTIDFilter {
+	return &executedGTIDFilter{executed: executed}
+}
+
+// skip reports whether the event belongs to an executed transaction. The events outside of the transactions are never skipped.
+func (f *executedGTIDFilter) skip(e *replication.BinlogEvent) (bool, error) {
+	if f == nil || f.executed == nil {
+		return false, nil
+	}
+	switch e.Header.EventType {
+	case replication.GTID_EVENT:
+		gtidEvent := &replication.GTIDEvent{}
+		err := gtidEvent.Decode(e.RawData[replication.EventHeaderSize:])
+		if err != nil {
+			return false, err
+		}
+		sid, err := uuid.FromBytes(gtidEvent.SID)
+		if err != nil {
+			return false, err
+		}
+		uuidSet, ok := f.executed.Sets[sid.String()]
+		f.skipping = ok && uuidSet.Contain(mysql.NewUUIDSet(sid, mysql.Interval{Start: gtidEvent.GNO, Stop: gtidEvent.GNO + 1}))
+		return f.skipping, nil
+	case replication.ANONYMOUS_GTID_EVENT:
+		f.skipping = false
+		return false, nil
+	case replication.FORMAT_DESCRIPTION_EVENT, replication.PREVIOUS_GTIDS_EVENT, replication.ROTATE_EVENT,
+		replication.STOP_EVENT, replication.HEARTBEAT_EVENT:
+		return false, nil
+	default:
+		return f.skipping, nil
+	}
+}
+
+func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mysql.Position, filter *executedGTIDFilter,
+	s *replication.BinlogStreamer) {
 	err := addRotateEvent(s, pos)
 	handleEventError(err, s)
 
@@ -130,10 +208,16 @@ func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mys
 	// check checksum on our side - we should exit with error here rather than stuck waiting for MySQL apply all binlogs till `lastSentGTID`.
 	p.SetVerifyChecksum(true)
 
+	// the position of the last event sent to the replica
+	lastPos := pos
 	f := func(e *replication.BinlogEvent) error {
 		if int64(e.Header.Timestamp) > untilTS.Unix() {
 			return nil
 		}
+		skip, err := filter.skip(e)
+		if err != nil || skip {
+			return err
+		}
 		if e.Header.EventType == replication.GTID_EVENT {
 			gtidEvent := &replication.GTIDEvent{}
 			err = gtidEvent.Decode(e.RawData[replication.EventHeaderSize:])
@@ -141,13 +225,15 @@ func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mys
 			u, _ := uuid.FromBytes(gtidEvent.SID)
 			lastSentGTID = u.String() + ":1-" + strconv.Itoa(int(gtidEvent.GNO))
 		}
-		err := s.AddEventToStreamer(e)
-		return err
+		if e.Header.LogPos > 0 {
+			lastPos.Pos = e.Header.LogPos
+		}
+		return s.AddEventToStreamer(e)
 	}
 	dstDir, _ := internal.GetLogsDstSettings(conf.MysqlBinlogDstSetting)
 
 	for {
-		logFile, err := logFilesProvider.GetObject()
+		logFile, err := getNextBinlog(logFilesProvider, lastPos, s)
 		if errors.Is(err, storage.ErrNoMoreObjects) {
 			err := waitReplicationIsDone()
 			if err != nil {
@@ -162,6 +248,7 @@ func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mys
 		binlogName := utility.TrimFileExtension(logFile.GetName())
 		tracelog.InfoLogger.Printf("Synced binlog file %s", binlogName)
 		binlogPath := path.Join(dstDir, binlogName)
+		lastPos = mysql.Position{Name: binlogName, Pos: pos.Pos}
 		err = p.ParseFile(binlogPath, int64(pos.Pos), f)
 		handleEventError(err, s)
 
@@ -171,7 +258,7 @@ func sendEventsFromBinlogFiles(logFilesProvider *storage.ObjectProvider, pos mys
 	}
 }
 
-func syncBinlogFiles(pos mysql.Position, startTS time.Time, s *replication.BinlogStreamer) error {
+func syncBinlogFiles(pos mysql.Position, startTS time.Time, filter *executedGTIDFilter, s *replication.BinlogStreamer) error {
 	// get necessary settings
 	st, err := internal.ConfigureStorage()
 	if err != nil {
@@ -183,8 +270,8 @@ func syncBinlogFiles(pos mysql.Position, startTS time.Time, s *replication.Binlo
 	}
 	logFilesProvider := storage.NewLowMemoryObjectProvider()
 	// start sync
-	go sendEventsFromBinlogFiles(logFilesProvider, pos, s)
-	go provideLogs(st.RootFolder(), dstDir, startTS, untilTS, logFilesProvider)
+	go sendEventsFromBinlogFiles(logFilesProvider, pos, filter, s)
+	go provideLogs(st.RootFolder(), dstDir, startTS, untilTS, pollInterval, logFilesProvider)
 
 	return nil
 }
@@ -209,17 +296,55 @@ func (h Handler) HandleBinlogDump(pos mysql.Position) (*replication.BinlogStream
 	if err != nil {
 		return nil, err
 	}
-	err = syncBinlogFiles(pos, startTime, s)
+	err = syncBinlogFiles(pos, startTime, nil, s)
 	return s, err
 }
 
+// HandleBinlogDumpGTID streams the binlogs starting from the first archived binlog
+// which contains the transactions missing from the replica's executed GTID set
 func (h Handler) HandleBinlogDumpGTID(gtidSet *mysql.MysqlGTIDSet) (*replication.BinlogStreamer, error) {
 	s := replication.NewBinlogStreamer()
 
-	err := syncBinlogFiles(mysql.Position{Name: "host-binlog-file", Pos: 4}, startTS, s)
+	st, err := internal.ConfigureStorage()
+	if err != nil {
+		return nil, err
+	}
+	if gtidSet == nil {
+		gtidSet = &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	}
+
+	pos, since, err := findGTIDDumpStart(st.RootFolder(), gtidSet, startTS)
+	if err != nil {
+		return nil, err
+	}
+	err = syncBinlogFiles(pos, since, newExecutedGTIDFilter(gtidSet), s)
 	return s, err
 }
 
+// findGTIDDumpStart finds the binlog to stream to the replica with the executed GTID set and the time the binlogs
+// are streamed since, the binlogs since defaultSince are streamed if no archived binlog follows the GTID set
+func findGTIDDumpStart(folder storage.Folder, gtidSet *mysql.MysqlGTIDSet,
+	defaultSince time.Time) (mysql.Position, time.Time, error) {
+	logFiles, err := listBinlogsByModificationTime(folder.GetSubFolder(BinlogPath))
+	if err != nil {
+		return mysql.Position{}, time.Time{}, err
+	}
+	startBinlog, err := findBinlogBeforeGTID(folder, logFiles, gtidSet, mysql.MySQLFlavor)
+	if err != nil {
+		return mysql.Position{}, time.Time{}, err
+	}
+
+	pos := mysql.Position{Name: "host-binlog-file", Pos: 4}
+	if startBinlog == nil {
+		tracelog.WarningLogger.Printf("No archived binlog follows the replica executed GTID set '%s', "+
+			"streaming from the binlogs since %s", gtidSet, defaultSince.Format(time.RFC3339))
+		return pos, defaultSince, nil
+	}
+	pos.Name = utility.TrimFileExtension(startBinlog.GetName())
+	tracelog.InfoLogger.Printf("Replica executed GTID set is '%s', streaming from binlog %s", gtidSet, pos.Name)
+	return pos, startBinlog.Time, nil
+}
+
 func (h Handler) HandleQuery(query string) (*mysql.Result, error) {
 	switch strings.ToLower(query) {
 	case "select @master_binlog_checksum":
@@ -258,11 +383,21 @@ func (h Handler) HandleQuery(query string) (*mysql.Result, error) {
 	}
 }
 
-func HandleBinlogServer(since string, until string) {
+// HandleBinlogServer serves the binlogs to a replica. In the follow mode the server streams
+// the binlogs as they are uploaded instead of stopping at until.
+func HandleBinlogServer(since string, until string, follow bool) {
 	st, err := internal.ConfigureStorage()
 	tracelog.ErrorLogger.FatalOnError(err)
 	startTS, untilTS, _, err = getTimestamps(st.RootFolder(), since, until, "")
 	tracelog.ErrorLogger.FatalOnError(err)
+	if follow {
+		untilTS = utility.MaxTime
+		pollInterval, err = conf.GetDurationSetting(conf.MysqlBinlogServerPollInterval)
+		tracelog.ErrorLogger.FatalOnError(err)
+		heartbeatInterval, err = conf.GetDurationSetting(conf.MysqlBinlogServerHeartbeat)
+		tracelog.ErrorLogger.FatalOnError(err)
+		tracelog.InfoLogger.Printf("Following the uploaded binlogs every %v", pollInterval)
+	}
 
 	tracelog.InfoLogger.Printf("Starting binlog server")
 
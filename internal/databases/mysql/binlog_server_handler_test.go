@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const testServerUUID = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+func newTestGTIDEvent(t *testing.T, gno int64) *replication.BinlogEvent {
+	sid, err := uuid.Parse(testServerUUID)
+	require.NoError(t, err)
+	data := make([]byte, replication.EventHeaderSize+1+replication.SidLength+8)
+	body := data[replication.EventHeaderSize:]
+	body[0] = 1
+	copy(body[1:], sid[:])
+	binary.LittleEndian.PutUint64(body[1+replication.SidLength:], uint64(gno))
+	return &replication.BinlogEvent{
+		RawData: data,
+		Header:  &replication.EventHeader{EventType: replication.GTID_EVENT},
+	}
+}
+
+func newTestEvent(eventType replication.EventType) *replication.BinlogEvent {
+	return &replication.BinlogEvent{Header: &replication.EventHeader{EventType: eventType}}
+}
+
+func TestExecutedGTIDFilter(t *testing.T) {
+	executed, err := mysql.ParseMysqlGTIDSet(testServerUUID + ":1-5")
+	require.NoError(t, err)
+	filter := newExecutedGTIDFilter(executed.(*mysql.MysqlGTIDSet))
+
+	var tests = []struct {
+		name  string
+		event *replication.BinlogEvent
+		skip  bool
+	}{
+		{"format description", newTestEvent(replication.FORMAT_DESCRIPTION_EVENT), false},
+		{"executed gtid", newTestGTIDEvent(t, 5), true},
+		{"executed transaction query", newTestEvent(replication.QUERY_EVENT), true},
+		{"executed transaction xid", newTestEvent(replication.XID_EVENT), true},
+		{"rotate inside executed transaction", newTestEvent(replication.ROTATE_EVENT), false},
+		{"missing gtid", newTestGTIDEvent(t, 6), false},
+		{"missing transaction query", newTestEvent(replication.QUERY_EVENT), false},
+		{"executed gtid again", newTestGTIDEvent(t, 1), true},
+		{"anonymous gtid", newTestEvent(replication.ANONYMOUS_GTID_EVENT), false},
+		{"anonymous transaction query", newTestEvent(replication.QUERY_EVENT), false},
+	}
+	for _, test := range tests {
+		skip, err := filter.skip(test.event)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.skip, skip, test.name)
+	}
+}
+
+func TestExecutedGTIDFilter_Nil(t *testing.T) {
+	var filter *executedGTIDFilter
+	skip, err := filter.skip(newTestGTIDEvent(t, 1))
+	assert.NoError(t, err)
+	assert.False(t, skip)
+}
+
+func getTestStreamedEvent(t *testing.T, add func(s *replication.BinlogStreamer) error) (*replication.EventHeader, []byte) {
+	viper.Set(conf.MysqlBinlogServerID, "42")
+	defer viper.Set(conf.MysqlBinlogServerID, nil)
+
+	s := replication.NewBinlogStreamer()
+	require.NoError(t, add(s))
+	events := s.DumpEvents()
+	require.Len(t, events, 1)
+	data := events[0].RawData
+
+	header := &replication.EventHeader{}
+	require.NoError(t, header.Decode(data))
+	assert.Equal(t, uint32(42), header.ServerID)
+	assert.Equal(t, uint32(len(data)), header.EventSize)
+	bodyEnd := len(data) - replication.BinlogChecksumLength
+	assert.Equal(t, crc32.ChecksumIEEE(data[:bodyEnd]), binary.LittleEndian.Uint32(data[bodyEnd:]))
+	return header, data[replication.EventHeaderSize:bodyEnd]
+}
+
+func TestAddRotateEvent(t *testing.T) {
+	header, body := getTestStreamedEvent(t, func(s *replication.BinlogStreamer) error {
+		return addRotateEvent(s, mysql.Position{Name: "mysql-bin.000017", Pos: 4})
+	})
+	assert.Equal(t, replication.ROTATE_EVENT, header.EventType)
+	assert.Equal(t, uint32(0), header.LogPos)
+
+	rotateEvent := &replication.RotateEvent{}
+	require.NoError(t, rotateEvent.Decode(body))
+	assert.Equal(t, uint64(4), rotateEvent.Position)
+	assert.Equal(t, "mysql-bin.000017\x00", string(rotateEvent.NextLogName))
+}
+
+func TestAddHeartbeatEvent(t *testing.T) {
+	header, body := getTestStreamedEvent(t, func(s *replication.BinlogStreamer) error {
+		return addHeartbeatEvent(s, mysql.Position{Name: "mysql-bin.000017", Pos: 1234})
+	})
+	assert.Equal(t, replication.HEARTBEAT_EVENT, header.EventType)
+	assert.Equal(t, uint32(1234), header.LogPos)
+	assert.Equal(t, "mysql-bin.000017", string(body))
+}
+
+func TestFindGTIDDumpStart_IndexedBinlogs(t *testing.T) {
+	baseTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	uploadTime := baseTime.Add(10 * time.Hour)
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time {
+		return uploadTime
+	})))
+	// the binlogs were uploaded by the same binlog-push long after their last events
+	for i, previousGTIDs := range []string{testServerUUID + ":1-5", testServerUUID + ":1-10", testServerUUID + ":1-20"} {
+		binlogName := fmt.Sprintf("mysql-bin.%06d", i+1)
+		require.NoError(t, folder.GetSubFolder(BinlogPath).PutObject(binlogName+".br", &bytes.Buffer{}))
+		require.NoError(t, UploadBinlogIndex(folder, &BinlogIndexDto{
+			Binlog:         binlogName,
+			Flavor:         mysql.MySQLFlavor,
+			FirstEventTime: baseTime.Add(time.Duration(i) * time.Hour),
+			LastEventTime:  baseTime.Add(time.Duration(i+1) * time.Hour),
+			PreviousGTIDs:  previousGTIDs,
+		}))
+	}
+
+	executed, err := mysql.ParseMysqlGTIDSet(testServerUUID + ":1-15")
+	require.NoError(t, err)
+	pos, since, err := findGTIDDumpStart(folder, executed.(*mysql.MysqlGTIDSet), baseTime)
+	require.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000002", pos.Name)
+	assert.Equal(t, baseTime.Add(2*time.Hour), since)
+
+	binlogTS, err := GetBinlogTS(folder, "mysql-bin.000002")
+	require.NoError(t, err)
+	assert.Equal(t, since, binlogTS)
+
+	// the start binlog and the binlogs uploaded along with it are streamed
+	binlogs, err := getLogsCoveringInterval(folder, since, true, utility.MaxTime)
+	require.NoError(t, err)
+	var names []string
+	for _, binlog := range binlogs {
+		names = append(names, binlog.GetName())
+	}
+	assert.Equal(t, []string{"mysql-bin.000002.br", "mysql-bin.000003.br"}, names)
+}
@@ -0,0 +1,165 @@
+package mysql
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+)
+
+// binlogStopPoint is the first event which must not be applied during PITR.
+// The GTID stop point is resolved to the position of the GTID event when the binlog containing it is fetched.
+type binlogStopPoint struct {
+	gtid       *mysql.UUIDSet
+	binlogName string
+	position   uint32
+	reached    bool
+}
+
+func newBinlogStopPoint(untilGTID, untilPosition string) (*binlogStopPoint, error) {
+	switch {
+	case untilGTID != "" && untilPosition != "":
+		return nil, errors.New("only one of the GTID and the binlog position to stop at can be specified")
+	case untilGTID != "":
+		gtid, err := parseSingleGTID(untilGTID)
+		if err != nil {
+			return nil, err
+		}
+		return &binlogStopPoint{gtid: gtid}, nil
+	case untilPosition != "":
+		binlogName, position, err := parseBinlogPosition(untilPosition)
+		if err != nil {
+			return nil, err
+		}
+		return &binlogStopPoint{binlogName: binlogName, position: position}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func parseSingleGTID(gtid string) (*mysql.UUIDSet, error) {
+	uuidSet, err := mysql.ParseUUIDSet(gtid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse GTID '%s'", gtid)
+	}
+	if len(uuidSet.Intervals) != 1 || uuidSet.Intervals[0].Stop != uuidSet.Intervals[0].Start+1 {
+		return nil, errors.Errorf("expected a single GTID like 'source_id:transaction_id', got '%s'", gtid)
+	}
+	return uuidSet, nil
+}
+
+func parseBinlogPosition(binlogPosition string) (string, uint32, error) {
+	i := strings.LastIndex(binlogPosition, ":")
+	if i <= 0 {
+		return "", 0, errors.Errorf("expected binlog position like 'mysql-bin.000001:1234', got '%s'", binlogPosition)
+	}
+	position, err := strconv.ParseUint(binlogPosition[i+1:], 10, 32)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to parse binlog position '%s'", binlogPosition)
+	}
+	// the first event of the binlog follows the 4 bytes magic number
+	if position < 4 {
+		return "", 0, errors.Errorf("binlog position should be at least 4, got %d", position)
+	}
+	return binlogPosition[:i], uint32(position), nil
+}
+
+func (sp *binlogStopPoint) String() string {
+	if sp.binlogName != "" {
+		return fmt.Sprintf("%s:%d", sp.binlogName, sp.position)
+	}
+	return "GTID " + sp.gtid.String()
+}
+
+// locate reports whether the fetched binlog is the last one to apply.
+// The binlog index, if any, saves scanning the binlogs which don't contain the GTID.
+func (sp *binlogStopPoint) locate(binlogPath string, index *BinlogIndexDto) (bool, error) {
+	if sp == nil {
+		return false, nil
+	}
+	binlogName := path.Base(binlogPath)
+	if sp.gtid != nil && sp.binlogName == "" {
+		gtidSet := &mysql.MysqlGTIDSet{Sets: map[string]*mysql.UUIDSet{sp.gtid.SID.String(): sp.gtid}}
+		var previousGTIDs, gtids mysql.GTIDSet
+		var err error
+		if index != nil && index.Flavor == mysql.MySQLFlavor {
+			previousGTIDs, err = index.GetPreviousGTIDs()
+			if err != nil {
+				return false, err
+			}
+			gtids, err = index.GetGTIDs()
+		} else {
+			previousGTIDs, err = GetBinlogPreviousGTIDs(binlogPath, mysql.MySQLFlavor)
+		}
+		if err != nil {
+			return false, err
+		}
+		if previousGTIDs != nil && previousGTIDs.Contain(gtidSet) {
+			return false, errors.Errorf("GTID %s is executed before binlog %s", sp.gtid, binlogName)
+		}
+		if gtids != nil && !gtids.Contain(gtidSet) {
+			sp.reached = false
+			return false, nil
+		}
+		position, found, err := findGTIDEventPosition(binlogPath, sp.gtid)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			tracelog.InfoLogger.Printf("GTID %s is found in binlog %s at position %d", sp.gtid, binlogName, position)
+			sp.binlogName = binlogName
+			sp.position = position
+		}
+	}
+	sp.reached = sp.binlogName == binlogName
+	return sp.reached, nil
+}
+
+// stopPosition returns the position to stop applying the binlog at, zero means the binlog is applied completely
+func (sp *binlogStopPoint) stopPosition(binlogName string) uint32 {
+	if sp == nil || sp.binlogName != binlogName {
+		return 0
+	}
+	return sp.position
+}
+
+// findGTIDEventPosition returns the position of the GTID event starting the transaction
+func findGTIDEventPosition(binlogPath string, gtid *mysql.UUIDSet) (uint32, bool, error) {
+	var position uint32
+	found := false
+
+	parser := replication.NewBinlogParser()
+	parser.SetFlavor(mysql.MySQLFlavor)
+	parser.SetVerifyChecksum(false) // the faster, the better
+	parser.SetRawMode(true)         // choose events to parse manually
+	err := parser.ParseFile(binlogPath, 0, func(event *replication.BinlogEvent) error {
+		if event.Header.EventType != replication.GTID_EVENT {
+			return nil
+		}
+		gtidEvent := &replication.GTIDEvent{}
+		err := gtidEvent.Decode(event.RawData[replication.EventHeaderSize:])
+		if err != nil {
+			return err
+		}
+		sid, err := uuid.FromBytes(gtidEvent.SID)
+		if err != nil {
+			return err
+		}
+		if sid == gtid.SID && gtidEvent.GNO == gtid.Intervals[0].Start {
+			position = event.Header.LogPos - event.Header.EventSize
+			found = true
+			return fmt.Errorf("shallow file read finished")
+		}
+		return nil
+	})
+	if err != nil && !found {
+		return 0, false, errors.Wrapf(err, "failed to parse binlog %s", binlogPath)
+	}
+	return position, found, nil
+}
@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	conf "github.com/wal-g/wal-g/internal/config"
+)
+
+// the magic number, FORMAT_DESCRIPTION and PREVIOUS_GTIDS events of the test binlog
+const testBinlogHeaderSize = 194
+
+func appendTestBinlogEvent(data []byte, eventType replication.EventType, body []byte) []byte {
+	eventSize := replication.EventHeaderSize + len(body) + replication.BinlogChecksumLength
+	event := make([]byte, replication.EventHeaderSize, eventSize)
+	binary.LittleEndian.PutUint32(event, 1700000000)
+	event[4] = byte(eventType)
+	binary.LittleEndian.PutUint32(event[5:], 1)
+	binary.LittleEndian.PutUint32(event[9:], uint32(eventSize))
+	binary.LittleEndian.PutUint32(event[13:], uint32(len(data)+eventSize))
+	event = append(event, body...)
+	event = binary.LittleEndian.AppendUint32(event, crc32.ChecksumIEEE(event))
+	return append(data, event...)
+}
+
+func appendTestGTIDEvent(t *testing.T, data []byte, gno int64) []byte {
+	sid, err := uuid.Parse(testServerUUID)
+	require.NoError(t, err)
+	body := make([]byte, 1+replication.SidLength+8)
+	body[0] = 1
+	copy(body[1:], sid[:])
+	binary.LittleEndian.PutUint64(body[1+replication.SidLength:], uint64(gno))
+	return appendTestBinlogEvent(data, replication.GTID_EVENT, body)
+}
+
+func appendTestQueryEvent(data []byte, query string) []byte {
+	schema := "test"
+	// slave_proxy_id, execution time, schema length, error code and status vars length
+	body := make([]byte, 4+4+1+2+2)
+	body[8] = byte(len(schema))
+	body = append(body, schema...)
+	body = append(body, 0)
+	body = append(body, query...)
+	return appendTestBinlogEvent(data, replication.QUERY_EVENT, body)
+}
+
+// writeTestBinlog writes the binlog with the transactions 1, 2 and 3, the second one drops the table
+func writeTestBinlog(t *testing.T, name string) string {
+	header, err := os.ReadFile(testFilenameSmall)
+	require.NoError(t, err)
+	data := header[:testBinlogHeaderSize]
+	data = appendTestGTIDEvent(t, data, 1)
+	data = appendTestQueryEvent(data, "CREATE TABLE t1 (id INT)")
+	data = appendTestGTIDEvent(t, data, 2)
+	data = appendTestQueryEvent(data, "DROP TABLE t1")
+	data = appendTestGTIDEvent(t, data, 3)
+	data = appendTestQueryEvent(data, "CREATE TABLE t2 (id INT)")
+
+	binlogPath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(binlogPath, data, 0600))
+	return binlogPath
+}
+
+func TestNewBinlogStopPoint(t *testing.T) {
+	stopPoint, err := newBinlogStopPoint("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, stopPoint)
+
+	stopPoint, err = newBinlogStopPoint("", "mysql-bin.000017:1234")
+	require.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000017:1234", stopPoint.String())
+	assert.Equal(t, uint32(1234), stopPoint.stopPosition("mysql-bin.000017"))
+	assert.Equal(t, uint32(0), stopPoint.stopPosition("mysql-bin.000016"))
+
+	stopPoint, err = newBinlogStopPoint(testServerUUID+":42", "")
+	require.NoError(t, err)
+	assert.Equal(t, "GTID "+testServerUUID+":42", stopPoint.String())
+
+	for _, invalid := range [][2]string{
+		{testServerUUID + ":42", "mysql-bin.000017:1234"},
+		{testServerUUID + ":1-42", ""},
+		{"42", ""},
+		{"", "mysql-bin.000017"},
+		{"", "mysql-bin.000017:2"},
+		{"", ":1234"},
+	} {
+		_, err = newBinlogStopPoint(invalid[0], invalid[1])
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestBinlogStopPoint_LocateGTID(t *testing.T) {
+	stopPoint, err := newBinlogStopPoint(testServerUUID+":2", "")
+	require.NoError(t, err)
+
+	binlogPath := writeTestBinlog(t, "mysql-bin.000017")
+	last, err := stopPoint.locate(binlogPath, nil)
+	require.NoError(t, err)
+	assert.True(t, last)
+	assert.True(t, stopPoint.reached)
+	assert.Equal(t, "mysql-bin.000017", stopPoint.binlogName)
+	assert.Equal(t, uint32(0), stopPoint.stopPosition("mysql-bin.000016"))
+
+	// the second GTID event follows the first transaction
+	position := stopPoint.stopPosition("mysql-bin.000017")
+	data, err := os.ReadFile(binlogPath)
+	require.NoError(t, err)
+	assert.Equal(t, byte(replication.GTID_EVENT), data[position+4])
+	assert.Equal(t, uint64(2), binary.LittleEndian.Uint64(data[position+replication.EventHeaderSize+1+replication.SidLength:]))
+}
+
+func TestBinlogStopPoint_LocateMissingGTID(t *testing.T) {
+	stopPoint, err := newBinlogStopPoint(testServerUUID+":4", "")
+	require.NoError(t, err)
+
+	last, err := stopPoint.locate(writeTestBinlog(t, "mysql-bin.000017"), nil)
+	require.NoError(t, err)
+	assert.False(t, last)
+	assert.False(t, stopPoint.reached)
+}
+
+func TestBinlogStopPoint_LocatePosition(t *testing.T) {
+	stopPoint, err := newBinlogStopPoint("", "mysql-bin.000018:1234")
+	require.NoError(t, err)
+
+	last, err := stopPoint.locate(writeTestBinlog(t, "mysql-bin.000017"), nil)
+	require.NoError(t, err)
+	assert.False(t, last)
+
+	last, err = stopPoint.locate(writeTestBinlog(t, "mysql-bin.000018"), nil)
+	require.NoError(t, err)
+	assert.True(t, last)
+	assert.True(t, stopPoint.reached)
+}
+
+func TestCheckReplayCommandStopPosition(t *testing.T) {
+	viper.Set(conf.MysqlBinlogReplayCmd, "mysqlbinlog --stop-datetime=\"$WALG_MYSQL_BINLOG_END_TS\" \"$WALG_MYSQL_CURRENT_BINLOG\" | mysql")
+	defer viper.Set(conf.MysqlBinlogReplayCmd, nil)
+	assert.NoError(t, checkReplayCommandStopPosition(nil))
+
+	stopPoint, err := newBinlogStopPoint("", "mysql-bin.000017:1234")
+	require.NoError(t, err)
+	assert.Error(t, checkReplayCommandStopPosition(stopPoint))
+
+	viper.Set(conf.MysqlBinlogReplayCmd, "mysqlbinlog --stop-position=$WALG_MYSQL_BINLOG_STOP_POSITION \"$WALG_MYSQL_CURRENT_BINLOG\" | mysql")
+	assert.NoError(t, checkReplayCommandStopPosition(stopPoint))
+}
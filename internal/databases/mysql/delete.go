@@ -1,6 +1,14 @@
 package mysql
 
 import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
@@ -58,3 +66,100 @@ func NewDeleteHandler(folder storage.Folder) (*DeleteHandler, error) {
 func (h *DeleteHandler) HandleDeleteEverything(args []string, confirmed bool) {
 	h.DeleteHandler.HandleDeleteEverything(args, h.permanentBackups, confirmed)
 }
+
+// HandleDeleteRetainWindow deletes the backups and binlogs which are not needed
+// to restore to any point of the retention window. Nothing is deleted unless the binlogs
+// since the start of the oldest needed backup are continuous.
+func (h *DeleteHandler) HandleDeleteRetainWindow(args []string, confirmed bool) error {
+	window, err := internal.ParseRetainWindow(args[0])
+	if err != nil {
+		return err
+	}
+	windowStart := utility.TimeNowCrossPlatformUTC().Add(-window)
+
+	target, err := h.FindTargetRetainWindow(windowStart, NewGenericMetaFetcher())
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		tracelog.InfoLogger.Printf("No backup made before %s, nothing to delete", windowStart.Format(time.RFC3339))
+		return nil
+	}
+	tracelog.InfoLogger.Printf("Backup %s is needed to restore to any point after %s",
+		target.GetBackupName(), windowStart.Format(time.RFC3339))
+
+	backup, err := internal.GetBackupByName(target.GetBackupName(), utility.BaseBackupPath, h.Folder)
+	if err != nil {
+		return err
+	}
+	var streamSentinel StreamSentinelDto
+	err = backup.FetchSentinel(&streamSentinel)
+	if err != nil {
+		return err
+	}
+	sinceTS, err := getBinlogSinceTS(h.Folder, backup)
+	if err != nil {
+		return err
+	}
+	binlogs, err := getLogsCoveringInterval(h.Folder, sinceTS, true, utility.MaxTime)
+	if err != nil {
+		return err
+	}
+	binlogObjects := make([]storage.Object, 0, len(binlogs))
+	for _, binlog := range binlogs {
+		binlogObjects = append(binlogObjects, binlog.Object)
+	}
+	err = checkBinlogSequence(binlogObjects, streamSentinel.BinLogStart)
+	if err != nil {
+		return errors.Wrap(err, "the retention window is not covered, nothing is deleted")
+	}
+
+	return internal.RunNotifiedDelete("retain-window", confirmed, func() error {
+		return h.DeleteBeforeTargetWhere(target, confirmed, func(object storage.Object) bool {
+			// the binlogs since the backup start binlog and their indexes are needed to restore from the target backup
+			isBinlog := strings.HasPrefix(object.GetName(), BinlogPath) || strings.HasPrefix(object.GetName(), BinlogIndexPath)
+			return !isBinlog || object.GetLastModified().Before(sinceTS)
+		}, func(string) bool { return true })
+	})
+}
+
+// checkBinlogSequence checks that the binlogs starting from the startBinlog have no gaps in their sequence numbers
+func checkBinlogSequence(binlogs []storage.Object, startBinlog string) error {
+	startNumber, err := getBinlogSequenceNumber(startBinlog)
+	if err != nil {
+		return err
+	}
+	numbers := make([]uint64, 0, len(binlogs))
+	for _, binlog := range binlogs {
+		// the binlogs are stored with the compression extension, like mysql-bin.000042.br
+		number, err := getBinlogSequenceNumber(utility.TrimFileExtension(binlog.GetName()))
+		if err != nil {
+			tracelog.WarningLogger.Printf("Skipping %s: %v", binlog.GetName(), err)
+			continue
+		}
+		if number >= startNumber {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	if len(numbers) == 0 || numbers[0] != startNumber {
+		return fmt.Errorf("backup start binlog %s is not found in storage", startBinlog)
+	}
+	for i := 1; i < len(numbers); i++ {
+		if numbers[i] > numbers[i-1]+1 {
+			return fmt.Errorf("binlogs with sequence numbers from %d to %d are missing in storage",
+				numbers[i-1]+1, numbers[i]-1)
+		}
+	}
+	return nil
+}
+
+// getBinlogSequenceNumber parses the sequence number of the binlog file name like mysql-bin.000042
+func getBinlogSequenceNumber(name string) (uint64, error) {
+	number, err := strconv.ParseUint(strings.TrimPrefix(path.Ext(name), "."), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the binlog sequence number of '%s'", name)
+	}
+	return number, nil
+}
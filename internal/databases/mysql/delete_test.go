@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/testtools"
+	"github.com/wal-g/wal-g/utility"
+)
+
+func newTestBinlogs(names ...string) []storage.Object {
+	binlogs := make([]storage.Object, 0, len(names))
+	for _, name := range names {
+		binlogs = append(binlogs, storage.NewLocalObject(name, time.Now(), 0))
+	}
+	return binlogs
+}
+
+func TestCheckBinlogSequence(t *testing.T) {
+	assert.NoError(t, checkBinlogSequence(
+		newTestBinlogs("mysql-bin.000010.br", "mysql-bin.000009.br", "mysql-bin.000011.br", "mysql-bin.000008.br"),
+		"mysql-bin.000009"))
+	assert.NoError(t, checkBinlogSequence(
+		newTestBinlogs("mysql-bin.000999.lz4", "mysql-bin.001000.lz4", "mysql-bin.001000.lz4"), "mysql-bin.000999"))
+
+	assert.Error(t, checkBinlogSequence(
+		newTestBinlogs("mysql-bin.000010.br", "mysql-bin.000011.br"), "mysql-bin.000009"),
+		"the backup start binlog is missing")
+	assert.Error(t, checkBinlogSequence(
+		newTestBinlogs("mysql-bin.000009.br", "mysql-bin.000010.br", "mysql-bin.000012.br"), "mysql-bin.000009"))
+	assert.Error(t, checkBinlogSequence(nil, "mysql-bin"))
+}
+
+// setupRetainWindowTestFolder creates testtools.MakeRetainWindowTestFolder with the stream backups and the binlogs
+func setupRetainWindowTestFolder(t *testing.T, skipBinlogs map[int]bool) (storage.Folder, []string) {
+	viper.Set(conf.SerializerTypeSetting, string(internal.RegularJSONSerializer))
+	t.Cleanup(func() { viper.Set(conf.SerializerTypeSetting, nil) })
+
+	var backupNames []string
+	folder := testtools.MakeRetainWindowTestFolder(t, skipBinlogs,
+		func(folder storage.Folder, binlogNo int, startTime, finishTime time.Time) error {
+			backupName := "stream_" + startTime.Format(utility.BackupTimeFormat)
+			sentinel, err := json.Marshal(StreamSentinelDto{
+				BinLogStart:    fmt.Sprintf("mysql-bin.%06d", binlogNo),
+				StartLocalTime: startTime,
+				StopLocalTime:  finishTime,
+			})
+			if err != nil {
+				return err
+			}
+			backupNames = append(backupNames, backupName)
+			err = folder.PutObject(utility.BaseBackupPath+backupName+utility.SentinelSuffix, bytes.NewReader(sentinel))
+			if err != nil {
+				return err
+			}
+			return folder.PutObject(utility.BaseBackupPath+backupName+"/stream.br", &bytes.Buffer{})
+		},
+		func(folder storage.Folder, binlogNo int) error {
+			return folder.PutObject(fmt.Sprintf("%smysql-bin.%06d.br", BinlogPath, binlogNo), &bytes.Buffer{})
+		})
+	return folder, backupNames
+}
+
+func assertObjectsExist(t *testing.T, folder storage.Folder, expected map[string]bool) {
+	for name, exists := range expected {
+		actual, err := folder.Exists(name)
+		require.NoError(t, err)
+		assert.Equal(t, exists, actual, name)
+	}
+}
+
+func TestHandleDeleteRetainWindow(t *testing.T) {
+	folder, backupNames := setupRetainWindowTestFolder(t, nil)
+	deleteHandler, err := NewDeleteHandler(folder)
+	require.NoError(t, err)
+
+	require.NoError(t, deleteHandler.HandleDeleteRetainWindow([]string{"14d"}, true))
+
+	assertObjectsExist(t, folder, map[string]bool{
+		utility.BaseBackupPath + backupNames[0] + utility.SentinelSuffix: false,
+		utility.BaseBackupPath + backupNames[0] + "/stream.br":           false,
+		utility.BaseBackupPath + backupNames[1] + utility.SentinelSuffix: true,
+		utility.BaseBackupPath + backupNames[2] + utility.SentinelSuffix: true,
+		BinlogPath + "mysql-bin.000005.br":                               false,
+		BinlogPath + "mysql-bin.000006.br":                               true,
+		BinlogPath + "mysql-bin.000015.br":                               true,
+	})
+}
+
+func TestHandleDeleteRetainWindow_LostBinlog(t *testing.T) {
+	folder, backupNames := setupRetainWindowTestFolder(t, map[int]bool{8: true})
+	deleteHandler, err := NewDeleteHandler(folder)
+	require.NoError(t, err)
+
+	assert.Error(t, deleteHandler.HandleDeleteRetainWindow([]string{"14d"}, true))
+
+	assertObjectsExist(t, folder, map[string]bool{
+		utility.BaseBackupPath + backupNames[0] + utility.SentinelSuffix: true,
+		BinlogPath + "mysql-bin.000001.br":                               true,
+	})
+}
+
+func TestHandleDeleteRetainWindow_NoBackupBeforeWindow(t *testing.T) {
+	folder, backupNames := setupRetainWindowTestFolder(t, nil)
+	deleteHandler, err := NewDeleteHandler(folder)
+	require.NoError(t, err)
+
+	require.NoError(t, deleteHandler.HandleDeleteRetainWindow([]string{"60d"}, true))
+
+	assertObjectsExist(t, folder, map[string]bool{
+		utility.BaseBackupPath + backupNames[0] + utility.SentinelSuffix: true,
+		BinlogPath + "mysql-bin.000001.br":                               true,
+	})
+}
@@ -120,28 +120,59 @@ func getLastUploadedBinlog(folder storage.Folder) (string, error) {
 }
 
 func getLastUploadedBinlogBeforeGTID(folder storage.Folder, gtid gomysql.GTIDSet, flavor string) (string, error) {
-	folder = folder.GetSubFolder(BinlogPath)
-	logFiles, _, err := folder.ListFolder()
+	logFiles, err := listBinlogsByModificationTime(folder.GetSubFolder(BinlogPath))
 	if err != nil {
 		return "", err
 	}
-	sort.Slice(logFiles, func(i, j int) bool {
-		return logFiles[i].GetLastModified().Before(logFiles[j].GetLastModified())
-	})
 	if len(logFiles) == 0 {
 		return "", nil
 	}
-	for i := len(logFiles) - 1; i > 0; i-- {
-		prevGtid, err := GetBinlogPreviousGTIDsRemote(folder, logFiles[i].GetName(), flavor)
+	logFile, err := findBinlogBeforeGTID(folder, logFiles[1:], gtid, flavor)
+	if err != nil {
+		return "", err
+	}
+	if logFile == nil {
+		tracelog.WarningLogger.Printf("failed to find uploaded binlog behind %s", gtid)
+		return "", nil
+	}
+	return utility.TrimFileExtension(logFile.GetName()), nil
+}
+
+// listBinlogsByModificationTime lists the binlogs in the order they were archived, the binlogs found
+// in the list are passed to newArchivedBinlog to get the time they are selected by
+func listBinlogsByModificationTime(logFolder storage.Folder) ([]storage.Object, error) {
+	logFiles, _, err := logFolder.ListFolder()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(logFiles, func(i, j int) bool {
+		if logFiles[i].GetLastModified().Equal(logFiles[j].GetLastModified()) {
+			// the binlogs uploaded by the same binlog-push may have the same modification time
+			return logFiles[i].GetName() < logFiles[j].GetName()
+		}
+		return logFiles[i].GetLastModified().Before(logFiles[j].GetLastModified())
+	})
+	return logFiles, nil
+}
+
+// findBinlogBeforeGTID finds the latest of the sorted binlogs whose previous GTIDs are contained in the GTID set,
+// so the binlogs before it have no transactions missing from the set. It returns nil if there is no such binlog.
+func findBinlogBeforeGTID(folder storage.Folder, logFiles []storage.Object, gtid gomysql.GTIDSet,
+	flavor string) (*archivedBinlog, error) {
+	for i := len(logFiles) - 1; i >= 0; i-- {
+		binlog, err := newArchivedBinlog(folder, logFiles[i])
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		prevGtid, err := binlog.previousGTIDs(folder, flavor)
+		if err != nil {
+			return nil, err
 		}
 		if gtid.Contain(prevGtid) {
-			return utility.TrimFileExtension(logFiles[i].GetName()), nil
+			return &binlog, nil
 		}
 	}
-	tracelog.WarningLogger.Printf("failed to find uploaded binlog behind %s", gtid)
-	return "", nil
+	return nil, nil
 }
 
 func getMySQLConnection() (*sql.DB, error) {
@@ -237,6 +268,9 @@ type StreamSentinelDto struct {
 	IncrementFrom     *string `json:"DeltaFrom,omitempty"`
 	IncrementFullName *string `json:"DeltaFullName,omitempty"`
 	IncrementCount    *int    `json:"DeltaCount,omitempty"`
+
+	// CompressionLevels are the levels chosen for the stream files by the adaptive compression method
+	CompressionLevels map[string]string `json:"CompressionLevels,omitempty"`
 	//todo: add other fields from internal.GenericMetadata
 }
 
@@ -252,18 +286,21 @@ type binlogHandler interface {
 	handleBinlog(binlogPath string) error
 }
 
-func fetchLogs(folder storage.Folder, dstDir string, startTS, endTS, endBinlogTS time.Time, handler binlogHandler) error {
+// fetchLogs downloads the binlogs archived since startTS and passes them to the handler until a binlog starts after endTS
+// or the stop point is reached
+func fetchLogs(folder storage.Folder, dstDir string, startTS, endTS, endBinlogTS time.Time, stopPoint *binlogStopPoint,
+	handler binlogHandler) error {
 	logFolder := folder.GetSubFolder(BinlogPath)
 	includeStart := true
 outer:
 	for {
-		logsToFetch, err := getLogsCoveringInterval(logFolder, startTS, includeStart, endBinlogTS)
+		logsToFetch, err := getLogsCoveringInterval(folder, startTS, includeStart, endBinlogTS)
 		includeStart = false
 		if err != nil {
 			return err
 		}
 		for _, logFile := range logsToFetch {
-			startTS = logFile.GetLastModified()
+			startTS = logFile.Time
 			binlogName := utility.TrimFileExtension(logFile.GetName())
 			binlogPath := path.Join(dstDir, binlogName)
 			tracelog.InfoLogger.Printf("downloading %s into %s", binlogName, binlogPath)
@@ -271,7 +308,17 @@ outer:
 				tracelog.ErrorLogger.Printf("failed to download %s: %v", binlogName, err)
 				return err
 			}
-			timestamp, err := GetBinlogStartTimestamp(binlogPath, gomysql.MySQLFlavor)
+			index := logFile.Index
+			var timestamp time.Time
+			if index != nil {
+				timestamp = index.FirstEventTime
+			} else {
+				timestamp, err = GetBinlogStartTimestamp(binlogPath, gomysql.MySQLFlavor)
+				if err != nil {
+					return err
+				}
+			}
+			last, err := stopPoint.locate(binlogPath, index)
 			if err != nil {
 				return err
 			}
@@ -279,7 +326,7 @@ outer:
 			if err != nil {
 				return err
 			}
-			if timestamp.After(endTS) {
+			if last || timestamp.After(endTS) {
 				break outer
 			}
 		}
@@ -287,10 +334,17 @@ outer:
 			break
 		}
 	}
+	if stopPoint != nil && !stopPoint.reached {
+		return fmt.Errorf("%s is not found in the binlogs fetched", stopPoint)
+	}
 	return nil
 }
 
-func provideLogs(folder storage.Folder, dstDir string, startTS, endTS time.Time, p *storage.ObjectProvider) {
+// provideLogs downloads the binlogs archived since startTS and adds them to the provider until a binlog starts after endTS.
+// The storage is listed again when the listed binlogs are provided to pick up the binlogs archived meanwhile.
+// When pollInterval is positive, the newly uploaded binlogs are polled for indefinitely.
+func provideLogs(folder storage.Folder, dstDir string, startTS, endTS time.Time, pollInterval time.Duration,
+	p *storage.ObjectProvider) {
 	defer p.Close()
 	_, err := os.Stat(dstDir)
 	if os.IsNotExist(err) {
@@ -302,45 +356,75 @@ func provideLogs(folder storage.Folder, dstDir string, startTS, endTS time.Time,
 	}
 
 	logFolder := folder.GetSubFolder(BinlogPath)
-	logsToFetch, err := getLogsCoveringInterval(logFolder, startTS, true, utility.MaxTime)
-	p.HandleError(err)
-	if err != nil {
-		return
-	}
-
-	for _, logFile := range logsToFetch {
-		// download log files
-		binlogName := utility.TrimFileExtension(logFile.GetName())
-		binlogPath := path.Join(dstDir, binlogName)
-		tracelog.InfoLogger.Printf("downloading %s into %s", binlogName, binlogPath)
-		if err = internal.DownloadFileTo(internal.NewFolderReader(logFolder), binlogName, binlogPath); err != nil {
-			if os.IsExist(err) {
-				tracelog.WarningLogger.Printf("file %s exist skipping", binlogName)
-			} else {
-				tracelog.ErrorLogger.Printf("failed to download %s: %v", binlogName, err)
-				p.HandleError(err)
-				return
-			}
-		}
-
-		// add file to provider
-		err = p.AddObject(logFile)
+	provided := make(map[string]bool)
+	for {
+		logsToFetch, err := getLogsCoveringInterval(folder, startTS, true, utility.MaxTime)
 		p.HandleError(err)
 		if err != nil {
 			return
 		}
 
-		timestamp, err := GetBinlogStartTimestamp(binlogPath, gomysql.MySQLFlavor)
-		p.HandleError(err)
-		if err != nil {
-			return
+		newLogs := 0
+		listed := make(map[string]bool, len(logsToFetch))
+		for _, logFile := range logsToFetch {
+			listed[logFile.GetName()] = true
+			if provided[logFile.GetName()] {
+				continue
+			}
+			newLogs++
+
+			finished, err := provideLog(logFolder, dstDir, logFile, endTS, p)
+			if err != nil || finished {
+				return
+			}
+			startTS = logFile.Time
 		}
-		if timestamp.After(endTS) {
-			return
+		// the binlogs which are not listed anymore can't be listed again, so only the listed ones are remembered
+		provided = listed
+		if newLogs == 0 {
+			if pollInterval <= 0 {
+				return
+			}
+			tracelog.DebugLogger.Printf("No new binlogs since %s, polling again in %v", startTS.Format(time.RFC3339), pollInterval)
+			time.Sleep(pollInterval)
 		}
 	}
 }
 
+// provideLog downloads the binlog and adds it to the provider, it reports whether the binlog starts after endTS
+func provideLog(logFolder storage.Folder, dstDir string, logFile archivedBinlog, endTS time.Time,
+	p *storage.ObjectProvider) (finished bool, err error) {
+	binlogName := utility.TrimFileExtension(logFile.GetName())
+	binlogPath := path.Join(dstDir, binlogName)
+	tracelog.InfoLogger.Printf("downloading %s into %s", binlogName, binlogPath)
+	if err = internal.DownloadFileTo(internal.NewFolderReader(logFolder), binlogName, binlogPath); err != nil {
+		if os.IsExist(err) {
+			tracelog.WarningLogger.Printf("file %s exist skipping", binlogName)
+		} else {
+			tracelog.ErrorLogger.Printf("failed to download %s: %v", binlogName, err)
+			p.HandleError(err)
+			return false, err
+		}
+	}
+
+	// add file to provider
+	err = p.AddObject(logFile.Object)
+	p.HandleError(err)
+	if err != nil {
+		return false, err
+	}
+
+	if logFile.Index != nil {
+		return logFile.Index.FirstEventTime.After(endTS), nil
+	}
+	timestamp, err := GetBinlogStartTimestamp(binlogPath, gomysql.MySQLFlavor)
+	p.HandleError(err)
+	if err != nil {
+		return false, err
+	}
+	return timestamp.After(endTS), nil
+}
+
 func getBinlogSinceTS(folder storage.Folder, backup internal.Backup) (time.Time, error) {
 	startTS := utility.MaxTime // far future
 	var streamSentinel StreamSentinelDto
@@ -368,34 +452,91 @@ func getBinlogSinceTS(folder storage.Folder, backup internal.Backup) (time.Time,
 	if err != nil {
 		return time.Time{}, err
 	}
-	for _, binlog := range binlogs {
-		if strings.HasPrefix(binlog.GetName(), streamSentinel.BinLogStart) {
-			tracelog.InfoLogger.Printf("Backup start binlog: %s (%s)", binlog.GetName(), binlog.GetLastModified())
-			if binlog.GetLastModified().Before(startTS) {
-				startTS = binlog.GetLastModified()
+	for _, logFile := range binlogs {
+		if strings.HasPrefix(logFile.GetName(), streamSentinel.BinLogStart) {
+			binlog, err := newArchivedBinlog(folder, logFile)
+			if err != nil {
+				return time.Time{}, err
+			}
+			tracelog.InfoLogger.Printf("Backup start binlog: %s (%s)", binlog.GetName(), binlog.Time)
+			if binlog.Time.Before(startTS) {
+				startTS = binlog.Time
 			}
 		}
 	}
 	return startTS, nil
 }
 
-// getLogsCoveringInterval lists the operation logs that cover the interval
-func getLogsCoveringInterval(folder storage.Folder, start time.Time, includeStart bool, endBinlogTS time.Time) ([]storage.Object, error) {
-	logFiles, _, err := folder.ListFolder()
+// archivedBinlog is the binlog object with the time it is ordered and selected by
+type archivedBinlog struct {
+	storage.Object
+	// Index is nil if the binlog was archived without the index
+	Index *BinlogIndexDto
+	// Time is the time of the last event of the binlog if it is indexed, otherwise the time it was archived at
+	Time time.Time
+}
+
+func newArchivedBinlog(folder storage.Folder, logFile storage.Object) (archivedBinlog, error) {
+	index, err := FetchBinlogIndex(folder, utility.TrimFileExtension(logFile.GetName()))
+	if err != nil {
+		return archivedBinlog{}, err
+	}
+	return newArchivedBinlogWithIndex(logFile, index), nil
+}
+
+func newArchivedBinlogWithIndex(logFile storage.Object, index *BinlogIndexDto) archivedBinlog {
+	binlog := archivedBinlog{Object: logFile, Index: index, Time: logFile.GetLastModified()}
+	if index != nil {
+		binlog.Time = index.LastEventTime
+	}
+	return binlog
+}
+
+// previousGTIDs reads the previous GTIDs from the binlog index or from the binlog itself if it has no index
+func (binlog archivedBinlog) previousGTIDs(folder storage.Folder, flavor string) (gomysql.GTIDSet, error) {
+	if binlog.Index != nil && binlog.Index.Flavor == flavor {
+		return binlog.Index.GetPreviousGTIDs()
+	}
+	return GetBinlogPreviousGTIDsRemote(folder.GetSubFolder(BinlogPath), binlog.GetName(), flavor)
+}
+
+// getLogsCoveringInterval lists the operation logs that cover the interval.
+// The indexed binlogs are selected by the time of their last event, the storage may change the time they were
+// archived at, e.g. when they are copied, the other ones are selected by the time they were archived at.
+// The binlogs archived after endBinlogTS are skipped whether they are indexed or not.
+func getLogsCoveringInterval(folder storage.Folder, start time.Time, includeStart bool,
+	endBinlogTS time.Time) ([]archivedBinlog, error) {
+	logFiles, _, err := folder.GetSubFolder(BinlogPath).ListFolder()
 	if err != nil {
 		return nil, err
 	}
-	sort.Slice(logFiles, func(i, j int) bool {
-		return logFiles[i].GetLastModified().Before(logFiles[j].GetLastModified())
-	})
-	var logsToFetch []storage.Object
+	indexed, err := listIndexedBinlogs(folder)
+	if err != nil {
+		return nil, err
+	}
+	var logsToFetch []archivedBinlog
 	for _, logFile := range logFiles {
 		if logFile.GetLastModified().After(endBinlogTS) {
 			continue // don't fetch binlogs from future
 		}
-		if start.Before(logFile.GetLastModified()) || includeStart && start.Equal(logFile.GetLastModified()) {
-			logsToFetch = append(logsToFetch, logFile)
+		if logFile.GetLastModified().Before(start) {
+			// the binlog is archived after its last event, so it ends before the start whether it is indexed or not
+			continue
+		}
+		var index *BinlogIndexDto
+		if binlogName := utility.TrimFileExtension(logFile.GetName()); indexed[binlogName] {
+			index, err = FetchBinlogIndex(folder, binlogName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		binlog := newArchivedBinlogWithIndex(logFile, index)
+		if start.Before(binlog.Time) || includeStart && start.Equal(binlog.Time) {
+			logsToFetch = append(logsToFetch, binlog)
 		}
 	}
+	sort.Slice(logsToFetch, func(i, j int) bool {
+		return logsToFetch[i].Time.Before(logsToFetch[j].Time)
+	})
 	return logsToFetch, nil
 }
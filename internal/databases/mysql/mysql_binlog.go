@@ -155,6 +155,8 @@ func GetBinlogStartTimestamp(filename string, flavor string) (time.Time, error)
 	return time.Unix(int64(ts), 0), nil
 }
 
+// GetBinlogTS returns the time the binlog is selected by: the time of its last event if it is indexed,
+// otherwise the time it was archived at
 func GetBinlogTS(folder storage.Folder, binlogName string) (time.Time, error) {
 	logFolder := folder.GetSubFolder(BinlogPath)
 	logFiles, _, err := logFolder.ListFolder()
@@ -165,7 +167,11 @@ func GetBinlogTS(folder storage.Folder, binlogName string) (time.Time, error) {
 	for _, logFile := range logFiles {
 		logFileName := strings.TrimSuffix(logFile.GetName(), filepath.Ext(logFile.GetName()))
 		if logFileName == binlogName {
-			return logFile.GetLastModified(), nil
+			binlog, err := newArchivedBinlog(folder, logFile)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return binlog.Time, nil
 		}
 	}
 	return time.Time{}, fmt.Errorf("binlog %s not found", binlogName)
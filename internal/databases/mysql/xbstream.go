@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// xbstream chunk layout (see xbstream_read.cc in percona-xtrabackup):
+// magic[8] flags[1] type[1] pathLen[4] path[pathLen]
+// for EOF chunks nothing follows, sparse chunks are followed by sparseMapSize[4]
+// then payloadLen[8] payloadOffset[8] checksum[4] sparseMap[sparseMapSize*8] payload[payloadLen]
+const (
+	xbstreamMagic          = "XBSTCK01"
+	xbstreamFlagIgnorable  = 0x01
+	xbstreamChunkPayload   = 'P'
+	xbstreamChunkSparse    = 'S'
+	xbstreamChunkEOF       = 'E'
+	xbstreamMaxPathLen     = 4096
+	xbstreamSparseMapEntry = 8
+)
+
+// filterXbstream copies to dst only the chunks of the files whose path matches
+func filterXbstream(dst io.Writer, src io.Reader, match func(path string) bool) error {
+	reader := bufio.NewReader(src)
+	header := &bytes.Buffer{}
+	for {
+		header.Reset()
+		path, chunkType, err := readXbstreamChunkPath(reader, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		payloadLen, err := readXbstreamChunkPayloadHeader(reader, header, chunkType)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read xbstream chunk of %s", path)
+		}
+
+		out := io.Discard
+		if match(path) {
+			out = dst
+		}
+		if _, err = out.Write(header.Bytes()); err != nil {
+			return err
+		}
+		if _, err = io.CopyN(out, reader, payloadLen); err != nil {
+			return errors.Wrapf(err, "failed to copy xbstream chunk of %s", path)
+		}
+	}
+}
+
+// readXbstreamChunkPath reads the common part of the chunk header, it returns io.EOF at the end of the stream only
+func readXbstreamChunkPath(reader io.Reader, header *bytes.Buffer) (string, byte, error) {
+	prefix := make([]byte, len(xbstreamMagic)+1+1+4)
+	n, err := io.ReadFull(reader, prefix)
+	if err == io.EOF {
+		return "", 0, io.EOF
+	}
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to read xbstream chunk header, got %d bytes", n)
+	}
+	if string(prefix[:len(xbstreamMagic)]) != xbstreamMagic {
+		return "", 0, errors.New("wrong xbstream chunk magic, the backup is not in the xbstream format")
+	}
+	flags := prefix[len(xbstreamMagic)]
+	chunkType := prefix[len(xbstreamMagic)+1]
+	switch chunkType {
+	case xbstreamChunkPayload, xbstreamChunkSparse, xbstreamChunkEOF:
+	default:
+		if flags&xbstreamFlagIgnorable == 0 {
+			return "", 0, errors.Errorf("unknown xbstream chunk type %q", chunkType)
+		}
+	}
+	pathLen := binary.LittleEndian.Uint32(prefix[len(xbstreamMagic)+2:])
+	if pathLen > xbstreamMaxPathLen {
+		return "", 0, errors.Errorf("xbstream chunk path is too long: %d", pathLen)
+	}
+	path := make([]byte, pathLen)
+	if _, err = io.ReadFull(reader, path); err != nil {
+		return "", 0, errors.Wrap(err, "failed to read xbstream chunk path")
+	}
+	header.Write(prefix)
+	header.Write(path)
+	return string(path), chunkType, nil
+}
+
+// readXbstreamChunkPayloadHeader reads the rest of the chunk header and returns the payload length
+func readXbstreamChunkPayloadHeader(reader io.Reader, header *bytes.Buffer, chunkType byte) (int64, error) {
+	if chunkType == xbstreamChunkEOF {
+		return 0, nil
+	}
+	var sparseMapSize uint32
+	if chunkType == xbstreamChunkSparse {
+		if _, err := io.CopyN(header, reader, 4); err != nil {
+			return 0, err
+		}
+		sparseMapSize = binary.LittleEndian.Uint32(header.Bytes()[header.Len()-4:])
+	}
+	// payload length, payload offset and checksum
+	if _, err := io.CopyN(header, reader, 8+8+4); err != nil {
+		return 0, err
+	}
+	payloadLen := binary.LittleEndian.Uint64(header.Bytes()[header.Len()-20:])
+	if _, err := io.CopyN(header, reader, int64(sparseMapSize)*xbstreamSparseMapEntry); err != nil {
+		return 0, err
+	}
+	return int64(payloadLen), nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
@@ -88,6 +89,15 @@ func readXtrabackupInfo(xtrabackupExtraDirectory string) (XtrabackupInfo, error)
 	return NewXtrabackupInfo(string(raw)), nil
 }
 
+// redirectRestoreCommand makes the restore command extract the backup to dir,
+// the target directory is expected to be the last argument, e.g. `xbstream -x -C /var/lib/mysql`
+func redirectRestoreCommand(restoreCmd *exec.Cmd, dir string) *exec.Cmd {
+	restoreCmd = cloneCommand(restoreCmd)
+	restoreArgs := strings.Fields(restoreCmd.Args[len(restoreCmd.Args)-1])
+	replaceCommandArgument(restoreCmd, restoreArgs[len(restoreArgs)-1], dir)
+	return restoreCmd
+}
+
 func enrichBackupArgs(backupCmd *exec.Cmd, xtrabackupExtraDirectory string, isFullBackup bool, prevBackupInfo *PrevBackupInfo) {
 	if prevBackupInfo == nil {
 		tracelog.ErrorLogger.Fatalf("PrevBackupInfo is null")
@@ -95,16 +105,44 @@ func enrichBackupArgs(backupCmd *exec.Cmd, xtrabackupExtraDirectory string, isFu
 	// -–extra-lsndir=DIRECTORY - save an extra copy of the xtrabackup_checkpoints and xtrabackup_info files in this directory.
 	injectCommandArgument(backupCmd, "--extra-lsndir="+xtrabackupExtraDirectory)
 
-	if !isFullBackup && (*prevBackupInfo != PrevBackupInfo{} && prevBackupInfo.sentinel.LSN != nil) {
+	if !isFullBackup && prevBackupInfo.name != "" && prevBackupInfo.sentinel.LSN != nil {
 		// –-incremental-lsn=LSN
 		injectCommandArgument(backupCmd, "--incremental-lsn="+prevBackupInfo.sentinel.LSN.String())
 	}
 }
 
-func GetXtrabackupFetcher(restoreCmd, prepareCmd *exec.Cmd) func(folder storage.Folder, backup internal.Backup) {
-	return func(folder storage.Folder, backup internal.Backup) {
-		err := xtrabackupFetch(backup.Name, folder, restoreCmd, prepareCmd, true)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v", err)
+func GetXtrabackupFetcher(restoreCmd, prepareCmd *exec.Cmd) internal.Fetcher {
+	return func(folder storage.Folder, backup internal.Backup) error {
+		err := xtrabackupFetch(backup.Name, folder, restoreCmd, prepareCmd, nil, true)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch backup")
+		}
+		return nil
+	}
+}
+
+// GetXtrabackupTablesFetcher restores only the given tables to tablesDst and prepares them for the import
+func GetXtrabackupTablesFetcher(restoreCmd, prepareCmd *exec.Cmd,
+	tables []string, tablesDst string) internal.Fetcher {
+	return func(folder storage.Folder, backup internal.Backup) error {
+		restore, err := newTableRestore(tables, tablesDst)
+		if err != nil {
+			return err
+		}
+		if prepareCmd == nil {
+			return errors.Errorf("%s is required to export the tables", conf.MysqlBackupPrepareCmd)
+		}
+		err = os.MkdirAll(tablesDst, 0750)
+		if err != nil {
+			return errors.Wrap(err, "failed to create tables directory")
+		}
+
+		err = xtrabackupFetch(backup.Name, folder, restoreCmd, prepareCmd, restore, true)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch tables")
+		}
+		restore.printImportInstructions()
+		return nil
 	}
 }
 
@@ -113,6 +151,7 @@ func xtrabackupFetch(
 	folder storage.Folder,
 	restoreCmd *exec.Cmd,
 	prepareCmd *exec.Cmd,
+	tables *tableRestore,
 	isLast bool) error {
 	backup, err := internal.GetBackupByName(backupName, utility.BaseBackupPath, folder)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v", err)
@@ -135,21 +174,31 @@ func xtrabackupFetch(
 
 	if sentinel.IsIncremental {
 		tracelog.InfoLogger.Printf("Delta from %v at LSN %x \n", *sentinel.IncrementFrom, *sentinel.IncrementFromLSN)
-		err = xtrabackupFetch(*sentinel.IncrementFrom, folder, restoreCmd, prepareCmd, false)
+		err = xtrabackupFetch(*sentinel.IncrementFrom, folder, restoreCmd, prepareCmd, tables, false)
 		if err != nil {
 			return err
 		}
 	}
 
 	if sentinel.IsIncremental {
-		restoreCmd = cloneCommand(restoreCmd)
-		restoreArgs := strings.Fields(restoreCmd.Args[len(restoreCmd.Args)-1])
-		replaceCommandArgument(restoreCmd, restoreArgs[len(restoreArgs)-1], tempDeltaDir)
+		restoreCmd = redirectRestoreCommand(restoreCmd, tempDeltaDir)
 
 		prepareCmd = cloneCommand(prepareCmd)
 		injectCommandArgument(prepareCmd, XtrabackupIncrementalDir+"="+tempDeltaDir)
 	}
+	if tables != nil {
+		// xtrabackup takes the last --target-dir, so the tables are prepared apart from the datadir
+		if !sentinel.IsIncremental {
+			restoreCmd = redirectRestoreCommand(restoreCmd, tables.dstDir)
+			prepareCmd = cloneCommand(prepareCmd)
+		}
+		injectCommandArgument(prepareCmd, "--target-dir="+tables.dstDir)
+		if isLast {
+			injectCommandArgument(prepareCmd, XtrabackupExport)
+		}
+	}
 	if !isLast {
+		prepareCmd = cloneCommand(prepareCmd)
 		injectCommandArgument(prepareCmd, XtrabackupApplyLogOnly)
 	}
 
@@ -168,7 +217,15 @@ func xtrabackupFetch(
 		tracelog.ErrorLogger.Printf("Failed to detect backup format: %v\n", err)
 		return err
 	}
-	err = fetcher(backup, stdin)
+	if tables != nil {
+		tablesStream, filterDone := tables.filter(stdin)
+		err = fetcher(backup, tablesStream)
+		if filterErr := <-filterDone; err == nil {
+			err = filterErr
+		}
+	} else {
+		err = fetcher(backup, stdin)
+	}
 	cmdErr := restoreCmd.Wait()
 	if cmdErr != nil {
 		tracelog.ErrorLogger.Printf("Restore command output:\n%s", stderr.String())
@@ -178,6 +235,11 @@ func xtrabackupFetch(
 		return err
 	}
 	tracelog.InfoLogger.Printf("Restored %s", backupName)
+	if tables != nil && isLast {
+		if missing := tables.missingTables(); len(missing) > 0 {
+			return errors.Errorf("tables %s are not found in the backup %s", strings.Join(missing, ", "), backupName)
+		}
+	}
 
 	if prepareCmd != nil {
 		tracelog.InfoLogger.Printf("Preparing %s with cmd %v", backupName, prepareCmd.Args)
@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+)
+
+const XtrabackupExport = "--export"
+
+var (
+	// the suffixes xtrabackup adds to the files of compressed, encrypted and incremental backups
+	xtrabackupFileSuffixes = []string{".xbcrypt", ".qp", ".zst", ".lz4", ".delta", ".meta"}
+	// the files needed to import the table: the tablespace, the exported metadata and the table definition
+	tableFileExtensions = []string{".ibd", ".cfg", ".cfp", ".frm", ".isl"}
+)
+
+// tableRestore extracts only the files of the given tables and the backup metadata from the xbstream backup,
+// so the tables can be prepared with `xtrabackup --prepare --export` and imported with
+// `ALTER TABLE ... IMPORT TABLESPACE`
+type tableRestore struct {
+	dstDir string
+	// found tables by their paths within the datadir, e.g. db/tbl
+	tables map[string]bool
+}
+
+func newTableRestore(tables []string, dstDir string) (*tableRestore, error) {
+	if dstDir == "" {
+		return nil, errors.New("destination directory for the tables is not specified")
+	}
+	result := &tableRestore{dstDir: dstDir, tables: make(map[string]bool, len(tables))}
+	for _, table := range tables {
+		table = strings.TrimSpace(table)
+		parts := strings.Split(table, ".")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("table %q should be specified as db.tbl", table)
+		}
+		result.tables[parts[0]+"/"+parts[1]] = false
+	}
+	if len(result.tables) == 0 {
+		return nil, errors.New("no tables to restore")
+	}
+	return result, nil
+}
+
+// match tells whether the file from the backup should be extracted.
+// The files in the backup root (ibdata, undo tablespaces, mysql.ibd, xtrabackup_logfile, xtrabackup_checkpoints, etc)
+// are needed for the prepare.
+func (restore *tableRestore) match(path string) bool {
+	dir, file := filepath.Split(path)
+	if dir == "" {
+		return true
+	}
+	for _, suffix := range xtrabackupFileSuffixes {
+		file = strings.TrimSuffix(file, suffix)
+	}
+	ext := filepath.Ext(file)
+	if !containsString(tableFileExtensions, ext) {
+		return false
+	}
+	table := strings.TrimSuffix(file, ext)
+	// partitions are stored as tbl#p#p0.ibd or tbl#P#p0.ibd
+	if i := strings.Index(strings.ToLower(table), "#p#"); i >= 0 {
+		table = table[:i]
+	}
+	key := filepath.Clean(dir) + "/" + table
+	if _, ok := restore.tables[key]; !ok {
+		return false
+	}
+	restore.tables[key] = true
+	return true
+}
+
+func (restore *tableRestore) missingTables() []string {
+	missing := make([]string, 0)
+	for table, found := range restore.tables {
+		if !found {
+			missing = append(missing, strings.Replace(table, "/", ".", 1))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// filter copies the backup stream to the restore command keeping only the files of the tables,
+// the returned channel gets the result of the filtering once the stream is over
+func (restore *tableRestore) filter(dst io.WriteCloser) (io.WriteCloser, <-chan error) {
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := filterXbstream(dst, reader, restore.match)
+		closeErr := dst.Close()
+		if err == nil {
+			err = closeErr
+		}
+		// unblock the backup download if the filter failed
+		_ = reader.CloseWithError(err)
+		done <- err
+	}()
+	return writer, done
+}
+
+// printImportInstructions shows how to import the prepared tables
+func (restore *tableRestore) printImportInstructions() {
+	tables := make([]string, 0, len(restore.tables))
+	for table := range restore.tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	tracelog.InfoLogger.Printf("Tables are prepared in %s. To import them create the tables with the same definition "+
+		"and run for each table:", restore.dstDir)
+	for _, table := range tables {
+		name := fmt.Sprintf("`%s`", strings.Replace(table, "/", "`.`", 1))
+		files, _ := filepath.Glob(filepath.Join(restore.dstDir, table+".*"))
+		partitionFiles, _ := filepath.Glob(filepath.Join(restore.dstDir, table+"#[pP]#*"))
+		files = append(files, partitionFiles...)
+		fmt.Fprintf(os.Stdout, "ALTER TABLE %s DISCARD TABLESPACE;\n", name)
+		fmt.Fprintf(os.Stdout, "-- copy %s to the table's database directory in the datadir, chown them to mysql\n",
+			strings.Join(files, " "))
+		fmt.Fprintf(os.Stdout, "ALTER TABLE %s IMPORT TABLESPACE;\n", name)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendTestXbstreamChunk(data []byte, chunkType byte, path string, payload []byte) []byte {
+	data = append(data, xbstreamMagic...)
+	data = append(data, 0, chunkType)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(path)))
+	data = append(data, path...)
+	if chunkType == xbstreamChunkEOF {
+		return data
+	}
+	if chunkType == xbstreamChunkSparse {
+		data = binary.LittleEndian.AppendUint32(data, 1)
+	}
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(payload)))
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, 0)
+	if chunkType == xbstreamChunkSparse {
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(payload)))
+	}
+	return append(data, payload...)
+}
+
+func appendTestXbstreamFile(data []byte, path string, payload string) []byte {
+	data = appendTestXbstreamChunk(data, xbstreamChunkPayload, path, []byte(payload))
+	return appendTestXbstreamChunk(data, xbstreamChunkEOF, path, nil)
+}
+
+func TestNewTableRestore(t *testing.T) {
+	restore, err := newTableRestore([]string{"db.t1", " db.t2"}, "/tmp/tables")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"db/t1": false, "db/t2": false}, restore.tables)
+
+	for _, invalid := range [][]string{{}, {"t1"}, {"db."}, {".t1"}, {"db.t1.ibd"}} {
+		_, err = newTableRestore(invalid, "/tmp/tables")
+		assert.Error(t, err, invalid)
+	}
+	_, err = newTableRestore([]string{"db.t1"}, "")
+	assert.Error(t, err)
+}
+
+func TestTableRestore_Match(t *testing.T) {
+	restore, err := newTableRestore([]string{"db.t1", "db.t2", "db.t3"}, "/tmp/tables")
+	require.NoError(t, err)
+
+	for path, exp := range map[string]bool{
+		"xtrabackup_checkpoints": true,
+		"xtrabackup_logfile":     true,
+		"ibdata1.qp":             true,
+		"mysql.ibd":              true,
+		"db/t1.ibd":              true,
+		"db/t1.cfg":              true,
+		"db/t1.frm.zst.xbcrypt":  true,
+		"db/t2#p#p0.ibd.delta":   true,
+		"db/t2#P#p1.ibd.meta":    true,
+		"db/t10.ibd":             false,
+		"db/t1.MYD":              false,
+		"db/t1_1234.sdi":         false,
+		"other/t1.ibd":           false,
+		"mysql/t1.ibd":           false,
+	} {
+		assert.Equal(t, exp, restore.match(path), path)
+	}
+	assert.Equal(t, []string{"db.t3"}, restore.missingTables())
+}
+
+func TestFilterXbstream(t *testing.T) {
+	var stream []byte
+	stream = appendTestXbstreamFile(stream, "xtrabackup_checkpoints", "to_lsn = 42")
+	stream = appendTestXbstreamFile(stream, "db/t1.ibd", "t1 data")
+	stream = appendTestXbstreamFile(stream, "db/t2.ibd", "t2 data")
+	stream = appendTestXbstreamChunk(stream, xbstreamChunkSparse, "db/t1.ibd", []byte("t1 sparse data"))
+
+	restore, err := newTableRestore([]string{"db.t1"}, "/tmp/tables")
+	require.NoError(t, err)
+	filtered := &bytes.Buffer{}
+	require.NoError(t, filterXbstream(filtered, bytes.NewReader(stream), restore.match))
+
+	var expected []byte
+	expected = appendTestXbstreamFile(expected, "xtrabackup_checkpoints", "to_lsn = 42")
+	expected = appendTestXbstreamFile(expected, "db/t1.ibd", "t1 data")
+	expected = appendTestXbstreamChunk(expected, xbstreamChunkSparse, "db/t1.ibd", []byte("t1 sparse data"))
+	assert.Equal(t, expected, filtered.Bytes())
+	assert.Empty(t, restore.missingTables())
+}
+
+func TestFilterXbstream_Invalid(t *testing.T) {
+	stream := appendTestXbstreamFile(nil, "db/t1.ibd", "t1 data")
+	match := func(string) bool { return true }
+
+	err := filterXbstream(io.Discard, bytes.NewReader(stream[:len(stream)-5]), match)
+	assert.Error(t, err)
+
+	err = filterXbstream(io.Discard, bytes.NewReader([]byte("-- MySQL dump 10.13")), match)
+	assert.Error(t, err)
+}
+
+func TestTableRestore_Filter(t *testing.T) {
+	restore, err := newTableRestore([]string{"db.t1"}, "/tmp/tables")
+	require.NoError(t, err)
+	reader, writer := io.Pipe()
+	filtered, done := restore.filter(writer)
+
+	var stream []byte
+	stream = appendTestXbstreamFile(stream, "db/t1.ibd", "t1 data")
+	stream = appendTestXbstreamFile(stream, "db/t2.ibd", "t2 data")
+	go func() {
+		_, _ = filtered.Write(stream)
+		_ = filtered.Close()
+	}()
+
+	result, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NoError(t, <-done)
+	assert.Equal(t, appendTestXbstreamFile(nil, "db/t1.ibd", "t1 data"), result)
+}
@@ -100,21 +100,27 @@ func deltaFetchRecursionOld(backup Backup, rootFolder storage.Folder, dbDataDire
 }
 
 func GetFetcherOld(dbDataDirectory, fileMask, restoreSpecPath string, extractProv ExtractProvider) internal.Fetcher {
-	return func(rootFolder storage.Folder, backup internal.Backup) {
+	return func(rootFolder storage.Folder, backup internal.Backup) error {
 		pgBackup := ToPgBackup(backup)
 		filesToUnwrap, err := pgBackup.GetFilesToUnwrap(fileMask)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
 
 		var spec *TablespaceSpec
 		if restoreSpecPath != "" {
 			spec = &TablespaceSpec{}
 			err := readRestoreSpec(restoreSpecPath, spec)
-			errMessage := fmt.Sprintf("Invalid restore specification path %s\n", restoreSpecPath)
-			tracelog.ErrorLogger.FatalfOnError(errMessage, err)
+			if err != nil {
+				return fmt.Errorf("invalid restore specification path %s: %w", restoreSpecPath, err)
+			}
 		}
 
 		err = deltaFetchRecursionOld(pgBackup, rootFolder, utility.ResolveSymlink(dbDataDirectory), spec, filesToUnwrap, extractProv)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
+		return nil
 	}
 }
 
@@ -12,26 +12,30 @@ import (
 func GetFetcherNew(dbDataDirectory, fileMask, restoreSpecPath string, skipRedundantTars bool,
 	extractProv ExtractProvider,
 ) internal.Fetcher {
-	return func(rootFolder storage.Folder, backup internal.Backup) {
+	return func(rootFolder storage.Folder, backup internal.Backup) error {
 		pgBackup := ToPgBackup(backup)
 		filesToUnwrap, err := pgBackup.GetFilesToUnwrap(fileMask)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
 
 		var spec *TablespaceSpec
 		if restoreSpecPath != "" {
 			spec = &TablespaceSpec{}
 			err := readRestoreSpec(restoreSpecPath, spec)
-			errMessege := fmt.Sprintf("Invalid restore specification path %s\n", restoreSpecPath)
-			tracelog.ErrorLogger.FatalfOnError(errMessege, err)
+			if err != nil {
+				return fmt.Errorf("invalid restore specification path %s: %w", restoreSpecPath, err)
+			}
 		}
 
 		// directory must be empty before starting a deltaFetch
 		isEmpty, err := utility.IsDirectoryEmpty(dbDataDirectory)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
 
 		if !isEmpty {
-			tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n",
-				NewNonEmptyDBDataDirectoryError(dbDataDirectory))
+			return fmt.Errorf("failed to fetch backup: %w", NewNonEmptyDBDataDirectoryError(dbDataDirectory))
 		}
 		config := NewFetchConfig(
 			utility.ResolveSymlink(dbDataDirectory),
@@ -43,7 +47,10 @@ func GetFetcherNew(dbDataDirectory, fileMask, restoreSpecPath string, skipRedund
 			extractProv,
 		)
 		err = deltaFetchRecursionNew(config)
-		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v\n", err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch backup: %w", err)
+		}
+		return nil
 	}
 }
 
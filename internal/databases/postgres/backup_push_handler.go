@@ -18,6 +18,7 @@ import (
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/notify"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -65,6 +66,7 @@ type BackupArguments struct {
 	withoutFilesMetadata     bool
 	composerInitFunc         func(handler *BackupHandler) error
 	preventConcurrentBackups bool
+	daemonMode               bool
 }
 
 // CurBackupInfo holds all information that is harvest during the backup process
@@ -114,6 +116,9 @@ type BackupHandler struct {
 	Arguments      BackupArguments
 	Workers        BackupWorkers
 	PgInfo         BackupPgInfo
+	notification   *notify.Notification
+	// stopTerminator stops watching for the backup interruption once the backup is finished
+	stopTerminator func()
 }
 
 // NewBackupArguments creates a BackupArgument object to hold the arguments from the cmd
@@ -143,7 +148,13 @@ func (ba *BackupArguments) EnablePreventConcurrentBackups() {
 	tracelog.InfoLogger.Println("Concurrent backups are disabled")
 }
 
-func (bh *BackupHandler) createAndPushBackup(ctx context.Context) {
+// EnableDaemonMode makes the backup interruption fail only the backup instead of exiting the process.
+// The daemon handles the signals itself and interrupts the backup by canceling its context.
+func (ba *BackupArguments) EnableDaemonMode() {
+	ba.daemonMode = true
+}
+
+func (bh *BackupHandler) createAndPushBackup(ctx context.Context) error {
 	var err error
 	folder := bh.Arguments.Uploader.Folder()
 	// TODO: AB: this subfolder switch look ugly.
@@ -157,25 +168,44 @@ func (bh *BackupHandler) createAndPushBackup(ctx context.Context) {
 		bh.prevBackupInfo.sentinelDto.BackupStartLSN, bh.prevBackupInfo.filesMetadataDto.Files, arguments.forceIncremental,
 		viper.GetInt64(conf.TarSizeThresholdSetting))
 
-	err = bh.startBackup()
-	tracelog.ErrorLogger.FatalOnError(err)
-	bh.handleDeltaBackup(folder)
-	tarFileSets := bh.uploadBackup()
+	err = bh.startBackup(ctx)
+	if err != nil {
+		return err
+	}
+	err = bh.handleDeltaBackup(folder)
+	if err != nil {
+		return err
+	}
+	// the sentinel must list only the levels of this backup tar files
+	internal.ResetAdaptiveCompressionLevels(bh.Arguments.Uploader)
+	tarFileSets, err := bh.uploadBackup()
+	if err != nil {
+		return err
+	}
 	sentinelDto, filesMetaDto, err := bh.setupDTO(tarFileSets)
-	tracelog.ErrorLogger.FatalOnError(err)
-	bh.markBackups(folder, sentinelDto)
-	bh.uploadMetadata(ctx, sentinelDto, filesMetaDto)
+	if err != nil {
+		return err
+	}
+	err = bh.markBackups(folder, sentinelDto)
+	if err != nil {
+		return err
+	}
+	err = bh.uploadMetadata(ctx, sentinelDto, filesMetaDto)
+	if err != nil {
+		return err
+	}
 
 	storageNames := multistorage.UsedStorages(folder)
 	if len(storageNames) == 0 {
-		tracelog.ErrorLogger.Fatalf("No storages are used in the uploading folder")
+		return errors.New("No storages are used in the uploading folder")
 	}
 
 	// logging backup set Name
 	tracelog.InfoLogger.Printf("Wrote backup with name %s to storage %s", bh.CurBackupInfo.Name, storageNames[0])
+	return nil
 }
 
-func (bh *BackupHandler) startBackup() error {
+func (bh *BackupHandler) startBackup(ctx context.Context) error {
 	// Connect to postgres and start/finish a nonexclusive backup.
 	tracelog.DebugLogger.Println("Connecting to Postgres.")
 	conn, err := Connect()
@@ -226,28 +256,39 @@ func (bh *BackupHandler) startBackup() error {
 	bh.CurBackupInfo.startLSN = backupStartLSN
 	bh.CurBackupInfo.Name = backupName
 	tracelog.DebugLogger.Printf("Backup name: %s\nBackup start LSN: %s", backupName, backupStartLSN)
-	bh.initBackupTerminator()
-	return nil
+	bh.stopTerminator, err = bh.initBackupTerminator(ctx)
+	return err
 }
 
-func (bh *BackupHandler) handleDeltaBackup(folder storage.Folder) {
+func (bh *BackupHandler) handleDeltaBackup(folder storage.Folder) error {
 	if len(bh.prevBackupInfo.name) > 0 && bh.prevBackupInfo.sentinelDto.BackupStartLSN != nil {
 		tracelog.InfoLogger.Println("Delta backup enabled")
 		tracelog.DebugLogger.Printf("Previous backup: %s\nBackup start LSN: %d", bh.prevBackupInfo.name,
 			bh.prevBackupInfo.sentinelDto.BackupStartLSN)
 		if *bh.prevBackupInfo.sentinelDto.BackupFinishLSN > bh.CurBackupInfo.startLSN {
-			tracelog.ErrorLogger.FatalOnError(newBackupFromFuture(bh.prevBackupInfo.name))
+			return newBackupFromFuture(bh.prevBackupInfo.name)
 		}
 		if bh.prevBackupInfo.sentinelDto.SystemIdentifier != nil &&
 			bh.PgInfo.systemIdentifier != nil &&
 			*bh.PgInfo.systemIdentifier != *bh.prevBackupInfo.sentinelDto.SystemIdentifier {
-			tracelog.ErrorLogger.FatalOnError(newBackupFromOtherBD())
+			return newBackupFromOtherBD()
 		}
 
 		useWalDelta, _, err := configureWalDeltaUsage()
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return err
+		}
 
-		if useWalDelta {
+		if bh.isWalSummarizationEnabled() {
+			err := bh.Workers.Bundle.LoadWalSummariesDeltaMap(bh.CurBackupInfo.startLSN)
+			if err == nil {
+				tracelog.InfoLogger.Println("Successfully loaded delta map from WAL summaries, delta backup will be made " +
+					"with provided delta map")
+			} else {
+				tracelog.WarningLogger.Printf("Error during loading delta map from WAL summaries: '%v'\n", err)
+			}
+		}
+		if useWalDelta && bh.Workers.Bundle.DeltaMap == nil {
 			err := bh.Workers.Bundle.DownloadDeltaMap(internal.NewFolderReader(folder.GetSubFolder(utility.WalPath)), bh.CurBackupInfo.startLSN)
 			if err == nil {
 				tracelog.InfoLogger.Println("Successfully loaded delta map, delta backup will be made with provided " +
@@ -260,6 +301,20 @@ func (bh *BackupHandler) handleDeltaBackup(folder storage.Folder) {
 		bh.CurBackupInfo.Name = bh.CurBackupInfo.Name + "_D_" + utility.StripWalFileName(bh.prevBackupInfo.name)
 		tracelog.DebugLogger.Printf("Suffixing Backup name with Delta info: %s", bh.CurBackupInfo.Name)
 	}
+	return nil
+}
+
+// isWalSummarizationEnabled checks if PostgreSQL writes the WAL summaries, which the delta map can be built from
+func (bh *BackupHandler) isWalSummarizationEnabled() bool {
+	if bh.PgInfo.PgVersion < WalSummaryMinPgVersion {
+		return false
+	}
+	summarizeWal, err := bh.Workers.QueryRunner.GetParameter("summarize_wal")
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to check if WAL summarization is enabled: %v", err)
+		return false
+	}
+	return summarizeWal == "on"
 }
 
 func (bh *BackupHandler) setupDTO(tarFileSets internal.TarFileSets) (sentinelDto BackupSentinelDto,
@@ -275,13 +330,14 @@ func (bh *BackupHandler) setupDTO(tarFileSets internal.TarFileSets) (sentinelDto
 	return sentinelDto, filesMeta, err
 }
 
-func (bh *BackupHandler) markBackups(folder storage.Folder, sentinelDto BackupSentinelDto) {
+func (bh *BackupHandler) markBackups(folder storage.Folder, sentinelDto BackupSentinelDto) error {
 	// If pushing permanent delta backup, mark all previous backups permanent
 	// Do this before uploading current meta to ensure that backups are marked in increasing order
 	if bh.Arguments.isPermanent && sentinelDto.IsIncremental() {
 		markBackupHandler := internal.NewBackupMarkHandler(NewGenericMetaInteractor(), folder)
-		markBackupHandler.MarkBackup(bh.prevBackupInfo.name, true)
+		return markBackupHandler.TryMarkBackup(bh.prevBackupInfo.name, true)
 	}
+	return nil
 }
 
 func (bh *BackupHandler) SetComposerInitFunc(initFunc func(handler *BackupHandler) error) {
@@ -300,41 +356,57 @@ func configureTarBallComposer(bh *BackupHandler, tarBallComposerType TarBallComp
 	return bh.Workers.Bundle.SetupComposer(maker)
 }
 
-func (bh *BackupHandler) uploadBackup() internal.TarFileSets {
+func (bh *BackupHandler) uploadBackup() (internal.TarFileSets, error) {
 	bundle := bh.Workers.Bundle
 	// Start a new tar bundle, walk the pgDataDirectory and upload everything there.
 	tracelog.InfoLogger.Println("Starting a new tar bundle")
 	err := bundle.StartQueue(internal.NewStorageTarBallMaker(bh.CurBackupInfo.Name, bh.Arguments.Uploader))
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	err = bh.Arguments.composerInitFunc(bh)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.InfoLogger.Println("Walking ...")
 	err = filepath.Walk(bh.PgInfo.PgDataDirectory, bundle.HandleWalkedFSObject)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.InfoLogger.Println("Packing ...")
 	tarFileSets, err := bundle.FinishTarComposer()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.DebugLogger.Println("Finishing queue ...")
 	err = bundle.FinishQueue()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.DebugLogger.Println("Uploading pg_control ...")
 	err = bundle.UploadPgControl(bh.Arguments.Uploader.Compression().FileExtension())
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	// Stops backup and write/upload postgres `backup_label` and `tablespace_map` Files
 	tracelog.DebugLogger.Println("Stop backup and upload backup_label and tablespace_map")
 	labelFilesTarBallName, labelFilesList, finishLsn, err := bundle.uploadLabelFiles(bh.Workers.QueryRunner)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 	bh.CurBackupInfo.endLSN = finishLsn
 	bh.CurBackupInfo.uncompressedSize = atomic.LoadInt64(bundle.TarBallQueue.AllTarballsSize)
 	bh.CurBackupInfo.compressedSize, err = bh.Arguments.Uploader.UploadedDataSize()
 	bh.CurBackupInfo.dataCatalogSize = atomic.LoadInt64(bundle.DataCatalogSize)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 	tarFileSets.AddFiles(labelFilesTarBallName, labelFilesList)
 	timelineChanged := bundle.checkTimelineChanged(bh.Workers.QueryRunner)
 	tracelog.DebugLogger.Printf("Labelfiles tarball name: %s", labelFilesTarBallName)
@@ -347,30 +419,64 @@ func (bh *BackupHandler) uploadBackup() internal.TarFileSets {
 	tracelog.DebugLogger.Println("Waiting for all uploads to finish")
 	bh.Arguments.Uploader.Finish()
 	if bh.Arguments.Uploader.Failed() {
-		tracelog.ErrorLogger.Fatalf("Uploading failed during '%s' backup.\n", bh.CurBackupInfo.Name)
+		return nil, errors.Errorf("Uploading failed during '%s' backup.", bh.CurBackupInfo.Name)
 	}
 	if timelineChanged {
-		tracelog.ErrorLogger.Fatalf("Cannot finish backup because of changed timeline.")
+		return nil, errors.New("Cannot finish backup because of changed timeline.")
 	}
-	return tarFileSets
+	return tarFileSets, nil
 }
 
 // HandleBackupPush handles the backup being read from Postgres or filesystem and being pushed to the repository
 // TODO : unit tests
 func (bh *BackupHandler) HandleBackupPush(ctx context.Context) {
+	err := bh.PushBackup(ctx)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// PushBackup pushes the backup like HandleBackupPush, but returns the error instead of exiting
+func (bh *BackupHandler) PushBackup(ctx context.Context) (err error) {
 	bh.CurBackupInfo.StartTime = utility.TimeNowCrossPlatformUTC()
 
+	bh.notification, err = notify.Start(notify.BackupPush)
+	if err != nil {
+		return err
+	}
+	defer bh.finishBackup()
+	defer func() {
+		bh.notification.SetBackupName(bh.CurBackupInfo.Name)
+		if err == nil {
+			bh.notification.SetSizes(bh.CurBackupInfo.compressedSize, bh.CurBackupInfo.uncompressedSize)
+		}
+		bh.notification.Finish(err)
+	}()
+
 	if bh.Arguments.pgDataDirectory == "" {
-		bh.handleBackupPushRemote(ctx)
-	} else {
-		bh.handleBackupPushLocal(ctx)
+		return bh.handleBackupPushRemote(ctx)
+	}
+	return bh.handleBackupPushLocal(ctx)
+}
+
+// finishBackup stops the backup terminator and closes the backup connection, so the failed backup is aborted
+// by Postgres even if the process keeps running
+func (bh *BackupHandler) finishBackup() {
+	if bh.stopTerminator != nil {
+		bh.stopTerminator()
+		bh.stopTerminator = nil
+	}
+	if bh.Workers.QueryRunner != nil {
+		err := bh.Workers.QueryRunner.Connection.Close()
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to close the backup connection: %v", err)
+		}
+		bh.Workers.QueryRunner = nil
 	}
 }
 
-func (bh *BackupHandler) handleBackupPushRemote(ctx context.Context) {
+func (bh *BackupHandler) handleBackupPushRemote(ctx context.Context) error {
 	if bh.Arguments.forceIncremental {
 		tracelog.ErrorLogger.Println("Delta backup not available for remote backup.")
-		tracelog.ErrorLogger.Fatal("To run delta backup, supply [db_directory].")
+		return errors.New("To run delta backup, supply [db_directory].")
 	}
 	// If no arg is parsed, try to run remote backup using pglogrepl's BASE_BACKUP functionality
 	tracelog.InfoLogger.Println("Running remote backup through Postgres connection.")
@@ -380,17 +486,18 @@ func (bh *BackupHandler) handleBackupPushRemote(ctx context.Context) {
 		tracelog.InfoLogger.Println("VerifyPageChecksums=false is only supported for streaming backup since PG11")
 		bh.Arguments.verifyPageChecksums = true
 	}
-	bh.createAndPushRemoteBackup(ctx)
+	return bh.createAndPushRemoteBackup(ctx)
 }
 
-func (bh *BackupHandler) handleBackupPushLocal(ctx context.Context) {
+func (bh *BackupHandler) handleBackupPushLocal(ctx context.Context) error {
 	{
 		// The 'data' path provided on the command line must point at the same directory as the one listed by the Postgresql server.
 		// If mismatched, this means we aren't connected to the correct server. This is a fatal error.
 		fromCli := bh.Arguments.pgDataDirectory
 		fromServer := bh.PgInfo.PgDataDirectory // that value is expected to already be absolute and "unsymlinked"
 		if utility.AbsResolveSymlink(fromCli) != fromServer {
-			tracelog.ErrorLogger.Fatalf("Data directory from command line '%s' is not the same as Postgres' one '%s'", fromCli, fromServer)
+			return errors.Errorf("Data directory from command line '%s' is not the same as Postgres' one '%s'",
+				fromCli, fromServer)
 		}
 	}
 
@@ -398,21 +505,25 @@ func (bh *BackupHandler) handleBackupPushLocal(ctx context.Context) {
 	baseBackupFolder := folder.GetSubFolder(bh.Arguments.backupsFolder)
 	tracelog.DebugLogger.Printf("Base backup folder: %s", baseBackupFolder.GetPath())
 
-	bh.checkPgVersionAndPgControl()
+	err := bh.checkPgVersionAndPgControl()
+	if err != nil {
+		return err
+	}
 
 	if bh.Arguments.isFullBackup {
 		tracelog.InfoLogger.Println("Doing full backup.")
 	} else {
-		var err error
 		bh.prevBackupInfo, bh.CurBackupInfo.incrementCount, err = bh.Arguments.deltaConfigurator.Configure(
 			folder, bh.Arguments.isPermanent)
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return err
+		}
 	}
 
-	bh.createAndPushBackup(ctx)
+	return bh.createAndPushBackup(ctx)
 }
 
-func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context) {
+func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context) error {
 	var err error
 	uploader := bh.Arguments.Uploader
 	uploader.ChangeDirectory(utility.BaseBackupPath)
@@ -425,40 +536,51 @@ func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context) {
 		tarFileSets = internal.NewRegularTarFileSets()
 	}
 
-	baseBackup := bh.runRemoteBackup(ctx)
+	internal.ResetAdaptiveCompressionLevels(uploader)
+	baseBackup, err := bh.runRemoteBackup(ctx)
+	if err != nil {
+		return err
+	}
 	tracelog.InfoLogger.Println("Updating metadata")
 	bh.CurBackupInfo.startLSN = LSN(baseBackup.StartLSN)
 	bh.CurBackupInfo.endLSN = LSN(baseBackup.EndLSN)
 
 	bh.CurBackupInfo.uncompressedSize = baseBackup.UncompressedSize
 	bh.CurBackupInfo.compressedSize, err = bh.Arguments.Uploader.UploadedDataSize()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 	sentinelDto := NewBackupSentinelDto(bh, baseBackup.GetTablespaceSpec())
 	filesMetadataDto := NewFilesMetadataDto(baseBackup.Files, tarFileSets)
 	bh.CurBackupInfo.Name = baseBackup.BackupName()
 	tracelog.InfoLogger.Println("Uploading metadata")
-	bh.uploadMetadata(ctx, sentinelDto, filesMetadataDto)
+	err = bh.uploadMetadata(ctx, sentinelDto, filesMetadataDto)
+	if err != nil {
+		return err
+	}
 	// logging backup set Name
 	tracelog.InfoLogger.Printf("Wrote backup with name %s", bh.CurBackupInfo.Name)
+	return nil
 }
 
-func (bh *BackupHandler) uploadMetadata(ctx context.Context, sentinelDto BackupSentinelDto, filesMetaDto FilesMetadataDto) {
+func (bh *BackupHandler) uploadMetadata(ctx context.Context, sentinelDto BackupSentinelDto, filesMetaDto FilesMetadataDto) error {
 	curBackupName := bh.CurBackupInfo.Name
 	meta := NewExtendedMetadataDto(bh.Arguments.isPermanent, bh.PgInfo.PgDataDirectory,
 		bh.CurBackupInfo.StartTime, sentinelDto)
 
 	err := bh.uploadExtendedMetadata(ctx, meta)
 	if err != nil {
-		tracelog.ErrorLogger.Fatalf("Failed to upload metadata file for backup %s: %v", curBackupName, err)
+		return errors.Wrapf(err, "Failed to upload metadata file for backup %s", curBackupName)
 	}
 	err = bh.uploadFilesMetadata(ctx, filesMetaDto)
 	if err != nil {
-		tracelog.ErrorLogger.Fatalf("Failed to upload files metadata for backup %s: %v", curBackupName, err)
+		return errors.Wrapf(err, "Failed to upload files metadata for backup %s", curBackupName)
 	}
 	err = internal.UploadSentinel(bh.Arguments.Uploader, NewBackupSentinelDtoV2(sentinelDto, meta), bh.CurBackupInfo.Name)
 	if err != nil {
-		tracelog.ErrorLogger.Fatalf("Failed to upload sentinel file for backup %s: %v", curBackupName, err)
+		return errors.Wrapf(err, "Failed to upload sentinel file for backup %s", curBackupName)
 	}
+	return nil
 }
 
 func (bh *BackupHandler) collectDatabaseNamesMetadata() (DatabasesByNames, error) {
@@ -499,7 +621,7 @@ func NewBackupHandler(arguments BackupArguments) (bh *BackupHandler, err error)
 	return bh, nil
 }
 
-func (bh *BackupHandler) runRemoteBackup(ctx context.Context) *StreamingBaseBackup {
+func (bh *BackupHandler) runRemoteBackup(ctx context.Context) (*StreamingBaseBackup, error) {
 	var diskLimit int32
 	if viper.IsSet(conf.DiskRateLimitSetting) {
 		// Note that BASE_BACKUP (pg protocol) allows to limit in kb/sec
@@ -512,7 +634,9 @@ func (bh *BackupHandler) runRemoteBackup(ctx context.Context) *StreamingBaseBack
 	// Connect to postgres and start/finish a nonexclusive backup.
 	tracelog.DebugLogger.Println("Connecting to Postgres (replication connection)")
 	conn, err := pgconn.Connect(context.Background(), "replication=yes")
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	baseBackup := NewStreamingBaseBackup(bh.PgInfo.PgDataDirectory, viper.GetInt64(conf.TarSizeThresholdSetting), conn)
 	var bundleFiles internal.BundleFiles
@@ -523,21 +647,29 @@ func (bh *BackupHandler) runRemoteBackup(ctx context.Context) *StreamingBaseBack
 	}
 	tracelog.InfoLogger.Println("Starting remote backup")
 	err = baseBackup.Start(bh.Arguments.verifyPageChecksums, diskLimit)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.InfoLogger.Println("Streaming remote backup")
 	err = baseBackup.Upload(ctx, bh.Arguments.Uploader, bundleFiles)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.InfoLogger.Println("Finishing backup")
 	tracelog.InfoLogger.Println("If wal-g hangs during this step, please Postgres log file for details.")
 	err = baseBackup.Finish()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 
 	tracelog.DebugLogger.Println("Closing Postgres connection (replication connection)")
 	err = conn.Close(context.Background())
-	tracelog.ErrorLogger.FatalOnError(err)
-	return baseBackup
+	if err != nil {
+		return nil, err
+	}
+	return baseBackup, nil
 }
 
 func getPgServerInfo() (pgInfo BackupPgInfo, err error) {
@@ -606,52 +738,117 @@ func (bh *BackupHandler) uploadFilesMetadata(ctx context.Context, filesMetaDto F
 	return bh.Arguments.Uploader.Upload(ctx, getFilesMetadataPath(bh.CurBackupInfo.Name), bytes.NewReader(dtoBody))
 }
 
-func (bh *BackupHandler) checkPgVersionAndPgControl() {
+func (bh *BackupHandler) checkPgVersionAndPgControl() error {
 	_, err := os.ReadFile(filepath.Join(bh.PgInfo.PgDataDirectory, PgControlPath))
-	tracelog.ErrorLogger.FatalfOnError(
-		"It looks like you are trying to backup not pg_data. PgControl file not found: %v\n", err)
+	if err != nil {
+		return fmt.Errorf("It looks like you are trying to backup not pg_data. PgControl file not found: %v", err)
+	}
 	_, err = os.ReadFile(filepath.Join(bh.PgInfo.PgDataDirectory, "PG_VERSION"))
-	tracelog.ErrorLogger.FatalfOnError(
-		"It looks like you are trying to backup not pg_data. PG_VERSION file not found: %v\n", err)
+	if err != nil {
+		return fmt.Errorf("It looks like you are trying to backup not pg_data. PG_VERSION file not found: %v", err)
+	}
+	return nil
 }
 
-func (bh *BackupHandler) initBackupTerminator() {
+// initBackupTerminator gracefully stops the running backup on the interruption signal or if Postgres is not alive.
+// The CLI exits after the termination. In the daemon mode the signals are left to the daemon, which cancels ctx,
+// and only the backup fails.
+// The returned function stops the terminator once the backup is finished.
+func (bh *BackupHandler) initBackupTerminator(ctx context.Context) (stop func(), err error) {
 	errCh := make(chan error, 1)
+	done := make(chan struct{})
 
-	addSignalListener(errCh)
-	addPgIsAliveChecker(bh.Workers.QueryRunner, errCh)
+	var stopListeners []func()
+	if bh.Arguments.daemonMode {
+		addContextListener(ctx, errCh, done)
+	} else {
+		stopListeners = append(stopListeners, addSignalListener(errCh, done))
+	}
+	stopChecker, err := addPgIsAliveChecker(bh.Workers.QueryRunner, errCh, done)
+	if err != nil {
+		return nil, err
+	}
+	stopListeners = append(stopListeners, stopChecker)
 
 	terminator := NewBackupTerminator(bh.Workers.QueryRunner, bh.PgInfo.PgVersion, bh.PgInfo.PgDataDirectory)
 
+	stopped := make(chan struct{})
 	go func() {
-		err := <-errCh
-		tracelog.ErrorLogger.Printf("Error: %v, gracefully stopping the running backup...", err)
-		terminator.TerminateBackup()
-		tracelog.ErrorLogger.Fatal("Finished backup termination, will now exit")
+		defer close(stopped)
+		select {
+		case <-done:
+			return
+		case err := <-errCh:
+			tracelog.ErrorLogger.Printf("Error: %v, gracefully stopping the running backup...", err)
+			terminator.TerminateBackup()
+			bh.notification.Finish(err)
+			if !bh.Arguments.daemonMode {
+				tracelog.ErrorLogger.Fatal("Finished backup termination, will now exit")
+			}
+		}
 	}()
+	return func() {
+		close(done)
+		for _, stopListener := range stopListeners {
+			stopListener()
+		}
+		<-stopped
+	}, nil
 }
 
-func addSignalListener(errCh chan error) {
+func addSignalListener(errCh chan error, done <-chan struct{}) (stop func()) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
 	go func() {
-		sig := <-sigCh
-		errCh <- fmt.Errorf("received interruption signal: %s", sig)
+		select {
+		case sig := <-sigCh:
+			sendTerminationError(errCh, fmt.Errorf("received interruption signal: %s", sig))
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sigCh)
+	}
+}
+
+func addContextListener(ctx context.Context, errCh chan error, done <-chan struct{}) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			sendTerminationError(errCh, fmt.Errorf("backup is canceled: %w", ctx.Err()))
+		case <-done:
+		}
 	}()
 }
 
-func addPgIsAliveChecker(queryRunner *PgQueryRunner, errCh chan error) {
+func addPgIsAliveChecker(queryRunner *PgQueryRunner, errCh chan error, done <-chan struct{}) (stop func(), err error) {
 	if !viper.IsSet(conf.PgAliveCheckInterval) {
-		return
+		return func() {}, nil
 	}
 	stateUpdateInterval, err := conf.GetDurationSetting(conf.PgAliveCheckInterval)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return nil, err
+	}
 	tracelog.InfoLogger.Printf("Initializing the PG alive checker (interval=%s)...", stateUpdateInterval)
 	pgWatcher := NewPgWatcher(queryRunner, stateUpdateInterval)
 
 	go func() {
-		err := <-pgWatcher.Err
-		errCh <- fmt.Errorf("PG alive check failed: %v", err)
+		select {
+		case err := <-pgWatcher.Err:
+			if err != nil {
+				sendTerminationError(errCh, fmt.Errorf("PG alive check failed: %v", err))
+			}
+		case <-done:
+		}
 	}()
+	return pgWatcher.Stop, nil
+}
+
+// sendTerminationError never blocks, the first error is enough to terminate the backup
+func sendTerminationError(errCh chan error, err error) {
+	select {
+	case errCh <- err:
+	default:
+	}
 }
@@ -33,6 +33,9 @@ type BackupSentinelDto struct {
 	UserData interface{} `json:"UserData,omitempty"`
 
 	FilesMetadataDisabled bool `json:"FilesMetadataDisabled,omitempty"`
+
+	// CompressionLevels are the levels chosen for the tar files by the adaptive compression method
+	CompressionLevels map[string]string `json:"CompressionLevels,omitempty"`
 }
 
 func NewBackupSentinelDto(bh *BackupHandler, tbsSpec *TablespaceSpec) BackupSentinelDto {
@@ -59,6 +62,7 @@ func NewBackupSentinelDto(bh *BackupHandler, tbsSpec *TablespaceSpec) BackupSent
 	sentinel.CompressedSize = bh.CurBackupInfo.compressedSize
 	sentinel.DataCatalogSize = bh.CurBackupInfo.dataCatalogSize
 	sentinel.FilesMetadataDisabled = bh.Arguments.withoutFilesMetadata
+	sentinel.CompressionLevels = internal.AdaptiveCompressionLevels(bh.Arguments.Uploader)
 	return sentinel
 }
 
@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupTerminator_StopInDaemonMode(t *testing.T) {
+	bh := &BackupHandler{Arguments: BackupArguments{daemonMode: true}}
+	stop, err := bh.initBackupTerminator(context.Background())
+	assert.NoError(t, err)
+	stop()
+}
+
+func TestAddContextListener(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	addContextListener(ctx, errCh, make(chan struct{}))
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
@@ -0,0 +1,355 @@
+package postgres
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+)
+
+const (
+	BackupVerifyStatusOk      = "OK"
+	BackupVerifyStatusFailure = "FAILURE"
+
+	// the offset of the pg_control checksum depends on the Postgres version,
+	// so it is searched for in this range of the ControlFileData sizes
+	pgControlMinCrcOffset = 128
+	pgControlMaxCrcOffset = 512
+)
+
+// BackupVerifyReport is the result of the backup pages verification
+type BackupVerifyReport struct {
+	Status string `json:"status"`
+	// BackupChain is the verified backup followed by the backups it is a delta from
+	BackupChain  []string `json:"backup_chain"`
+	CheckedFiles int      `json:"checked_files"`
+	CheckedPages uint64   `json:"checked_pages"`
+	// CorruptFiles are the files with the pages having the invalid header or checksum
+	CorruptFiles []CorruptFileReport `json:"corrupt_files,omitempty"`
+	// MissingFiles are the files in the backup metadata which aren't found in any backup of the chain,
+	// or which have some blocks neither in the increments nor in the base version
+	MissingFiles []string        `json:"missing_files,omitempty"`
+	PgControl    PgControlReport `json:"pg_control"`
+}
+
+type CorruptFileReport struct {
+	Path string `json:"path"`
+	// InvalidHeaderBlocks are the blocks of the file with the invalid page header
+	InvalidHeaderBlocks []uint32 `json:"invalid_header_blocks,omitempty"`
+	// CorruptBlocks are the blocks of the file with the page checksum mismatch
+	CorruptBlocks []uint32 `json:"corrupt_blocks,omitempty"`
+}
+
+type PgControlReport struct {
+	Found            bool   `json:"found"`
+	ChecksumValid    bool   `json:"checksum_valid"`
+	SystemIdentifier uint64 `json:"system_identifier,omitempty"`
+	Timeline         uint32 `json:"timeline,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// HandleBackupVerify verifies the pages of the backup and writes the JSON report to the output.
+// It returns the error if the backup can't be read, the damaged backup is reported with the failure status.
+func HandleBackupVerify(backup Backup, output io.Writer) (BackupVerifyReport, error) {
+	report, err := VerifyBackup(backup)
+	if err != nil {
+		return report, err
+	}
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return report, encoder.Encode(report)
+}
+
+// VerifyBackup streams every tar of the backup and the backups it is a delta from and validates the header
+// and checksum of every page of the relation files as they are after the restore, and the pg_control file.
+// Nothing is written to disk: the backups are read from the newest to the base one, and each page is verified
+// in the newest version containing it, so only the map of the verified blocks of each file is kept in memory.
+func VerifyBackup(backup Backup) (BackupVerifyReport, error) {
+	sentinel, filesMeta, err := backup.GetSentinelAndFilesMetadata()
+	if err != nil {
+		return BackupVerifyReport{}, err
+	}
+	verifier := newBackupPagesVerifier(filesMeta.Files, sentinel.SystemIdentifier)
+
+	current := backup
+	for {
+		verifier.report.BackupChain = append(verifier.report.BackupChain, current.Name)
+		tracelog.InfoLogger.Printf("Verifying backup %s", current.Name)
+		err = verifier.verifyLayer(current)
+		if err != nil {
+			return BackupVerifyReport{}, errors.Wrapf(err, "failed to verify backup %s", current.Name)
+		}
+		if !current.SentinelDto.IsIncremental() {
+			break
+		}
+		current, err = NewBackup(current.Folder, *current.SentinelDto.IncrementFrom)
+		if err != nil {
+			return BackupVerifyReport{}, err
+		}
+		_, _, err = current.GetSentinelAndFilesMetadata()
+		if err != nil {
+			return BackupVerifyReport{}, err
+		}
+	}
+	return verifier.buildReport(), nil
+}
+
+// verifiedFile is the state of the file verification collected from the backups of the chain
+type verifiedFile struct {
+	// pageCount is the number of pages of the file after the restore
+	pageCount uint32
+	// complete means that the full version of the file is found, so the older backups aren't needed
+	complete            bool
+	verifiedBlocks      *roaring.Bitmap
+	invalidHeaderBlocks *roaring.Bitmap
+	corruptBlocks       *roaring.Bitmap
+}
+
+type backupPagesVerifier struct {
+	mutex sync.Mutex
+	// expectedFiles are the files of the verified backup, nil if the backup has no files metadata
+	expectedFiles    internal.BackupFileList
+	systemIdentifier *uint64
+	files            map[string]*verifiedFile
+	report           BackupVerifyReport
+
+	// layer is the backup currently being read
+	layer Backup
+}
+
+func newBackupPagesVerifier(expectedFiles internal.BackupFileList, systemIdentifier *uint64) *backupPagesVerifier {
+	if len(expectedFiles) == 0 {
+		expectedFiles = nil
+	}
+	return &backupPagesVerifier{
+		expectedFiles:    expectedFiles,
+		systemIdentifier: systemIdentifier,
+		files:            map[string]*verifiedFile{},
+	}
+}
+
+func (verifier *backupPagesVerifier) verifyLayer(layer Backup) error {
+	verifier.layer = layer
+	tarsToExtract, pgControlKey, err := FilesToExtractProviderImpl{}.Get(layer, nil, false)
+	if err != nil {
+		return err
+	}
+	if pgControlKey != "" {
+		tarsToExtract = append(tarsToExtract, internal.NewStorageReaderMaker(layer.getTarPartitionFolder(), pgControlKey))
+	}
+	return internal.ExtractAll(verifier, tarsToExtract)
+}
+
+// Interpret verifies the file from the backup tar
+func (verifier *backupPagesVerifier) Interpret(reader io.Reader, header *tar.Header) error {
+	if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+		return nil
+	}
+	if verifier.expectedFiles != nil && !isUtilityFile(header.Name) {
+		if _, expected := verifier.expectedFiles[header.Name]; !expected {
+			// the file is deleted by the time of the newer backup
+			return nil
+		}
+	}
+
+	verifier.mutex.Lock()
+	file, seen := verifier.files[header.Name]
+	if !seen {
+		file = &verifiedFile{
+			verifiedBlocks:      roaring.New(),
+			invalidHeaderBlocks: roaring.New(),
+			corruptBlocks:       roaring.New(),
+		}
+		verifier.files[header.Name] = file
+	}
+	verifier.mutex.Unlock()
+	if file.complete {
+		return nil
+	}
+
+	if header.Name == PgControlPath {
+		file.complete = true
+		verifier.verifyPgControl(reader)
+		return nil
+	}
+
+	bufferedReader := bufio.NewReader(reader)
+	if verifier.isIncrement(header.Name, bufferedReader) {
+		return verifier.verifyIncrement(header.Name, bufferedReader, file, !seen)
+	}
+	if !seen {
+		file.pageCount = uint32(header.Size / DatabasePageSize)
+	}
+	file.complete = true
+	if !isPagedFileName(header.Name) || header.Size%DatabasePageSize != 0 {
+		return nil
+	}
+	for blockNo := uint32(0); blockNo < uint32(header.Size/DatabasePageSize); blockNo++ {
+		err := verifier.verifyPage(header.Name, blockNo, bufferedReader, file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (verifier *backupPagesVerifier) isIncrement(name string, reader *bufio.Reader) bool {
+	if !verifier.layer.SentinelDto.IsIncremental() {
+		return false
+	}
+	if description, ok := verifier.layer.FilesMetadataDto.Files[name]; ok {
+		return description.IsIncremented
+	}
+	// no files metadata, so check the increment file signature
+	signature, err := reader.Peek(sizeofInt32)
+	return err == nil && signature[0] == 'w' && signature[1] == 'i' && signature[3] == SignatureMagicNumber
+}
+
+func (verifier *backupPagesVerifier) verifyIncrement(name string, increment io.Reader, file *verifiedFile, isNew bool) error {
+	fileSize, diffBlockCount, diffMap, err := GetIncrementHeaderFields(increment)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the increment of %s", name)
+	}
+	if isNew {
+		file.pageCount = uint32(fileSize / uint64(DatabasePageSize))
+	}
+	for i := uint32(0); i < diffBlockCount; i++ {
+		blockNo := binary.LittleEndian.Uint32(diffMap[i*sizeofInt32 : (i+1)*sizeofInt32])
+		err = verifier.verifyPage(name, blockNo, increment, file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyPage reads the page and verifies it unless its newer version is already verified
+func (verifier *backupPagesVerifier) verifyPage(name string, blockNo uint32, pages io.Reader, file *verifiedFile) error {
+	page := PgDatabasePage{}
+	_, err := io.ReadFull(pages, page[:])
+	if err != nil {
+		return errors.Wrapf(err, "failed to read block %d of %s", blockNo, name)
+	}
+	if blockNo >= file.pageCount || file.verifiedBlocks.Contains(blockNo) {
+		return nil
+	}
+	file.verifiedBlocks.Add(blockNo)
+
+	pageHeader, err := parsePostgresPageHeader(bytes.NewReader(page[:]))
+	if err != nil {
+		return err
+	}
+	if pageHeader.isNew() {
+		return nil
+	}
+	if !pageHeader.isValid() {
+		file.invalidHeaderBlocks.Add(blockNo)
+		return nil
+	}
+	corrupted, err := isPageCorrupted(name, blockNo, &page)
+	if err != nil {
+		return err
+	}
+	if corrupted {
+		file.corruptBlocks.Add(blockNo)
+	}
+	return nil
+}
+
+func (verifier *backupPagesVerifier) verifyPgControl(reader io.Reader) {
+	report := &verifier.report.PgControl
+	report.Found = true
+	content := make([]byte, pgControlSize)
+	_, err := io.ReadFull(reader, content)
+	if err != nil {
+		report.Error = errors.Wrap(err, "failed to read pg_control").Error()
+		return
+	}
+	controlData, err := extractPgControlData(bytes.NewReader(content))
+	if err != nil {
+		report.Error = err.Error()
+		return
+	}
+	report.SystemIdentifier = controlData.GetSystemIdentifier()
+	report.Timeline = controlData.GetCurrentTimeline()
+	report.ChecksumValid = isPgControlChecksumValid(content)
+	if verifier.systemIdentifier != nil && *verifier.systemIdentifier != report.SystemIdentifier {
+		report.Error = "the system identifier doesn't match the backup sentinel"
+	}
+}
+
+// isPgControlChecksumValid checks the CRC-32C of the ControlFileData stored right after it
+func isPgControlChecksumValid(content []byte) bool {
+	table := crc32.MakeTable(crc32.Castagnoli)
+	for offset := pgControlMinCrcOffset; offset <= pgControlMaxCrcOffset; offset += sizeofInt32 {
+		if crc32.Checksum(content[:offset], table) == binary.LittleEndian.Uint32(content[offset:offset+sizeofInt32]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (verifier *backupPagesVerifier) buildReport() BackupVerifyReport {
+	report := verifier.report
+	if verifier.expectedFiles != nil {
+		for name, description := range verifier.expectedFiles {
+			if _, seen := verifier.files[name]; !seen && !isUtilityFile(name) {
+				tracelog.WarningLogger.Printf("File %s (skipped: %v) is not found in the backups", name, description.IsSkipped)
+				report.MissingFiles = append(report.MissingFiles, name)
+			}
+		}
+	}
+	for name, file := range verifier.files {
+		report.CheckedFiles++
+		report.CheckedPages += file.verifiedBlocks.GetCardinality()
+		if !file.complete && uint64(file.pageCount) > file.verifiedBlocks.GetCardinality() {
+			tracelog.WarningLogger.Printf("Base version of the incremented file %s is not found", name)
+			report.MissingFiles = append(report.MissingFiles, name)
+		}
+		if file.invalidHeaderBlocks.IsEmpty() && file.corruptBlocks.IsEmpty() {
+			continue
+		}
+		report.CorruptFiles = append(report.CorruptFiles, CorruptFileReport{
+			Path:                name,
+			InvalidHeaderBlocks: file.invalidHeaderBlocks.ToArray(),
+			CorruptBlocks:       file.corruptBlocks.ToArray(),
+		})
+	}
+	sort.Strings(report.MissingFiles)
+	sort.Slice(report.CorruptFiles, func(i, j int) bool {
+		return report.CorruptFiles[i].Path < report.CorruptFiles[j].Path
+	})
+
+	report.Status = BackupVerifyStatusOk
+	pgControlOk := report.PgControl.Found && report.PgControl.ChecksumValid && report.PgControl.Error == ""
+	if len(report.CorruptFiles) > 0 || len(report.MissingFiles) > 0 || !pgControlOk {
+		report.Status = BackupVerifyStatusFailure
+	}
+	return report
+}
+
+// isPagedFileName checks the path of the file in the backup the same way as isPagedFile
+func isPagedFileName(name string) bool {
+	if _, ignored := ignoredFileNames[path.Base(name)]; ignored {
+		return false
+	}
+	return (strings.Contains(name, DefaultTablespace) || strings.Contains(name, NonDefaultTablespace)) &&
+		pagedFilenameRegexp.MatchString(path.Base(name))
+}
+
+func isUtilityFile(name string) bool {
+	_, ok := UtilityFilePaths[name]
+	return ok
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+)
+
+const verifyTestFileName = "/base/1/1234"
+
+func newVerifyTestPage(blockNo uint32, validChecksum bool) []byte {
+	page := PgDatabasePage{}
+	binary.LittleEndian.PutUint32(page[4:8], 0x1000) // pd_lsn
+	binary.LittleEndian.PutUint16(page[12:14], headerSize)
+	binary.LittleEndian.PutUint16(page[14:16], uint16(DatabasePageSize-16))
+	binary.LittleEndian.PutUint16(page[16:18], uint16(DatabasePageSize))
+	binary.LittleEndian.PutUint16(page[18:20], uint16(DatabasePageSize+layoutVersion))
+	page[100] = byte(blockNo + 1)
+	checksum := pgChecksumPage(blockNo, &page)
+	if !validChecksum {
+		checksum++
+	}
+	binary.LittleEndian.PutUint16(page[PdChecksumOffset:PdChecksumOffset+PdChecksumLen], checksum)
+	return page[:]
+}
+
+func newVerifyTestIncrement(fileSize uint64, blocks map[uint32][]byte, blockNos ...uint32) []byte {
+	increment := bytes.NewBuffer(IncrementFileHeader)
+	_ = binary.Write(increment, binary.LittleEndian, fileSize)
+	_ = binary.Write(increment, binary.LittleEndian, uint32(len(blockNos)))
+	for _, blockNo := range blockNos {
+		_ = binary.Write(increment, binary.LittleEndian, blockNo)
+	}
+	for _, blockNo := range blockNos {
+		increment.Write(blocks[blockNo])
+	}
+	return increment.Bytes()
+}
+
+func newVerifyTestPgControl(systemIdentifier uint64, validChecksum bool) []byte {
+	const crcOffset = 296
+	content := make([]byte, pgControlSize)
+	binary.LittleEndian.PutUint64(content[0:8], systemIdentifier)
+	binary.LittleEndian.PutUint32(content[8:12], 1300)
+	binary.LittleEndian.PutUint32(content[48:52], 3)
+	checksum := crc32.Checksum(content[:crcOffset], crc32.MakeTable(crc32.Castagnoli))
+	if !validChecksum {
+		checksum++
+	}
+	binary.LittleEndian.PutUint32(content[crcOffset:crcOffset+4], checksum)
+	return content
+}
+
+func interpretVerifyTestFile(t *testing.T, verifier *backupPagesVerifier, name string, content []byte) {
+	header := &tar.Header{Name: name, Typeflag: tar.TypeReg, Size: int64(len(content))}
+	require.NoError(t, verifier.Interpret(bytes.NewReader(content), header))
+}
+
+func newVerifyTestLayer(name string, incrementFrom string, files internal.BackupFileList) Backup {
+	sentinel := &BackupSentinelDto{}
+	if incrementFrom != "" {
+		lsn, count := LSN(0), 1
+		sentinel.IncrementFrom = &incrementFrom
+		sentinel.IncrementFullName = &incrementFrom
+		sentinel.IncrementFromLSN = &lsn
+		sentinel.IncrementCount = &count
+	}
+	return Backup{
+		Backup:           internal.Backup{Name: name},
+		SentinelDto:      sentinel,
+		FilesMetadataDto: &FilesMetadataDto{Files: files},
+	}
+}
+
+func TestBackupPagesVerifier_FullBackup(t *testing.T) {
+	systemIdentifier := uint64(42)
+	files := internal.BackupFileList{verifyTestFileName: {}, "/base/1/PG_VERSION": {}, "/base/1/5678": {}}
+	verifier := newBackupPagesVerifier(files, &systemIdentifier)
+	verifier.layer = newVerifyTestLayer("base_000000010000000000000002", "", files)
+
+	invalidHeaderPage := make([]byte, DatabasePageSize)
+	binary.LittleEndian.PutUint16(invalidHeaderPage[14:16], 1)
+	var relation []byte
+	relation = append(relation, newVerifyTestPage(0, true)...)
+	relation = append(relation, newVerifyTestPage(1, false)...)
+	relation = append(relation, make([]byte, DatabasePageSize)...)
+	relation = append(relation, invalidHeaderPage...)
+	interpretVerifyTestFile(t, verifier, verifyTestFileName, relation)
+	interpretVerifyTestFile(t, verifier, "/base/1/PG_VERSION", []byte("15\n"))
+	interpretVerifyTestFile(t, verifier, "/base/1/9999", newVerifyTestPage(0, false))
+	interpretVerifyTestFile(t, verifier, PgControlPath, newVerifyTestPgControl(systemIdentifier, true))
+
+	report := verifier.buildReport()
+	assert.Equal(t, BackupVerifyStatusFailure, report.Status)
+	assert.Equal(t, 3, report.CheckedFiles)
+	assert.Equal(t, uint64(4), report.CheckedPages)
+	assert.Equal(t, []CorruptFileReport{{
+		Path:                verifyTestFileName,
+		InvalidHeaderBlocks: []uint32{3},
+		CorruptBlocks:       []uint32{1},
+	}}, report.CorruptFiles)
+	assert.Equal(t, []string{"/base/1/5678"}, report.MissingFiles)
+	assert.Equal(t, PgControlReport{Found: true, ChecksumValid: true, SystemIdentifier: 42, Timeline: 3}, report.PgControl)
+}
+
+func TestBackupPagesVerifier_DeltaChain(t *testing.T) {
+	files := internal.BackupFileList{verifyTestFileName: {IsIncremented: true}, "/base/1/5678": {IsIncremented: true}}
+	verifier := newBackupPagesVerifier(files, nil)
+
+	// the delta backup has the newer version of the block corrupted in the base backup
+	verifier.layer = newVerifyTestLayer("base_000000010000000000000004_D_000000010000000000000002",
+		"base_000000010000000000000002", files)
+	blocks := map[uint32][]byte{1: newVerifyTestPage(1, true), 2: newVerifyTestPage(2, true)}
+	interpretVerifyTestFile(t, verifier, verifyTestFileName, newVerifyTestIncrement(3*uint64(DatabasePageSize), blocks, 1, 2))
+	interpretVerifyTestFile(t, verifier, "/base/1/5678", newVerifyTestIncrement(2*uint64(DatabasePageSize), blocks, 1))
+	interpretVerifyTestFile(t, verifier, PgControlPath, newVerifyTestPgControl(1, true))
+
+	// the file was truncated by the delta backup, so its last block in the base backup is ignored
+	verifier.layer = newVerifyTestLayer("base_000000010000000000000002", "", internal.BackupFileList{})
+	var relation []byte
+	relation = append(relation, newVerifyTestPage(0, true)...)
+	relation = append(relation, newVerifyTestPage(1, false)...)
+	relation = append(relation, newVerifyTestPage(2, true)...)
+	relation = append(relation, newVerifyTestPage(3, false)...)
+	interpretVerifyTestFile(t, verifier, verifyTestFileName, relation)
+	interpretVerifyTestFile(t, verifier, PgControlPath, newVerifyTestPgControl(1, false))
+
+	report := verifier.buildReport()
+	assert.Equal(t, BackupVerifyStatusFailure, report.Status)
+	assert.Equal(t, uint64(4), report.CheckedPages)
+	assert.Empty(t, report.CorruptFiles)
+	// the base version of the block 0 of the second file is missing
+	assert.Equal(t, []string{"/base/1/5678"}, report.MissingFiles)
+	assert.True(t, report.PgControl.ChecksumValid, "pg_control is taken from the newest backup")
+}
+
+func TestBackupPagesVerifier_PgControl(t *testing.T) {
+	systemIdentifier := uint64(42)
+	verifier := newBackupPagesVerifier(nil, &systemIdentifier)
+	verifier.layer = newVerifyTestLayer("base_000000010000000000000002", "", nil)
+	interpretVerifyTestFile(t, verifier, verifyTestFileName, newVerifyTestPage(0, true))
+	interpretVerifyTestFile(t, verifier, PgControlPath, newVerifyTestPgControl(43, true))
+
+	report := verifier.buildReport()
+	assert.Equal(t, BackupVerifyStatusFailure, report.Status)
+	assert.True(t, report.PgControl.ChecksumValid)
+	assert.NotEmpty(t, report.PgControl.Error)
+
+	verifier = newBackupPagesVerifier(nil, &systemIdentifier)
+	verifier.layer = newVerifyTestLayer("base_000000010000000000000002", "", nil)
+	interpretVerifyTestFile(t, verifier, verifyTestFileName, newVerifyTestPage(0, true))
+	assert.Equal(t, BackupVerifyStatusFailure, verifier.buildReport().Status, "pg_control is missing")
+	interpretVerifyTestFile(t, verifier, PgControlPath, newVerifyTestPgControl(42, true))
+	assert.Equal(t, BackupVerifyStatusOk, verifier.buildReport().Status)
+}
@@ -410,6 +410,16 @@ func (bundle *Bundle) DownloadDeltaMap(reader internal.StorageFolderReader, back
 	return nil
 }
 
+// LoadWalSummariesDeltaMap builds the delta map from the WAL summaries written by PostgreSQL 17+
+func (bundle *Bundle) LoadWalSummariesDeltaMap(backupStartLSN LSN) error {
+	deltaMap, err := getDeltaMapFromWalSummaries(bundle.Directory, bundle.Timeline, *bundle.IncrementFromLsn, backupStartLSN)
+	if err != nil {
+		return err
+	}
+	bundle.DeltaMap = deltaMap
+	return nil
+}
+
 func (bundle *Bundle) FinishTarComposer() (internal.TarFileSets, error) {
 	return bundle.TarBallComposer.FinishComposing()
 }
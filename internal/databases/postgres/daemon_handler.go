@@ -1,27 +1,52 @@
 package postgres
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"os"
+	"os/signal"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/daemon"
+	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/multistorage/policies"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
 const (
 	SdNotifyWatchdog = "WATCHDOG=1"
+
+	DaemonBackupPushFullOption      = "full"
+	DaemonBackupPushPermanentOption = "permanent"
+	DaemonBackupPushVerifyOption    = "verify"
+)
+
+var (
+	// daemonProgressInterval is the interval between the progress messages of the long operations
+	daemonProgressInterval = 10 * time.Second
+	// configureDaemonStorage configures the storage for every message, replaced in tests
+	configureDaemonStorage = func() (storage.Storage, error) {
+		multiSt, err := ConfigureMultiStorage(true)
+		if err != nil {
+			return nil, err
+		}
+		return multiSt, nil
+	}
+	// daemonPushBackup runs the backups of the daemon, replaced in tests
+	daemonPushBackup daemonBackupPusher = pushDaemonBackup
 )
 
 type SocketWriteFailedError struct {
@@ -82,6 +107,7 @@ func (h *ArchiveMessageHandler) Handle(ctx context.Context, messageBody []byte)
 	if err != nil {
 		return fmt.Errorf("file archiving failed: %w", err)
 	}
+	state.setPushedWal(walFileName)
 	_, err = h.fd.Write(daemon.OkType.ToBytes())
 	if err != nil {
 		return newSocketWriteFailedError(err)
@@ -130,6 +156,302 @@ func (h *WalFetchMessageHandler) Handle(_ context.Context, messageBody []byte) e
 	return nil
 }
 
+// DaemonStatus is the daemon state reported to the status command
+type DaemonStatus struct {
+	StartTime         time.Time  `json:"start_time"`
+	LastPushedWal     string     `json:"last_pushed_wal,omitempty"`
+	LastPushedWalTime *time.Time `json:"last_pushed_wal_time,omitempty"`
+	// ArchiveQueueDepth is the number of WAL segments waiting for archiving in the archive_status directory
+	ArchiveQueueDepth *int       `json:"archive_queue_depth,omitempty"`
+	BackupInProgress  bool       `json:"backup_in_progress"`
+	LastBackup        string     `json:"last_backup,omitempty"`
+	LastBackupTime    *time.Time `json:"last_backup_time,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	LastErrorTime     *time.Time `json:"last_error_time,omitempty"`
+}
+
+// daemonState is shared between the connections of the daemon
+type daemonState struct {
+	mutex  sync.Mutex
+	status DaemonStatus
+}
+
+var state = &daemonState{status: DaemonStatus{StartTime: utility.TimeNowCrossPlatformUTC()}}
+
+func (s *daemonState) setPushedWal(walFileName string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := utility.TimeNowCrossPlatformUTC()
+	s.status.LastPushedWal = walFileName
+	s.status.LastPushedWalTime = &now
+}
+
+func (s *daemonState) setError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := utility.TimeNowCrossPlatformUTC()
+	s.status.LastError = err.Error()
+	s.status.LastErrorTime = &now
+}
+
+// startBackup returns false if the backup is already running
+func (s *daemonState) startBackup() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.status.BackupInProgress {
+		return false
+	}
+	s.status.BackupInProgress = true
+	return true
+}
+
+func (s *daemonState) finishBackup(backupName string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status.BackupInProgress = false
+	if backupName != "" {
+		now := utility.TimeNowCrossPlatformUTC()
+		s.status.LastBackup = backupName
+		s.status.LastBackupTime = &now
+	}
+}
+
+func (s *daemonState) getStatus() DaemonStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
+
+func writeFramedMessage(fd net.Conn, messageType daemon.SocketMessageType, body []byte) error {
+	message, err := daemon.NewMessage(messageType, body)
+	if err != nil {
+		return err
+	}
+	_, err = fd.Write(message)
+	if err != nil {
+		return newSocketWriteFailedError(err)
+	}
+	return nil
+}
+
+type StatusMessageHandler struct {
+	fd net.Conn
+}
+
+func (h *StatusMessageHandler) Handle(_ context.Context, _ []byte) error {
+	status := state.getStatus()
+	queueDepth, err := getArchiveQueueDepth()
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to get the archive queue depth: %v", err)
+	} else {
+		status.ArchiveQueueDepth = &queueDepth
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return writeFramedMessage(h.fd, daemon.OkType, body)
+}
+
+// getArchiveQueueDepth counts the WAL segments which Postgres has marked as ready for archiving
+func getArchiveQueueDepth() (int, error) {
+	archiveStatusPath, err := getFullPath(filepath.Join("pg_wal", "archive_status"))
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(archiveStatusPath)
+	if err != nil {
+		return 0, err
+	}
+	queueDepth := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".ready") {
+			queueDepth++
+		}
+	}
+	return queueDepth, nil
+}
+
+type WalPrefetchMessageHandler struct {
+	fd     net.Conn
+	reader internal.StorageFolderReader
+}
+
+func (h *WalPrefetchMessageHandler) Handle(_ context.Context, messageBody []byte) error {
+	args, err := daemon.BytesToArgs(messageBody)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("wal-prefetch incorrect arguments count")
+	}
+	location, err := getFullPath(args[1])
+	if err != nil {
+		return err
+	}
+	tracelog.DebugLogger.Printf("starting wal-prefetch after %v to %v\n", args[0], location)
+
+	err = HandleWALPrefetch(h.reader, args[0], location)
+	if err != nil {
+		return fmt.Errorf("WAL prefetch failed: %w", err)
+	}
+	return writeFramedMessage(h.fd, daemon.OkType, nil)
+}
+
+// daemonBackupPusher runs the backup and returns its name
+type daemonBackupPusher func(ctx context.Context, uploader internal.Uploader, options []string) (string, error)
+
+type BackupPushMessageHandler struct {
+	fd         net.Conn
+	storage    storage.Storage
+	pushBackup daemonBackupPusher
+}
+
+func (h *BackupPushMessageHandler) Handle(ctx context.Context, messageBody []byte) error {
+	options, err := daemon.BytesToArgs(messageBody)
+	if err != nil {
+		return err
+	}
+	if !state.startBackup() {
+		return fmt.Errorf("backup-push is already running")
+	}
+	// the state is updated before the response, so the status requested right after it is up to date
+	backupName, err := h.runBackup(ctx, options)
+	state.finishBackup(backupName)
+	if err != nil {
+		return err
+	}
+	return writeFramedMessage(h.fd, daemon.OkType, []byte(backupName))
+}
+
+func (h *BackupPushMessageHandler) runBackup(ctx context.Context, options []string) (string, error) {
+	uploader, err := h.configureUploader()
+	if err != nil {
+		return "", err
+	}
+
+	err = writeFramedMessage(h.fd, daemon.ProgressType, []byte("backup-push started"))
+	if err != nil {
+		return "", err
+	}
+	stopProgress := h.reportProgress(uploader)
+	backupName, err := h.pushBackup(ctx, uploader, options)
+	stopProgress()
+	if err != nil {
+		return "", fmt.Errorf("backup-push failed: %w", err)
+	}
+	return backupName, nil
+}
+
+// pushDaemonBackup runs the backup within the daemon, so the failures are returned instead of exiting
+func pushDaemonBackup(ctx context.Context, uploader internal.Uploader, options []string) (string, error) {
+	arguments, err := newDaemonBackupArguments(uploader, options)
+	if err != nil {
+		return "", err
+	}
+	arguments.EnableDaemonMode()
+	backupHandler, err := NewBackupHandler(arguments)
+	if err != nil {
+		return "", err
+	}
+	err = backupHandler.PushBackup(ctx)
+	if err != nil {
+		return "", err
+	}
+	return backupHandler.CurBackupInfo.Name, nil
+}
+
+func (h *BackupPushMessageHandler) configureUploader() (internal.Uploader, error) {
+	rootFolder, err := multistorage.UseFirstAliveStorage(
+		multistorage.SetPolicies(h.storage.RootFolder(), policies.TakeFirstStorage))
+	if err != nil {
+		return nil, err
+	}
+	tracelog.InfoLogger.Printf("Backup will be pushed to storage: %v", multistorage.UsedStorages(rootFolder)[0])
+	return internal.ConfigureUploaderToFolder(rootFolder)
+}
+
+// reportProgress sends the uploaded data size periodically until the returned stop function is called
+func (h *BackupPushMessageHandler) reportProgress(uploader internal.Uploader) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(daemonProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				rawSize, _ := uploader.RawDataSize()
+				uploadedSize, _ := uploader.UploadedDataSize()
+				progress := fmt.Sprintf("backup-push in progress: %d bytes read, %d bytes uploaded", rawSize, uploadedSize)
+				if err := writeFramedMessage(h.fd, daemon.ProgressType, []byte(progress)); err != nil {
+					tracelog.WarningLogger.Printf("Failed to send the backup-push progress: %v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// newDaemonBackupArguments builds the backup-push arguments from the daemon configuration and the message options
+func newDaemonBackupArguments(uploader internal.Uploader, options []string) (BackupArguments, error) {
+	isFullBackup := false
+	isPermanent := false
+	verifyPageChecksums := viper.GetBool(conf.VerifyPageChecksumsSetting)
+	for _, option := range options {
+		switch option {
+		case DaemonBackupPushFullOption:
+			isFullBackup = true
+		case DaemonBackupPushPermanentOption:
+			isPermanent = true
+		case DaemonBackupPushVerifyOption:
+			verifyPageChecksums = true
+		default:
+			return BackupArguments{}, fmt.Errorf("unknown backup-push option: %s", option)
+		}
+	}
+
+	dataDirectory, ok := conf.GetSetting(conf.PgDataSetting)
+	if !ok {
+		return BackupArguments{}, fmt.Errorf("PGDATA is not set in the conf")
+	}
+
+	composerType := RegularComposer
+	switch {
+	case viper.GetBool(conf.UseCopyComposerSetting):
+		composerType = CopyComposer
+		isFullBackup = true
+	case viper.GetBool(conf.UseDatabaseComposerSetting):
+		composerType = DatabaseComposer
+	case viper.GetBool(conf.UseRatingComposerSetting):
+		composerType = RatingComposer
+	}
+	withoutFilesMetadata := viper.GetBool(conf.WithoutFilesMetadataSetting)
+	if withoutFilesMetadata {
+		isFullBackup = true
+	}
+
+	deltaBaseSelector, err := internal.NewDeltaBaseSelector(viper.GetString(conf.DeltaFromNameSetting),
+		viper.GetString(conf.DeltaFromUserDataSetting), NewGenericMetaFetcher())
+	if err != nil {
+		return BackupArguments{}, err
+	}
+	userData, err := internal.UnmarshalSentinelUserData(viper.GetString(conf.SentinelUserDataSetting))
+	if err != nil {
+		return BackupArguments{}, err
+	}
+
+	return NewBackupArguments(uploader, dataDirectory, utility.BaseBackupPath, isPermanent, verifyPageChecksums,
+		isFullBackup, viper.GetBool(conf.StoreAllCorruptBlocksSetting), composerType,
+		NewRegularDeltaBackupConfigurator(deltaBaseSelector), userData, withoutFilesMetadata), nil
+}
+
 func NewMessageHandler(
 	messageType daemon.SocketMessageType,
 	c net.Conn,
@@ -151,6 +473,16 @@ func NewMessageHandler(
 		}
 
 		return &WalFetchMessageHandler{c, folderReader}, nil
+	case daemon.WalPrefetchType:
+		folderReader, err := internal.PrepareMultiStorageFolderReader(storage.RootFolder(), "")
+		if err != nil {
+			return nil, err
+		}
+		return &WalPrefetchMessageHandler{c, folderReader}, nil
+	case daemon.BackupPushType:
+		return &BackupPushMessageHandler{c, storage, daemonPushBackup}, nil
+	case daemon.StatusType:
+		return &StatusMessageHandler{c}, nil
 	default:
 		return nil, nil
 	}
@@ -166,27 +498,11 @@ func NewMessageReader(c net.Conn) *SocketMessageReader {
 
 // Next method reads messages sequentially from the Reader
 func (r SocketMessageReader) Next() (messageType daemon.SocketMessageType, messageBody []byte, err error) {
-	messageParameters := make([]byte, 3)
-	_, err = io.ReadFull(r.c, messageParameters)
-	if err != nil {
-		return daemon.ErrorType, nil, fmt.Errorf("failed to read params: %w", err)
-	}
-	messageType = daemon.SocketMessageType(messageParameters[0])
-	var messageLength uint16
-	l := bytes.NewReader(messageParameters[1:3])
-	err = binary.Read(l, binary.BigEndian, &messageLength)
-	if err != nil {
-		return daemon.ErrorType, nil, fmt.Errorf("fail to read message len: %w", err)
-	}
-	messageBody = make([]byte, messageLength-3)
-	_, err = io.ReadFull(r.c, messageBody)
-	if err != nil {
-		return daemon.ErrorType, nil, fmt.Errorf("failed to read msg body: %w", err)
-	}
-	return messageType, messageBody, err
+	return daemon.ReadMessage(r.c)
 }
 
-// HandleDaemon is invoked to perform daemon mode
+// HandleDaemon is invoked to perform daemon mode. On the interruption signal it stops accepting the connections,
+// cancels the running operations, so the running backup is gracefully terminated, and waits for them to finish.
 func HandleDaemon(options DaemonOptions) {
 	if _, err := os.Stat(options.SocketPath); err == nil {
 		err = os.Remove(options.SocketPath)
@@ -203,13 +519,39 @@ func HandleDaemon(options DaemonOptions) {
 	defer sdNotifyTicker.Stop()
 	go SendSdNotify(sdNotifyTicker.C)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			tracelog.InfoLogger.Printf("Received %s, stopping the daemon...", sig)
+			cancel()
+			// the listener removes the socket file on close
+			utility.LoggedClose(l, "Failed to close the daemon socket")
+		case <-ctx.Done():
+		}
+	}()
+
+	var connections sync.WaitGroup
 	for {
 		fd, err := l.Accept()
 		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
 			tracelog.ErrorLogger.Fatal("Failed to accept, err:", err)
 		}
-		go Listen(context.Background(), fd)
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			Listen(ctx, fd)
+		}()
 	}
+	connections.Wait()
+	tracelog.InfoLogger.Println("The daemon is stopped")
 }
 
 // Listen is used for listening connection and processing messages
@@ -219,21 +561,24 @@ func Listen(ctx context.Context, c net.Conn) {
 	for {
 		messageType, messageBody, err := messageReader.Next()
 		if err != nil {
-			failAndLogError(c, fmt.Errorf("read message from %s, err: %v", c.RemoteAddr(), err))
+			failAndLogError(c, daemon.ErrorType, fmt.Errorf("read message from %s, err: %v", c.RemoteAddr(), err))
 			return
 		}
 		err = handleMessage(ctx, messageType, messageBody, c)
 		if err != nil {
-			failAndLogError(c, err)
+			state.setError(err)
+			failAndLogError(c, messageType, err)
 			return
 		}
-		if messageType == daemon.WalPushType {
+		switch messageType {
+		case daemon.WalPushType:
 			tracelog.DebugLogger.Printf("successfully archived: %s\n", string(messageBody))
 			return
-		}
-		if messageType == daemon.WalFetchType {
+		case daemon.WalFetchType:
 			tracelog.DebugLogger.Printf("successfully fetched: %s\n", string(messageBody))
 			return
+		case daemon.WalPrefetchType, daemon.BackupPushType, daemon.StatusType:
+			return
 		}
 	}
 }
@@ -244,11 +589,11 @@ func handleMessage(
 	messageBody []byte,
 	conn net.Conn,
 ) error {
-	multiSt, err := ConfigureMultiStorage(true)
-	defer utility.LoggedClose(multiSt, "close multi-storage")
+	multiSt, err := configureDaemonStorage()
 	if err != nil {
 		return fmt.Errorf("configure multi-storage: %w", err)
 	}
+	defer utility.LoggedClose(multiSt, "close multi-storage")
 	messageHandler, err := NewMessageHandler(messageType, conn, multiSt)
 	if err != nil {
 		return fmt.Errorf("init handler for message type %s: %v", string(messageType), err)
@@ -263,9 +608,13 @@ func handleMessage(
 	return nil
 }
 
-func failAndLogError(c net.Conn, err error) {
+func failAndLogError(c net.Conn, messageType daemon.SocketMessageType, err error) {
 	tracelog.ErrorLogger.Printf("Message loop failure: %v", err)
-	_, err = c.Write(daemon.ErrorType.ToBytes())
+	if messageType.HasFramedResponse() {
+		err = writeFramedMessage(c, daemon.ErrorType, []byte(err.Error()))
+	} else {
+		_, err = c.Write(daemon.ErrorType.ToBytes())
+	}
 	if err != nil {
 		tracelog.ErrorLogger.Printf("Sending error response failed: %v", err)
 	}
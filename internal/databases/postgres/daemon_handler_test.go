@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/daemon"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// startTestDaemon serves the daemon socket with the memory storage and the given backup function
+func startTestDaemon(t *testing.T, pushBackup daemonBackupPusher) string {
+	pgData := t.TempDir()
+	archiveStatus := filepath.Join(pgData, "pg_wal", "archive_status")
+	require.NoError(t, os.MkdirAll(archiveStatus, 0700))
+	for _, name := range []string{"000000010000000000000003.ready", "000000010000000000000004.ready",
+		"000000010000000000000002.done"} {
+		require.NoError(t, os.WriteFile(filepath.Join(archiveStatus, name), nil, 0600))
+	}
+	viper.Set(conf.PgDataSetting, pgData)
+	viper.Set(conf.CompressionMethodSetting, "lz4")
+
+	oldConfigureStorage, oldPushBackup, oldState := configureDaemonStorage, daemonPushBackup, state
+	configureDaemonStorage = func() (storage.Storage, error) {
+		return memory.NewStorage("", memory.NewKVS()), nil
+	}
+	daemonPushBackup = pushBackup
+	state = &daemonState{status: DaemonStatus{StartTime: utility.TimeNowCrossPlatformUTC()}}
+
+	socketPath := filepath.Join(t.TempDir(), "walg.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	connections := &sync.WaitGroup{}
+	connections.Add(1)
+	go func() {
+		defer connections.Done()
+		for {
+			fd, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			go func() {
+				defer connections.Done()
+				Listen(context.Background(), fd)
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		connections.Wait()
+		configureDaemonStorage, daemonPushBackup, state = oldConfigureStorage, oldPushBackup, oldState
+		viper.Set(conf.PgDataSetting, nil)
+		viper.Set(conf.CompressionMethodSetting, nil)
+	})
+	return socketPath
+}
+
+func sendTestDaemonCommand(socketPath string, messageType daemon.SocketMessageType,
+	args ...string) (string, []byte, error) {
+	progress := &bytes.Buffer{}
+	result, err := daemon.SendStreamingCommand(&daemon.RunOptions{
+		MessageType:                   messageType,
+		SocketName:                    socketPath,
+		MessageArgs:                   args,
+		DaemonOperationTimeout:        10 * time.Second,
+		DaemonSocketConnectionTimeout: 10 * time.Second,
+	}, progress)
+	return progress.String(), result, err
+}
+
+func getTestDaemonStatus(t *testing.T, socketPath string) DaemonStatus {
+	_, result, err := sendTestDaemonCommand(socketPath, daemon.StatusType)
+	require.NoError(t, err)
+	var status DaemonStatus
+	require.NoError(t, json.Unmarshal(result, &status))
+	return status
+}
+
+func TestDaemonBackupPush(t *testing.T) {
+	var pushedOptions []string
+	socketPath := startTestDaemon(t, func(_ context.Context, uploader internal.Uploader, options []string) (string, error) {
+		require.NotNil(t, uploader)
+		pushedOptions = options
+		assert.True(t, state.getStatus().BackupInProgress)
+		return "base_000000010000000000000002", nil
+	})
+
+	progress, result, err := sendTestDaemonCommand(socketPath, daemon.BackupPushType,
+		DaemonBackupPushFullOption, DaemonBackupPushPermanentOption)
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", string(result))
+	assert.Equal(t, "backup-push started\n", progress)
+	assert.Equal(t, []string{DaemonBackupPushFullOption, DaemonBackupPushPermanentOption}, pushedOptions)
+
+	status := getTestDaemonStatus(t, socketPath)
+	assert.False(t, status.BackupInProgress)
+	assert.Equal(t, "base_000000010000000000000002", status.LastBackup)
+	assert.NotNil(t, status.LastBackupTime)
+	assert.Empty(t, status.LastError)
+}
+
+func TestDaemonBackupPush_Failure(t *testing.T) {
+	socketPath := startTestDaemon(t, func(context.Context, internal.Uploader, []string) (string, error) {
+		return "", errors.New("pre-hook command failed")
+	})
+
+	_, _, err := sendTestDaemonCommand(socketPath, daemon.BackupPushType)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-hook command failed")
+
+	// the daemon keeps serving and reports the failure
+	status := getTestDaemonStatus(t, socketPath)
+	assert.False(t, status.BackupInProgress)
+	assert.Empty(t, status.LastBackup)
+	assert.Contains(t, status.LastError, "pre-hook command failed")
+	assert.NotNil(t, status.LastErrorTime)
+
+	_, _, err = sendTestDaemonCommand(socketPath, daemon.BackupPushType, "incremental")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-hook command failed")
+}
+
+func TestDaemonBackupPush_AlreadyRunning(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	socketPath := startTestDaemon(t, func(context.Context, internal.Uploader, []string) (string, error) {
+		close(started)
+		<-release
+		return "base_000000010000000000000002", nil
+	})
+
+	firstResult := make(chan error, 1)
+	go func() {
+		_, _, err := sendTestDaemonCommand(socketPath, daemon.BackupPushType)
+		firstResult <- err
+	}()
+	<-started
+
+	_, _, err := sendTestDaemonCommand(socketPath, daemon.BackupPushType)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already running")
+	assert.True(t, getTestDaemonStatus(t, socketPath).BackupInProgress)
+
+	close(release)
+	assert.NoError(t, <-firstResult)
+}
+
+func TestDaemonStatus(t *testing.T) {
+	socketPath := startTestDaemon(t, nil)
+	state.setPushedWal("000000010000000000000002")
+
+	status := getTestDaemonStatus(t, socketPath)
+	assert.Equal(t, "000000010000000000000002", status.LastPushedWal)
+	assert.NotNil(t, status.LastPushedWalTime)
+	require.NotNil(t, status.ArchiveQueueDepth)
+	assert.Equal(t, 2, *status.ArchiveQueueDepth)
+	assert.False(t, status.BackupInProgress)
+	assert.False(t, status.StartTime.IsZero())
+}
@@ -243,7 +243,9 @@ func (dh *DeleteHandler) HandleDeleteGarbage(args []string, confirm bool) error
 	}
 
 	folderFilter := func(string) bool { return true }
-	return dh.DeleteBeforeTargetWhere(target, confirm, predicate, folderFilter)
+	return internal.RunNotifiedDelete("garbage", confirm, func() error {
+		return dh.DeleteBeforeTargetWhere(target, confirm, predicate, folderFilter)
+	})
 }
 
 // ExtractDeleteGarbagePredicate extracts delete modifier the "delete garbage" command
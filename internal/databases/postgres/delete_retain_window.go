@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// HandleDeleteRetainWindow deletes the backups and WAL segments which are not needed
+// to restore to any point of the retention window. Nothing is deleted unless the WAL
+// from the oldest needed backup up to the latest WAL segment in storage is continuous.
+func (dh *DeleteHandler) HandleDeleteRetainWindow(args []string, confirmed bool) error {
+	window, err := internal.ParseRetainWindow(args[0])
+	if err != nil {
+		return err
+	}
+	windowStart := utility.TimeNowCrossPlatformUTC().Add(-window)
+
+	target, err := dh.FindTargetRetainWindow(windowStart, NewGenericMetaFetcher())
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		tracelog.InfoLogger.Printf("No backup made before %s, nothing to delete", windowStart.Format(time.RFC3339))
+		return nil
+	}
+	tracelog.InfoLogger.Printf("Backup %s is needed to restore to any point after %s",
+		target.GetBackupName(), windowStart.Format(time.RFC3339))
+
+	err = VerifyWalSinceBackup(dh.Folder, target.GetBackupName())
+	if err != nil {
+		return errors.Wrap(err, "the retention window is not covered, nothing is deleted")
+	}
+	return internal.RunNotifiedDelete("retain-window", confirmed, func() error {
+		return dh.DeleteBeforeTarget(target, confirmed)
+	})
+}
+
+// VerifyWalSinceBackup checks that the backup belongs to the history of the highest timeline in storage
+// and that there are no lost WAL segments from the backup start up to the latest WAL segment in storage
+func VerifyWalSinceBackup(rootFolder storage.Folder, backupName string) error {
+	walFolder := rootFolder.GetSubFolder(utility.WalPath)
+	walFolderFilenames, err := getFolderFilenames(walFolder)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch WAL folder filenames")
+	}
+
+	highestTimeline := tryFindHighestTimelineID(walFolderFilenames)
+	lastSegmentNo, ok := findLastSegmentNo(getSegmentsFromFiles(walFolderFilenames), highestTimeline)
+	if !ok {
+		return errors.Errorf("no WAL segments of the timeline %d found in storage", highestTimeline)
+	}
+
+	timelineSwitchMap, err := createTimelineSwitchMap(highestTimeline, walFolder)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize timeline history map")
+	}
+
+	backupTimeline, backupStartSegNo, err := ParseWALFilename(utility.StripWalFileName(backupName))
+	if err != nil {
+		return err
+	}
+	if !checkBackupTimeline(highestTimeline, backupName, backupTimeline, WalSegmentNo(backupStartSegNo),
+		getSwitchSegNoByTimeline(timelineSwitchMap)) {
+		return errors.Errorf("backup %s does not belong to the history of the timeline %d", backupName, highestTimeline)
+	}
+
+	uploadingSegmentRangeSize, err := conf.GetMaxUploadConcurrency()
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve MaxUploadConcurrency")
+	}
+	check := IntegrityCheckRunner{
+		// start right after the latest segment in storage, so the latest segment is checked as well
+		startWalSegment:           WalSegmentDescription{Timeline: highestTimeline, Number: lastSegmentNo.Next()},
+		stopWalSegmentNo:          WalSegmentNo(backupStartSegNo),
+		uploadingSegmentRangeSize: uploadingSegmentRangeSize,
+		delayedSegmentRangeSize:   viper.GetInt(conf.MaxDelayedSegmentsCount),
+		walFolderFilenames:        walFolderFilenames,
+		timelineSwitchMap:         timelineSwitchMap,
+	}
+	result, err := check.Run()
+	if err != nil {
+		return err
+	}
+	if result.Status == StatusFailure {
+		if reader, err := result.Details.NewPlainTextReader(); err == nil {
+			details, _ := io.ReadAll(reader)
+			tracelog.ErrorLogger.Printf("WAL integrity check since backup %s:\n%s", backupName, details)
+		}
+		return errors.Errorf("there are lost WAL segments since backup %s", backupName)
+	}
+	return nil
+}
+
+func findLastSegmentNo(segments map[WalSegmentDescription]bool, timeline uint32) (WalSegmentNo, bool) {
+	var lastSegmentNo WalSegmentNo
+	found := false
+	for segment := range segments {
+		if segment.Timeline == timeline && (!found || segment.Number > lastSegmentNo) {
+			lastSegmentNo = segment.Number
+			found = true
+		}
+	}
+	return lastSegmentNo, found
+}
@@ -0,0 +1,84 @@
+package postgres_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/testtools"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// setupRetainWindowTestFolder creates testtools.MakeRetainWindowTestFolder with the backups and the WAL segments
+// of the timeline 1
+func setupRetainWindowTestFolder(t *testing.T, skipSegments map[int]bool) storage.Folder {
+	return testtools.MakeRetainWindowTestFolder(t, skipSegments,
+		func(folder storage.Folder, segmentNo int, startTime, finishTime time.Time) error {
+			backupName := utility.BackupNamePrefix + postgres.WalSegmentNo(segmentNo).GetFilename(1)
+			metadata, err := json.Marshal(postgres.ExtendedMetadataDto{StartTime: startTime, FinishTime: finishTime})
+			if err != nil {
+				return err
+			}
+			err = folder.PutObject(utility.BaseBackupPath+backupName+"/"+utility.MetadataFileName, bytes.NewReader(metadata))
+			if err != nil {
+				return err
+			}
+			return folder.PutObject(utility.BaseBackupPath+backupName+utility.SentinelSuffix, strings.NewReader("{}"))
+		},
+		func(folder storage.Folder, segmentNo int) error {
+			name := postgres.WalSegmentNo(segmentNo).GetFilename(1) + ".lz4"
+			return folder.PutObject(utility.WalPath+name, new(bytes.Buffer))
+		})
+}
+
+func TestHandleDeleteRetainWindow(t *testing.T) {
+	folder := setupRetainWindowTestFolder(t, nil)
+	deleteHandler, err := postgres.NewDeleteHandler(folder, nil, nil, false)
+	require.NoError(t, err)
+
+	require.NoError(t, deleteHandler.HandleDeleteRetainWindow([]string{"14d"}, true))
+
+	verifyThatExistBackupsAndWals(t, map[string]bool{
+		"base_000000010000000000000001": false,
+		"base_000000010000000000000006": true,
+		"base_00000001000000000000000D": true,
+	}, map[string]bool{
+		"000000010000000000000005": false,
+		"000000010000000000000006": true,
+		"00000001000000000000000F": true,
+	}, folder)
+}
+
+func TestHandleDeleteRetainWindow_LostSegment(t *testing.T) {
+	folder := setupRetainWindowTestFolder(t, map[int]bool{8: true})
+	deleteHandler, err := postgres.NewDeleteHandler(folder, nil, nil, false)
+	require.NoError(t, err)
+
+	assert.Error(t, deleteHandler.HandleDeleteRetainWindow([]string{"14d"}, true))
+
+	verifyThatExistBackupsAndWals(t, map[string]bool{
+		"base_000000010000000000000001": true,
+	}, map[string]bool{
+		"000000010000000000000001": true,
+	}, folder)
+}
+
+func TestHandleDeleteRetainWindow_NoBackupBeforeWindow(t *testing.T) {
+	folder := setupRetainWindowTestFolder(t, nil)
+	deleteHandler, err := postgres.NewDeleteHandler(folder, nil, nil, false)
+	require.NoError(t, err)
+
+	require.NoError(t, deleteHandler.HandleDeleteRetainWindow([]string{"60d"}, true))
+
+	verifyThatExistBackupsAndWals(t, map[string]bool{
+		"base_000000010000000000000001": true,
+	}, map[string]bool{
+		"000000010000000000000001": true,
+	}, folder)
+}
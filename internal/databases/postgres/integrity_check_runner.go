@@ -243,13 +243,8 @@ func getEarliestBackupStartSegmentNo(timelineSwitchMap map[WalSegmentNo]*Timelin
 		return 0, err
 	}
 
-	// switchLsnBySegNo is used for fast lookup of the timeline switch segment
-	switchLsnBySegNo := make(map[uint32]WalSegmentNo, len(timelineSwitchMap))
-	for _, historyRecord := range timelineSwitchMap {
-		switchLsnBySegNo[historyRecord.timeline] = NewWalSegmentNo(historyRecord.lsn)
-	}
 	earliestBackup, earliestBackupSegNo, err :=
-		findEarliestBackup(currentTimeline, backupDetails, switchLsnBySegNo)
+		findEarliestBackup(currentTimeline, backupDetails, getSwitchSegNoByTimeline(timelineSwitchMap))
 	if err != nil {
 		return 0, err
 	}
@@ -259,6 +254,15 @@ func getEarliestBackupStartSegmentNo(timelineSwitchMap map[WalSegmentNo]*Timelin
 	return earliestBackupSegNo, nil
 }
 
+// getSwitchSegNoByTimeline is used for fast lookup of the timeline switch segment
+func getSwitchSegNoByTimeline(timelineSwitchMap map[WalSegmentNo]*TimelineHistoryRecord) map[uint32]WalSegmentNo {
+	switchSegNoByTimeline := make(map[uint32]WalSegmentNo, len(timelineSwitchMap))
+	for _, historyRecord := range timelineSwitchMap {
+		switchSegNoByTimeline[historyRecord.timeline] = NewWalSegmentNo(historyRecord.lsn)
+	}
+	return switchSegNoByTimeline
+}
+
 // findEarliestBackup finds earliest correct backup available in storage.
 func findEarliestBackup(
 	currentTimeline uint32,
@@ -312,7 +316,18 @@ func checkBackupIsCorrect(
 				"as the earliest backup for wal-verify.\n", backupDetail.BackupName)
 		return false
 	}
+	return checkBackupTimeline(currentTimeline, backupDetail.BackupName, backupTimeline, backupStartSegNo, switchSegNoByTimeline)
+}
 
+// checkBackupTimeline checks that the backup start LSN belongs to
+// the range [backup timeline start LSN, backup timeline end LSN] of the current timeline history
+func checkBackupTimeline(
+	currentTimeline uint32,
+	backupName string,
+	backupTimeline uint32,
+	backupStartSegNo WalSegmentNo,
+	switchSegNoByTimeline map[uint32]WalSegmentNo,
+) bool {
 	// perform the check only if .history file exists
 	if len(switchSegNoByTimeline) > 0 {
 		// if backup start segment is less than timeline start segment => incorrect backup
@@ -320,9 +335,9 @@ func checkBackupIsCorrect(
 			backupTimelineStartSegNo, ok := switchSegNoByTimeline[backupTimeline-1]
 			if ok && backupStartSegNo < backupTimelineStartSegNo {
 				tracelog.WarningLogger.Printf(
-					"checkBackupIsCorrect: %s: backup start segment number %d "+
+					"checkBackupTimeline: %s: backup start segment number %d "+
 						"is less than the backup timeline start segment number %d.\n",
-					backupDetail.BackupName, backupStartSegNo, backupTimelineStartSegNo)
+					backupName, backupStartSegNo, backupTimelineStartSegNo)
 				return false
 			}
 		}
@@ -336,18 +351,18 @@ func checkBackupIsCorrect(
 		timelineSwitchSegNo, ok := switchSegNoByTimeline[backupTimeline]
 		if !ok {
 			tracelog.WarningLogger.Printf(
-				"checkBackupIsCorrect: %s: backup timeline %d "+
+				"checkBackupTimeline: %s: backup timeline %d "+
 					"is not present in .history file and is not current.\n",
-				backupDetail.BackupName, backupTimeline)
+				backupName, backupTimeline)
 			return false
 		}
 
 		// if backup start segment is higher than switch segment of the previous timeline => incorrect backup
 		if backupStartSegNo >= timelineSwitchSegNo {
 			tracelog.WarningLogger.Printf(
-				"checkBackupIsCorrect: %s: backup start segment number %d "+
+				"checkBackupTimeline: %s: backup start segment number %d "+
 					"should be less than the backup timeline end segment number %d.\n",
-				backupDetail.BackupName, backupStartSegNo, timelineSwitchSegNo)
+				backupName, backupStartSegNo, timelineSwitchSegNo)
 			return false
 		}
 	}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/wal-g/tracelog"
@@ -10,21 +11,34 @@ import (
 func NewPgWatcher(queryRunner *PgQueryRunner, aliveCheckInterval time.Duration) *PgAliveWatcher {
 	ticker := time.NewTicker(aliveCheckInterval)
 	errCh := make(chan error, 1)
+	done := make(chan struct{})
 	go func() {
-		errCh <- watchPgStatus(queryRunner, ticker)
+		defer ticker.Stop()
+		errCh <- watchPgStatus(queryRunner, ticker, done)
 		close(errCh)
 	}()
 
-	return &PgAliveWatcher{Err: errCh}
+	return &PgAliveWatcher{Err: errCh, done: done}
 }
 
 type PgAliveWatcher struct {
-	Err <-chan error
+	Err  <-chan error
+	done chan struct{}
+	once sync.Once
 }
 
-func watchPgStatus(queryRunner *PgQueryRunner, ticker *time.Ticker) error {
+// Stop stops the checks, Err is closed without an error then
+func (w *PgAliveWatcher) Stop() {
+	w.once.Do(func() { close(w.done) })
+}
+
+func watchPgStatus(queryRunner *PgQueryRunner, ticker *time.Ticker, done <-chan struct{}) error {
 	for {
-		<-ticker.C
+		select {
+		case <-ticker.C:
+		case <-done:
+			return nil
+		}
 		tracelog.DebugLogger.Printf("Checking if Postgres is still alive...")
 
 		err := queryRunner.Ping()
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPgAliveWatcher_Stop(t *testing.T) {
+	watcher := NewPgWatcher(nil, time.Hour)
+	watcher.Stop()
+	watcher.Stop()
+
+	select {
+	case err := <-watcher.Err:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watcher is not stopped")
+	}
+}
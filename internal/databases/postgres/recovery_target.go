@@ -0,0 +1,375 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	recoverySignalFileName = "recovery.signal"
+	recoveryConfFileName   = "recovery.conf"
+	autoConfFileName       = "postgresql.auto.conf"
+	// recoverySignalPgVersion is the first version which reads the recovery settings from the regular config
+	recoverySignalPgVersion = 120000
+)
+
+// RecoveryTarget is the point in time which the database should be recovered to.
+// Exactly one of the fields is set.
+type RecoveryTarget struct {
+	Time *time.Time
+	LSN  *LSN
+	Xid  *uint64
+}
+
+// NewRecoveryTarget parses the recovery target, it returns nil if none of the targets is specified
+func NewRecoveryTarget(targetTime, targetLSN, targetXid string) (*RecoveryTarget, error) {
+	specified := 0
+	for _, value := range []string{targetTime, targetLSN, targetXid} {
+		if value != "" {
+			specified++
+		}
+	}
+	if specified == 0 {
+		return nil, nil
+	}
+	if specified > 1 {
+		return nil, errors.New("only one of the recovery target time, LSN and transaction ID can be specified")
+	}
+
+	target := &RecoveryTarget{}
+	switch {
+	case targetTime != "":
+		parsedTime, err := time.Parse(time.RFC3339, targetTime)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recovery target time %q, RFC3339 is expected", targetTime)
+		}
+		target.Time = &parsedTime
+	case targetLSN != "":
+		lsn, err := ParseLSN(targetLSN)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recovery target LSN %q", targetLSN)
+		}
+		target.LSN = &lsn
+	default:
+		xid, err := strconv.ParseUint(targetXid, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recovery target transaction ID %q", targetXid)
+		}
+		target.Xid = &xid
+	}
+	return target, nil
+}
+
+func (target RecoveryTarget) String() string {
+	switch {
+	case target.Time != nil:
+		return "time " + target.Time.Format(time.RFC3339Nano)
+	case target.LSN != nil:
+		return "LSN " + target.LSN.String()
+	default:
+		return fmt.Sprintf("transaction ID %d", *target.Xid)
+	}
+}
+
+// settings returns the recovery_target_* settings of the target
+func (target RecoveryTarget) settings() []string {
+	var setting string
+	switch {
+	case target.Time != nil:
+		setting = "recovery_target_time = " + quoteConfigValue(target.Time.Format("2006-01-02 15:04:05.999999Z07:00"))
+	case target.LSN != nil:
+		setting = "recovery_target_lsn = " + quoteConfigValue(target.LSN.String())
+	default:
+		setting = "recovery_target_xid = " + quoteConfigValue(strconv.FormatUint(*target.Xid, 10))
+	}
+	return []string{setting, "recovery_target_timeline = 'latest'"}
+}
+
+// precedes checks that the backup is finished before the target.
+// The backups can't be matched against the transaction IDs, so any backup precedes them.
+func (target RecoveryTarget) precedes(backup BackupDetail) bool {
+	switch {
+	case target.Time != nil:
+		finishTime := backup.FinishTime
+		if finishTime.IsZero() {
+			finishTime = backup.Time
+		}
+		return finishTime.Before(*target.Time)
+	case target.LSN != nil:
+		return backup.FinishLsn != 0 && backup.FinishLsn <= *target.LSN
+	default:
+		return true
+	}
+}
+
+// RecoveryTargetBackupSelector selects the newest backup which the recovery target
+// is reachable from over the latest timeline using the WAL in storage
+type RecoveryTargetBackupSelector struct {
+	target     RecoveryTarget
+	backupName string
+}
+
+// NewRecoveryTargetBackupSelector creates the selector, the backupName limits the choice to the specific backup
+func NewRecoveryTargetBackupSelector(target RecoveryTarget, backupName string) RecoveryTargetBackupSelector {
+	if backupName == internal.LatestString {
+		backupName = ""
+	}
+	return RecoveryTargetBackupSelector{target: target, backupName: backupName}
+}
+
+func (s RecoveryTargetBackupSelector) Select(folder storage.Folder) (internal.Backup, error) {
+	baseBackupFolder := folder.GetSubFolder(utility.BaseBackupPath)
+	backupTimes, err := internal.GetBackups(baseBackupFolder)
+	if err != nil {
+		return internal.Backup{}, err
+	}
+	if s.backupName != "" {
+		var namedBackupTimes []internal.BackupTime
+		for _, backupTime := range backupTimes {
+			if backupTime.BackupName == s.backupName {
+				namedBackupTimes = append(namedBackupTimes, backupTime)
+			}
+		}
+		if len(namedBackupTimes) == 0 {
+			return internal.Backup{}, internal.NewBackupNonExistenceError(s.backupName)
+		}
+		backupTimes = namedBackupTimes
+	}
+	backups, err := GetBackupsDetails(baseBackupFolder, backupTimes)
+	if err != nil {
+		return internal.Backup{}, err
+	}
+	SortBackupDetails(backups)
+
+	walPath, err := newRecoveryWalPath(folder.GetSubFolder(utility.WalPath))
+	if err != nil {
+		return internal.Backup{}, err
+	}
+	if s.target.Xid != nil {
+		tracelog.WarningLogger.Printf("The backups can't be matched against the transaction ID, " +
+			"the newest reachable one is selected. Specify the backup explicitly if the transaction is older.")
+	}
+
+	tracelog.InfoLogger.Printf("Selecting the backup to recover to %s over timeline %d...", s.target, walPath.timeline)
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		if !s.target.precedes(backup) {
+			tracelog.DebugLogger.Printf("Backup %s is not finished before the recovery target", backup.BackupName)
+			continue
+		}
+		err = walPath.checkBackupReachable(backup)
+		if err != nil {
+			tracelog.WarningLogger.Printf("Skipping backup %s: %v", backup.BackupName, err)
+			continue
+		}
+		err = walPath.checkWalPresence(backup, s.target)
+		if err != nil {
+			return internal.Backup{}, errors.Wrapf(err, "the recovery target is unreachable from backup %s",
+				backup.BackupName)
+		}
+		tracelog.InfoLogger.Printf("Selected backup %s finished at %s (LSN %s)",
+			backup.BackupName, internal.FormatTime(backup.FinishTime), backup.FinishLsn)
+		return internal.NewBackupInStorage(baseBackupFolder, backup.BackupName, backup.StorageName)
+	}
+	return internal.Backup{}, errors.Errorf("no backup which the recovery target %s is reachable from", s.target)
+}
+
+// recoveryWalPath is the history of the latest timeline in storage, which the recovery follows
+type recoveryWalPath struct {
+	timeline uint32
+	// history is the ancestor timelines ordered by their switch LSNs
+	history []*TimelineHistoryRecord
+	// segments maps the WAL segments in storage to their modification times
+	segments map[WalSegmentDescription]time.Time
+}
+
+func newRecoveryWalPath(walFolder storage.Folder) (*recoveryWalPath, error) {
+	objects, _, err := walFolder.ListFolder()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the WAL folder")
+	}
+	filenames := make([]string, 0, len(objects))
+	segments := make(map[WalSegmentDescription]time.Time)
+	for _, object := range objects {
+		filenames = append(filenames, object.GetName())
+		segment, err := NewWalSegmentDescription(utility.TrimFileExtension(object.GetName()))
+		if err == nil {
+			segments[segment] = object.GetLastModified()
+		}
+	}
+	timeline := tryFindHighestTimelineID(filenames)
+	if timeline == 0 {
+		return nil, errors.New("no WAL files found in storage")
+	}
+	history, err := GetTimeLineHistoryRecords(timeline, walFolder)
+	if _, ok := err.(HistoryFileNotFoundError); ok {
+		if timeline > 1 {
+			tracelog.WarningLogger.Printf("History file of timeline %d is not found, "+
+				"considering it the only timeline", timeline)
+		}
+		history, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].lsn < history[j].lsn
+	})
+	return &recoveryWalPath{timeline: timeline, history: history, segments: segments}, nil
+}
+
+// timelineOf returns the timeline of the path which the segment belongs to. The segment with
+// the switch point belongs to the new timeline, since it is copied there during the switch.
+func (path *recoveryWalPath) timelineOf(segmentNo WalSegmentNo) uint32 {
+	for _, record := range path.history {
+		if segmentNo < NewWalSegmentNo(record.lsn) {
+			return record.timeline
+		}
+	}
+	return path.timeline
+}
+
+// checkBackupReachable checks that the backup timeline is on the path and the backup
+// is finished before the path left it
+func (path *recoveryWalPath) checkBackupReachable(backup BackupDetail) error {
+	backupTimeline, _, err := ParseWALFilename(backup.WalFileName)
+	if err != nil {
+		return err
+	}
+	if backupTimeline == path.timeline {
+		return nil
+	}
+	for _, record := range path.history {
+		if record.timeline != backupTimeline {
+			continue
+		}
+		if backup.FinishLsn > record.lsn {
+			return errors.Errorf("backup is finished at %s after timeline %d switched at %s",
+				backup.FinishLsn, backupTimeline, record.lsn)
+		}
+		return nil
+	}
+	return errors.Errorf("backup timeline %d is not an ancestor of timeline %d", backupTimeline, path.timeline)
+}
+
+// checkWalPresence checks that every WAL segment from the backup start to the target is in storage.
+// The segment of the time target is the first one after the backup end archived not before the target time,
+// since a segment is archived after its records are written. The segment of the transaction ID target
+// is unknown, so every segment up to the latest one is required then.
+func (path *recoveryWalPath) checkWalPresence(backup BackupDetail, target RecoveryTarget) error {
+	_, startSegmentNo, err := ParseWALFilename(backup.WalFileName)
+	if err != nil {
+		return err
+	}
+	backupEndSegmentNo := NewWalSegmentNo(backup.FinishLsn)
+	lastSegmentNo := backupEndSegmentNo
+	if target.LSN != nil {
+		lastSegmentNo = NewWalSegmentNo(*target.LSN)
+	} else {
+		for segment := range path.segments {
+			if segment.Number > lastSegmentNo && segment.Timeline == path.timelineOf(segment.Number) {
+				lastSegmentNo = segment.Number
+			}
+		}
+	}
+
+	for segmentNo := WalSegmentNo(startSegmentNo); segmentNo <= lastSegmentNo; segmentNo++ {
+		segment := WalSegmentDescription{Number: segmentNo, Timeline: path.timelineOf(segmentNo)}
+		archiveTime, ok := path.segments[segment]
+		if !ok {
+			return errors.Errorf("WAL segment %s is missing in storage", segment.GetFileName())
+		}
+		if target.Time != nil && segmentNo >= backupEndSegmentNo && !archiveTime.Before(*target.Time) {
+			return nil
+		}
+	}
+	if target.Time != nil {
+		return errors.Errorf("the WAL up to the recovery target time is not archived yet, "+
+			"the latest WAL segment in storage is %s", WalSegmentDescription{
+			Number: lastSegmentNo, Timeline: path.timelineOf(lastSegmentNo)}.GetFileName())
+	}
+	return nil
+}
+
+// WriteRecoveryConfig makes the restored database recover to the target on startup: the recovery settings
+// are appended to postgresql.auto.conf and recovery.signal is created, or recovery.conf is written before Postgres 12
+func WriteRecoveryConfig(dbDataDirectory string, pgVersion int, target RecoveryTarget, restoreCommand string) error {
+	settings := append([]string{"restore_command = " + quoteConfigValue(restoreCommand)}, target.settings()...)
+	content := "# recovery settings added by wal-g backup-fetch\n" + strings.Join(settings, "\n") + "\n"
+
+	if pgVersion != 0 && pgVersion < recoverySignalPgVersion {
+		return os.WriteFile(filepath.Join(dbDataDirectory, recoveryConfFileName), []byte(content), 0600)
+	}
+
+	autoConf, err := os.OpenFile(filepath.Join(dbDataDirectory, autoConfFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = autoConf.WriteString(content)
+	if err != nil {
+		utility.LoggedClose(autoConf, "")
+		return err
+	}
+	err = autoConf.Close()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dbDataDirectory, recoverySignalFileName), nil, 0600)
+}
+
+// HandleRecoveryTargetFetch fetches the backup selected for the recovery target and writes the recovery config
+func HandleRecoveryTargetFetch(
+	folder storage.Folder,
+	selector RecoveryTargetBackupSelector,
+	fetcher internal.Fetcher,
+	dbDataDirectory string,
+	restoreCommand string,
+) error {
+	return internal.HandleBackupFetch(folder, selector, func(folder storage.Folder, backup internal.Backup) error {
+		err := fetcher(folder, backup)
+		if err != nil {
+			return err
+		}
+
+		pgBackup := ToPgBackup(backup)
+		sentinel, err := pgBackup.GetSentinel()
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch the backup sentinel")
+		}
+		err = WriteRecoveryConfig(dbDataDirectory, sentinel.PgVersion, selector.target, restoreCommand)
+		if err != nil {
+			return errors.Wrap(err, "failed to write the recovery config")
+		}
+		tracelog.InfoLogger.Printf("The database is configured to recover to %s", selector.target)
+		return nil
+	})
+}
+
+// BuildRestoreCommand creates the restore_command fetching WAL with the WAL-G binary and the config file, if any.
+// The paths are quoted since the command is run by the shell.
+func BuildRestoreCommand(walgPath, configPath string) string {
+	restoreCommand := quoteShellArgument(walgPath) + ` wal-fetch "%f" "%p"`
+	if configPath != "" {
+		restoreCommand += " --config " + quoteShellArgument(configPath)
+	}
+	return restoreCommand
+}
+
+func quoteShellArgument(argument string) string {
+	return "'" + strings.ReplaceAll(argument, "'", `'\''`) + "'"
+}
+
+func quoteConfigValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,217 @@
+package postgres_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+var recoveryTestStartTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// putRecoveryTestBackup puts the backup starting at the WAL segment and finishing an hour later at the LSN
+func putRecoveryTestBackup(t *testing.T, folder storage.Folder, walName string, finishLsn postgres.LSN, hour int) {
+	name := utility.BackupNamePrefix + walName
+	meta := postgres.ExtendedMetadataDto{
+		StartTime:  recoveryTestStartTime.Add(time.Duration(hour) * time.Hour),
+		FinishTime: recoveryTestStartTime.Add(time.Duration(hour+1) * time.Hour),
+		PgVersion:  150000,
+		FinishLsn:  finishLsn,
+	}
+	metaBytes, err := json.Marshal(meta)
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(utility.BaseBackupPath+name+utility.SentinelSuffix, new(bytes.Buffer)))
+	require.NoError(t, folder.PutObject(utility.BaseBackupPath+name+"/"+utility.MetadataFileName,
+		bytes.NewReader(metaBytes)))
+}
+
+func putRecoveryTestWal(folder storage.Folder, timeline uint32, from, to uint64) {
+	var names []string
+	for segmentNo := from; segmentNo <= to; segmentNo++ {
+		names = append(names, postgres.WalSegmentNo(segmentNo).GetFilename(timeline)+".lz4")
+	}
+	putWalSegments(names, folder.GetSubFolder(utility.WalPath))
+}
+
+func selectForRecovery(folder storage.Folder, target *postgres.RecoveryTarget, backupName string) (string, error) {
+	backup, err := postgres.NewRecoveryTargetBackupSelector(*target, backupName).Select(folder)
+	return backup.Name, err
+}
+
+func TestRecoveryTargetBackupSelector_LSN(t *testing.T) {
+	folder := setupTestStorageFolder()
+	putRecoveryTestBackup(t, folder, "000000010000000000000002", 0x2000100, 0)
+	putRecoveryTestBackup(t, folder, "000000010000000000000005", 0x5000100, 3)
+	putRecoveryTestWal(folder, 1, 2, 8)
+
+	target, err := postgres.NewRecoveryTarget("", "0/4000000", "")
+	require.NoError(t, err)
+	name, err := selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", name)
+
+	target, err = postgres.NewRecoveryTarget("", "0/7000000", "")
+	require.NoError(t, err)
+	name, err = selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000005", name)
+
+	target, err = postgres.NewRecoveryTarget("", "0/9000000", "")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err, "the WAL segment with the target is missing")
+
+	target, err = postgres.NewRecoveryTarget("", "0/1000000", "")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err, "no backup precedes the target")
+}
+
+func TestRecoveryTargetBackupSelector_MissingWal(t *testing.T) {
+	folder := setupTestStorageFolder()
+	putRecoveryTestBackup(t, folder, "000000010000000000000002", 0x2000100, 0)
+	putRecoveryTestWal(folder, 1, 2, 3)
+	putRecoveryTestWal(folder, 1, 5, 8)
+
+	target, err := postgres.NewRecoveryTarget("", "0/6000000", "")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err)
+
+	// the transaction may be after the gap
+	target, err = postgres.NewRecoveryTarget("", "", "1234")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err)
+}
+
+func TestRecoveryTargetBackupSelector_TimeMissingWal(t *testing.T) {
+	archiveTime := recoveryTestStartTime
+	folder := memory.NewFolder("in_memory/", memory.NewKVS(memory.WithCustomTime(func() time.Time {
+		return archiveTime
+	})))
+	putRecoveryTestBackup(t, folder, "000000010000000000000002", 0x2000100, 0)
+	// the segment N is archived at hour N-1, the segment 4 is missing
+	for _, segmentNo := range []uint64{2, 3, 5, 6} {
+		archiveTime = recoveryTestStartTime.Add(time.Duration(segmentNo-1) * time.Hour)
+		putRecoveryTestWal(folder, 1, segmentNo, segmentNo)
+	}
+
+	target, err := postgres.NewRecoveryTarget(recoveryTestStartTime.Add(90*time.Minute).Format(time.RFC3339), "", "")
+	require.NoError(t, err)
+	name, err := selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", name)
+
+	target, err = postgres.NewRecoveryTarget(recoveryTestStartTime.Add(150*time.Minute).Format(time.RFC3339), "", "")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err, "the target is in the missing segment 4 or after it")
+
+	target, err = postgres.NewRecoveryTarget(recoveryTestStartTime.Add(10*time.Hour).Format(time.RFC3339), "", "")
+	require.NoError(t, err)
+	_, err = selectForRecovery(folder, target, "")
+	assert.Error(t, err, "the WAL up to the target is not archived yet")
+}
+
+func TestRecoveryTargetBackupSelector_Timelines(t *testing.T) {
+	folder := setupTestStorageFolder()
+	putRecoveryTestBackup(t, folder, "000000010000000000000002", 0x2000100, 0)
+	// finished after the timeline 1 switched to 2, so it is on the abandoned branch
+	putRecoveryTestBackup(t, folder, "000000010000000000000005", 0x5000100, 3)
+	putRecoveryTestBackup(t, folder, "000000020000000000000007", 0x7000100, 6)
+	putRecoveryTestWal(folder, 1, 2, 6)
+	putRecoveryTestWal(folder, 2, 4, 9)
+	historyName, historyFile, err := newTimelineHistoryFile(fmt.Sprintf("1\t0/%X\tno recovery target specified\n", 0x4000100), 2)
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(utility.WalPath+historyName, historyFile))
+
+	target, err := postgres.NewRecoveryTarget(recoveryTestStartTime.Add(5*time.Hour).Format(time.RFC3339), "", "")
+	require.NoError(t, err)
+	name, err := selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", name)
+
+	target, err = postgres.NewRecoveryTarget("", "0/8000000", "")
+	require.NoError(t, err)
+	name, err = selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000020000000000000007", name)
+
+	_, err = selectForRecovery(folder, target, "base_000000010000000000000005")
+	assert.Error(t, err)
+}
+
+func TestRecoveryTargetBackupSelector_Xid(t *testing.T) {
+	folder := setupTestStorageFolder()
+	putRecoveryTestBackup(t, folder, "000000010000000000000002", 0x2000100, 0)
+	putRecoveryTestBackup(t, folder, "000000010000000000000005", 0x5000100, 3)
+	putRecoveryTestWal(folder, 1, 2, 8)
+
+	target, err := postgres.NewRecoveryTarget("", "", "1234")
+	require.NoError(t, err)
+	name, err := selectForRecovery(folder, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000005", name)
+
+	name, err = selectForRecovery(folder, target, "base_000000010000000000000002")
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", name)
+}
+
+func TestNewRecoveryTarget(t *testing.T) {
+	target, err := postgres.NewRecoveryTarget("", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, target)
+
+	_, err = postgres.NewRecoveryTarget("2024-05-01T12:00:00Z", "0/1000000", "")
+	assert.Error(t, err)
+
+	_, err = postgres.NewRecoveryTarget("yesterday", "", "")
+	assert.Error(t, err)
+
+	_, err = postgres.NewRecoveryTarget("", "", "-1")
+	assert.Error(t, err)
+}
+
+func TestWriteRecoveryConfig(t *testing.T) {
+	target, err := postgres.NewRecoveryTarget("2024-05-01T12:00:00+03:00", "", "")
+	require.NoError(t, err)
+
+	dataDir := t.TempDir()
+	autoConfPath := filepath.Join(dataDir, "postgresql.auto.conf")
+	require.NoError(t, os.WriteFile(autoConfPath, []byte("work_mem = '8MB'\n"), 0600))
+	require.NoError(t, postgres.WriteRecoveryConfig(dataDir, 150000, *target, `wal-g wal-fetch "%f" "%p"`))
+
+	autoConf, err := os.ReadFile(autoConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "work_mem = '8MB'\n"+
+		"# recovery settings added by wal-g backup-fetch\n"+
+		"restore_command = 'wal-g wal-fetch \"%f\" \"%p\"'\n"+
+		"recovery_target_time = '2024-05-01 12:00:00+03:00'\n"+
+		"recovery_target_timeline = 'latest'\n", string(autoConf))
+	assert.FileExists(t, filepath.Join(dataDir, "recovery.signal"))
+
+	oldDataDir := t.TempDir()
+	require.NoError(t, postgres.WriteRecoveryConfig(oldDataDir, 110000, *target, "wal-g wal-fetch '%f' '%p'"))
+	recoveryConf, err := os.ReadFile(filepath.Join(oldDataDir, "recovery.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(recoveryConf), "restore_command = 'wal-g wal-fetch ''%f'' ''%p'''\n")
+	assert.NoFileExists(t, filepath.Join(oldDataDir, "recovery.signal"))
+}
+
+func TestBuildRestoreCommand(t *testing.T) {
+	assert.Equal(t, `'/usr/bin/wal-g' wal-fetch "%f" "%p"`, postgres.BuildRestoreCommand("/usr/bin/wal-g", ""))
+	assert.Equal(t, `'/opt/wal g/wal-g' wal-fetch "%f" "%p" --config '/etc/it'\''s wal-g.yaml'`,
+		postgres.BuildRestoreCommand("/opt/wal g/wal-g", "/etc/it's wal-g.yaml"))
+}
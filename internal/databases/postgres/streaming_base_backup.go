@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path"
 	"time"
 
 	"github.com/wal-g/wal-g/internal"
@@ -130,8 +131,9 @@ func (bb *StreamingBaseBackup) Upload(ctx context.Context, uploader internal.Upl
 	bb.streamer = NewTarballStreamer(bb, bb.maxTarSize, bundleFiles)
 	for {
 		tbsTar := ioextensions.NewNamedReaderImpl(bb.streamer, bb.FileName())
-		compressedFile := internal.CompressAndEncrypt(tbsTar, bb.uploader.Compression(), internal.ConfigureCrypter())
 		dstPath := fmt.Sprintf("%s.%s", bb.Path(), bb.uploader.Compression().FileExtension())
+		compressedFile := internal.CompressAndEncryptNamed(tbsTar, bb.uploader.Compression(), internal.ConfigureCrypter(),
+			path.Base(dstPath))
 		err = bb.uploader.Upload(ctx, dstPath, compressedFile)
 		if err != nil {
 			return err
@@ -154,8 +156,9 @@ func (bb *StreamingBaseBackup) Upload(ctx context.Context, uploader internal.Upl
 	// Upload the extra tar
 	if len(bb.streamer.Tee) > 0 {
 		teeTar := ioextensions.NewNamedReaderImpl(bb.streamer.TeeIo, bb.FileName())
-		teeCompressedFile := internal.CompressAndEncrypt(teeTar, bb.uploader.Compression(), internal.ConfigureCrypter())
 		teeFileName := fmt.Sprintf("pg_control.tar.%s", bb.uploader.Compression().FileExtension())
+		teeCompressedFile := internal.CompressAndEncryptNamed(teeTar, bb.uploader.Compression(), internal.ConfigureCrypter(),
+			teeFileName)
 		teeFilePath := storage.JoinPath(bb.BackupName(), internal.TarPartitionFolderName, teeFileName)
 		err = bb.uploader.Upload(ctx, teeFilePath, teeCompressedFile)
 		if err != nil {
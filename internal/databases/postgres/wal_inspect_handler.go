@@ -0,0 +1,335 @@
+package postgres
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/walparser"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// forkNames are the relation fork names, see src/common/relpath.c
+var forkNames = []string{"main", "fsm", "vm", "init"}
+
+// WalInspectArguments are the WAL segments range to inspect and the inspection settings
+type WalInspectArguments struct {
+	Timeline     uint32
+	FirstSegment WalSegmentNo
+	LastSegment  WalSegmentNo
+	// Stats enables the aggregated statistics output instead of the records
+	Stats bool
+	// Rmgr is the name of the resource manager to show the records of, all if empty
+	Rmgr string
+	// Relation is the relation to show the records referring to, all if nil
+	Relation *walparser.RelFileNode
+	// TopRelations is the number of the relations with the largest WAL volume in the statistics, all if zero
+	TopRelations int
+}
+
+// ParseWalInspectRange parses the WAL segment name or the inclusive range of the segment names
+// on the same timeline separated by the hyphen
+func ParseWalInspectRange(walRange string) (timeline uint32, first, last WalSegmentNo, err error) {
+	firstName, lastName, isRange := strings.Cut(walRange, "-")
+	if !isRange {
+		lastName = firstName
+	}
+	timeline, firstNo, err := ParseWALFilename(firstName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	lastTimeline, lastNo, err := ParseWALFilename(lastName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if lastTimeline != timeline {
+		return 0, 0, 0, errors.Errorf("WAL segments %s and %s are on different timelines", firstName, lastName)
+	}
+	if lastNo < firstNo {
+		return 0, 0, 0, errors.Errorf("WAL segment %s precedes %s", lastName, firstName)
+	}
+	return timeline, WalSegmentNo(firstNo), WalSegmentNo(lastNo), nil
+}
+
+// ParseRelFileNode parses the relation in the spcOid/dbOid/relNumber form used by pg_waldump
+func ParseRelFileNode(relation string) (*walparser.RelFileNode, error) {
+	parts := strings.Split(relation, "/")
+	if len(parts) != 3 {
+		return nil, errors.Errorf("invalid relation '%s', expected tablespace/database/relation", relation)
+	}
+	oids := make([]walparser.Oid, 0, len(parts))
+	for _, part := range parts {
+		oid, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid relation '%s'", relation)
+		}
+		oids = append(oids, walparser.Oid(oid))
+	}
+	return &walparser.RelFileNode{SpcNode: oids[0], DBNode: oids[1], RelNode: oids[2]}, nil
+}
+
+// WalInspectRecord is the decoded WAL record
+type WalInspectRecord struct {
+	Lsn     string `json:"lsn"`
+	PrevLsn string `json:"prev_lsn"`
+	Rmgr    string `json:"rmgr"`
+	Info    uint8  `json:"info"`
+	Xid     uint32 `json:"xid"`
+	// Length is the total record length, FpiLength is the length of the full page images in it
+	Length    uint32               `json:"length"`
+	FpiLength uint32               `json:"fpi_length"`
+	Blocks    []WalInspectBlockRef `json:"blocks,omitempty"`
+}
+
+type WalInspectBlockRef struct {
+	ID        uint8  `json:"id"`
+	Relation  string `json:"relation"`
+	Fork      string `json:"fork"`
+	Block     uint32 `json:"block"`
+	FpiLength uint16 `json:"fpi_length,omitempty"`
+}
+
+// WalInspectStats is the WAL volume aggregated by the resource managers and the relations
+type WalInspectStats struct {
+	Timeline     uint32 `json:"timeline"`
+	FirstSegment string `json:"first_segment"`
+	LastSegment  string `json:"last_segment"`
+	WalInspectVolume
+	Rmgrs []WalInspectRmgrStats `json:"rmgrs"`
+	// Relations are sorted by the volume of their block references, the largest first
+	Relations []WalInspectRelationStats `json:"relations"`
+}
+
+// WalInspectVolume is the number and the size of the records and of their full page images.
+// The record size doesn't include the full page images like in pg_waldump --stats.
+type WalInspectVolume struct {
+	Records     uint64 `json:"records"`
+	RecordBytes uint64 `json:"record_bytes"`
+	FpiCount    uint64 `json:"fpi_count"`
+	FpiBytes    uint64 `json:"fpi_bytes"`
+}
+
+type WalInspectRmgrStats struct {
+	Rmgr string `json:"rmgr"`
+	WalInspectVolume
+}
+
+type WalInspectRelationStats struct {
+	Relation string `json:"relation"`
+	// BlockRefs is the number of the block references to the relation, FpiCount of them have the full page image
+	BlockRefs uint64 `json:"block_refs"`
+	FpiCount  uint64 `json:"fpi_count"`
+	FpiBytes  uint64 `json:"fpi_bytes"`
+}
+
+func (volume *WalInspectVolume) add(record *WalInspectRecord) {
+	volume.Records++
+	volume.RecordBytes += uint64(record.Length - record.FpiLength)
+	volume.FpiBytes += uint64(record.FpiLength)
+	for _, block := range record.Blocks {
+		if block.FpiLength > 0 {
+			volume.FpiCount++
+		}
+	}
+}
+
+// HandleWalInspect fetches the WAL segments from storage, decodes their records
+// and writes either the records or their statistics
+func HandleWalInspect(walFolder storage.Folder, args WalInspectArguments, writer WalInspectOutputWriter) error {
+	inspector := newWalInspector(args, writer)
+	folderReader := internal.NewFolderReader(walFolder)
+	for segmentNo := args.FirstSegment; segmentNo <= args.LastSegment; segmentNo = segmentNo.Next() {
+		filename := segmentNo.GetFilename(args.Timeline)
+		err := inspector.inspectSegment(folderReader, filename)
+		if err != nil {
+			return err
+		}
+		tracelog.DebugLogger.Printf("Inspected WAL segment %s", filename)
+	}
+	if !args.Stats {
+		return nil
+	}
+	return writer.WriteStats(inspector.buildStats())
+}
+
+type walInspector struct {
+	args   WalInspectArguments
+	writer WalInspectOutputWriter
+	parser *walparser.WalParser
+
+	total     WalInspectVolume
+	rmgrs     map[string]*WalInspectRmgrStats
+	relations map[string]*WalInspectRelationStats
+}
+
+func newWalInspector(args WalInspectArguments, writer WalInspectOutputWriter) *walInspector {
+	return &walInspector{
+		args:      args,
+		writer:    writer,
+		parser:    walparser.NewWalParser(),
+		rmgrs:     map[string]*WalInspectRmgrStats{},
+		relations: map[string]*WalInspectRelationStats{},
+	}
+}
+
+func (inspector *walInspector) inspectSegment(folderReader internal.StorageFolderReader, filename string) error {
+	walFile, err := internal.DownloadAndDecompressStorageFile(folderReader, filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch WAL segment %s", filename)
+	}
+	defer utility.LoggedClose(walFile, "")
+
+	pageReader := walparser.NewWalPageReader(walFile)
+	for {
+		data, err := pageReader.ReadPageData()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read WAL segment %s", filename)
+		}
+		_, records, err := inspector.parser.ParseRecordsFromPage(bytes.NewReader(data))
+		switch err.(type) {
+		case nil, walparser.PartialPageError, walparser.ZeroPageError:
+		default:
+			return errors.Wrapf(err, "failed to parse WAL segment %s", filename)
+		}
+		for i := range records {
+			err = inspector.inspectRecord(&records[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (inspector *walInspector) inspectRecord(xlogRecord *walparser.XLogRecord) error {
+	if xlogRecord.IsZero() {
+		return nil
+	}
+	record := newWalInspectRecord(xlogRecord)
+	if !inspector.matches(xlogRecord, record) {
+		return nil
+	}
+	if !inspector.args.Stats {
+		return inspector.writer.WriteRecord(record)
+	}
+
+	inspector.total.add(&record)
+	rmgrStats, ok := inspector.rmgrs[record.Rmgr]
+	if !ok {
+		rmgrStats = &WalInspectRmgrStats{Rmgr: record.Rmgr}
+		inspector.rmgrs[record.Rmgr] = rmgrStats
+	}
+	rmgrStats.add(&record)
+	for _, block := range record.Blocks {
+		relationStats, ok := inspector.relations[block.Relation]
+		if !ok {
+			relationStats = &WalInspectRelationStats{Relation: block.Relation}
+			inspector.relations[block.Relation] = relationStats
+		}
+		relationStats.BlockRefs++
+		if block.FpiLength > 0 {
+			relationStats.FpiCount++
+			relationStats.FpiBytes += uint64(block.FpiLength)
+		}
+	}
+	return nil
+}
+
+func (inspector *walInspector) matches(xlogRecord *walparser.XLogRecord, record WalInspectRecord) bool {
+	if inspector.args.Rmgr != "" && !strings.EqualFold(inspector.args.Rmgr, record.Rmgr) {
+		return false
+	}
+	if inspector.args.Relation == nil {
+		return true
+	}
+	for _, block := range xlogRecord.Blocks {
+		if block.Header.BlockLocation.RelationFileNode == *inspector.args.Relation {
+			return true
+		}
+	}
+	return false
+}
+
+func (inspector *walInspector) buildStats() WalInspectStats {
+	stats := WalInspectStats{
+		Timeline:         inspector.args.Timeline,
+		FirstSegment:     inspector.args.FirstSegment.GetFilename(inspector.args.Timeline),
+		LastSegment:      inspector.args.LastSegment.GetFilename(inspector.args.Timeline),
+		WalInspectVolume: inspector.total,
+		Rmgrs:            make([]WalInspectRmgrStats, 0, len(inspector.rmgrs)),
+		Relations:        make([]WalInspectRelationStats, 0, len(inspector.relations)),
+	}
+	for _, rmgrStats := range inspector.rmgrs {
+		stats.Rmgrs = append(stats.Rmgrs, *rmgrStats)
+	}
+	sort.Slice(stats.Rmgrs, func(i, j int) bool {
+		left, right := stats.Rmgrs[i], stats.Rmgrs[j]
+		if left.RecordBytes+left.FpiBytes != right.RecordBytes+right.FpiBytes {
+			return left.RecordBytes+left.FpiBytes > right.RecordBytes+right.FpiBytes
+		}
+		return left.Rmgr < right.Rmgr
+	})
+	for _, relationStats := range inspector.relations {
+		stats.Relations = append(stats.Relations, *relationStats)
+	}
+	sort.Slice(stats.Relations, func(i, j int) bool {
+		left, right := stats.Relations[i], stats.Relations[j]
+		if left.FpiBytes != right.FpiBytes {
+			return left.FpiBytes > right.FpiBytes
+		}
+		if left.BlockRefs != right.BlockRefs {
+			return left.BlockRefs > right.BlockRefs
+		}
+		return left.Relation < right.Relation
+	})
+	if inspector.args.TopRelations > 0 && len(stats.Relations) > inspector.args.TopRelations {
+		stats.Relations = stats.Relations[:inspector.args.TopRelations]
+	}
+	return stats
+}
+
+func newWalInspectRecord(xlogRecord *walparser.XLogRecord) WalInspectRecord {
+	record := WalInspectRecord{
+		Lsn:     LSN(xlogRecord.Lsn).String(),
+		PrevLsn: LSN(xlogRecord.Header.PrevRecordPtr).String(),
+		Rmgr:    walparser.ResourceManagerNames[xlogRecord.Header.ResourceManagerID],
+		Info:    xlogRecord.Header.Info,
+		Xid:     xlogRecord.Header.XactID,
+		Length:  xlogRecord.Header.TotalRecordLength,
+	}
+	for _, block := range xlogRecord.Blocks {
+		location := block.Header.BlockLocation
+		blockRef := WalInspectBlockRef{
+			ID:       block.Header.BlockID,
+			Relation: formatRelFileNode(location.RelationFileNode),
+			Fork:     forkName(block.Header.ForkNum()),
+			Block:    location.BlockNo,
+		}
+		if block.Header.HasImage() {
+			blockRef.FpiLength = block.Header.ImageHeader.ImageLength
+			record.FpiLength += uint32(blockRef.FpiLength)
+		}
+		record.Blocks = append(record.Blocks, blockRef)
+	}
+	return record
+}
+
+func formatRelFileNode(node walparser.RelFileNode) string {
+	return fmt.Sprintf("%d/%d/%d", node.SpcNode, node.DBNode, node.RelNode)
+}
+
+func forkName(forkNum uint8) string {
+	if int(forkNum) < len(forkNames) {
+		return forkNames[forkNum]
+	}
+	return strconv.Itoa(int(forkNum))
+}
@@ -0,0 +1,148 @@
+package postgres_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/internal/walparser"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// walInspectTestData is the WAL with the long record crossing its second page
+const walInspectTestData = "../../walparser/testdata/long_record"
+
+func putWalInspectTestSegment(t *testing.T, walFolder storage.Folder, name string, content []byte) {
+	var compressed bytes.Buffer
+	writer := compression.Compressors[lz4.AlgorithmName].NewWriter(&compressed)
+	_, err := writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, walFolder.PutObject(name+"."+lz4.FileExtension, &compressed))
+}
+
+func inspectTestWal(t *testing.T, walFolder storage.Folder, walRange string,
+	args postgres.WalInspectArguments, outputType postgres.WalInspectOutputType) string {
+	var err error
+	args.Timeline, args.FirstSegment, args.LastSegment, err = postgres.ParseWalInspectRange(walRange)
+	require.NoError(t, err)
+	var output bytes.Buffer
+	err = postgres.HandleWalInspect(walFolder, args, postgres.NewWalInspectOutputWriter(outputType, &output))
+	require.NoError(t, err)
+	return output.String()
+}
+
+func TestWalInspect_Records(t *testing.T) {
+	wal, err := os.ReadFile(walInspectTestData)
+	require.NoError(t, err)
+	walFolder := setupTestStorageFolder().GetSubFolder(utility.WalPath)
+	putWalInspectTestSegment(t, walFolder, "000000010000000000000001", wal)
+
+	output := inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{}, postgres.WalInspectJSONOutput)
+	var records []postgres.WalInspectRecord
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		var record postgres.WalInspectRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NotEmpty(t, records)
+	for i := 1; i < len(records); i++ {
+		assert.Equal(t, records[i-1].Lsn, records[i].PrevLsn)
+	}
+
+	textOutput := inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{}, postgres.WalInspectTextOutput)
+	lines := strings.Split(strings.TrimSpace(textOutput), "\n")
+	assert.Len(t, lines, len(records))
+	assert.Contains(t, lines[1], "lsn: "+records[1].Lsn+", prev "+records[0].Lsn)
+}
+
+func TestWalInspect_Stats(t *testing.T) {
+	wal, err := os.ReadFile(walInspectTestData)
+	require.NoError(t, err)
+	walFolder := setupTestStorageFolder().GetSubFolder(utility.WalPath)
+	putWalInspectTestSegment(t, walFolder, "000000010000000000000001", wal)
+	// the long record is continued in the next segment
+	splitFolder := setupTestStorageFolder().GetSubFolder(utility.WalPath)
+	putWalInspectTestSegment(t, splitFolder, "000000010000000000000001", wal[:2*walparser.WalPageSize])
+	putWalInspectTestSegment(t, splitFolder, "000000010000000000000002", wal[2*walparser.WalPageSize:])
+
+	var stats, splitStats postgres.WalInspectStats
+	require.NoError(t, json.Unmarshal([]byte(inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{Stats: true}, postgres.WalInspectJSONOutput)), &stats))
+	require.NoError(t, json.Unmarshal([]byte(inspectTestWal(t, splitFolder, "000000010000000000000001-000000010000000000000002",
+		postgres.WalInspectArguments{Stats: true}, postgres.WalInspectJSONOutput)), &splitStats))
+	assert.Equal(t, stats.WalInspectVolume, splitStats.WalInspectVolume)
+	assert.Equal(t, stats.Rmgrs, splitStats.Rmgrs)
+	assert.Equal(t, "000000010000000000000002", splitStats.LastSegment)
+
+	var rmgrRecords uint64
+	for _, rmgr := range stats.Rmgrs {
+		rmgrRecords += rmgr.Records
+	}
+	assert.Equal(t, stats.Records, rmgrRecords)
+	require.NotEmpty(t, stats.Relations)
+
+	topRmgr := stats.Rmgrs[0]
+	var filteredStats postgres.WalInspectStats
+	require.NoError(t, json.Unmarshal([]byte(inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{Stats: true, Rmgr: strings.ToLower(topRmgr.Rmgr), TopRelations: 1},
+		postgres.WalInspectJSONOutput)), &filteredStats))
+	assert.Equal(t, topRmgr.WalInspectVolume, filteredStats.WalInspectVolume)
+	assert.LessOrEqual(t, len(filteredStats.Relations), 1)
+
+	relation, err := postgres.ParseRelFileNode(stats.Relations[0].Relation)
+	require.NoError(t, err)
+	var relationStats postgres.WalInspectStats
+	require.NoError(t, json.Unmarshal([]byte(inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{Stats: true, Relation: relation}, postgres.WalInspectJSONOutput)), &relationStats))
+	assert.Equal(t, stats.Relations[0], relationStats.Relations[0])
+
+	textOutput := inspectTestWal(t, walFolder, "000000010000000000000001",
+		postgres.WalInspectArguments{Stats: true}, postgres.WalInspectTextOutput)
+	assert.Contains(t, textOutput, topRmgr.Rmgr)
+	assert.Contains(t, textOutput, stats.Relations[0].Relation)
+}
+
+func TestWalInspect_MissingSegment(t *testing.T) {
+	walFolder := setupTestStorageFolder().GetSubFolder(utility.WalPath)
+	args := postgres.WalInspectArguments{Timeline: 1, FirstSegment: 1, LastSegment: 1}
+	err := postgres.HandleWalInspect(walFolder, args,
+		postgres.NewWalInspectOutputWriter(postgres.WalInspectTextOutput, new(bytes.Buffer)))
+	assert.Error(t, err)
+}
+
+func TestParseWalInspectRange(t *testing.T) {
+	timeline, first, last, err := postgres.ParseWalInspectRange("0000000200000001000000FE-000000020000000200000001")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), timeline)
+	assert.Equal(t, "0000000200000001000000FE", first.GetFilename(timeline))
+	assert.Equal(t, "000000020000000200000001", last.GetFilename(timeline))
+
+	_, first, last, err = postgres.ParseWalInspectRange("000000010000000000000003")
+	require.NoError(t, err)
+	assert.Equal(t, first, last)
+
+	_, _, _, err = postgres.ParseWalInspectRange("000000010000000000000003-000000020000000000000004")
+	assert.Error(t, err)
+	_, _, _, err = postgres.ParseWalInspectRange("000000010000000000000003-000000010000000000000002")
+	assert.Error(t, err)
+	_, _, _, err = postgres.ParseWalInspectRange("0/3000000")
+	assert.Error(t, err)
+
+	_, err = postgres.ParseRelFileNode("1663/5/16384")
+	assert.NoError(t, err)
+	_, err = postgres.ParseRelFileNode("16384")
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+)
+
+type WalInspectOutputType int
+
+const (
+	WalInspectTextOutput WalInspectOutputType = iota + 1
+	WalInspectJSONOutput
+)
+
+// WalInspectOutputWriter writes the output of wal-inspect command execution result
+type WalInspectOutputWriter interface {
+	WriteRecord(record WalInspectRecord) error
+	WriteStats(stats WalInspectStats) error
+}
+
+func NewWalInspectOutputWriter(outputType WalInspectOutputType, output io.Writer) WalInspectOutputWriter {
+	switch outputType {
+	case WalInspectJSONOutput:
+		return &WalInspectJSONOutputWriter{encoder: json.NewEncoder(output)}
+	default:
+		return &WalInspectTextOutputWriter{output: output}
+	}
+}
+
+// WalInspectJSONOutputWriter writes every record as a separate JSON line and the statistics as a JSON object
+type WalInspectJSONOutputWriter struct {
+	encoder *json.Encoder
+}
+
+func (writer *WalInspectJSONOutputWriter) WriteRecord(record WalInspectRecord) error {
+	return writer.encoder.Encode(record)
+}
+
+func (writer *WalInspectJSONOutputWriter) WriteStats(stats WalInspectStats) error {
+	return writer.encoder.Encode(stats)
+}
+
+// WalInspectTextOutputWriter writes the records in the pg_waldump format and the statistics as tables
+type WalInspectTextOutputWriter struct {
+	output io.Writer
+}
+
+func (writer *WalInspectTextOutputWriter) WriteRecord(record WalInspectRecord) error {
+	var line strings.Builder
+	fmt.Fprintf(&line, "rmgr: %-11s len (rec/tot): %6d/%6d, tx: %10d, lsn: %s, prev %s, info: 0x%02X",
+		record.Rmgr, record.Length-record.FpiLength, record.Length, record.Xid, record.Lsn, record.PrevLsn, record.Info)
+	for _, block := range record.Blocks {
+		fmt.Fprintf(&line, ", blkref #%d: rel %s fork %s blk %d", block.ID, block.Relation, block.Fork, block.Block)
+		if block.FpiLength > 0 {
+			fmt.Fprintf(&line, " FPW (%d bytes)", block.FpiLength)
+		}
+	}
+	line.WriteString("\n")
+	_, err := io.WriteString(writer.output, line.String())
+	return err
+}
+
+func (writer *WalInspectTextOutputWriter) WriteStats(stats WalInspectStats) error {
+	_, err := fmt.Fprintf(writer.output, "WAL segments %s - %s\n", stats.FirstSegment, stats.LastSegment)
+	if err != nil {
+		return err
+	}
+
+	rmgrsTable := table.NewWriter()
+	rmgrsTable.SetOutputMirror(writer.output)
+	rmgrsTable.AppendHeader(table.Row{"Rmgr", "Records", "(%)", "Record size", "(%)",
+		"FPI count", "FPI size", "(%)", "Combined size", "(%)"})
+	for _, rmgr := range stats.Rmgrs {
+		rmgrsTable.AppendRow(newWalInspectVolumeRow(rmgr.Rmgr, rmgr.WalInspectVolume, stats.WalInspectVolume))
+	}
+	rmgrsTable.AppendFooter(newWalInspectVolumeRow("Total", stats.WalInspectVolume, stats.WalInspectVolume))
+	rmgrsTable.Render()
+
+	relationsTable := table.NewWriter()
+	relationsTable.SetOutputMirror(writer.output)
+	relationsTable.AppendHeader(table.Row{"Relation", "Block refs", "FPI count", "FPI size", "(%)"})
+	for _, relation := range stats.Relations {
+		relationsTable.AppendRow(table.Row{relation.Relation, relation.BlockRefs, relation.FpiCount,
+			relation.FpiBytes, formatPercentage(relation.FpiBytes, stats.FpiBytes)})
+	}
+	relationsTable.Render()
+	return nil
+}
+
+func newWalInspectVolumeRow(name string, volume, total WalInspectVolume) table.Row {
+	return table.Row{name,
+		volume.Records, formatPercentage(volume.Records, total.Records),
+		volume.RecordBytes, formatPercentage(volume.RecordBytes, total.RecordBytes),
+		volume.FpiCount,
+		volume.FpiBytes, formatPercentage(volume.FpiBytes, total.FpiBytes),
+		volume.RecordBytes + volume.FpiBytes,
+		formatPercentage(volume.RecordBytes+volume.FpiBytes, total.RecordBytes+total.FpiBytes),
+	}
+}
+
+func formatPercentage(value, total uint64) string {
+	if total == 0 {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", float64(value)*100/float64(total))
+}
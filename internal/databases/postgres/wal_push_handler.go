@@ -11,6 +11,7 @@ import (
 
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/notify"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -33,7 +34,14 @@ func (err CantOverwriteWalFileError) Error() string {
 
 // TODO : unit tests
 // HandleWALPush is invoked to perform wal-g wal-push
-func HandleWALPush(ctx context.Context, uploader *WalUploader, walFilePath string) error {
+func HandleWALPush(ctx context.Context, uploader *WalUploader, walFilePath string) (err error) {
+	notification, err := notify.Start(notify.WalPush, notify.WithDetail("wal_file", filepath.Base(walFilePath)),
+		notify.InBackground())
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
 	if uploader.ArchiveStatusManager.IsWalAlreadyUploaded(walFilePath) {
 		err := uploader.ArchiveStatusManager.UnmarkWalFile(walFilePath)
 
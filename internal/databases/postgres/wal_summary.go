@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/walparser"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// WAL summaries are written by PostgreSQL 17+ with summarize_wal enabled, see
+// https://github.com/postgres/postgres/blob/REL_17_STABLE/src/common/blkreftable.c
+const (
+	WalSummaryMinPgVersion = 170000
+	WalSummariesDirectory  = "summaries"
+
+	walSummaryMagic = 0x652b137b
+	// walSummaryEntrySize is the size of BlockRefTableSerializedEntry: the relation spcOid, dbOid, relNumber,
+	// the fork number, the limit block and the number of chunks
+	walSummaryEntrySize        = 24
+	walSummaryBlocksPerChunk   = 1 << 16
+	walSummaryBlocksPerEntry   = 16
+	walSummaryMaxChunkEntries  = walSummaryBlocksPerChunk / walSummaryBlocksPerEntry
+	walSummaryMainForkNumber   = 0
+	walSummaryInvalidBlockNo   = math.MaxUint32
+	walSummaryWaitTimeout      = time.Minute
+	walSummaryWaitPollInterval = time.Second
+)
+
+var walSummaryFilenameRegexp = regexp.MustCompile(`^([0-9A-F]{8})([0-9A-F]{16})([0-9A-F]{16})\.summary$`)
+
+// WalSummaryFile is the summary of the block references of WAL records in [StartLsn, EndLsn) on the timeline
+type WalSummaryFile struct {
+	Timeline uint32
+	StartLsn LSN
+	EndLsn   LSN
+	Name     string
+}
+
+func (summary WalSummaryFile) String() string {
+	return fmt.Sprintf("%08X%016X%016X.summary", summary.Timeline, uint64(summary.StartLsn), uint64(summary.EndLsn))
+}
+
+func ParseWalSummaryFilename(name string) (WalSummaryFile, error) {
+	match := walSummaryFilenameRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return WalSummaryFile{}, errors.Errorf("not a WAL summary file name: '%s'", name)
+	}
+	timeline, err := strconv.ParseUint(match[1], 16, 32)
+	if err != nil {
+		return WalSummaryFile{}, err
+	}
+	startLsn, err := strconv.ParseUint(match[2], 16, 64)
+	if err != nil {
+		return WalSummaryFile{}, err
+	}
+	endLsn, err := strconv.ParseUint(match[3], 16, 64)
+	if err != nil {
+		return WalSummaryFile{}, err
+	}
+	return WalSummaryFile{Timeline: uint32(timeline), StartLsn: LSN(startLsn), EndLsn: LSN(endLsn), Name: name}, nil
+}
+
+// AddLocationsFromWalSummary adds the blocks modified according to the WAL summary to the delta map.
+// The blocks of the truncated relations starting from the truncation point are all considered modified.
+func (deltaMap *PagedFileDeltaMap) AddLocationsFromWalSummary(summary io.Reader) error {
+	reader := newWalSummaryReader(summary)
+	var magic uint32
+	err := reader.read(&magic)
+	if err != nil {
+		return err
+	}
+	if magic != walSummaryMagic {
+		return errors.Errorf("invalid WAL summary magic number %x", magic)
+	}
+
+	entry := make([]uint32, walSummaryEntrySize/sizeofInt32)
+	for {
+		err = reader.read(entry)
+		if err != nil {
+			return err
+		}
+		relFileNode := walparser.RelFileNode{
+			SpcNode: walparser.Oid(entry[0]),
+			DBNode:  walparser.Oid(entry[1]),
+			RelNode: walparser.Oid(entry[2]),
+		}
+		forkNumber, limitBlock, chunkCount := entry[3], entry[4], entry[5]
+		if relFileNode == (walparser.RelFileNode{}) && forkNumber == 0 && limitBlock == 0 && chunkCount == 0 {
+			// the zeroed entry terminates the list
+			break
+		}
+
+		blocks, err := reader.readBlocks(chunkCount)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the blocks of relation %v", relFileNode)
+		}
+		// only the main fork files are backed up as increments
+		if forkNumber != walSummaryMainForkNumber {
+			continue
+		}
+		if limitBlock != walSummaryInvalidBlockNo {
+			blocks.AddRange(uint64(limitBlock), walSummaryInvalidBlockNo)
+		}
+		if bitmap, ok := (*deltaMap)[relFileNode]; ok {
+			bitmap.Or(blocks)
+		} else {
+			(*deltaMap)[relFileNode] = blocks
+		}
+	}
+	return reader.checkCrc()
+}
+
+// walSummaryReader reads the WAL summary and computes its CRC-32C
+type walSummaryReader struct {
+	reader io.Reader
+	crc    hash.Hash32
+}
+
+func newWalSummaryReader(summary io.Reader) *walSummaryReader {
+	crc := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	return &walSummaryReader{reader: io.TeeReader(bufio.NewReader(summary), crc), crc: crc}
+}
+
+func (reader *walSummaryReader) read(data interface{}) error {
+	err := binary.Read(reader.reader, binary.LittleEndian, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to read WAL summary")
+	}
+	return nil
+}
+
+// readBlocks reads the chunks of the relation fork. Each chunk holds the blocks either as the bitmap,
+// if it is full, or as the array of the block offsets in the chunk.
+func (reader *walSummaryReader) readBlocks(chunkCount uint32) (*roaring.Bitmap, error) {
+	blocks := roaring.New()
+	chunkUsage := make([]uint16, chunkCount)
+	err := reader.read(chunkUsage)
+	if err != nil {
+		return nil, err
+	}
+	for chunkNo, entryCount := range chunkUsage {
+		if entryCount == 0 {
+			continue
+		}
+		if entryCount > walSummaryMaxChunkEntries {
+			return nil, errors.Errorf("invalid number of entries %d in chunk %d", entryCount, chunkNo)
+		}
+		entries := make([]uint16, entryCount)
+		err = reader.read(entries)
+		if err != nil {
+			return nil, err
+		}
+		chunkStart := uint32(chunkNo) * walSummaryBlocksPerChunk
+		if entryCount < walSummaryMaxChunkEntries {
+			for _, offset := range entries {
+				blocks.Add(chunkStart + uint32(offset))
+			}
+			continue
+		}
+		for entryNo, bits := range entries {
+			for bit := uint32(0); bit < walSummaryBlocksPerEntry; bit++ {
+				if bits&(1<<bit) != 0 {
+					blocks.Add(chunkStart + uint32(entryNo)*walSummaryBlocksPerEntry + bit)
+				}
+			}
+		}
+	}
+	return blocks, nil
+}
+
+func (reader *walSummaryReader) checkCrc() error {
+	expected := reader.crc.Sum32()
+	var crc uint32
+	err := binary.Read(reader.reader, binary.LittleEndian, &crc)
+	if err != nil {
+		return errors.Wrap(err, "failed to read WAL summary checksum")
+	}
+	if crc != expected {
+		return errors.Errorf("WAL summary checksum mismatch: expected %x, found %x", expected, crc)
+	}
+	return nil
+}
+
+// timelineRange is the LSN range [start, end) which the timeline history passes on the timeline
+type timelineRange struct {
+	timeline uint32
+	start    LSN
+	end      LSN
+}
+
+func newTimelineRanges(timeline uint32, history []*TimelineHistoryRecord) []timelineRange {
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].lsn < history[j].lsn
+	})
+	ranges := make([]timelineRange, 0, len(history)+1)
+	start := LSN(0)
+	for _, record := range history {
+		ranges = append(ranges, timelineRange{timeline: record.timeline, start: start, end: record.lsn})
+		start = record.lsn
+	}
+	return append(ranges, timelineRange{timeline: timeline, start: start, end: math.MaxUint64})
+}
+
+// selectWalSummaries selects the summaries covering the LSN range [from, to) along the timeline history.
+// If the range isn't covered, it returns the LSN the range is covered up to.
+func selectWalSummaries(summaries []WalSummaryFile, ranges []timelineRange,
+	from, to LSN) (selected []WalSummaryFile, coveredUntil LSN, covered bool) {
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StartLsn < summaries[j].StartLsn
+	})
+	coveredUntil = from
+	for _, timelineRange := range ranges {
+		start, end := timelineRange.start, timelineRange.end
+		if start < from {
+			start = from
+		}
+		if end > to {
+			end = to
+		}
+		if start >= end {
+			continue
+		}
+		for _, summary := range summaries {
+			if summary.Timeline != timelineRange.timeline || summary.EndLsn <= start || summary.StartLsn >= end {
+				continue
+			}
+			if summary.StartLsn > coveredUntil {
+				return nil, coveredUntil, false
+			}
+			selected = append(selected, summary)
+			if summary.EndLsn > coveredUntil {
+				coveredUntil = summary.EndLsn
+			}
+		}
+		if coveredUntil < end {
+			return nil, coveredUntil, false
+		}
+		coveredUntil = end
+	}
+	return selected, coveredUntil, true
+}
+
+// getDeltaMapFromWalSummaries builds the delta map of the blocks modified in the LSN range
+// [firstUsedLSN, firstNotUsedLSN) from the WAL summaries in the cluster data directory.
+// The summarizer may lag behind the backup start, so the missing summaries are awaited for a while.
+func getDeltaMapFromWalSummaries(pgDataDirectory string, timeline uint32,
+	firstUsedLSN, firstNotUsedLSN LSN) (PagedFileDeltaMap, error) {
+	walDirectory := filepath.Join(pgDataDirectory, "pg_wal")
+	ranges, err := getLocalTimelineRanges(timeline, walDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	summariesDirectory := filepath.Join(walDirectory, WalSummariesDirectory)
+	deadline := time.Now().Add(walSummaryWaitTimeout)
+	var selected []WalSummaryFile
+	for {
+		summaries, err := listWalSummaries(summariesDirectory)
+		if err != nil {
+			return nil, err
+		}
+		var coveredUntil LSN
+		var covered bool
+		selected, coveredUntil, covered = selectWalSummaries(summaries, ranges, firstUsedLSN, firstNotUsedLSN)
+		if covered {
+			break
+		}
+		// the gap at the start of the range won't be filled by the summarizer
+		if coveredUntil == firstUsedLSN || time.Now().After(deadline) {
+			return nil, errors.Errorf("WAL summaries cover the range from %s to %s only up to %s",
+				firstUsedLSN, firstNotUsedLSN, coveredUntil)
+		}
+		tracelog.InfoLogger.Printf("Waiting for WAL summarizer to reach %s, summarized up to %s",
+			firstNotUsedLSN, coveredUntil)
+		time.Sleep(walSummaryWaitPollInterval)
+	}
+
+	deltaMap := NewPagedFileDeltaMap()
+	for _, summary := range selected {
+		err = addLocationsFromWalSummaryFile(deltaMap, filepath.Join(summariesDirectory, summary.Name))
+		if err != nil {
+			return nil, err
+		}
+		tracelog.DebugLogger.Printf("Successfully read WAL summary %s", summary.Name)
+	}
+	return deltaMap, nil
+}
+
+func getLocalTimelineRanges(timeline uint32, walDirectory string) ([]timelineRange, error) {
+	if timeline == 1 {
+		return newTimelineRanges(timeline, nil), nil
+	}
+	historyReadCloser, err := getLocalHistoryFile(timeline, walDirectory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the history file of timeline %d", timeline)
+	}
+	defer utility.LoggedClose(historyReadCloser, "")
+	history, err := parseHistoryFile(historyReadCloser)
+	if err != nil {
+		return nil, err
+	}
+	return newTimelineRanges(timeline, history), nil
+}
+
+func listWalSummaries(summariesDirectory string) ([]WalSummaryFile, error) {
+	entries, err := os.ReadDir(summariesDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list WAL summaries")
+	}
+	summaries := make([]WalSummaryFile, 0, len(entries))
+	for _, entry := range entries {
+		summary, err := ParseWalSummaryFilename(entry.Name())
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func addLocationsFromWalSummaryFile(deltaMap PagedFileDeltaMap, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open WAL summary '%s'", path)
+	}
+	defer utility.LoggedClose(file, "")
+	err = deltaMap.AddLocationsFromWalSummary(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read WAL summary '%s'", path)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/walparser"
+)
+
+type walSummaryTestEntry struct {
+	relFileNode walparser.RelFileNode
+	forkNumber  uint32
+	limitBlock  uint32
+	// chunks are the block offsets of each chunk, the full chunks are written as the bitmap
+	chunks [][]uint16
+}
+
+func newWalSummaryTestData(entries ...walSummaryTestEntry) []byte {
+	summary := new(bytes.Buffer)
+	write := func(data interface{}) {
+		_ = binary.Write(summary, binary.LittleEndian, data)
+	}
+	write(uint32(walSummaryMagic))
+	for _, entry := range entries {
+		write([]uint32{uint32(entry.relFileNode.SpcNode), uint32(entry.relFileNode.DBNode),
+			uint32(entry.relFileNode.RelNode), entry.forkNumber, entry.limitBlock, uint32(len(entry.chunks))})
+		chunks := make([][]uint16, len(entry.chunks))
+		for i, offsets := range entry.chunks {
+			chunks[i] = offsets
+			if len(offsets) >= walSummaryMaxChunkEntries {
+				chunks[i] = make([]uint16, walSummaryMaxChunkEntries)
+				for _, offset := range offsets {
+					chunks[i][offset/walSummaryBlocksPerEntry] |= 1 << (offset % walSummaryBlocksPerEntry)
+				}
+			}
+			write(uint16(len(chunks[i])))
+		}
+		for _, chunk := range chunks {
+			write(chunk)
+		}
+	}
+	write(make([]uint32, walSummaryEntrySize/sizeofInt32))
+	write(crc32.Checksum(summary.Bytes(), crc32.MakeTable(crc32.Castagnoli)))
+	return summary.Bytes()
+}
+
+func newWalSummaryTestNode(relNode walparser.Oid) walparser.RelFileNode {
+	return walparser.RelFileNode{SpcNode: DefaultSpcNode, DBNode: 5, RelNode: relNode}
+}
+
+func TestAddLocationsFromWalSummary(t *testing.T) {
+	fullChunk := make([]uint16, 0, walSummaryBlocksPerChunk/2)
+	for offset := 0; offset < walSummaryBlocksPerChunk; offset += 2 {
+		fullChunk = append(fullChunk, uint16(offset))
+	}
+	summary := newWalSummaryTestData(
+		walSummaryTestEntry{relFileNode: newWalSummaryTestNode(1), limitBlock: walSummaryInvalidBlockNo,
+			chunks: [][]uint16{{1, 7}, nil, {3}}},
+		walSummaryTestEntry{relFileNode: newWalSummaryTestNode(2), limitBlock: walSummaryInvalidBlockNo,
+			chunks: [][]uint16{fullChunk}},
+		// the relation is truncated to 10 blocks and extended again
+		walSummaryTestEntry{relFileNode: newWalSummaryTestNode(3), limitBlock: 10, chunks: [][]uint16{{2, 12}}},
+		walSummaryTestEntry{relFileNode: newWalSummaryTestNode(4), forkNumber: 1, limitBlock: walSummaryInvalidBlockNo,
+			chunks: [][]uint16{{0}}},
+	)
+
+	deltaMap := NewPagedFileDeltaMap()
+	deltaMap.AddLocationToDelta(walparser.BlockLocation{RelationFileNode: newWalSummaryTestNode(1), BlockNo: 100})
+	require.NoError(t, deltaMap.AddLocationsFromWalSummary(bytes.NewReader(summary)))
+
+	assert.Equal(t, []uint32{1, 7, 100, 2*walSummaryBlocksPerChunk + 3}, deltaMap[newWalSummaryTestNode(1)].ToArray())
+	assert.Equal(t, uint64(walSummaryBlocksPerChunk/2), deltaMap[newWalSummaryTestNode(2)].GetCardinality())
+	assert.True(t, deltaMap[newWalSummaryTestNode(2)].Contains(walSummaryBlocksPerChunk-2))
+	assert.False(t, deltaMap[newWalSummaryTestNode(2)].Contains(walSummaryBlocksPerChunk-1))
+	truncated := deltaMap[newWalSummaryTestNode(3)]
+	assert.True(t, truncated.Contains(2))
+	assert.False(t, truncated.Contains(9))
+	assert.True(t, truncated.Contains(10))
+	assert.True(t, truncated.Contains(uint32(BlocksInRelFile)))
+	assert.NotContains(t, deltaMap, newWalSummaryTestNode(4), "only the main fork is tracked")
+
+	bitmap, err := deltaMap.GetDeltaBitmapFor("/pgdata/base/5/1.1")
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{2*walSummaryBlocksPerChunk + 3 - uint32(BlocksInRelFile)}, bitmap.ToArray())
+}
+
+func TestAddLocationsFromWalSummary_Invalid(t *testing.T) {
+	summary := newWalSummaryTestData(walSummaryTestEntry{relFileNode: newWalSummaryTestNode(1),
+		limitBlock: walSummaryInvalidBlockNo, chunks: [][]uint16{{1}}})
+
+	corrupted := bytes.Clone(summary)
+	corrupted[len(corrupted)-1] ^= 0xFF
+	deltaMap := NewPagedFileDeltaMap()
+	assert.Error(t, deltaMap.AddLocationsFromWalSummary(bytes.NewReader(corrupted)))
+
+	assert.Error(t, deltaMap.AddLocationsFromWalSummary(bytes.NewReader(summary[:len(summary)-8])))
+	assert.Error(t, deltaMap.AddLocationsFromWalSummary(bytes.NewReader(summary[4:])))
+}
+
+func TestParseWalSummaryFilename(t *testing.T) {
+	summary, err := ParseWalSummaryFilename("0000000200000001000000280000000100000030.summary")
+	require.NoError(t, err)
+	assert.Equal(t, WalSummaryFile{Timeline: 2, StartLsn: 0x100000028, EndLsn: 0x100000030,
+		Name: "0000000200000001000000280000000100000030.summary"}, summary)
+	assert.Equal(t, summary.Name, summary.String())
+
+	_, err = ParseWalSummaryFilename("000000010000000000000001")
+	assert.Error(t, err)
+}
+
+func putWalSummaryTestFile(t *testing.T, summariesDirectory string, timeline uint32, start, end LSN, relNode walparser.Oid) {
+	summary := newWalSummaryTestData(walSummaryTestEntry{relFileNode: newWalSummaryTestNode(relNode),
+		limitBlock: walSummaryInvalidBlockNo, chunks: [][]uint16{{uint16(relNode)}}})
+	name := WalSummaryFile{Timeline: timeline, StartLsn: start, EndLsn: end}.String()
+	require.NoError(t, os.WriteFile(filepath.Join(summariesDirectory, name), summary, 0600))
+}
+
+func TestGetDeltaMapFromWalSummaries(t *testing.T) {
+	dataDirectory := t.TempDir()
+	walDirectory := filepath.Join(dataDirectory, "pg_wal")
+	summariesDirectory := filepath.Join(walDirectory, WalSummariesDirectory)
+	require.NoError(t, os.MkdirAll(summariesDirectory, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(walDirectory, "00000002.history"),
+		[]byte("1\t0/5000100\tno recovery target specified\n"), 0600))
+
+	putWalSummaryTestFile(t, summariesDirectory, 1, 0x1000000, 0x3000000, 1)
+	putWalSummaryTestFile(t, summariesDirectory, 1, 0x3000000, 0x5000100, 2)
+	// the part of the old timeline after the switch is abandoned
+	putWalSummaryTestFile(t, summariesDirectory, 1, 0x5000100, 0x6000000, 3)
+	putWalSummaryTestFile(t, summariesDirectory, 2, 0x5000100, 0x7000000, 4)
+	putWalSummaryTestFile(t, summariesDirectory, 2, 0x7000000, 0x9000000, 5)
+
+	deltaMap, err := getDeltaMapFromWalSummaries(dataDirectory, 2, 0x2000000, 0x8000000)
+	require.NoError(t, err)
+	assert.Len(t, deltaMap, 4)
+	for _, relNode := range []walparser.Oid{1, 2, 4, 5} {
+		assert.Equal(t, roaring.BitmapOf(uint32(relNode)), deltaMap[newWalSummaryTestNode(relNode)])
+	}
+
+	// the summaries before the start of the range are already removed
+	_, err = getDeltaMapFromWalSummaries(dataDirectory, 2, 0x800000, 0x8000000)
+	assert.Error(t, err)
+
+	_, err = getDeltaMapFromWalSummaries(dataDirectory, 3, 0x2000000, 0x8000000)
+	assert.Error(t, err, "the history file is missing")
+}
+
+func TestSelectWalSummaries(t *testing.T) {
+	summaries := []WalSummaryFile{
+		{Timeline: 1, StartLsn: 0x1000, EndLsn: 0x2000},
+		{Timeline: 1, StartLsn: 0x3000, EndLsn: 0x4000},
+	}
+	ranges := newTimelineRanges(1, nil)
+
+	selected, _, covered := selectWalSummaries(summaries, ranges, 0x1800, 0x2000)
+	assert.True(t, covered)
+	assert.Equal(t, summaries[:1], selected)
+
+	_, coveredUntil, covered := selectWalSummaries(summaries, ranges, 0x1000, 0x3800)
+	assert.False(t, covered)
+	assert.Equal(t, LSN(0x2000), coveredUntil)
+
+	_, coveredUntil, covered = selectWalSummaries(summaries, ranges, 0x3000, 0x5000)
+	assert.False(t, covered)
+	assert.Equal(t, LSN(0x4000), coveredUntil)
+}
@@ -5,14 +5,20 @@ import (
 	"os/exec"
 
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
-func HandleBackupFetch(ctx context.Context, folder storage.Folder, backupName string, restoreCmd *exec.Cmd) error {
+func HandleBackupFetch(ctx context.Context, folder storage.Folder, backupName string, restoreCmd *exec.Cmd) (err error) {
 	backup, err := internal.GetBackupByName(backupName, utility.BaseBackupPath, folder)
 	if err != nil {
 		return err
 	}
+	notification, err := notify.Start(notify.BackupFetch, notify.WithBackupName(backup.Name))
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
 	return internal.StreamBackupToCommandStdin(restoreCmd, backup)
 }
@@ -1,19 +1,36 @@
 package redis
 
 import (
+	"fmt"
 	"os/exec"
 
-	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	"github.com/wal-g/wal-g/internal/notify"
 	"github.com/wal-g/wal-g/utility"
 )
 
-func HandleBackupPush(uploader internal.Uploader, backupCmd *exec.Cmd, metaConstructor internal.MetaConstructor) error {
+func HandleBackupPush(uploader internal.Uploader, backupCmd *exec.Cmd, metaConstructor internal.MetaConstructor) (err error) {
+	notification, err := notify.Start(notify.BackupPush)
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+
 	stdout, err := utility.StartCommandWithStdoutPipe(backupCmd)
-	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to start backup create command: %w", err)
+	}
 
 	redisUploader := archive.NewRedisStorageUploader(uploader)
 
-	return redisUploader.UploadBackup(stdout, backupCmd, metaConstructor)
+	err = redisUploader.UploadBackup(stdout, backupCmd, metaConstructor)
+	if err != nil {
+		return err
+	}
+	if backup, ok := metaConstructor.MetaInfo().(*archive.Backup); ok {
+		notification.SetBackupName(backup.BackupName)
+		notification.SetSizes(backup.BackupSize, backup.DataSize)
+	}
+	return nil
 }
@@ -15,6 +15,7 @@ import (
 type PurgeSettings struct {
 	retainCount  *int
 	retainAfter  *time.Time
+	gfsPolicy    *internal.GfsPolicy
 	purgeGarbage bool
 	dryRun       bool
 }
@@ -35,6 +36,13 @@ func PurgeRetainCount(retainCount int) PurgeOption {
 	}
 }
 
+// PurgeGfs ...
+func PurgeGfs(policy internal.GfsPolicy) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.gfsPolicy = &policy
+	}
+}
+
 // PurgeGarbage ...
 func PurgeGarbage(purgeGarbage bool) PurgeOption {
 	return func(args *PurgeSettings) {
@@ -73,6 +81,10 @@ func HandlePurge(backupsPath string, setters ...PurgeOption) error {
 		return err
 	}
 
+	if err := internal.DeleteUnreferencedDedupChunks(backupFolder, !opts.dryRun); err != nil {
+		return err
+	}
+
 	if opts.purgeGarbage {
 		tracelog.InfoLogger.Printf("Garbage prefixes in backups folder: %v", garbage)
 		if !opts.dryRun {
@@ -102,7 +114,7 @@ func HandleBackupsDelete(backupTimes []internal.BackupTime,
 	timedBackup := archive.RedisModelToTimedBackup(backups)
 
 	internal.SortTimedBackup(timedBackup)
-	purgeBackups, retainBackups, err := internal.SplitPurgingBackups(timedBackup, opts.retainCount, opts.retainAfter)
+	purgeBackups, retainBackups, err := internal.SplitPurgingTimedBackups(timedBackup, opts.retainCount, opts.retainAfter, opts.gfsPolicy)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,144 @@
+package dedup
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const (
+	// MinAvgChunkSize is the smallest supported average chunk size
+	MinAvgChunkSize = 1 << 10
+	// MaxAvgChunkSize is the largest supported average chunk size
+	MaxAvgChunkSize = 1 << 26
+)
+
+// gearTable maps every byte to a pseudo-random 64-bit value for the rolling gear hash.
+// It is generated with a fixed seed because the chunk boundaries (and so the deduplication
+// ratio between the existing and the new backups) depend on it, so it must never change.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x5741_4c2d_4744_4544) // "WAL-GDED"
+	for i := range table {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// Chunker splits a stream into content-defined chunks using the FastCDC algorithm
+// with normalized chunking: a chunk boundary depends only on the bytes around it,
+// so an insertion or a deletion in the stream changes only the adjacent chunks.
+type Chunker struct {
+	reader  io.Reader
+	minSize int
+	avgSize int
+	maxSize int
+	maskS   uint64
+	maskL   uint64
+
+	buf   []byte
+	start int
+	end   int
+	eof   bool
+}
+
+// NewChunker creates a chunker producing chunks of avgChunkSize bytes on average.
+// The chunk sizes lie between avgChunkSize/4 and avgChunkSize*4.
+func NewChunker(reader io.Reader, avgChunkSize int) (*Chunker, error) {
+	if avgChunkSize < MinAvgChunkSize || avgChunkSize > MaxAvgChunkSize {
+		return nil, fmt.Errorf("average chunk size must be between %d and %d bytes, got %d",
+			MinAvgChunkSize, MaxAvgChunkSize, avgChunkSize)
+	}
+	avgBits := bits.Len(uint(avgChunkSize)) - 1
+	maxSize := avgChunkSize * 4
+	return &Chunker{
+		reader:  reader,
+		minSize: avgChunkSize / 4,
+		avgSize: avgChunkSize,
+		maxSize: maxSize,
+		// the harder mask is used before the average size is reached and the easier one after it,
+		// which keeps the chunk sizes close to the average
+		maskS: topBitsMask(avgBits + 2),
+		maskL: topBitsMask(avgBits - 2),
+		buf:   make([]byte, maxSize*2),
+	}, nil
+}
+
+// Next returns the next chunk of the stream or io.EOF if the stream is over.
+// The returned slice is only valid until the next call.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.end-c.start < c.maxSize && !c.eof {
+		if err := c.fill(); err != nil {
+			return nil, err
+		}
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	size := c.cut(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+size]
+	c.start += size
+	return chunk, nil
+}
+
+func (c *Chunker) fill() error {
+	copy(c.buf, c.buf[c.start:c.end])
+	c.end -= c.start
+	c.start = 0
+
+	for c.end < len(c.buf) {
+		n, err := c.reader.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut returns the length of the chunk at the beginning of data
+func (c *Chunker) cut(data []byte) int {
+	size := len(data)
+	if size <= c.minSize {
+		return size
+	}
+	if size > c.maxSize {
+		size = c.maxSize
+	}
+	normalSize := c.avgSize
+	if size < normalSize {
+		normalSize = size
+	}
+
+	var fingerprint uint64
+	i := c.minSize
+	for ; i < normalSize; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+		if fingerprint&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < size; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+		if fingerprint&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return size
+}
+
+// topBitsMask returns a mask with the n most significant bits set. The gear hash shifts
+// to the left, so its top bits depend on the widest window of the recent bytes.
+func topBitsMask(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
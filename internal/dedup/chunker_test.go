@@ -0,0 +1,83 @@
+package dedup_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal-g/wal-g/internal/dedup"
+)
+
+const testAvgChunkSize = 4 << 10
+
+func randomBytes(seed int64, size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func splitIntoChunks(t *testing.T, data []byte) [][]byte {
+	chunker, err := dedup.NewChunker(bytes.NewReader(data), testAvgChunkSize)
+	require.NoError(t, err)
+
+	var chunks [][]byte
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			return chunks
+		}
+		require.NoError(t, err)
+		chunks = append(chunks, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker_ReassemblesStream(t *testing.T) {
+	data := randomBytes(1, 1<<20)
+
+	chunks := splitIntoChunks(t, data)
+
+	assert.Equal(t, data, bytes.Join(chunks, nil))
+	for i, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), testAvgChunkSize*4)
+		if i != len(chunks)-1 {
+			assert.GreaterOrEqual(t, len(chunk), testAvgChunkSize/4)
+		}
+	}
+}
+
+func TestChunker_EmptyStream(t *testing.T) {
+	chunks := splitIntoChunks(t, nil)
+
+	assert.Empty(t, chunks)
+}
+
+func TestChunker_InsertionChangesFewChunks(t *testing.T) {
+	data := randomBytes(2, 1<<20)
+	modified := append(append(bytes.Clone(data[:len(data)/2]), []byte("inserted bytes")...), data[len(data)/2:]...)
+
+	original := make(map[[sha256.Size]byte]bool)
+	for _, chunk := range splitIntoChunks(t, data) {
+		original[sha256.Sum256(chunk)] = true
+	}
+	modifiedChunks := splitIntoChunks(t, modified)
+	changed := 0
+	for _, chunk := range modifiedChunks {
+		if !original[sha256.Sum256(chunk)] {
+			changed++
+		}
+	}
+
+	assert.LessOrEqual(t, changed, 2)
+	assert.Greater(t, len(modifiedChunks), 100)
+}
+
+func TestNewChunker_InvalidSize(t *testing.T) {
+	_, err := dedup.NewChunker(bytes.NewReader(nil), 10)
+
+	assert.Error(t, err)
+}
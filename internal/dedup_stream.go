@@ -0,0 +1,351 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/dedup"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	// DedupChunksPath is the chunk store of the deduplicated stream backups, relative to the backups folder
+	DedupChunksPath       = "dedup_chunks/"
+	DedupManifestFileName = "dedup_manifest.json"
+
+	// DedupChunkGracePeriod protects the recently uploaded chunks from the garbage collection,
+	// since they may belong to a backup which is being uploaded and has no manifest yet
+	DedupChunkGracePeriod = 24 * time.Hour
+	// DedupChunkRefreshAge is the age of the stored chunk after which the backup reusing it uploads it again,
+	// so the chunk is protected by the grace period until the manifest of the backup is uploaded
+	DedupChunkRefreshAge = DedupChunkGracePeriod / 2
+)
+
+// DedupStreamManifest lists the chunks of a deduplicated stream backup in the order of their appearance in the stream
+type DedupStreamManifest struct {
+	Chunks []DedupChunk `json:"chunks"`
+}
+
+type DedupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// DedupStreamUploader splits the streams into content-defined chunks and uploads only the chunks
+// which are missing in the chunk store, so the similar backups share most of their data
+type DedupStreamUploader struct {
+	Uploader
+	avgChunkSize int
+	concurrency  int
+	rawSize      *int64
+}
+
+var _ Uploader = &DedupStreamUploader{}
+
+func NewDedupStreamUploader(uploader Uploader, avgChunkSize int, concurrency int) *DedupStreamUploader {
+	return &DedupStreamUploader{
+		Uploader:     uploader,
+		avgChunkSize: avgChunkSize,
+		concurrency:  concurrency,
+		rawSize:      new(int64),
+	}
+}
+
+// PushStream chunks the stream, uploads the new chunks and the manifest of the backup
+func (uploader *DedupStreamUploader) PushStream(ctx context.Context, stream io.Reader) (string, error) {
+	backupName := StreamPrefix + utility.TimeNowCrossPlatformUTC().Format(utility.BackupTimeFormat)
+	extension := uploader.Compression().FileExtension()
+
+	storedChunks, err := listDedupChunks(uploader.Folder().GetSubFolder(DedupChunksPath))
+	if err != nil {
+		return backupName, fmt.Errorf("failed to list the chunk store: %w", err)
+	}
+	chunker, err := dedup.NewChunker(stream, uploader.avgChunkSize)
+	if err != nil {
+		return backupName, err
+	}
+
+	var manifest DedupStreamManifest
+	var uploadedChunks, refreshedChunks int
+	refreshBefore := utility.TimeNowCrossPlatformUTC().Add(-DedupChunkRefreshAge)
+	errGroup, ctx := errgroup.WithContext(ctx)
+	errGroup.SetLimit(uploader.concurrency)
+	for ctx.Err() == nil {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = errGroup.Wait()
+			return backupName, fmt.Errorf("failed to read the stream: %w", err)
+		}
+		atomic.AddInt64(uploader.rawSize, int64(len(chunk)))
+
+		hash := sha256.Sum256(chunk)
+		dedupChunk := DedupChunk{Hash: hex.EncodeToString(hash[:]), Size: int64(len(chunk))}
+		manifest.Chunks = append(manifest.Chunks, dedupChunk)
+
+		chunkName := GetDedupChunkName(dedupChunk.Hash, extension)
+		lastModified, stored := storedChunks[chunkName]
+		if stored && lastModified.After(refreshBefore) {
+			continue
+		}
+		// the old chunk may be unreferenced, so it is uploaded again not to be collected before the manifest is uploaded
+		if stored {
+			refreshedChunks++
+		} else {
+			uploadedChunks++
+		}
+		storedChunks[chunkName] = utility.TimeNowCrossPlatformUTC()
+
+		content := bytes.Clone(chunk)
+		errGroup.Go(func() error {
+			return uploader.PushStreamToDestination(ctx, bytes.NewReader(content), path.Join(DedupChunksPath, chunkName))
+		})
+	}
+	if err := errGroup.Wait(); err != nil {
+		tracelog.WarningLogger.Printf("Failed to upload chunks of backup: %v", err)
+		return backupName, err
+	}
+	tracelog.InfoLogger.Printf("Stream is split into %d chunks, %d of them are uploaded, %d are refreshed, %d are already stored",
+		len(manifest.Chunks), uploadedChunks, refreshedChunks, len(manifest.Chunks)-uploadedChunks-refreshedChunks)
+
+	err = UploadDto(uploader.Folder(), manifest, DedupManifestNameFromBackup(backupName))
+	if err != nil {
+		return backupName, fmt.Errorf("failed to upload the manifest: %w", err)
+	}
+	meta := BackupStreamMetadata{
+		Type:        DedupStreamBackup,
+		Compression: extension,
+	}
+	err = UploadBackupStreamMetadata(uploader, meta, backupName)
+
+	return backupName, err
+}
+
+// RawDataSize returns the size of the whole stream, including the chunks which were not uploaded
+func (uploader *DedupStreamUploader) RawDataSize() (int64, error) {
+	return atomic.LoadInt64(uploader.rawSize), nil
+}
+
+func (uploader *DedupStreamUploader) Clone() Uploader {
+	return &DedupStreamUploader{
+		Uploader:     uploader.Uploader.Clone(),
+		avgChunkSize: uploader.avgChunkSize,
+		concurrency:  uploader.concurrency,
+		rawSize:      uploader.rawSize,
+	}
+}
+
+// DownloadAndDecompressDedupStream reassembles the deduplicated stream from its chunks and writes it to writeCloser
+func DownloadAndDecompressDedupStream(backup Backup, extension string, writeCloser io.WriteCloser, concurrency int) error {
+	defer utility.LoggedClose(writeCloser, "")
+
+	decompressor := compression.FindDecompressor(extension)
+	if decompressor == nil {
+		return fmt.Errorf("decompressor for file type '%s' not found", extension)
+	}
+	var manifest DedupStreamManifest
+	err := FetchDto(backup.Folder, &manifest, DedupManifestNameFromBackup(backup.Name))
+	if err != nil {
+		return err
+	}
+
+	type chunkResult struct {
+		content []byte
+		err     error
+	}
+	// chunks are downloaded concurrently, but are written in the order of the manifest
+	results := make(chan chan chunkResult, concurrency)
+	done := make(chan struct{})
+	defer close(done)
+	chunkFolder := NewFolderReader(backup.Folder.GetSubFolder(DedupChunksPath))
+	go func() {
+		defer close(results)
+		for _, chunk := range manifest.Chunks {
+			result := make(chan chunkResult, 1)
+			select {
+			case results <- result:
+			case <-done:
+				return
+			}
+			go func(chunk DedupChunk) {
+				content, err := downloadDedupChunk(chunkFolder, chunk, decompressor)
+				result <- chunkResult{content, err}
+			}(chunk)
+		}
+	}()
+
+	for result := range results {
+		chunk := <-result
+		if chunk.err != nil {
+			return chunk.err
+		}
+		_, err = writeCloser.Write(chunk.content)
+		if err != nil {
+			return fmt.Errorf("failed to write the stream: %w", err)
+		}
+	}
+	return nil
+}
+
+func downloadDedupChunk(folder StorageFolderReader, chunk DedupChunk, decompressor compression.Decompressor) ([]byte, error) {
+	chunkName := GetDedupChunkName(chunk.Hash, decompressor.FileExtension())
+	archiveReader, err := folder.ReadObject(chunkName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chunk %s: %w", chunkName, err)
+	}
+	defer utility.LoggedClose(archiveReader, "")
+
+	decompressedReader, err := DecompressDecryptBytes(archiveReader, decompressor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress/decrypt chunk %s: %w", chunkName, err)
+	}
+	defer utility.LoggedClose(decompressedReader, "")
+
+	content, err := io.ReadAll(decompressedReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress/decrypt chunk %s: %w", chunkName, err)
+	}
+	hash := sha256.Sum256(content)
+	if int64(len(content)) != chunk.Size || hex.EncodeToString(hash[:]) != chunk.Hash {
+		return nil, fmt.Errorf("chunk %s is corrupted: expected %d bytes with hash %s, got %d bytes with hash %x",
+			chunkName, chunk.Size, chunk.Hash, len(content), hash)
+	}
+	return content, nil
+}
+
+// DeleteUnreferencedDedupChunks deletes the chunks from the chunk store of the backups folder
+// that aren't referenced by any of the deduplicated stream backups in it
+func DeleteUnreferencedDedupChunks(backupsFolder storage.Folder, confirm bool) error {
+	// the references are collected before listing the chunks, so a chunk refreshed by the backup
+	// being uploaded meanwhile is seen as recent
+	referencedChunks, err := getReferencedDedupChunks(backupsFolder)
+	if err != nil {
+		return err
+	}
+	chunkFolder := backupsFolder.GetSubFolder(DedupChunksPath)
+	chunks, err := storage.ListFolderRecursively(chunkFolder)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	gracePeriodStart := utility.TimeNowCrossPlatformUTC().Add(-DedupChunkGracePeriod)
+	garbage := make([]string, 0)
+	for _, chunk := range chunks {
+		if referencedChunks[getDedupChunkHash(chunk.GetName())] || chunk.GetLastModified().After(gracePeriodStart) {
+			continue
+		}
+		garbage = append(garbage, chunk.GetName())
+	}
+	tracelog.InfoLogger.Printf("Unreferenced chunks in the chunk store: %d of %d\n", len(garbage), len(chunks))
+	if len(garbage) == 0 {
+		return nil
+	}
+	if !confirm {
+		tracelog.InfoLogger.Println("Dry run, no chunks were deleted")
+		return nil
+	}
+
+	groups := make([][]string, 0, len(garbage))
+	for _, chunk := range garbage {
+		groups = append(groups, []string{chunk})
+	}
+	return DeleteObjectGroups(chunkFolder, groups)
+}
+
+// getReferencedDedupChunks returns the hashes of the chunks referenced by the manifests. The backups which
+// have the manifest, but have no metadata yet, are being uploaded right now, so their chunks are referenced too.
+func getReferencedDedupChunks(backupsFolder storage.Folder) (map[string]bool, error) {
+	_, backupFolders, err := backupsFolder.ListFolder()
+	if err != nil {
+		return nil, err
+	}
+
+	referencedChunks := make(map[string]bool)
+	for _, folder := range backupFolders {
+		backupName := utility.StripPrefixName(folder.GetPath())
+		if IsDedupChunkStore(backupName) {
+			continue
+		}
+		var metadata BackupStreamMetadata
+		err := FetchDto(backupsFolder, &metadata, StreamMetadataNameFromBackup(backupName))
+		_, metadataMissing := errors.Cause(err).(storage.ObjectNotFoundError)
+		if err != nil && !metadataMissing {
+			return nil, err
+		}
+		if !metadataMissing && metadata.Type != DedupStreamBackup {
+			continue
+		}
+
+		var manifest DedupStreamManifest
+		err = FetchDto(backupsFolder, &manifest, DedupManifestNameFromBackup(backupName))
+		if _, ok := errors.Cause(err).(storage.ObjectNotFoundError); ok && metadataMissing {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the manifest of %s: %w", backupName, err)
+		}
+		for _, chunk := range manifest.Chunks {
+			referencedChunks[chunk.Hash] = true
+		}
+	}
+	return referencedChunks, nil
+}
+
+// listDedupChunks returns the modification times of the stored chunks by their names
+func listDedupChunks(chunkFolder storage.Folder) (map[string]time.Time, error) {
+	chunks, err := storage.ListFolderRecursively(chunkFolder)
+	if err != nil {
+		return nil, err
+	}
+	chunkNames := make(map[string]time.Time, len(chunks))
+	for _, chunk := range chunks {
+		chunkNames[chunk.GetName()] = chunk.GetLastModified()
+	}
+	return chunkNames, nil
+}
+
+// GetDedupChunkName returns the chunk path relative to the chunk store.
+// The chunks are spread over subfolders by the hash prefix to keep the folders small.
+func GetDedupChunkName(hash string, extension string) string {
+	return hash[:2] + "/" + hash + "." + extension
+}
+
+// getDedupChunkHash extracts the hash from the chunk path relative to the chunk store
+func getDedupChunkHash(chunkName string) string {
+	hash := path.Base(chunkName)
+	if i := strings.IndexByte(hash, '.'); i >= 0 {
+		hash = hash[:i]
+	}
+	return hash
+}
+
+func DedupManifestNameFromBackup(backupName string) string {
+	return backupName + "/" + DedupManifestFileName
+}
+
+// IsDedupChunkStore checks if the object or folder path points to the chunk store or inside it
+func IsDedupChunkStore(objectPath string) bool {
+	chunkStore := strings.TrimSuffix(DedupChunksPath, "/")
+	return objectPath == chunkStore ||
+		strings.HasPrefix(objectPath, DedupChunksPath) ||
+		strings.Contains(objectPath, "/"+DedupChunksPath)
+}
@@ -0,0 +1,130 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+const dedupTestAvgChunkSize = 4 << 10
+
+func pushDedupStream(t *testing.T, folder storage.Folder, data []byte) (string, *internal.DedupStreamUploader) {
+	uploader := internal.NewDedupStreamUploader(
+		internal.NewRegularUploader(compression.Compressors[lz4.AlgorithmName], folder),
+		dedupTestAvgChunkSize,
+		4,
+	)
+	backupName, err := uploader.PushStream(context.Background(), bytes.NewReader(data))
+	require.NoError(t, err)
+	return backupName, uploader
+}
+
+func fetchDedupStream(t *testing.T, folder storage.Folder, backupName string) []byte {
+	backup := internal.Backup{Name: backupName, Folder: folder}
+	fetcher, err := internal.GetBackupStreamFetcher(backup)
+	require.NoError(t, err)
+
+	writer := newTestWriter()
+	err = fetcher(backup, writer)
+	require.NoError(t, err)
+	return writer.Result
+}
+
+func countDedupChunks(t *testing.T, folder storage.Folder) int {
+	chunks, err := storage.ListFolderRecursively(folder.GetSubFolder(internal.DedupChunksPath))
+	require.NoError(t, err)
+	return len(chunks)
+}
+
+func TestDedupStream_PushAndFetch(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	data := getByteSampleArray(1 << 20)
+
+	backupName, uploader := pushDedupStream(t, folder, data)
+
+	assert.Equal(t, data, fetchDedupStream(t, folder, backupName))
+	rawSize, err := uploader.RawDataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), rawSize)
+}
+
+func TestDedupStream_SimilarStreamsShareChunks(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	data := getByteSampleArray(1 << 20)
+	modified := append(append(bytes.Clone(data[:len(data)/2]), []byte("some new data")...), data[len(data)/2:]...)
+
+	firstBackup, _ := pushDedupStream(t, folder, data)
+	chunksAfterFirst := countDedupChunks(t, folder)
+	time.Sleep(time.Second) // backup names have the second precision
+	secondBackup, _ := pushDedupStream(t, folder, modified)
+
+	assert.LessOrEqual(t, countDedupChunks(t, folder)-chunksAfterFirst, 2)
+	assert.Equal(t, data, fetchDedupStream(t, folder, firstBackup))
+	assert.Equal(t, modified, fetchDedupStream(t, folder, secondBackup))
+}
+
+func TestDeleteUnreferencedDedupChunks(t *testing.T) {
+	now := time.Now()
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time { return now })))
+	now = now.Add(-2 * internal.DedupChunkGracePeriod)
+	firstBackup, _ := pushDedupStream(t, folder, getByteSampleArray(1<<20))
+	time.Sleep(time.Second)
+	secondBackup, _ := pushDedupStream(t, folder, getByteSampleArray(1<<19))
+	chunksBefore := countDedupChunks(t, folder)
+
+	err := internal.DeleteBackups(folder, []string{firstBackup})
+	require.NoError(t, err)
+	err = internal.DeleteUnreferencedDedupChunks(folder, false)
+	require.NoError(t, err)
+	assert.Equal(t, chunksBefore, countDedupChunks(t, folder))
+
+	err = internal.DeleteUnreferencedDedupChunks(folder, true)
+	require.NoError(t, err)
+	assert.Less(t, countDedupChunks(t, folder), chunksBefore)
+	assert.Equal(t, getByteSampleArray(1<<19), fetchDedupStream(t, folder, secondBackup))
+}
+
+func TestDedupStream_RefreshesOldChunks(t *testing.T) {
+	now := time.Now()
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time { return now })))
+	data := getByteSampleArray(1 << 20)
+	now = now.Add(-2 * internal.DedupChunkGracePeriod)
+	firstBackup, _ := pushDedupStream(t, folder, data)
+	require.NoError(t, internal.DeleteBackups(folder, []string{firstBackup}))
+	chunksBefore := countDedupChunks(t, folder)
+
+	// the unreferenced chunks are reused by the next backup, which has uploaded neither the manifest
+	// nor the metadata when the garbage is collected
+	now = time.Now()
+	time.Sleep(time.Second)
+	secondBackup, _ := pushDedupStream(t, folder, data)
+	require.NoError(t, folder.DeleteObjects([]string{
+		internal.StreamMetadataNameFromBackup(secondBackup),
+		internal.DedupManifestNameFromBackup(secondBackup),
+	}))
+	require.NoError(t, internal.DeleteUnreferencedDedupChunks(folder, true))
+
+	assert.Equal(t, chunksBefore, countDedupChunks(t, folder))
+}
+
+func TestDeleteUnreferencedDedupChunks_KeepsBackupInProgress(t *testing.T) {
+	now := time.Now().Add(-2 * internal.DedupChunkGracePeriod)
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time { return now })))
+	backupName, _ := pushDedupStream(t, folder, getByteSampleArray(1<<20))
+	chunksBefore := countDedupChunks(t, folder)
+
+	// the manifest is uploaded, but the metadata is not yet
+	require.NoError(t, folder.DeleteObjects([]string{internal.StreamMetadataNameFromBackup(backupName)}))
+	require.NoError(t, internal.DeleteUnreferencedDedupChunks(folder, true))
+	assert.Equal(t, chunksBefore, countDedupChunks(t, folder))
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strconv"
@@ -12,6 +13,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/notify"
+	"github.com/wal-g/wal-g/internal/printlist"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
@@ -41,13 +44,30 @@ const (
   target base_0000000100000000000000C9_D_0000000100000000000000C4	delete delta backup and all dependant delta backups 
   target FIND_FULL base_0000000100000000000000C9_D_0000000100000000000000C4	delete delta backup and all delta backups with the same base backup` //nolint:lll
 
-	DeleteEverythingUsageExample = "everything [FORCE]"
-	DeleteRetainUsageExample     = "retain [FULL|FIND_FULL] backup_count"
-	DeleteBeforeUsageExample     = "before [FIND_FULL] backup_name|timestamp"
-	DeleteTargetUsageExample     = "target [FIND_FULL] backup_name | --target-user-data <data>"
+	DeleteGfsExamples = `  gfs --daily 7 --weekly 4 --monthly 12 --yearly 3	keep the newest backup of each of the last 7 days, 4 weeks, 12 months and 3 years
+  gfs --weekly 8 --confirm	keep the newest backup of each of the last 8 weeks and delete the others` //nolint:lll
+
+	DeleteRetainWindowExamples = `  retain-window 14d            keep everything needed to restore to any point of the last 14 days
+  retain-window 36h --confirm  keep everything needed to restore to any point of the last 36 hours and delete the rest`
+
+	DeleteEverythingUsageExample   = "everything [FORCE]"
+	DeleteRetainUsageExample       = "retain [FULL|FIND_FULL] backup_count"
+	DeleteBeforeUsageExample       = "before [FIND_FULL] backup_name|timestamp"
+	DeleteTargetUsageExample       = "target [FIND_FULL] backup_name | --target-user-data <data>"
+	DeleteGfsUsageExample          = "gfs [--daily N] [--weekly N] [--monthly N] [--yearly N]"
+	DeleteRetainWindowUsageExample = "retain-window window"
 
 	DeleteTargetUserDataFlag        = "target-user-data"
 	DeleteTargetUserDataDescription = "delete storage backup which has the specified user data"
+
+	DeleteGfsDailyFlag          = "daily"
+	DeleteGfsDailyDescription   = "Keep the newest backup of each of the last N days"
+	DeleteGfsWeeklyFlag         = "weekly"
+	DeleteGfsWeeklyDescription  = "Keep the newest backup of each of the last N ISO weeks"
+	DeleteGfsMonthlyFlag        = "monthly"
+	DeleteGfsMonthlyDescription = "Keep the newest backup of each of the last N months"
+	DeleteGfsYearlyFlag         = "yearly"
+	DeleteGfsYearlyDescription  = "Keep the newest backup of each of the last N years"
 )
 
 var StringModifiers = []string{"FULL", "FIND_FULL"}
@@ -121,7 +141,9 @@ func (h *DeleteHandler) HandleDeleteBefore(args []string, confirmed bool) {
 		os.Exit(0)
 	}
 
-	err = h.DeleteBeforeTarget(target, confirmed)
+	err = RunNotifiedDelete("before", confirmed, func() error {
+		return h.DeleteBeforeTarget(target, confirmed)
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
@@ -136,7 +158,9 @@ func (h *DeleteHandler) HandleDeleteRetain(args []string, confirmed bool) {
 		tracelog.InfoLogger.Printf("No backup found for deletion")
 		os.Exit(0)
 	}
-	err = h.DeleteBeforeTarget(target, confirmed)
+	err = RunNotifiedDelete("retain", confirmed, func() error {
+		return h.DeleteBeforeTarget(target, confirmed)
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
@@ -153,7 +177,9 @@ func (h *DeleteHandler) HandleDeleteRetainAfter(args []string, confirmed bool) {
 		os.Exit(0)
 	}
 
-	err = h.DeleteBeforeTarget(target, confirmed)
+	err = RunNotifiedDelete("retain", confirmed, func() error {
+		return h.DeleteBeforeTarget(target, confirmed)
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
@@ -168,7 +194,9 @@ func (h *DeleteHandler) HandleDeleteTarget(targetSelector BackupSelector, confir
 	}
 
 	folderFilter := func(name string) bool { return true }
-	err = h.DeleteTarget(target, confirmed, findFull, folderFilter)
+	err = RunNotifiedDelete("target", confirmed, func() error {
+		return h.DeleteTarget(target, confirmed, findFull, folderFilter)
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
@@ -188,6 +216,122 @@ func (h *DeleteHandler) HandleDeleteEverything(args []string, permanentBackups [
 	h.DeleteEverything(confirmed)
 }
 
+func (h *DeleteHandler) HandleDeleteGfs(policy GfsPolicy, confirmed bool) {
+	decisions := h.FindGfsRetention(policy)
+	err := WriteGfsDecisions(decisions, os.Stdout)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	err = RunNotifiedDelete("gfs", confirmed, func() error {
+		return h.DeleteGfs(decisions, confirmed, func(string) bool { return true })
+	})
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// RunNotifiedDelete reports the lifecycle events of the confirmed deletion, the dry runs are not reported
+func RunNotifiedDelete(mode string, confirmed bool, deleteFunc func() error) (err error) {
+	if !confirmed {
+		return deleteFunc()
+	}
+	notification, err := notify.Start(notify.Delete, notify.WithDetail("mode", mode))
+	if err != nil {
+		return err
+	}
+	defer func() { notification.Finish(err) }()
+	return deleteFunc()
+}
+
+// FindGfsRetention decides which backups are kept by the GFS retention policy.
+// The bases of the kept delta backups and the permanent backups are kept as well.
+func (h *DeleteHandler) FindGfsRetention(policy GfsPolicy) []GfsDecision {
+	candidates := make([]gfsCandidate, 0, len(h.backups))
+	for _, backup := range h.backups {
+		candidate := gfsCandidate{
+			name:      backup.GetBackupName(),
+			time:      backup.GetBackupTime(),
+			permanent: h.isPermanent(rootPathBackupObject{backup}),
+		}
+		if !backup.IsFullBackup() {
+			candidate.incrementFrom = backup.GetIncrementFromName()
+		}
+		candidates = append(candidates, candidate)
+	}
+	return selectGfsBackups(candidates, policy)
+}
+
+// FindGfsTargets returns the backups which are not kept by the GFS decisions
+// and the oldest kept backup, everything older than it is not needed anymore
+func (h *DeleteHandler) FindGfsTargets(decisions []GfsDecision) ([]BackupObject, BackupObject, error) {
+	isKept := make(map[string]bool, len(decisions))
+	for _, decision := range decisions {
+		isKept[decision.BackupName] = decision.IsKept()
+	}
+
+	var targets []BackupObject
+	var oldestKept BackupObject
+	isTarget := make(map[string]bool)
+	for _, backup := range h.backups {
+		name := backup.GetBackupName()
+		if isKept[name] {
+			if oldestKept == nil || h.less(backup, oldestKept) {
+				oldestKept = backup
+			}
+			continue
+		}
+		// deleting a backup deletes all of its increments, so none of them may be kept
+		for _, dependant := range h.findDependantBackups(backup) {
+			if isKept[dependant.GetBackupName()] {
+				return nil, nil, utility.NewForbiddenActionError(fmt.Sprintf(
+					"%s is kept, but its increment base %s is not", dependant.GetBackupName(), name))
+			}
+		}
+		if !isTarget[name] {
+			isTarget[name] = true
+			targets = append(targets, backup)
+		}
+	}
+	return targets, oldestKept, nil
+}
+
+func (h *DeleteHandler) DeleteGfs(decisions []GfsDecision, confirmed bool, folderFilter func(name string) bool) error {
+	targets, oldestKept, err := h.FindGfsTargets(decisions)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		return nil
+	}
+
+	backupNamesToDelete := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		backupNamesToDelete[target.GetBackupName()] = true
+	}
+	isKept := make(map[string]bool, len(decisions))
+	for _, decision := range decisions {
+		isKept[decision.BackupName] = decision.IsKept()
+	}
+	tracelog.InfoLogger.Println("Start delete")
+
+	err = DeleteObjectsWhere(h.Folder, confirmed, func(object storage.Object) bool {
+		// the chunks of the deduplicated stream backups are shared, they are garbage-collected separately
+		if IsDedupChunkStore(object.GetName()) || h.isPermanent(object) {
+			return false
+		}
+		if strings.HasPrefix(object.GetName(), utility.BaseBackupPath) {
+			backupName := utility.StripLeftmostBackupName(strings.TrimPrefix(object.GetName(), utility.BaseBackupPath))
+			if backupNamesToDelete[backupName] || isKept[backupName] {
+				return backupNamesToDelete[backupName]
+			}
+		}
+		// the objects older than the oldest kept backup, i.e. WAL or binlogs, are not needed to restore any of them
+		return oldestKept != nil && h.less(object, oldestKept)
+	}, folderFilter)
+	if err != nil {
+		return err
+	}
+	return DeleteUnreferencedDedupChunks(h.Folder.GetSubFolder(utility.BaseBackupPath), confirmed)
+}
+
 // TODO: unit tests
 func (h *DeleteHandler) FindTargetBefore(beforeStr string, modifier int) (BackupObject, error) {
 	timeLine, err := time.Parse(time.RFC3339, beforeStr)
@@ -235,6 +379,33 @@ func (h *DeleteHandler) FindTargetRetain(retentionCount, modifier int) (BackupOb
 	return target, nil
 }
 
+// FindTargetRetainWindow finds the newest full backup which is needed to restore to any point after windowStart:
+// it is the base of the newest backup finished before the window start.
+// The finish time is taken from the backup metadata, since the sentinel may be rewritten later, e.g. by backup-mark.
+// Returns nil if there is no backup finished before the window start.
+func (h *DeleteHandler) FindTargetRetainWindow(windowStart time.Time, metaFetcher GenericMetaFetcher) (BackupObject, error) {
+	var fetchErr error
+	potentialTarget, err := findTarget(h.backups, h.greater, func(object BackupObject) bool {
+		meta, err := metaFetcher.Fetch(object.GetBackupName(), h.Folder.GetSubFolder(utility.BaseBackupPath))
+		if err != nil {
+			fetchErr = errors.Wrapf(err, "failed to fetch the metadata of backup %s", object.GetBackupName())
+			return true
+		}
+		// the backup can't be restored to a point before its finish
+		return !meta.FinishTime.After(windowStart)
+	})
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err == errNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.FindTargetBeforeName(potentialTarget.GetName(), FindFullDeleteModifier)
+}
+
 // TODO: unit tests
 func (h *DeleteHandler) FindTargetByName(bname string) (BackupObject, error) {
 	return findTarget(h.backups, h.greater, func(object BackupObject) bool {
@@ -348,7 +519,9 @@ func (h *DeleteHandler) FindTargetRetainAfterTime(retentionCount int, timeLine t
 func (h *DeleteHandler) DeleteEverything(confirmed bool) {
 	filter := func(object storage.Object) bool { return true }
 	folderFilter := func(path string) bool { return true }
-	err := DeleteObjectsWhere(h.Folder, confirmed, filter, folderFilter)
+	err := RunNotifiedDelete("everything", confirmed, func() error {
+		return DeleteObjectsWhere(h.Folder, confirmed, filter, folderFilter)
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
@@ -371,9 +544,15 @@ func (h *DeleteHandler) DeleteBeforeTargetWhere(
 	}
 	tracelog.InfoLogger.Println("Start delete")
 
-	return DeleteObjectsWhere(h.Folder, confirmed, func(object storage.Object) bool {
-		return objSelector(object) && h.less(object, target) && !h.isPermanent(object)
+	err := DeleteObjectsWhere(h.Folder, confirmed, func(object storage.Object) bool {
+		// the chunks of the deduplicated stream backups are shared, they are garbage-collected separately
+		return objSelector(object) && !IsDedupChunkStore(object.GetName()) &&
+			h.less(object, target) && !h.isPermanent(object)
 	}, folderFilter)
+	if err != nil {
+		return err
+	}
+	return DeleteUnreferencedDedupChunks(h.Folder.GetSubFolder(utility.BaseBackupPath), confirmed)
 }
 
 func (h *DeleteHandler) DeleteTarget(target BackupObject, confirmed, findFull bool,
@@ -396,10 +575,14 @@ func (h *DeleteHandler) DeleteTarget(target BackupObject, confirmed, findFull bo
 		backupNamesToDelete[bTarget.GetBackupName()] = true
 	}
 
-	return DeleteObjectsWhere(h.Folder.GetSubFolder(utility.BaseBackupPath),
+	err := DeleteObjectsWhere(h.Folder.GetSubFolder(utility.BaseBackupPath),
 		confirmed, func(object storage.Object) bool {
 			return backupNamesToDelete[utility.StripLeftmostBackupName(object.GetName())] && !h.isPermanent(object)
 		}, folderFilter)
+	if err != nil {
+		return err
+	}
+	return DeleteUnreferencedDedupChunks(h.Folder.GetSubFolder(utility.BaseBackupPath), confirmed)
 }
 
 // TODO: unit tests
@@ -460,6 +643,210 @@ func (h *DeleteHandler) findDependantBackups(target BackupObject) []BackupObject
 	return dependantBackups
 }
 
+// GfsPolicy is the grandfather-father-son retention policy. For each of the last Daily days,
+// Weekly ISO weeks, Monthly months and Yearly years which have backups the newest backup is kept.
+type GfsPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+}
+
+type gfsPeriod struct {
+	name  string
+	count int
+	key   func(backupTime time.Time) string
+}
+
+func (policy GfsPolicy) periods() []gfsPeriod {
+	return []gfsPeriod{
+		{"daily", policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", policy.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{"yearly", policy.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+}
+
+// GfsDecision tells whether the backup is kept by the GFS retention policy and why
+type GfsDecision struct {
+	BackupName string
+	BackupTime time.Time
+	Reasons    []string
+}
+
+func (decision GfsDecision) IsKept() bool {
+	return len(decision.Reasons) > 0
+}
+
+func (decision GfsDecision) PrintableFields() []printlist.TableField {
+	prettyTime := PrettyFormatTime(decision.BackupTime)
+	action := "delete"
+	if decision.IsKept() {
+		action = "keep"
+	}
+	return []printlist.TableField{
+		{
+			Name:       "backup_name",
+			PrettyName: "Backup name",
+			Value:      decision.BackupName,
+		},
+		{
+			Name:        "time",
+			PrettyName:  "Time",
+			Value:       FormatTime(decision.BackupTime),
+			PrettyValue: &prettyTime,
+		},
+		{
+			Name:       "action",
+			PrettyName: "Action",
+			Value:      action,
+		},
+		{
+			Name:       "reason",
+			PrettyName: "Reason",
+			Value:      strings.Join(decision.Reasons, ", "),
+		},
+	}
+}
+
+// WriteGfsDecisions prints the table of the kept and deleted backups
+func WriteGfsDecisions(decisions []GfsDecision, output io.Writer) error {
+	entities := make([]printlist.Entity, len(decisions))
+	for i := range decisions {
+		entities[i] = decisions[i]
+	}
+	return printlist.List(entities, output, true, false)
+}
+
+// FindTimedBackupsGfsRetention decides which backups are kept by the GFS retention policy
+// for the databases without the delta backups
+func FindTimedBackupsGfsRetention(backups []TimedBackup, policy GfsPolicy) []GfsDecision {
+	candidates := make([]gfsCandidate, 0, len(backups))
+	for _, backup := range backups {
+		candidates = append(candidates, gfsCandidate{
+			name:      backup.Name(),
+			time:      backup.StartTime(),
+			permanent: backup.IsPermanent(),
+		})
+	}
+	return selectGfsBackups(candidates, policy)
+}
+
+// SplitGfsDecisions partitions backups to delete and retain according to the GFS decisions
+func SplitGfsDecisions(decisions []GfsDecision) (purge, retain map[string]bool) {
+	purge = make(map[string]bool)
+	retain = make(map[string]bool)
+	for _, decision := range decisions {
+		if decision.IsKept() {
+			retain[decision.BackupName] = true
+		} else {
+			purge[decision.BackupName] = true
+		}
+	}
+	return purge, retain
+}
+
+// SplitPurgingTimedBackups partitions backups to delete and retain by the GFS policy if it is set,
+// otherwise by the retain count and the retain time, the GFS decisions are printed to stdout
+func SplitPurgingTimedBackups(backups []TimedBackup, retainCount *int, retainAfter *time.Time,
+	gfsPolicy *GfsPolicy) (purge, retain map[string]bool, err error) {
+	if gfsPolicy == nil {
+		return SplitPurgingBackups(backups, retainCount, retainAfter)
+	}
+	decisions := FindTimedBackupsGfsRetention(backups, *gfsPolicy)
+	if err := WriteGfsDecisions(decisions, os.Stdout); err != nil {
+		return nil, nil, err
+	}
+	purge, retain = SplitGfsDecisions(decisions)
+	return purge, retain, nil
+}
+
+// rootPathBackupObject names the backup sentinel by its path from the storage root
+// the way the storage objects checked for permanence are named
+type rootPathBackupObject struct {
+	BackupObject
+}
+
+func (o rootPathBackupObject) GetName() string {
+	return utility.BaseBackupPath + o.BackupObject.GetName()
+}
+
+type gfsCandidate struct {
+	name          string
+	time          time.Time
+	permanent     bool
+	incrementFrom string
+}
+
+// selectGfsBackups returns the decisions for the unique backups ordered from the newest to the oldest
+func selectGfsBackups(candidates []gfsCandidate, policy GfsPolicy) []GfsDecision {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].time.After(candidates[j].time)
+	})
+	decisions := make([]GfsDecision, 0, len(candidates))
+	decisionByName := make(map[string]*GfsDecision, len(candidates))
+	uniqueCandidates := make([]gfsCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if _, ok := decisionByName[candidate.name]; ok {
+			continue
+		}
+		decisions = append(decisions, GfsDecision{BackupName: candidate.name, BackupTime: candidate.time})
+		decisionByName[candidate.name] = nil
+		uniqueCandidates = append(uniqueCandidates, candidate)
+	}
+	for i := range decisions {
+		decisionByName[decisions[i].BackupName] = &decisions[i]
+	}
+
+	for _, period := range policy.periods() {
+		seenKeys := make(map[string]bool)
+		for _, candidate := range uniqueCandidates {
+			key := period.key(candidate.time.UTC())
+			if seenKeys[key] {
+				continue
+			}
+			if len(seenKeys) >= period.count {
+				break
+			}
+			seenKeys[key] = true
+			decision := decisionByName[candidate.name]
+			decision.Reasons = append(decision.Reasons, period.name+" "+key)
+		}
+	}
+
+	for _, candidate := range uniqueCandidates {
+		if candidate.permanent {
+			decision := decisionByName[candidate.name]
+			decision.Reasons = append(decision.Reasons, "permanent")
+		}
+	}
+
+	// the delta backups cannot be restored without their increment chain
+	incrementFromByName := make(map[string]string, len(uniqueCandidates))
+	for _, candidate := range uniqueCandidates {
+		incrementFromByName[candidate.name] = candidate.incrementFrom
+	}
+	for _, candidate := range uniqueCandidates {
+		if !decisionByName[candidate.name].IsKept() || candidate.incrementFrom == "" {
+			continue
+		}
+		for base := candidate.incrementFrom; base != ""; base = incrementFromByName[base] {
+			decision, ok := decisionByName[base]
+			if !ok {
+				tracelog.WarningLogger.Printf("Increment base %s of %s is not found\n", base, candidate.name)
+				break
+			}
+			if !decision.IsKept() {
+				decision.Reasons = append(decision.Reasons, "increment base of "+candidate.name)
+			}
+		}
+	}
+	return decisions
+}
+
 func DeleteObjectsWhere(
 	folder storage.Folder,
 	confirm bool,
@@ -470,26 +857,104 @@ func DeleteObjectsWhere(
 	if err != nil {
 		return err
 	}
-	filteredRelativePaths := make([]string, 0)
+	// all the objects of a backup are deleted together, so a backup is never deleted partially
+	var groups [][]string
+	groupByKey := make(map[string]int)
 	tracelog.InfoLogger.Println("Objects in folder:")
 	for _, object := range relativePathObjects {
 		if objFilter(object) {
 			tracelog.InfoLogger.Printf("\twill be deleted: %s, from storage: %s\n", object.GetName(), multistorage.GetStorage(object))
-			filteredRelativePaths = append(filteredRelativePaths, object.GetName())
+			key := deletionGroupKey(folder.GetPath(), object.GetName())
+			i, ok := groupByKey[key]
+			if !ok {
+				i = len(groups)
+				groupByKey[key] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], object.GetName())
 		} else {
 			tracelog.DebugLogger.Printf("\tskipped: %s, in storage: %s\n", object.GetName(), multistorage.GetStorage(object))
 		}
 	}
-	if len(filteredRelativePaths) == 0 {
+	if len(groups) == 0 {
 		return nil
 	}
 	if confirm {
-		return folder.DeleteObjects(filteredRelativePaths)
+		return DeleteObjectGroups(folder, groups)
 	}
 	tracelog.InfoLogger.Println("Dry run, nothing were deleted")
 	return nil
 }
 
+// deletionGroupKey returns the backup the object belongs to. The objects which don't belong to any backup,
+// e.g. WAL, binlogs or the chunks of the deduplicated backups, are deleted on their own.
+func deletionGroupKey(folderPath, objectPath string) string {
+	if IsDedupChunkStore(objectPath) {
+		return objectPath
+	}
+	if i := strings.LastIndex(objectPath, utility.BaseBackupPath); i >= 0 {
+		backupPath := objectPath[i+len(utility.BaseBackupPath):]
+		return objectPath[:i] + utility.BaseBackupPath + utility.StripLeftmostBackupName(backupPath)
+	}
+	if strings.HasSuffix(folderPath, utility.BaseBackupPath) {
+		return utility.StripLeftmostBackupName(objectPath)
+	}
+	return objectPath
+}
+
+// DeleteObjectGroups deletes the groups of objects, e.g. the files of the backups. If some objects are locked in
+// the storage, their groups are kept completely and reported, while all the other groups are deleted.
+func DeleteObjectGroups(folder storage.Folder, groups [][]string) error {
+	for len(groups) > 0 {
+		var paths []string
+		for _, group := range groups {
+			paths = append(paths, group...)
+		}
+		err := folder.DeleteObjects(paths)
+		var lockedErr storage.ObjectsLockedError
+		if !errors.As(err, &lockedErr) {
+			return err
+		}
+		reportLockedObjects(lockedErr.LockedObjects)
+
+		isLocked := make(map[string]bool, len(lockedErr.LockedObjects))
+		for _, object := range lockedErr.LockedObjects {
+			isLocked[object.Path] = true
+		}
+		unlockedGroups := make([][]string, 0, len(groups))
+		for _, group := range groups {
+			if !containsLockedObject(group, isLocked) {
+				unlockedGroups = append(unlockedGroups, group)
+			}
+		}
+		if len(unlockedGroups) == len(groups) {
+			// the storage reported objects we didn't ask to delete
+			return err
+		}
+		groups = unlockedGroups
+	}
+	return nil
+}
+
+func containsLockedObject(group []string, isLocked map[string]bool) bool {
+	for _, path := range group {
+		if isLocked[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// reportLockedObjects prints the objects that can't be deleted since they are still locked
+func reportLockedObjects(lockedObjects []storage.LockedObject) {
+	tracelog.WarningLogger.Printf("%d objects are locked in the storage, the backups they belong to are kept:\n",
+		len(lockedObjects))
+	for _, object := range lockedObjects {
+		tracelog.WarningLogger.Printf("\tlocked: %s (%s)\n", object.Path, object.Reason)
+	}
+	tracelog.WarningLogger.Println("Run the deletion again after the locks expire")
+}
+
 func findTarget(objects []BackupObject,
 	compare func(object1, object2 storage.Object) bool,
 	isTarget func(object BackupObject) bool) (BackupObject, error) {
@@ -662,6 +1127,70 @@ func DeleteRetainAfterArgsValidator(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func DeleteRetainWindowArgsValidator(cmd *cobra.Command, args []string) error {
+	err := cobra.ExactArgs(1)(cmd, args)
+	if err != nil {
+		return err
+	}
+	_, err = ParseRetainWindow(args[0])
+	return err
+}
+
+// ParseRetainWindow parses the retention window of the "delete retain-window" command,
+// in addition to the time.ParseDuration units it accepts the number of days ("14d") and weeks ("2w")
+func ParseRetainWindow(windowStr string) (time.Duration, error) {
+	var window time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(windowStr, "d") || strings.HasSuffix(windowStr, "w"):
+		var count int
+		count, err = strconv.Atoi(windowStr[:len(windowStr)-1])
+		window = time.Duration(count) * 24 * time.Hour
+		if strings.HasSuffix(windowStr, "w") {
+			window *= 7
+		}
+	default:
+		window, err = time.ParseDuration(windowStr)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "expected to get a window like 14d, 2w or 36h, but got: '%s'", windowStr)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("retention window must be positive, but got: '%s'", windowStr)
+	}
+	return window, nil
+}
+
+// AddDeleteGfsFlags registers the GFS retention policy flags of the "delete gfs" command
+func AddDeleteGfsFlags(cmd *cobra.Command, policy *GfsPolicy) {
+	cmd.Flags().IntVar(&policy.Daily, DeleteGfsDailyFlag, 0, DeleteGfsDailyDescription)
+	cmd.Flags().IntVar(&policy.Weekly, DeleteGfsWeeklyFlag, 0, DeleteGfsWeeklyDescription)
+	cmd.Flags().IntVar(&policy.Monthly, DeleteGfsMonthlyFlag, 0, DeleteGfsMonthlyDescription)
+	cmd.Flags().IntVar(&policy.Yearly, DeleteGfsYearlyFlag, 0, DeleteGfsYearlyDescription)
+}
+
+func DeleteGfsArgsValidator(cmd *cobra.Command, args []string) error {
+	err := cobra.NoArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	keepsBackups := false
+	for _, flag := range []string{DeleteGfsDailyFlag, DeleteGfsWeeklyFlag, DeleteGfsMonthlyFlag, DeleteGfsYearlyFlag} {
+		count, err := cmd.Flags().GetInt(flag)
+		if err != nil {
+			return err
+		}
+		if count < 0 {
+			return fmt.Errorf("--%s cannot be negative", flag)
+		}
+		keepsBackups = keepsBackups || count > 0
+	}
+	if !keepsBackups {
+		return fmt.Errorf("cannot retain less than one backup. Check out delete everything")
+	}
+	return nil
+}
+
 func DeleteArgsValidator(args, stringModifiers []string, minArgs int, maxArgs int) error {
 	if len(args) < minArgs || len(args) > maxArgs {
 		return fmt.Errorf("accepts between %d and %d arg(s), received %d", minArgs, maxArgs, len(args))
@@ -2,12 +2,18 @@ package internal
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/memory/mock"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
 )
 
 func CreateMockStorageFolder() storage.Folder {
@@ -60,3 +66,333 @@ func TestDeleteOldObjectsWithFilter(t *testing.T) {
 	assert.Equal(t, 1, len(savedObjects))
 	assert.Equal(t, expectedOnlyOneSavedObjectName, savedObjects[0].GetName())
 }
+
+// testGfsBackupObject is the backup finished at the sentinel modification time and started 2 hours earlier
+type testGfsBackupObject struct {
+	storage.Object
+	incrementFrom string
+}
+
+func (o testGfsBackupObject) GetBackupName() string {
+	return utility.StripRightmostBackupName(o.GetName())
+}
+
+func (o testGfsBackupObject) GetBaseBackupName() string {
+	return o.GetBackupName()
+}
+
+func (o testGfsBackupObject) GetIncrementFromName() string {
+	return o.incrementFrom
+}
+
+func (o testGfsBackupObject) IsFullBackup() bool {
+	return o.incrementFrom == ""
+}
+
+func (o testGfsBackupObject) GetBackupTime() time.Time {
+	return o.GetLastModified().Add(-2 * time.Hour)
+}
+
+func (o testGfsBackupObject) GetStorage() string {
+	return "default"
+}
+
+// newTestGfsBackups creates base_0 ... base_6, where base_4 and base_5 are the increments of base_3
+func newTestGfsBackups() []BackupObject {
+	backups := []struct {
+		time          string
+		incrementFrom string
+	}{
+		{"2023-01-01T10:00:00Z", ""},
+		{"2023-06-15T10:00:00Z", ""},
+		{"2023-12-20T10:00:00Z", ""},
+		{"2024-01-05T10:00:00Z", ""},
+		{"2024-01-06T10:00:00Z", "base_3"},
+		{"2024-01-06T20:00:00Z", "base_4"},
+		{"2024-01-08T10:00:00Z", ""},
+	}
+	backupObjects := make([]BackupObject, 0, len(backups))
+	for i, backup := range backups {
+		backupTime, _ := time.Parse(time.RFC3339, backup.time)
+		name := "base_" + strconv.Itoa(i) + utility.SentinelSuffix
+		backupObjects = append(backupObjects, testGfsBackupObject{
+			Object:        storage.NewLocalObject(name, backupTime, 0),
+			incrementFrom: backup.incrementFrom,
+		})
+	}
+	return backupObjects
+}
+
+// gfsTestObjectPosition orders base_N backups as N*10 and the WAL segments by their number
+func gfsTestObjectPosition(object storage.Object) int {
+	name := strings.TrimPrefix(object.GetName(), utility.BaseBackupPath)
+	if strings.HasPrefix(name, utility.WalPath) {
+		position, _ := strconv.Atoi(strings.TrimPrefix(name, utility.WalPath))
+		return position
+	}
+	position, _ := strconv.Atoi(strings.TrimPrefix(utility.StripLeftmostBackupName(name), "base_"))
+	return position * 10
+}
+
+func newTestGfsDeleteHandler(folder storage.Folder, permanentBackup string) *DeleteHandler {
+	return NewDeleteHandler(folder, newTestGfsBackups(),
+		func(object1, object2 storage.Object) bool {
+			return gfsTestObjectPosition(object1) < gfsTestObjectPosition(object2)
+		},
+		IsPermanentFunc(func(object storage.Object) bool {
+			return permanentBackup != "" &&
+				utility.StripLeftmostBackupName(strings.TrimPrefix(object.GetName(), utility.BaseBackupPath)) == permanentBackup
+		}))
+}
+
+func TestFindGfsRetention(t *testing.T) {
+	deleteHandler := newTestGfsDeleteHandler(memory.NewFolder("in_memory/", memory.NewKVS()), "base_1")
+
+	decisions := deleteHandler.FindGfsRetention(GfsPolicy{Daily: 2, Weekly: 2, Monthly: 2, Yearly: 2})
+	reasons := make(map[string][]string)
+	names := make([]string, 0, len(decisions))
+	for _, decision := range decisions {
+		reasons[decision.BackupName] = decision.Reasons
+		names = append(names, decision.BackupName)
+	}
+	assert.Equal(t, []string{"base_6", "base_5", "base_4", "base_3", "base_2", "base_1", "base_0"}, names)
+	assert.Equal(t, map[string][]string{
+		"base_6": {"daily 2024-01-08", "weekly 2024-W02", "monthly 2024-01", "yearly 2024"},
+		"base_5": {"daily 2024-01-06", "weekly 2024-W01"},
+		"base_4": {"increment base of base_5"},
+		"base_3": {"increment base of base_5"},
+		"base_2": {"monthly 2023-12", "yearly 2023"},
+		"base_1": {"permanent"},
+		"base_0": nil,
+	}, reasons)
+	assert.False(t, decisions[len(decisions)-1].IsKept())
+
+	var output bytes.Buffer
+	assert.NoError(t, WriteGfsDecisions(decisions, &output))
+	assert.Contains(t, output.String(), "increment base of base_5")
+}
+
+func TestDeleteGfs(t *testing.T) {
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	for i := 0; i <= 6; i++ {
+		backupName := "base_" + strconv.Itoa(i)
+		assert.NoError(t, folder.PutObject(utility.BaseBackupPath+backupName+utility.SentinelSuffix, &bytes.Buffer{}))
+		assert.NoError(t, folder.PutObject(utility.BaseBackupPath+backupName+"/tar_partitions/part_1.tar", &bytes.Buffer{}))
+		assert.NoError(t, folder.PutObject(utility.WalPath+strconv.Itoa(i*10+5), &bytes.Buffer{}))
+	}
+	deleteHandler := newTestGfsDeleteHandler(folder, "base_1")
+
+	// the increments of base_3 are deleted along with it, but the WAL after the oldest kept backup remains
+	decisions := deleteHandler.FindGfsRetention(GfsPolicy{Daily: 1, Yearly: 2})
+	targets, oldestKept, err := deleteHandler.FindGfsTargets(decisions)
+	assert.NoError(t, err)
+	assert.Len(t, targets, 4)
+	assert.Equal(t, "base_1", oldestKept.GetBackupName())
+
+	assert.NoError(t, deleteHandler.DeleteGfs(decisions, true, func(string) bool { return true }))
+	objects, err := storage.ListFolderRecursively(folder)
+	assert.NoError(t, err)
+	remaining := make([]string, 0, len(objects))
+	for _, object := range objects {
+		remaining = append(remaining, object.GetName())
+	}
+	assert.ElementsMatch(t, []string{
+		"basebackups_005/base_1_backup_stop_sentinel.json", "basebackups_005/base_1/tar_partitions/part_1.tar",
+		"basebackups_005/base_2_backup_stop_sentinel.json", "basebackups_005/base_2/tar_partitions/part_1.tar",
+		"basebackups_005/base_6_backup_stop_sentinel.json", "basebackups_005/base_6/tar_partitions/part_1.tar",
+		"wal_005/15", "wal_005/25", "wal_005/35", "wal_005/45", "wal_005/55", "wal_005/65",
+	}, remaining)
+}
+
+type testTimedBackup struct {
+	name      string
+	startTime time.Time
+	permanent bool
+}
+
+func (backup testTimedBackup) Name() string         { return backup.name }
+func (backup testTimedBackup) StartTime() time.Time { return backup.startTime }
+func (backup testTimedBackup) IsPermanent() bool    { return backup.permanent }
+
+func TestSplitPurgingTimedBackups(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	var backups []TimedBackup
+	for i := 0; i < 5; i++ {
+		backups = append(backups, testTimedBackup{
+			name:      "stream_" + strconv.Itoa(i),
+			startTime: now.Add(-time.Duration(i) * 24 * time.Hour),
+			permanent: i == 4,
+		})
+	}
+
+	purge, retain, err := SplitPurgingTimedBackups(backups, nil, nil, &GfsPolicy{Daily: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"stream_2": true, "stream_3": true}, purge)
+	assert.Equal(t, map[string]bool{"stream_0": true, "stream_1": true, "stream_4": true}, retain)
+
+	retainCount := 3
+	purge, retain, err = SplitPurgingTimedBackups(backups, &retainCount, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"stream_3": true}, purge)
+	assert.Equal(t, map[string]bool{"stream_0": true, "stream_1": true, "stream_2": true, "stream_4": true}, retain)
+}
+
+func TestDeleteGfsArgsValidator(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddDeleteGfsFlags(cmd, &GfsPolicy{})
+	assert.Error(t, DeleteGfsArgsValidator(cmd, nil))
+
+	assert.NoError(t, cmd.Flags().Set(DeleteGfsWeeklyFlag, "4"))
+	assert.NoError(t, DeleteGfsArgsValidator(cmd, nil))
+	assert.Error(t, DeleteGfsArgsValidator(cmd, []string{"4"}))
+
+	assert.NoError(t, cmd.Flags().Set(DeleteGfsDailyFlag, "-1"))
+	assert.Error(t, DeleteGfsArgsValidator(cmd, nil))
+}
+
+// testFinishTimeFetcher returns the finish times of newTestGfsBackups as they are stored in the sentinels
+type testFinishTimeFetcher map[string]time.Time
+
+func (f testFinishTimeFetcher) Fetch(backupName string, _ storage.Folder) (GenericMetadata, error) {
+	finishTime, ok := f[backupName]
+	if !ok {
+		return GenericMetadata{}, errors.Errorf("no sentinel of %s", backupName)
+	}
+	return GenericMetadata{BackupName: backupName, FinishTime: finishTime}, nil
+}
+
+func TestFindTargetRetainWindow(t *testing.T) {
+	deleteHandler := newTestGfsDeleteHandler(memory.NewFolder("in_memory/", memory.NewKVS()), "")
+	metaFetcher := testFinishTimeFetcher{}
+	for _, backup := range deleteHandler.backups {
+		metaFetcher[backup.GetBackupName()] = backup.GetLastModified()
+	}
+	// the sentinel of base_6 is rewritten by backup-mark after the backup is finished
+	base6 := deleteHandler.backups[6].(testGfsBackupObject)
+	base6.Object = storage.NewLocalObject(base6.GetName(), base6.GetLastModified().Add(48*time.Hour), 0)
+	deleteHandler.backups[6] = base6
+
+	findTarget := func(windowStart string) BackupObject {
+		windowStartTime, err := time.Parse(time.RFC3339, windowStart)
+		assert.NoError(t, err)
+		target, err := deleteHandler.FindTargetRetainWindow(windowStartTime, metaFetcher)
+		assert.NoError(t, err)
+		return target
+	}
+
+	assert.Equal(t, "base_6", findTarget("2024-02-01T00:00:00Z").GetBackupName())
+	assert.Equal(t, "base_6", findTarget("2024-01-09T00:00:00Z").GetBackupName())
+	assert.Equal(t, "base_3", findTarget("2024-01-05T10:00:00Z").GetBackupName())
+	// the newest backup before the window is a delta, so its base is needed
+	assert.Equal(t, "base_3", findTarget("2024-01-07T00:00:00Z").GetBackupName())
+	// base_6 is started before the window, but it can't be restored to the window start since it's finished later
+	assert.Equal(t, "base_3", findTarget("2024-01-08T09:00:00Z").GetBackupName())
+	assert.Nil(t, findTarget("2022-12-31T00:00:00Z"))
+
+	delete(metaFetcher, "base_6")
+	_, err := deleteHandler.FindTargetRetainWindow(time.Now(), metaFetcher)
+	assert.Error(t, err)
+}
+
+func TestParseRetainWindow(t *testing.T) {
+	for windowStr, expected := range map[string]time.Duration{
+		"14d": 14 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"90m": 90 * time.Minute,
+	} {
+		window, err := ParseRetainWindow(windowStr)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, window, windowStr)
+	}
+	for _, windowStr := range []string{"", "d", "14", "-1d", "0d", "fourteen days"} {
+		_, err := ParseRetainWindow(windowStr)
+		assert.Error(t, err, windowStr)
+	}
+}
+
+// newLockingFolder emulates the storage with Object Lock: nothing is deleted if some of the objects are locked
+func newLockingFolder(kvs *memory.KVS, lockedPaths ...string) *mock.Folder {
+	folder := mock.NewFolder(memory.NewFolder("", kvs))
+	folder.DeleteObjectsMock = func(objectRelativePaths []string) error {
+		var lockedObjects []storage.LockedObject
+		for _, path := range objectRelativePaths {
+			for _, lockedPath := range lockedPaths {
+				if path == lockedPath {
+					lockedObjects = append(lockedObjects, storage.LockedObject{Path: path, Reason: "legal hold"})
+				}
+			}
+		}
+		if len(lockedObjects) > 0 {
+			return storage.NewObjectsLockedError(lockedObjects)
+		}
+		return folder.MemFolder.DeleteObjects(objectRelativePaths)
+	}
+	return folder
+}
+
+func TestDeleteObjectsWhere_KeepsLockedBackup(t *testing.T) {
+	kvs := memory.NewKVS()
+	folder := newLockingFolder(kvs, "basebackups_005/base_000000010000000000000002/tar_partitions/part_2.tar.lz4")
+	lockedBackup := []string{
+		"basebackups_005/base_000000010000000000000002_backup_stop_sentinel.json",
+		"basebackups_005/base_000000010000000000000002/metadata.json",
+		"basebackups_005/base_000000010000000000000002/tar_partitions/part_1.tar.lz4",
+		"basebackups_005/base_000000010000000000000002/tar_partitions/part_2.tar.lz4",
+	}
+	deleted := []string{
+		"basebackups_005/base_000000010000000000000004_backup_stop_sentinel.json",
+		"basebackups_005/base_000000010000000000000004/tar_partitions/part_1.tar.lz4",
+		"wal_005/000000010000000000000002.lz4",
+		"wal_005/000000010000000000000003.lz4",
+	}
+	for _, name := range append(append([]string{}, lockedBackup...), deleted...) {
+		assert.NoError(t, folder.PutObject(name, &bytes.Buffer{}))
+	}
+
+	err := DeleteObjectsWhere(folder, true, func(storage.Object) bool { return true }, func(string) bool { return true })
+	assert.NoError(t, err)
+
+	for _, name := range lockedBackup {
+		exists, err := folder.Exists(name)
+		assert.NoError(t, err)
+		assert.True(t, exists, name)
+	}
+	for _, name := range deleted {
+		exists, err := folder.Exists(name)
+		assert.NoError(t, err)
+		assert.False(t, exists, name)
+	}
+}
+
+func TestDeleteObjectGroups_LockedObjectOutsideGroups(t *testing.T) {
+	folder := newLockingFolder(memory.NewKVS(), "wal_005/000000010000000000000002.lz4")
+	folder.DeleteObjectsMock = func([]string) error {
+		return storage.NewObjectsLockedError([]storage.LockedObject{{Path: "unknown", Reason: "legal hold"}})
+	}
+
+	err := DeleteObjectGroups(folder, [][]string{{"wal_005/000000010000000000000003.lz4"}})
+	var lockedErr storage.ObjectsLockedError
+	assert.ErrorAs(t, err, &lockedErr)
+}
+
+func TestDeletionGroupKey(t *testing.T) {
+	for _, tc := range []struct {
+		folderPath string
+		objectPath string
+		expected   string
+	}{
+		{"", "basebackups_005/base_000000010000000000000002_backup_stop_sentinel.json",
+			"basebackups_005/base_000000010000000000000002"},
+		{"", "basebackups_005/base_000000010000000000000002/tar_partitions/part_1.tar.lz4",
+			"basebackups_005/base_000000010000000000000002"},
+		{"prefix/", "segments_005/seg0/basebackups_005/base_000000010000000000000002/metadata.json",
+			"segments_005/seg0/basebackups_005/base_000000010000000000000002"},
+		{"prefix/basebackups_005/", "stream_20240101T000000Z/stream.br", "stream_20240101T000000Z"},
+		{"prefix/basebackups_005/", "stream_20240101T000000Z_backup_stop_sentinel.json", "stream_20240101T000000Z"},
+		{"", "basebackups_005/dedup_chunks/ab/abcdef", "basebackups_005/dedup_chunks/ab/abcdef"},
+		{"", "wal_005/000000010000000000000002.lz4", "wal_005/000000010000000000000002.lz4"},
+	} {
+		assert.Equal(t, tc.expected, deletionGroupKey(tc.folderPath, tc.objectPath), tc.objectPath)
+	}
+}
@@ -0,0 +1,269 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/statistics"
+	"github.com/wal-g/wal-g/internal/webserver"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const MetricsPath = "/metrics"
+
+// Source describes the database specific part of the storage layout
+type Source struct {
+	// Database is the value of the database label of the exported metrics
+	Database string
+	// ArchivePath is the storage prefix of the WAL, binlog or oplog archive, empty if the database has no archive
+	ArchivePath string
+	MetaFetcher internal.GenericMetaFetcher
+}
+
+type metrics struct {
+	lastBackupTime      prometheus.Gauge
+	lastBackupSize      prometheus.Gauge
+	lastBackupDuration  prometheus.Gauge
+	backupsCount        prometheus.Gauge
+	oldestRecoveryPoint prometheus.Gauge
+	lastArchiveTime     prometheus.Gauge
+	archiveLag          prometheus.Gauge
+	storageUsage        *prometheus.GaugeVec
+	lastScrapeTime      prometheus.Gauge
+	lastScrapeSuccess   prometheus.Gauge
+}
+
+func newMetrics(database string) metrics {
+	labels := prometheus.Labels{"database": database}
+	newGauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        statistics.WalgMetricsPrefix + name,
+			Help:        help,
+			ConstLabels: labels,
+		})
+	}
+	return metrics{
+		lastBackupTime:     newGauge("backup_last_finish_timestamp_seconds", "Finish time of the latest backup."),
+		lastBackupSize:     newGauge("backup_last_compressed_size_bytes", "Compressed size of the latest backup."),
+		lastBackupDuration: newGauge("backup_last_duration_seconds", "Duration of the latest backup."),
+		backupsCount:       newGauge("backups", "Number of backups in the storage."),
+		oldestRecoveryPoint: newGauge("oldest_recovery_point_timestamp_seconds",
+			"Finish time of the oldest backup, the earliest point the database can be restored to."),
+		lastArchiveTime: newGauge("archive_last_upload_timestamp_seconds",
+			"Modification time of the newest WAL, binlog or oplog archive file."),
+		archiveLag: newGauge("archive_lag_seconds",
+			"Time since the newest WAL, binlog or oplog archive file was uploaded."),
+		storageUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        statistics.WalgMetricsPrefix + "storage_usage_bytes",
+			Help:        "Size of the objects in the storage by the top level prefix.",
+			ConstLabels: labels,
+		}, []string{"prefix"}),
+		lastScrapeTime:    newGauge("exporter_last_scrape_timestamp_seconds", "Time of the last storage scrape."),
+		lastScrapeSuccess: newGauge("exporter_last_scrape_success", "Whether the last storage scrape succeeded."),
+	}
+}
+
+func (m metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.lastBackupTime, m.lastBackupSize, m.lastBackupDuration, m.backupsCount,
+		m.oldestRecoveryPoint, m.lastArchiveTime, m.archiveLag, m.storageUsage, m.lastScrapeTime, m.lastScrapeSuccess}
+}
+
+// Exporter periodically lists the storage and exposes the backups and the archive state as the Prometheus metrics
+type Exporter struct {
+	source   Source
+	folder   storage.Folder
+	registry *prometheus.Registry
+	metrics  metrics
+	// backups caches the metadata of the backups which were seen by the previous scrapes
+	backups map[string]internal.GenericMetadata
+	// usageInterval is the interval between the recursive listings of the whole storage for the storage usage
+	usageInterval  time.Duration
+	lastUsageCheck time.Time
+	now            func() time.Time
+}
+
+// NewExporter creates the exporter which computes the storage usage every usageInterval, never if it's zero
+func NewExporter(folder storage.Folder, source Source, usageInterval time.Duration) (*Exporter, error) {
+	exporter := &Exporter{
+		source:        source,
+		folder:        folder,
+		registry:      prometheus.NewRegistry(),
+		metrics:       newMetrics(source.Database),
+		backups:       make(map[string]internal.GenericMetadata),
+		usageInterval: usageInterval,
+		now:           utility.TimeNowCrossPlatformUTC,
+	}
+	for _, collector := range exporter.metrics.collectors() {
+		if err := exporter.registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return exporter, nil
+}
+
+// Handler serves the exported metrics
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Run scrapes the storage every interval until the context is canceled
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	for {
+		err := e.Scrape()
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to scrape the storage: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Scrape lists the storage and updates the metrics
+func (e *Exporter) Scrape() error {
+	now := e.now()
+	e.metrics.lastScrapeTime.Set(float64(now.Unix()))
+	err := e.scrape(now)
+	if err != nil {
+		e.metrics.lastScrapeSuccess.Set(0)
+		return err
+	}
+	e.metrics.lastScrapeSuccess.Set(1)
+	return nil
+}
+
+func (e *Exporter) scrape(now time.Time) error {
+	if e.usageInterval > 0 && !now.Before(e.lastUsageCheck.Add(e.usageInterval)) {
+		err := e.scrapeStorageUsage()
+		if err != nil {
+			return err
+		}
+		e.lastUsageCheck = now
+	}
+
+	if e.source.ArchivePath != "" {
+		err := e.scrapeArchive(now)
+		if err != nil {
+			return err
+		}
+	}
+
+	backups, err := e.fetchBackups()
+	if err != nil {
+		return err
+	}
+	e.setBackupMetrics(backups)
+	return nil
+}
+
+// scrapeStorageUsage lists the whole storage, so it's done on the longer interval than the other metrics
+func (e *Exporter) scrapeStorageUsage() error {
+	objects, err := storage.ListFolderRecursively(e.folder)
+	if err != nil {
+		return fmt.Errorf("list storage: %w", err)
+	}
+	usage := make(map[string]int64)
+	for _, object := range objects {
+		prefix, _, _ := strings.Cut(object.GetName(), "/")
+		usage[prefix] += object.GetSize()
+	}
+	e.metrics.storageUsage.Reset()
+	for prefix, size := range usage {
+		e.metrics.storageUsage.WithLabelValues(prefix).Set(float64(size))
+	}
+	return nil
+}
+
+// scrapeArchive lists only the top level of the archive folder, where the WAL, binlog or oplog files are uploaded
+func (e *Exporter) scrapeArchive(now time.Time) error {
+	objects, _, err := e.folder.GetSubFolder(e.source.ArchivePath).ListFolder()
+	if err != nil {
+		return fmt.Errorf("list archive: %w", err)
+	}
+	var lastArchiveTime time.Time
+	for _, object := range objects {
+		if object.GetLastModified().After(lastArchiveTime) {
+			lastArchiveTime = object.GetLastModified()
+		}
+	}
+	if !lastArchiveTime.IsZero() {
+		e.metrics.lastArchiveTime.Set(float64(lastArchiveTime.Unix()))
+		e.metrics.archiveLag.Set(now.Sub(lastArchiveTime).Seconds())
+	}
+	return nil
+}
+
+func (e *Exporter) fetchBackups() ([]internal.GenericMetadata, error) {
+	sentinels, err := internal.GetBackupSentinelObjects(e.folder)
+	if err != nil {
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+	backupFolder := e.folder.GetSubFolder(utility.BaseBackupPath)
+	backups := make(map[string]internal.GenericMetadata, len(sentinels))
+	for _, backupTime := range internal.GetBackupTimeSlices(sentinels) {
+		backup, ok := e.backups[backupTime.BackupName]
+		if !ok {
+			backup, err = e.source.MetaFetcher.Fetch(backupTime.BackupName, backupFolder)
+			if err != nil {
+				tracelog.WarningLogger.Printf("Failed to fetch the metadata of backup %s: %v", backupTime.BackupName, err)
+				continue
+			}
+		}
+		backups[backupTime.BackupName] = backup
+	}
+	e.backups = backups
+
+	result := make([]internal.GenericMetadata, 0, len(backups))
+	for _, backup := range backups {
+		result = append(result, backup)
+	}
+	return result, nil
+}
+
+func (e *Exporter) setBackupMetrics(backups []internal.GenericMetadata) {
+	e.metrics.backupsCount.Set(float64(len(backups)))
+	if len(backups) == 0 {
+		e.metrics.lastBackupTime.Set(0)
+		e.metrics.lastBackupSize.Set(0)
+		e.metrics.lastBackupDuration.Set(0)
+		e.metrics.oldestRecoveryPoint.Set(0)
+		return
+	}
+
+	latest, oldest := backups[0], backups[0]
+	for _, backup := range backups[1:] {
+		if backup.FinishTime.After(latest.FinishTime) {
+			latest = backup
+		}
+		if backup.FinishTime.Before(oldest.FinishTime) {
+			oldest = backup
+		}
+	}
+	e.metrics.lastBackupTime.Set(float64(latest.FinishTime.Unix()))
+	e.metrics.lastBackupSize.Set(float64(latest.CompressedSize))
+	e.metrics.lastBackupDuration.Set(latest.FinishTime.Sub(latest.StartTime).Seconds())
+	e.metrics.oldestRecoveryPoint.Set(float64(oldest.FinishTime.Unix()))
+}
+
+// HandleExporter serves the metrics on the default web server and scrapes the storage until the process is stopped
+func HandleExporter(folder storage.Folder, source Source, interval, usageInterval time.Duration) {
+	if webserver.DefaultWebServer == nil {
+		tracelog.ErrorLogger.Fatal("The exporter requires HTTP_LISTEN to be set")
+	}
+	exporter, err := NewExporter(folder, source, usageInterval)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	webserver.DefaultWebServer.HandleFunc(MetricsPath, exporter.Handler().ServeHTTP)
+	tracelog.InfoLogger.Printf("Serving %s metrics on %s every %v", source.Database, MetricsPath, interval)
+	exporter.Run(context.Background(), interval)
+}
@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+var exporterTestTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testMetaFetcher takes the backup times from the test backups and counts the fetches
+type testMetaFetcher struct {
+	backups map[string]internal.GenericMetadata
+	fetches int
+}
+
+func (mf *testMetaFetcher) Fetch(backupName string, _ storage.Folder) (internal.GenericMetadata, error) {
+	mf.fetches++
+	backup, ok := mf.backups[backupName]
+	if !ok {
+		return internal.GenericMetadata{}, fmt.Errorf("backup %s not found", backupName)
+	}
+	return backup, nil
+}
+
+func TestExporter_Scrape(t *testing.T) {
+	now := exporterTestTime
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time { return now })))
+	metaFetcher := &testMetaFetcher{backups: map[string]internal.GenericMetadata{
+		"base_1": {BackupName: "base_1", StartTime: now.Add(-3 * time.Hour), FinishTime: now.Add(-2 * time.Hour),
+			CompressedSize: 100},
+		"base_2": {BackupName: "base_2", StartTime: now.Add(-30 * time.Minute), FinishTime: now.Add(-10 * time.Minute),
+			CompressedSize: 200},
+	}}
+	for name := range metaFetcher.backups {
+		putTestObject(t, folder, utility.BaseBackupPath+name+utility.SentinelSuffix, 10)
+		putTestObject(t, folder, utility.BaseBackupPath+name+"/tar_partitions/part_1.tar.lz4", 1000)
+	}
+	now = exporterTestTime.Add(-10 * time.Minute)
+	putTestObject(t, folder, utility.WalPath+"000000010000000000000001.lz4", 300)
+	now = exporterTestTime.Add(-5 * time.Minute)
+	putTestObject(t, folder, utility.WalPath+"000000010000000000000002.lz4", 300)
+	now = exporterTestTime
+
+	exporter, err := NewExporter(folder, Source{Database: "postgres", ArchivePath: utility.WalPath, MetaFetcher: metaFetcher},
+		time.Hour)
+	require.NoError(t, err)
+	exporter.now = func() time.Time { return now }
+	require.NoError(t, exporter.Scrape())
+
+	m := exporter.metrics
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.backupsCount))
+	assert.Equal(t, float64(now.Add(-10*time.Minute).Unix()), testutil.ToFloat64(m.lastBackupTime))
+	assert.Equal(t, 200.0, testutil.ToFloat64(m.lastBackupSize))
+	assert.Equal(t, (20 * time.Minute).Seconds(), testutil.ToFloat64(m.lastBackupDuration))
+	assert.Equal(t, float64(now.Add(-2*time.Hour).Unix()), testutil.ToFloat64(m.oldestRecoveryPoint))
+	assert.Equal(t, (5 * time.Minute).Seconds(), testutil.ToFloat64(m.archiveLag))
+	assert.Equal(t, 2020.0, testutil.ToFloat64(m.storageUsage.WithLabelValues("basebackups_005")))
+	assert.Equal(t, 600.0, testutil.ToFloat64(m.storageUsage.WithLabelValues("wal_005")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.lastScrapeSuccess))
+
+	// the metadata of the known backups is not fetched again
+	require.NoError(t, exporter.Scrape())
+	assert.Equal(t, 2, metaFetcher.fetches)
+
+	require.NoError(t, folder.DeleteObjects([]string{utility.BaseBackupPath + "base_2" + utility.SentinelSuffix}))
+	require.NoError(t, exporter.Scrape())
+	assert.Equal(t, 1.0, testutil.ToFloat64(exporter.metrics.backupsCount))
+	assert.Equal(t, 100.0, testutil.ToFloat64(exporter.metrics.lastBackupSize))
+
+	// the storage usage is computed only once per the usage interval, unlike the archive lag
+	putTestObject(t, folder, utility.WalPath+"000000010000000000000003.lz4", 300)
+	now = exporterTestTime.Add(30 * time.Minute)
+	require.NoError(t, exporter.Scrape())
+	assert.Equal(t, 600.0, testutil.ToFloat64(m.storageUsage.WithLabelValues("wal_005")))
+	assert.Equal(t, (30 * time.Minute).Seconds(), testutil.ToFloat64(m.archiveLag))
+	now = exporterTestTime.Add(time.Hour)
+	require.NoError(t, exporter.Scrape())
+	assert.Equal(t, 900.0, testutil.ToFloat64(m.storageUsage.WithLabelValues("wal_005")))
+}
+
+func TestExporter_NoBackups(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	exporter, err := NewExporter(folder, Source{Database: "mysql", MetaFetcher: &testMetaFetcher{}}, 0)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Scrape())
+
+	count, err := testutil.GatherAndCount(exporter.registry, "walg_backups")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 0.0, testutil.ToFloat64(exporter.metrics.backupsCount))
+
+	count, err = testutil.GatherAndCount(exporter.registry, "walg_storage_usage_bytes")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func putTestObject(t *testing.T, folder storage.Folder, name string, size int) {
+	require.NoError(t, folder.PutObject(name, bytes.NewReader(make([]byte, size))))
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +15,7 @@ import (
 	"github.com/wal-g/wal-g/internal/compression"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/tracing"
 	"github.com/wal-g/wal-g/utility"
 	"golang.org/x/sync/semaphore"
 )
@@ -145,10 +147,12 @@ func ExtractAll(tarInterpreter TarInterpreter, files []ReaderMaker) error {
 	return ExtractAllWithSleeper(tarInterpreter, files, NewExponentialSleeper(MinExtractRetryWait, MaxExtractRetryWait))
 }
 
-func ExtractAllWithSleeper(tarInterpreter TarInterpreter, files []ReaderMaker, sleeper Sleeper) error {
+func ExtractAllWithSleeper(tarInterpreter TarInterpreter, files []ReaderMaker, sleeper Sleeper) (err error) {
 	if len(files) == 0 {
 		return newNoFilesToExtractError()
 	}
+	ctx, span := tracing.Start(context.Background(), "extract_all", tracing.FilesCountKey.Int(len(files)))
+	defer func() { tracing.End(span, err) }()
 
 	// Set maximum number of goroutines spun off by ExtractAll
 	downloadingConcurrency, err := conf.GetMaxDownloadConcurrency()
@@ -158,7 +162,7 @@ func ExtractAllWithSleeper(tarInterpreter TarInterpreter, files []ReaderMaker, s
 	retries := conf.GetFetchRetries()
 
 	for currentRun := files; len(currentRun) > 0; {
-		failed := tryExtractFiles(currentRun, tarInterpreter, downloadingConcurrency)
+		failed := tryExtractFiles(ctx, currentRun, tarInterpreter, downloadingConcurrency)
 		if downloadingConcurrency > 1 {
 			downloadingConcurrency /= 2
 		} else if len(failed) == len(currentRun) && retries <= 0 {
@@ -198,7 +202,8 @@ func extractFile(tarInterpreter TarInterpreter, extractingReader io.Reader, file
 }
 
 // TODO : unit tests
-func tryExtractFiles(files []ReaderMaker,
+func tryExtractFiles(ctx context.Context,
+	files []ReaderMaker,
 	tarInterpreter TarInterpreter,
 	downloadingConcurrency int) (failed []ReaderMaker) {
 	downloadingContext := context.TODO()
@@ -217,13 +222,17 @@ func tryExtractFiles(files []ReaderMaker,
 		go func() {
 			defer downloadingSemaphore.Release(1)
 
+			_, span := tracing.Start(ctx, "extract_file", tracing.ObjectName(fileClosure.StoragePath()))
+			var extractedSize int64
 			readCloser, err := fileClosure.Reader()
 			if err == nil {
 				defer utility.LoggedClose(readCloser, "")
 
 				filePath := fileClosure.StoragePath()
+				span.SetAttributes(tracing.CompressionMethod(utility.GetFileExtension(filePath)))
 				var extractingReader io.ReadCloser
-				extractingReader, err = DecryptAndDecompressTar(readCloser, filePath, crypter)
+				extractingReader, err = DecryptAndDecompressTar(
+					utility.NewWithSizeReader(readCloser, &extractedSize), filePath, crypter)
 				if err == nil {
 					defer extractingReader.Close()
 					err = extractFile(tarInterpreter, extractingReader, fileClosure)
@@ -232,6 +241,8 @@ func tryExtractFiles(files []ReaderMaker,
 				}
 			}
 
+			span.SetAttributes(tracing.Bytes(atomic.LoadInt64(&extractedSize)))
+			tracing.End(span, err)
 			if err != nil {
 				isFailed.Store(fileClosure, true)
 				tracelog.ErrorLogger.Println(err)
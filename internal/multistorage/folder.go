@@ -3,6 +3,7 @@ package multistorage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path"
@@ -579,6 +580,12 @@ func (mf Folder) DeleteObjectsFromFirst(objectRelativePaths []string) error {
 	first := mf.usedFolders[0]
 	filesNum := len(objectRelativePaths)
 	err := first.DeleteObjects(objectRelativePaths)
+	var lockedErr storage.ObjectsLockedError
+	if errors.As(err, &lockedErr) {
+		// the storage is alive, it just refused to delete some objects
+		mf.statsCollector.ReportOperationResult(first.StorageName, stats.OperationDelete(filesNum), true)
+		return err
+	}
 	if err != nil {
 		mf.statsCollector.ReportOperationResult(first.StorageName, stats.OperationDelete(filesNum), false)
 		return fmt.Errorf("delete object from storage %q: %w", first.StorageName, err)
@@ -590,14 +597,24 @@ func (mf Folder) DeleteObjectsFromFirst(objectRelativePaths []string) error {
 // DeleteObjectsFromAll deletes the objects from all used storages.
 func (mf Folder) DeleteObjectsFromAll(objectRelativePaths []string) error {
 	filesNum := len(objectRelativePaths)
+	var lockedObjects []storage.LockedObject
 	for _, f := range mf.usedFolders {
 		err := f.DeleteObjects(objectRelativePaths)
+		var lockedErr storage.ObjectsLockedError
+		if errors.As(err, &lockedErr) {
+			// keep deleting from other storages, locked objects are reported at the end
+			lockedObjects = append(lockedObjects, lockedErr.LockedObjects...)
+			err = nil
+		}
 		if err != nil {
 			mf.statsCollector.ReportOperationResult(f.StorageName, stats.OperationDelete(filesNum), false)
 			return fmt.Errorf("delete objects from storage %q: %w", f.StorageName, err)
 		}
 		mf.statsCollector.ReportOperationResult(f.StorageName, stats.OperationDelete(filesNum), true)
 	}
+	if len(lockedObjects) > 0 {
+		return storage.NewObjectsLockedError(lockedObjects)
+	}
 	return nil
 }
 
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+
+	"github.com/wal-g/tracelog"
+)
+
+// runHook runs the shell command with the event as JSON on its stdin
+func runHook(command string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"WALG_EVENT_OPERATION="+string(event.Operation),
+		"WALG_EVENT_STATUS="+string(event.Status))
+	tracelog.DebugLogger.Printf("Running the %s hook command: %s", event.Operation, command)
+	return cmd.Run()
+}
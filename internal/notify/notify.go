@@ -0,0 +1,304 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/utility"
+)
+
+type Operation string
+
+const (
+	BackupPush  Operation = "backup-push"
+	BackupFetch Operation = "backup-fetch"
+	WalPush     Operation = "wal-push"
+	Delete      Operation = "delete"
+)
+
+type Status string
+
+// BackgroundTimeout limits how long the exit is delayed by the webhook events sent in the background
+const BackgroundTimeout = 5 * time.Second
+
+const (
+	Started   Status = "started"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+// Event is sent to the webhooks and to the stdin of the hook commands as JSON
+type Event struct {
+	Operation        Operation         `json:"operation"`
+	Status           Status            `json:"status"`
+	Database         string            `json:"database,omitempty"`
+	Hostname         string            `json:"hostname,omitempty"`
+	BackupName       string            `json:"backup_name,omitempty"`
+	Details          map[string]string `json:"details,omitempty"`
+	CompressedSize   int64             `json:"compressed_size,omitempty"`
+	UncompressedSize int64             `json:"uncompressed_size,omitempty"`
+	StartTime        time.Time         `json:"start_time"`
+	FinishTime       *time.Time        `json:"finish_time,omitempty"`
+	DurationSeconds  float64           `json:"duration_seconds,omitempty"`
+	Error            string            `json:"error,omitempty"`
+}
+
+// Settings configure where the lifecycle events are delivered
+type Settings struct {
+	Database        string
+	WebhookURLs     []string
+	WebhookSecret   string
+	WebhookRetries  int
+	WebhookTimeout  time.Duration
+	PreHookCommand  string
+	PostHookCommand string
+	// HookOperations are the operations the hook commands are run for, all of them if empty
+	HookOperations []Operation
+}
+
+// Notifier delivers the lifecycle events of the operations to the webhooks and the hook commands
+type Notifier struct {
+	settings Settings
+	webhooks *webhookSender
+	hostname string
+}
+
+func NewNotifier(settings Settings) *Notifier {
+	hostname, err := os.Hostname()
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to obtain the OS hostname: %v", err)
+	}
+	return &Notifier{
+		settings: settings,
+		webhooks: newWebhookSender(settings.WebhookURLs, settings.WebhookSecret, settings.WebhookRetries, settings.WebhookTimeout),
+		hostname: hostname,
+	}
+}
+
+func (notifier *Notifier) enabled(operation Operation) bool {
+	return len(notifier.settings.WebhookURLs) > 0 || notifier.hooksEnabled(operation) &&
+		(notifier.settings.PreHookCommand != "" || notifier.settings.PostHookCommand != "")
+}
+
+func (notifier *Notifier) hooksEnabled(operation Operation) bool {
+	if len(notifier.settings.HookOperations) == 0 {
+		return true
+	}
+	for _, hookOperation := range notifier.settings.HookOperations {
+		if hookOperation == operation {
+			return true
+		}
+	}
+	return false
+}
+
+type Option func(n *Notification)
+
+func WithBackupName(backupName string) Option {
+	return func(n *Notification) {
+		n.SetBackupName(backupName)
+	}
+}
+
+func WithDetail(key, value string) Option {
+	return func(n *Notification) {
+		n.SetDetail(key, value)
+	}
+}
+
+// InBackground makes the webhooks of the frequent operations, e.g. wal-push, not slow the operation down:
+// the events are sent in the background, and Wait gives them at most BackgroundTimeout before the exit
+func InBackground() Option {
+	return func(n *Notification) {
+		n.background = true
+	}
+}
+
+// Start reports the start of the operation. The operation must be aborted if the pre-hook command fails.
+func (notifier *Notifier) Start(operation Operation, options ...Option) (*Notification, error) {
+	n := &Notification{
+		notifier: notifier,
+		event: Event{
+			Operation: operation,
+			Status:    Started,
+			Database:  notifier.settings.Database,
+			Hostname:  notifier.hostname,
+			StartTime: utility.TimeNowCrossPlatformUTC(),
+		},
+	}
+	for _, option := range options {
+		option(n)
+	}
+	if !notifier.enabled(operation) {
+		return n, nil
+	}
+
+	if notifier.settings.PreHookCommand != "" && notifier.hooksEnabled(operation) {
+		err := runHook(notifier.settings.PreHookCommand, n.event)
+		if err != nil {
+			err = fmt.Errorf("%s pre-hook command failed: %w", operation, err)
+			n.Finish(err)
+			return nil, err
+		}
+	}
+	n.sendWebhooks(n.event)
+	return n, nil
+}
+
+// Wait waits at most for the timeout for the webhook events sent in the background to be delivered
+func (notifier *Notifier) Wait(timeout time.Duration) {
+	if !notifier.webhooks.waitBackground(timeout) {
+		tracelog.WarningLogger.Printf("The webhook events are not delivered in %v, giving up", timeout)
+	}
+}
+
+// Notification tracks a single operation, a nil Notification reports nothing
+type Notification struct {
+	notifier   *Notifier
+	mutex      sync.Mutex
+	event      Event
+	finished   bool
+	background bool
+}
+
+func (n *Notification) sendWebhooks(event Event) {
+	if n.background {
+		n.notifier.webhooks.sendInBackground(event)
+		return
+	}
+	n.notifier.webhooks.send(event)
+}
+
+func (n *Notification) SetBackupName(backupName string) {
+	if n == nil {
+		return
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.event.BackupName = backupName
+}
+
+func (n *Notification) SetDetail(key, value string) {
+	if n == nil {
+		return
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.event.Details == nil {
+		n.event.Details = make(map[string]string)
+	}
+	n.event.Details[key] = value
+}
+
+func (n *Notification) SetSizes(compressedSize, uncompressedSize int64) {
+	if n == nil {
+		return
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.event.CompressedSize = compressedSize
+	n.event.UncompressedSize = uncompressedSize
+}
+
+// Finish reports the success or the failure of the operation and runs the post-hook command.
+// Only the first call has an effect. The handlers call it from a defer with the error they return,
+// so the operations exiting on a fatal error deep inside the handler report no result.
+func (n *Notification) Finish(err error) {
+	if n == nil {
+		return
+	}
+	n.mutex.Lock()
+	if n.finished {
+		n.mutex.Unlock()
+		return
+	}
+	n.finished = true
+	event := n.event
+	n.mutex.Unlock()
+	if !n.notifier.enabled(event.Operation) {
+		return
+	}
+
+	finishTime := utility.TimeNowCrossPlatformUTC()
+	event.FinishTime = &finishTime
+	event.DurationSeconds = finishTime.Sub(event.StartTime).Seconds()
+	event.Status = Succeeded
+	if err != nil {
+		event.Status = Failed
+		event.Error = err.Error()
+	}
+
+	n.sendWebhooks(event)
+	if n.notifier.settings.PostHookCommand != "" && n.notifier.hooksEnabled(event.Operation) {
+		hookErr := runHook(n.notifier.settings.PostHookCommand, event)
+		if hookErr != nil {
+			tracelog.WarningLogger.Printf("%s post-hook command failed: %v", event.Operation, hookErr)
+		}
+	}
+}
+
+var (
+	defaultNotifierMutex sync.Mutex
+	defaultNotifier      = NewNotifier(Settings{})
+)
+
+// Configure sets up the default notifier from the settings.
+// Nothing is reported when neither the webhooks nor the hook commands are configured.
+func Configure(database string) error {
+	timeout, err := time.ParseDuration(viper.GetString(conf.WebhookTimeoutSetting))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", conf.WebhookTimeoutSetting, err)
+	}
+	var hookOperations []Operation
+	for _, operation := range splitList(viper.GetString(conf.HookOperationsSetting)) {
+		hookOperations = append(hookOperations, Operation(operation))
+	}
+	notifier := NewNotifier(Settings{
+		Database:        strings.ToLower(database),
+		WebhookURLs:     splitList(viper.GetString(conf.WebhookURLsSetting)),
+		WebhookSecret:   viper.GetString(conf.WebhookSecretSetting),
+		WebhookRetries:  viper.GetInt(conf.WebhookRetriesSetting),
+		WebhookTimeout:  timeout,
+		PreHookCommand:  viper.GetString(conf.PreHookCommandSetting),
+		PostHookCommand: viper.GetString(conf.PostHookCommandSetting),
+		HookOperations:  hookOperations,
+	})
+
+	defaultNotifierMutex.Lock()
+	defaultNotifier = notifier
+	defaultNotifierMutex.Unlock()
+	return nil
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Wait waits at most BackgroundTimeout for the webhook events of the default notifier sent in the background,
+// it is called before the exit
+func Wait() {
+	defaultNotifierMutex.Lock()
+	notifier := defaultNotifier
+	defaultNotifierMutex.Unlock()
+	notifier.Wait(BackgroundTimeout)
+}
+
+// Start reports the start of the operation with the default notifier
+func Start(operation Operation, options ...Option) (*Notification, error) {
+	defaultNotifierMutex.Lock()
+	notifier := defaultNotifier
+	defaultNotifierMutex.Unlock()
+	return notifier.Start(operation, options...)
+}
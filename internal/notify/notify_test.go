@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWebhook struct {
+	mutex    sync.Mutex
+	events   []Event
+	failures int
+	server   *httptest.Server
+}
+
+func newTestWebhook(t *testing.T, secret string, failures int) *testWebhook {
+	webhook := &testWebhook{failures: failures}
+	webhook.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhook.mutex.Lock()
+		defer webhook.mutex.Unlock()
+		if webhook.failures > 0 {
+			webhook.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if secret != "" {
+			assert.Equal(t, "sha256="+Sign([]byte(secret), body), r.Header.Get(SignatureHeader))
+		}
+		var event Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, string(event.Operation)+"."+string(event.Status), r.Header.Get(EventHeader))
+		webhook.events = append(webhook.events, event)
+	}))
+	t.Cleanup(webhook.server.Close)
+	return webhook
+}
+
+func newTestNotifier(settings Settings) *Notifier {
+	notifier := NewNotifier(settings)
+	notifier.webhooks.retryDelay = time.Millisecond
+	return notifier
+}
+
+func TestNotifier_Webhook(t *testing.T) {
+	webhook := newTestWebhook(t, "secret", 2)
+	notifier := newTestNotifier(Settings{
+		Database:       "postgres",
+		WebhookURLs:    []string{webhook.server.URL},
+		WebhookSecret:  "secret",
+		WebhookRetries: 3,
+		WebhookTimeout: time.Second,
+	})
+
+	n, err := notifier.Start(BackupPush)
+	require.NoError(t, err)
+	n.SetBackupName("base_000000010000000000000002")
+	n.SetSizes(100, 1000)
+	n.Finish(nil)
+	n.Finish(errors.New("ignored"))
+
+	require.Len(t, webhook.events, 2)
+	started, succeeded := webhook.events[0], webhook.events[1]
+	assert.Equal(t, Started, started.Status)
+	assert.Equal(t, "postgres", started.Database)
+	assert.Nil(t, started.FinishTime)
+	assert.Equal(t, Succeeded, succeeded.Status)
+	assert.Equal(t, "base_000000010000000000000002", succeeded.BackupName)
+	assert.Equal(t, int64(100), succeeded.CompressedSize)
+	assert.Equal(t, int64(1000), succeeded.UncompressedSize)
+	require.NotNil(t, succeeded.FinishTime)
+	assert.Empty(t, succeeded.Error)
+}
+
+func TestNotifier_WebhookRetriesExhausted(t *testing.T) {
+	webhook := newTestWebhook(t, "", 5)
+	notifier := newTestNotifier(Settings{
+		WebhookURLs:    []string{webhook.server.URL},
+		WebhookRetries: 1,
+		WebhookTimeout: time.Second,
+	})
+
+	n, err := notifier.Start(WalPush)
+	require.NoError(t, err)
+	n.Finish(nil)
+
+	assert.Empty(t, webhook.events)
+	// 2 attempts for each of the 2 events
+	assert.Equal(t, 1, webhook.failures)
+}
+
+func TestNotifier_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	preHookOutput := filepath.Join(dir, "pre.json")
+	postHookOutput := filepath.Join(dir, "post.json")
+	notifier := newTestNotifier(Settings{
+		PreHookCommand:  "cat > " + preHookOutput,
+		PostHookCommand: "cat > " + postHookOutput + " && test \"$WALG_EVENT_STATUS\" = failed",
+	})
+
+	n, err := notifier.Start(Delete, WithDetail("mode", "retain"))
+	require.NoError(t, err)
+	n.Finish(errors.New("delete failed"))
+
+	var preEvent, postEvent Event
+	readTestEvent(t, preHookOutput, &preEvent)
+	readTestEvent(t, postHookOutput, &postEvent)
+	assert.Equal(t, Delete, preEvent.Operation)
+	assert.Equal(t, Started, preEvent.Status)
+	assert.Equal(t, "retain", preEvent.Details["mode"])
+	assert.Equal(t, Failed, postEvent.Status)
+	assert.Equal(t, "delete failed", postEvent.Error)
+	assert.Equal(t, map[string]string{"mode": "retain"}, postEvent.Details)
+}
+
+func TestNotifier_PreHookFailureAborts(t *testing.T) {
+	webhook := newTestWebhook(t, "", 0)
+	notifier := newTestNotifier(Settings{
+		WebhookURLs:    []string{webhook.server.URL},
+		WebhookTimeout: time.Second,
+		PreHookCommand: "exit 3",
+	})
+
+	n, err := notifier.Start(BackupFetch)
+	assert.Error(t, err)
+	assert.Nil(t, n)
+	require.Len(t, webhook.events, 1)
+	assert.Equal(t, Failed, webhook.events[0].Status)
+}
+
+func TestNotifier_InBackground(t *testing.T) {
+	release := make(chan struct{})
+	webhook := newTestWebhook(t, "", 1)
+	slowWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slowWebhook.Close)
+	notifier := newTestNotifier(Settings{
+		WebhookURLs:    []string{slowWebhook.URL, webhook.server.URL},
+		WebhookRetries: 3,
+		WebhookTimeout: time.Minute,
+	})
+
+	n, err := notifier.Start(WalPush, InBackground())
+	require.NoError(t, err)
+	n.Finish(nil)
+	// the slow webhook holds both events, the wait gives up
+	start := time.Now()
+	notifier.Wait(50 * time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+
+	close(release)
+	notifier.Wait(time.Minute)
+	// the event which got the failure was retried
+	require.Len(t, webhook.events, 2)
+	assert.ElementsMatch(t, []Status{Started, Succeeded}, []Status{webhook.events[0].Status, webhook.events[1].Status})
+	assert.Equal(t, 0, webhook.failures)
+}
+
+func TestNotifier_HookOperations(t *testing.T) {
+	notifier := newTestNotifier(Settings{
+		PreHookCommand: "exit 3",
+		HookOperations: []Operation{BackupPush, Delete},
+	})
+
+	n, err := notifier.Start(WalPush)
+	require.NoError(t, err)
+	n.Finish(nil)
+
+	_, err = notifier.Start(BackupPush)
+	assert.Error(t, err)
+}
+
+func TestNotification_Nil(t *testing.T) {
+	var n *Notification
+	n.SetBackupName("base_000000010000000000000002")
+	n.SetDetail("mode", "retain")
+	n.SetSizes(1, 2)
+	n.Finish(nil)
+}
+
+func readTestEvent(t *testing.T, path string, event *Event) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, event))
+}
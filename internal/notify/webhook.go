@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wal-g/tracelog"
+)
+
+const (
+	SignatureHeader = "X-Walg-Signature"
+	EventHeader     = "X-Walg-Event"
+
+	webhookRetryDelay = time.Second
+)
+
+type webhookSender struct {
+	urls       []string
+	secret     []byte
+	retries    int
+	client     *http.Client
+	retryDelay time.Duration
+	background sync.WaitGroup
+}
+
+func newWebhookSender(urls []string, secret string, retries int, timeout time.Duration) *webhookSender {
+	return &webhookSender{
+		urls:       urls,
+		secret:     []byte(secret),
+		retries:    retries,
+		client:     &http.Client{Timeout: timeout},
+		retryDelay: webhookRetryDelay,
+	}
+}
+
+// send posts the event to every webhook, the delivery failures are logged and never fail the operation
+func (sender *webhookSender) send(event Event) {
+	sender.sendWithRetries(event, sender.retries)
+}
+
+// sendInBackground posts the event to every webhook without waiting for the delivery,
+// waitBackground must be called before the exit for the event not to be lost
+func (sender *webhookSender) sendInBackground(event Event) {
+	if len(sender.urls) == 0 {
+		return
+	}
+	sender.background.Add(1)
+	go func() {
+		defer sender.background.Done()
+		sender.sendWithRetries(event, sender.retries)
+	}()
+}
+
+// waitBackground waits for the events sent in the background at most for the timeout
+// and reports whether all of them are delivered or given up
+func (sender *webhookSender) waitBackground(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		sender.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (sender *webhookSender) sendWithRetries(event Event, retries int) {
+	if len(sender.urls) == 0 {
+		return
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to marshal the %s event: %v", event.Operation, err)
+		return
+	}
+	for _, url := range sender.urls {
+		err = sender.post(url, string(event.Operation)+"."+string(event.Status), body, retries)
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to send the %s %s event to the webhook %s: %v",
+				event.Operation, event.Status, url, err)
+		}
+	}
+}
+
+func (sender *webhookSender) post(url, eventName string, body []byte, retries int) error {
+	delay := sender.retryDelay
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = sender.postOnce(url, eventName, body)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+		tracelog.DebugLogger.Printf("Retrying the webhook %s in %v: %v", url, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postOnce returns whether the failed request should be retried
+func (sender *webhookSender) postOnce(url, eventName string, body []byte) (retry bool, err error) {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, eventName)
+	if len(sender.secret) > 0 {
+		request.Header.Set(SignatureHeader, "sha256="+Sign(sender.secret, body))
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		// the client errors won't go away on retry except for the rate limiting
+		retry = response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return false, nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 of the webhook body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
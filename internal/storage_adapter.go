@@ -10,6 +10,7 @@ import (
 	"github.com/wal-g/wal-g/pkg/storages/sh"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/pkg/storages/swift"
+	"github.com/wal-g/wal-g/pkg/storages/webdav"
 )
 
 type StorageAdapter struct {
@@ -57,4 +58,5 @@ var StorageAdapters = []StorageAdapter{
 	{"AZ", azure.SettingList, azure.ConfigureStorage},
 	{"SWIFT", swift.SettingList, swift.ConfigureStorage},
 	{"SSH", sh.SettingList, sh.ConfigureStorage},
+	{"WEBDAV", webdav.SettingList, webdav.ConfigureStorage},
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/tracing"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -82,9 +83,13 @@ func (tarBall *StorageTarBall) startUpload(name string, crypter crypto.Crypter)
 	path := tarBall.backupName + TarPartitionFolderName + name
 
 	tracelog.InfoLogger.Printf("Starting part %d ...\n", tarBall.partNumber)
+	ctx, span := tracing.Start(context.Background(), "tar_ball.part", tracing.ObjectName(path),
+		tracing.CompressionMethod(uploader.Compression().FileExtension()))
 
 	go func() {
-		err := uploader.Upload(context.Background(), path, pipeReader)
+		err := uploader.Upload(ctx, path, pipeReader)
+		span.SetAttributes(tracing.Bytes(tarBall.Size()))
+		tracing.End(span, err)
 		if compressingError, ok := err.(CompressAndEncryptError); ok {
 			tracelog.ErrorLogger.Printf("could not upload '%s' due to compression error\n%+v\n", path, compressingError)
 		}
@@ -111,8 +116,8 @@ func (tarBall *StorageTarBall) startUpload(name string, crypter crypto.Crypter)
 		writerToCompress = &utility.CascadeWriteCloser{WriteCloser: encryptedWriter, Underlying: pipeWriter}
 	}
 
-	return &utility.CascadeWriteCloser{WriteCloser: uploader.Compression().NewWriter(writerToCompress),
-		Underlying: writerToCompress}
+	compressedWriter := newNamedCompressedWriter(uploader.Compression(), writerToCompress, name)
+	return &utility.CascadeWriteCloser{WriteCloser: compressedWriter, Underlying: writerToCompress}
 }
 
 // Size accumulated in this tarball
@@ -0,0 +1,358 @@
+package storagetools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	// tarFileSetsField is the field of the Postgres files metadata (and sentinels of the old backups)
+	// that maps the names of the tar files to the files they contain
+	tarFileSetsField  = "TarFileSets"
+	filesMetadataName = "files_metadata.json"
+)
+
+type RecompressConfig struct {
+	Concurrency int
+	// Compressor is the compression method to convert the objects to
+	Compressor compression.Compressor
+	// SourceExtension limits the conversion to the objects with this extension, empty means all the objects
+	SourceExtension string
+	// Crypter decrypts and encrypts the objects, nil means the objects aren't encrypted
+	Crypter crypto.Crypter
+}
+
+// backupObjects are the objects by the prefix that belong to the same backup
+type backupObjects struct {
+	// root is the path of the folder with the backups (e.g. "basebackups_005/")
+	root    string
+	name    string
+	objects []string
+}
+
+// HandleRecompress converts the objects by the prefix compressed with another method to the configured one.
+// The actual compression method of each object is detected from its content, the extension is replaced
+// with the new one. The objects that belong to a backup are converted together: after all of them are
+// uploaded, the backup metadata referring to their names is updated, and only then the old objects are deleted.
+// The chunks of the deduplicated backups are skipped, since their names are shared by many backups.
+// The binlogs and the sentinels are not rewritten, see isDatedByModificationTime, so the old backups which list
+// their tar files in the sentinel are not recompressed.
+func HandleRecompress(ctx context.Context, prefix string, folder storage.Folder, cfg RecompressConfig) error {
+	err := checkRewritablePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	objects, err := storage.ListFolderRecursivelyWithPrefix(folder, prefix)
+	if err != nil {
+		return fmt.Errorf("list files by prefix: %w", err)
+	}
+
+	var standalone []string
+	backups := map[string]*backupObjects{}
+	for _, object := range objects {
+		name := object.GetName()
+		if !shouldRecompress(name, cfg) {
+			continue
+		}
+		root, backupName, ok := splitBackupObjectPath(name)
+		if !ok {
+			standalone = append(standalone, name)
+			continue
+		}
+		key := root + backupName
+		if backups[key] == nil {
+			backups[key] = &backupObjects{root: root, name: backupName}
+		}
+		backups[key].objects = append(backups[key].objects, name)
+	}
+	tracelog.InfoLogger.Printf("Recompressing to %s by prefix %q: %d backups and %d other objects",
+		cfg.Compressor.FileExtension(), prefix, len(backups), len(standalone))
+
+	failed := 0
+	backupKeys := make([]string, 0, len(backups))
+	for key := range backups {
+		backupKeys = append(backupKeys, key)
+	}
+	sort.Strings(backupKeys)
+	for _, key := range backupKeys {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := recompressBackup(ctx, folder, backups[key], cfg)
+		if err != nil {
+			tracelog.ErrorLogger.Printf("Failed to recompress backup %q: %v", key, err)
+			failed++
+		}
+	}
+
+	renames, errs := recompressObjects(ctx, folder, standalone, cfg)
+	for name, err := range errs {
+		tracelog.ErrorLogger.Printf("Failed to recompress %q: %v", name, err)
+	}
+	failed += len(errs)
+	err = deleteRecompressedObjects(folder, renames)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to recompress %d backups and objects", failed)
+	}
+	return nil
+}
+
+func shouldRecompress(name string, cfg RecompressConfig) bool {
+	extension := strings.TrimPrefix(path.Ext(name), ".")
+	if compression.FindDecompressor(extension) == nil || extension == cfg.Compressor.FileExtension() {
+		return false
+	}
+	if cfg.SourceExtension != "" && extension != cfg.SourceExtension {
+		return false
+	}
+	return !internal.IsDedupChunkStore(name) && !isDatedByModificationTime(name)
+}
+
+// splitBackupObjectPath finds the backup the object belongs to,
+// e.g. "basebackups_005/base_1/tar_partitions/part_1.tar.lz4" belongs to "base_1" in "basebackups_005/"
+func splitBackupObjectPath(objectPath string) (root, backupName string, ok bool) {
+	idx := strings.LastIndex("/"+objectPath, "/"+utility.BaseBackupPath)
+	if idx < 0 {
+		return "", "", false
+	}
+	root = objectPath[:idx+len(utility.BaseBackupPath)]
+	backupName, _, ok = strings.Cut(strings.TrimPrefix(objectPath, root), "/")
+	return root, backupName, ok
+}
+
+func recompressBackup(ctx context.Context, folder storage.Folder, backup *backupObjects, cfg RecompressConfig) error {
+	backupsFolder := folder.GetSubFolder(backup.root)
+
+	var streamMetadata internal.BackupStreamMetadata
+	hasStreamMetadata, err := readJSON(backupsFolder, internal.StreamMetadataNameFromBackup(backup.name), &streamMetadata)
+	if err != nil {
+		return err
+	}
+	listsTarFiles, err := sentinelListsTarFiles(backupsFolder, backup.name)
+	if err != nil {
+		return err
+	}
+	if listsTarFiles {
+		return fmt.Errorf("the sentinel lists the tar files, rewriting it would change the backup time")
+	}
+	tracelog.InfoLogger.Printf("Recompressing %d objects of backup %q", len(backup.objects), backup.name)
+	renames, errs := recompressObjects(ctx, folder, backup.objects, cfg)
+	if len(renames) < len(backup.objects) {
+		// the backup must not refer to the mix of the old and new objects, so get rid of the new ones
+		newObjects := make([]string, 0, len(renames))
+		for _, newName := range renames {
+			newObjects = append(newObjects, newName)
+		}
+		tracelog.ErrorLogger.PrintOnError(folder.DeleteObjects(newObjects))
+		for name, err := range errs {
+			tracelog.ErrorLogger.Printf("Failed to recompress %q: %v", name, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to recompress %d of %d objects", len(errs), len(backup.objects))
+	}
+
+	baseRenames := make(map[string]string, len(renames))
+	for oldName, newName := range renames {
+		baseRenames[path.Base(oldName)] = path.Base(newName)
+	}
+	metadataPath := backup.name + "/" + filesMetadataName
+	err = renameTarFiles(backupsFolder, metadataPath, baseRenames)
+	if err != nil {
+		return fmt.Errorf("update %q: %w", metadataPath, err)
+	}
+	if hasStreamMetadata && streamMetadata.Type == internal.SplitMergeStreamBackup {
+		streamMetadata.Compression = cfg.Compressor.FileExtension()
+		err = writeJSON(backupsFolder, internal.StreamMetadataNameFromBackup(backup.name), streamMetadata)
+		if err != nil {
+			return fmt.Errorf("update stream metadata: %w", err)
+		}
+	}
+
+	return deleteRecompressedObjects(folder, renames)
+}
+
+// recompressObjects uploads the recompressed objects and returns the new names of the succeeded ones
+func recompressObjects(
+	ctx context.Context,
+	folder storage.Folder,
+	names []string,
+	cfg RecompressConfig,
+) (renames map[string]string, errs map[string]error) {
+	renames = map[string]string{}
+	errs = map[string]error{}
+	mutex := new(sync.Mutex)
+
+	namesCh := make(chan string)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < utility.Max(cfg.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range namesCh {
+				newName, err := recompressObject(ctx, folder, name, cfg)
+
+				mutex.Lock()
+				if err != nil {
+					errs[name] = err
+				} else {
+					renames[name] = newName
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		namesCh <- name
+	}
+	close(namesCh)
+	wg.Wait()
+	return renames, errs
+}
+
+func recompressObject(ctx context.Context, folder storage.Folder, name string, cfg RecompressConfig) (string, error) {
+	extension := path.Ext(name)
+	newName := strings.TrimSuffix(name, extension) + "." + cfg.Compressor.FileExtension()
+
+	objReadCloser, err := folder.ReadObject(name)
+	if err != nil {
+		return "", err
+	}
+	defer utility.LoggedClose(objReadCloser, "")
+
+	var objReader io.Reader = objReadCloser
+	if cfg.Crypter != nil {
+		objReader, err = cfg.Crypter.Decrypt(objReader)
+		if err != nil {
+			return "", fmt.Errorf("init decryption: %w", err)
+		}
+	}
+	decompressor, objReader, err := compression.DetectDecompressor(objReader, extension)
+	if err != nil {
+		return "", err
+	}
+	if decompressor.FileExtension() != strings.TrimPrefix(extension, ".") {
+		tracelog.WarningLogger.Printf("Object %q is actually compressed with %s", name, decompressor.FileExtension())
+	}
+	decompressedReader, err := decompressor.Decompress(objReader)
+	if err != nil {
+		return "", fmt.Errorf("init decompression: %w", err)
+	}
+	defer utility.LoggedClose(decompressedReader, "")
+
+	err = folder.PutObjectWithContext(ctx, newName, internal.CompressAndEncrypt(decompressedReader, cfg.Compressor, cfg.Crypter))
+	if err != nil {
+		return "", fmt.Errorf("upload recompressed object: %w", err)
+	}
+	tracelog.DebugLogger.Printf("Recompressed %q to %q", name, newName)
+	return newName, nil
+}
+
+func deleteRecompressedObjects(folder storage.Folder, renames map[string]string) error {
+	oldObjects := make([]string, 0, len(renames))
+	for oldName := range renames {
+		oldObjects = append(oldObjects, oldName)
+	}
+	err := folder.DeleteObjects(oldObjects)
+	if err != nil {
+		return fmt.Errorf("delete the objects replaced by the recompressed ones: %w", err)
+	}
+	return nil
+}
+
+// sentinelListsTarFiles checks whether the backup is an old one with the TarFileSets in the sentinel
+func sentinelListsTarFiles(folder storage.Folder, backupName string) (bool, error) {
+	var sentinel map[string]json.RawMessage
+	_, err := readJSON(folder, internal.SentinelNameFromBackup(backupName), &sentinel)
+	if err != nil {
+		return false, err
+	}
+	var tarFileSets map[string]json.RawMessage
+	if sentinel[tarFileSetsField] != nil {
+		err = json.Unmarshal(sentinel[tarFileSetsField], &tarFileSets)
+		if err != nil {
+			return false, err
+		}
+	}
+	return len(tarFileSets) > 0, nil
+}
+
+// renameTarFiles renames the tar files in the TarFileSets of the JSON document if it has them
+func renameTarFiles(folder storage.Folder, documentPath string, renames map[string]string) error {
+	var document map[string]json.RawMessage
+	found, err := readJSON(folder, documentPath, &document)
+	if err != nil || !found || document[tarFileSetsField] == nil {
+		return err
+	}
+	var tarFileSets map[string]json.RawMessage
+	err = json.Unmarshal(document[tarFileSetsField], &tarFileSets)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for oldName, newName := range renames {
+		value, ok := tarFileSets[oldName]
+		if !ok {
+			continue
+		}
+		delete(tarFileSets, oldName)
+		tarFileSets[newName] = value
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	document[tarFileSetsField], err = json.Marshal(tarFileSets)
+	if err != nil {
+		return err
+	}
+	return writeJSON(folder, documentPath, document)
+}
+
+// readJSON reads the JSON document and returns false if it doesn't exist
+func readJSON(folder storage.Folder, documentPath string, document interface{}) (bool, error) {
+	reader, err := folder.ReadObject(documentPath)
+	var notFoundErr storage.ObjectNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer utility.LoggedClose(reader, "")
+	return true, json.NewDecoder(reader).Decode(document)
+}
+
+func writeJSON(folder storage.Folder, documentPath string, document interface{}) error {
+	content, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return folder.PutObject(documentPath, bytes.NewReader(content))
+}
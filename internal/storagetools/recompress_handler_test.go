@@ -0,0 +1,209 @@
+package storagetools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+func putCompressed(
+	t *testing.T,
+	folder storage.Folder,
+	name, content string,
+	compressor compression.Compressor,
+	crypter crypto.Crypter,
+) {
+	data, err := io.ReadAll(internal.CompressAndEncrypt(bytes.NewBufferString(content), compressor, crypter))
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(name, bytes.NewReader(data)))
+}
+
+func readDecompressed(t *testing.T, folder storage.Folder, name string, crypter crypto.Crypter) string {
+	reader, err := folder.ReadObject(name)
+	require.NoError(t, err)
+	defer reader.Close()
+	var src io.Reader = reader
+	if crypter != nil {
+		src, err = crypter.Decrypt(src)
+		require.NoError(t, err)
+	}
+	decompressed, err := zstd.Decompressor{}.Decompress(src)
+	require.NoError(t, err)
+	content, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func putJSON(t *testing.T, folder storage.Folder, name string, document interface{}) {
+	content, err := json.Marshal(document)
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(name, bytes.NewReader(content)))
+}
+
+func readTarFileSets(t *testing.T, folder storage.Folder, name string) map[string][]string {
+	var document struct {
+		TarFileSets map[string][]string
+	}
+	found, err := readJSON(folder, name, &document)
+	require.NoError(t, err)
+	require.True(t, found)
+	return document.TarFileSets
+}
+
+func listObjectNames(t *testing.T, folder storage.Folder, prefix string) []string {
+	objects, err := storage.ListFolderRecursivelyWithPrefix(folder, prefix)
+	require.NoError(t, err)
+	names := make([]string, 0, len(objects))
+	for _, object := range objects {
+		names = append(names, object.GetName())
+	}
+	return names
+}
+
+func TestHandleRecompress(t *testing.T) {
+	crypter := newAgeCrypter(t)
+	folder := memory.NewFolder("test/", memory.NewKVS())
+	lz4Compressor := lz4.Compressor{}
+
+	// Postgres backup with the files metadata
+	putCompressed(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.lz4", "part 1", lz4Compressor, crypter)
+	putCompressed(t, folder, "basebackups_005/base_1/tar_partitions/pg_control.tar.lz4", "pg_control", lz4Compressor, crypter)
+	putJSON(t, folder, "basebackups_005/base_1/files_metadata.json", map[string]interface{}{
+		"Files":       map[string]interface{}{"/PG_VERSION": map[string]interface{}{"IsSkipped": false}},
+		"TarFileSets": map[string][]string{"part_1.tar.lz4": {"/PG_VERSION"}, "pg_control.tar.lz4": {"/global/pg_control"}},
+	})
+	putJSON(t, folder, "basebackups_005/base_1_backup_stop_sentinel.json", map[string]interface{}{
+		"PgVersion":         150000,
+		"CompressionLevels": map[string]string{"part_1.tar.lz4": "best"},
+	})
+	// split stream backup
+	putCompressed(t, folder, "basebackups_005/stream_1/part_0000.lz4", "block 0", lz4Compressor, crypter)
+	putCompressed(t, folder, "basebackups_005/stream_1/part_0001.lz4", "block 1", lz4Compressor, crypter)
+	putJSON(t, folder, "basebackups_005/stream_1/stream_metadata.json", internal.BackupStreamMetadata{
+		Type: internal.SplitMergeStreamBackup, Partitions: 2, BlockSize: 1024, Compression: "lz4",
+	})
+	// deduplicated stream backup
+	putJSON(t, folder, "basebackups_005/stream_2/dedup_manifest.json", map[string]interface{}{})
+	putCompressed(t, folder, "basebackups_005/dedup_chunks/ab/abcd.lz4", "chunk", lz4Compressor, crypter)
+	putJSON(t, folder, "basebackups_005/stream_2/stream_metadata.json", internal.BackupStreamMetadata{
+		Type: internal.DedupStreamBackup, Compression: "lz4",
+	})
+	// WAL files, the last one has the wrong extension
+	putCompressed(t, folder, "wal_005/000000010000000000000001.lz4", "wal 1", lz4Compressor, crypter)
+	putCompressed(t, folder, "wal_005/000000010000000000000002.zst", "wal 2", zstd.Compressor{}, crypter)
+	putCompressed(t, folder, "wal_005/000000010000000000000003.lz4", "wal 3", zstd.Compressor{}, crypter)
+	// the binlogs are dated by their modification time
+	putCompressed(t, folder, "binlog_005/mysql-bin.000001.lz4", "binlog 1", lz4Compressor, crypter)
+
+	cfg := RecompressConfig{Concurrency: 2, Compressor: zstd.Compressor{}, Crypter: crypter}
+	require.NoError(t, HandleRecompress(context.Background(), "", folder, cfg))
+
+	assert.ElementsMatch(t, []string{
+		"basebackups_005/base_1/tar_partitions/part_1.tar.zst",
+		"basebackups_005/base_1/tar_partitions/pg_control.tar.zst",
+		"basebackups_005/base_1/files_metadata.json",
+		"basebackups_005/base_1_backup_stop_sentinel.json",
+		"basebackups_005/stream_1/part_0000.zst",
+		"basebackups_005/stream_1/part_0001.zst",
+		"basebackups_005/stream_1/stream_metadata.json",
+		"basebackups_005/stream_2/dedup_manifest.json",
+		"basebackups_005/stream_2/stream_metadata.json",
+		"basebackups_005/dedup_chunks/ab/abcd.lz4",
+		"wal_005/000000010000000000000001.zst",
+		"wal_005/000000010000000000000002.zst",
+		"wal_005/000000010000000000000003.zst",
+		"binlog_005/mysql-bin.000001.lz4",
+	}, listObjectNames(t, folder, ""))
+
+	assert.Equal(t, "part 1", readDecompressed(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.zst", crypter))
+	assert.Equal(t, "block 1", readDecompressed(t, folder, "basebackups_005/stream_1/part_0001.zst", crypter))
+	assert.Equal(t, "wal 3", readDecompressed(t, folder, "wal_005/000000010000000000000003.zst", crypter))
+
+	assert.Equal(t, map[string][]string{"part_1.tar.zst": {"/PG_VERSION"}, "pg_control.tar.zst": {"/global/pg_control"}},
+		readTarFileSets(t, folder, "basebackups_005/base_1/files_metadata.json"))
+
+	var filesMetadata map[string]json.RawMessage
+	_, err := readJSON(folder, "basebackups_005/base_1/files_metadata.json", &filesMetadata)
+	require.NoError(t, err)
+	assert.Contains(t, filesMetadata, "Files")
+
+	var sentinel map[string]json.RawMessage
+	_, err = readJSON(folder, "basebackups_005/base_1_backup_stop_sentinel.json", &sentinel)
+	require.NoError(t, err)
+	// the sentinel is not rewritten, since the backup is dated by its modification time
+	assert.JSONEq(t, `{"part_1.tar.lz4": "best"}`, string(sentinel["CompressionLevels"]))
+
+	var streamMetadata internal.BackupStreamMetadata
+	_, err = readJSON(folder, "basebackups_005/stream_1/stream_metadata.json", &streamMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, internal.BackupStreamMetadata{
+		Type: internal.SplitMergeStreamBackup, Partitions: 2, BlockSize: 1024, Compression: "zst",
+	}, streamMetadata)
+}
+
+func TestHandleRecompress_BrokenObjectKeepsBackup(t *testing.T) {
+	folder := memory.NewFolder("test/", memory.NewKVS())
+	putCompressed(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.lz4", "part 1", lz4.Compressor{}, nil)
+	require.NoError(t, folder.PutObject("basebackups_005/base_1/tar_partitions/part_2.tar.lz4",
+		bytes.NewBufferString("garbage")))
+	putJSON(t, folder, "basebackups_005/base_1/files_metadata.json", map[string]interface{}{
+		"TarFileSets": map[string][]string{"part_1.tar.lz4": {"/PG_VERSION"}, "part_2.tar.lz4": {"/base/1/1"}},
+	})
+	putCompressed(t, folder, "wal_005/000000010000000000000001.lz4", "wal 1", lz4.Compressor{}, nil)
+
+	cfg := RecompressConfig{Concurrency: 1, Compressor: zstd.Compressor{}, SourceExtension: "lz4"}
+	err := HandleRecompress(context.Background(), "", folder, cfg)
+
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []string{
+		"basebackups_005/base_1/tar_partitions/part_1.tar.lz4",
+		"basebackups_005/base_1/tar_partitions/part_2.tar.lz4",
+		"basebackups_005/base_1/files_metadata.json",
+		"wal_005/000000010000000000000001.zst",
+	}, listObjectNames(t, folder, ""))
+	assert.Equal(t, map[string][]string{"part_1.tar.lz4": {"/PG_VERSION"}, "part_2.tar.lz4": {"/base/1/1"}},
+		readTarFileSets(t, folder, "basebackups_005/base_1/files_metadata.json"))
+}
+
+func TestHandleRecompress_TarFileSetsInSentinel(t *testing.T) {
+	folder := memory.NewFolder("test/", memory.NewKVS())
+	putCompressed(t, folder, "basebackups_005/base_0/tar_partitions/part_1.tar.lz4", "old part 1", lz4.Compressor{}, nil)
+	putJSON(t, folder, "basebackups_005/base_0_backup_stop_sentinel.json", map[string]interface{}{
+		"PgVersion":   90600,
+		"TarFileSets": map[string][]string{"part_1.tar.lz4": {"/PG_VERSION"}},
+	})
+
+	cfg := RecompressConfig{Concurrency: 1, Compressor: zstd.Compressor{}}
+	assert.Error(t, HandleRecompress(context.Background(), "", folder, cfg))
+	assert.ElementsMatch(t, []string{
+		"basebackups_005/base_0/tar_partitions/part_1.tar.lz4",
+		"basebackups_005/base_0_backup_stop_sentinel.json",
+	}, listObjectNames(t, folder, ""))
+
+	assert.Error(t, HandleRecompress(context.Background(), "binlog_005/", folder, cfg))
+}
+
+func TestSplitBackupObjectPath(t *testing.T) {
+	root, name, ok := splitBackupObjectPath("segments_005/seg0/basebackups_005/base_1/tar_partitions/part_1.tar.lz4")
+	assert.True(t, ok)
+	assert.Equal(t, "segments_005/seg0/basebackups_005/", root)
+	assert.Equal(t, "base_1", name)
+
+	_, _, ok = splitBackupObjectPath("basebackups_005/base_1_backup_stop_sentinel.json")
+	assert.False(t, ok)
+
+	_, _, ok = splitBackupObjectPath("wal_005/000000010000000000000001.lz4")
+	assert.False(t, ok)
+}
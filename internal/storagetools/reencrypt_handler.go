@@ -0,0 +1,264 @@
+package storagetools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// ReencryptTmpSuffix is appended to the object name to get the name of its re-encrypted copy,
+// which replaces the object once it is uploaded completely
+const ReencryptTmpSuffix = ".reencrypt_tmp"
+
+// binlogPath is mysql.BinlogPath, the storage tools don't depend on the database packages
+const binlogPath = "binlog_" + utility.VersionStr + "/"
+
+// isDatedByModificationTime reports whether the modification time of the object is used as the time of its content:
+// the binlogs without the index are selected by it and the backups are dated by their sentinels. Rewriting such
+// an object would change what is restored and deleted, since the storages don't keep the time of the rewritten objects.
+func isDatedByModificationTime(name string) bool {
+	return strings.Contains("/"+name, "/"+binlogPath) || strings.HasSuffix(name, utility.SentinelSuffix)
+}
+
+// checkRewritablePrefix refuses the prefixes which contain only the objects dated by their modification time
+func checkRewritablePrefix(prefix string) error {
+	if strings.Contains("/"+prefix, "/"+binlogPath) || strings.HasSuffix(prefix, utility.SentinelSuffix) {
+		return fmt.Errorf("the objects by prefix %q are dated by their modification time and can't be rewritten", prefix)
+	}
+	return nil
+}
+
+type ReencryptConfig struct {
+	Concurrency int
+	// OldCrypter decrypts the objects that have not been re-encrypted yet
+	OldCrypter crypto.Crypter
+	// NewCrypter encrypts the objects, it must be able to decrypt them too
+	// to recognize the objects that were re-encrypted before the interruption
+	NewCrypter crypto.Crypter
+}
+
+type reencryptStats struct {
+	mutex       sync.Mutex
+	reencrypted int
+	skipped     int
+	failed      int
+}
+
+// HandleReencrypt re-encrypts every object by the prefix that has a known compression extension
+// (these are the only objects WAL-G encrypts). Each object is decrypted with the old crypter, encrypted
+// with the new one and uploaded under a temporary name, then the temporary object is copied over the
+// original one. The objects that the new crypter already decrypts are skipped, so the command can be
+// restarted after an interruption. The binlogs and the sentinels are not re-encrypted, see isDatedByModificationTime.
+func HandleReencrypt(ctx context.Context, prefix string, folder storage.Folder, cfg ReencryptConfig) error {
+	if cfg.OldCrypter == nil || cfg.NewCrypter == nil {
+		return errors.New("both old and new encryption must be configured")
+	}
+	err := checkCrypterCycle(cfg.NewCrypter)
+	if err != nil {
+		return fmt.Errorf("new crypter must be able to decrypt the objects it encrypts: %w", err)
+	}
+	err = checkRewritablePrefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	objects, err := storage.ListFolderRecursivelyWithPrefix(folder, prefix)
+	if err != nil {
+		return fmt.Errorf("list files by prefix: %w", err)
+	}
+
+	tmpObjects := map[string]bool{}
+	var objectNames []string
+	dated := 0
+	for _, object := range objects {
+		name := object.GetName()
+		switch {
+		case isDatedByModificationTime(name):
+			dated++
+		case strings.HasSuffix(name, ReencryptTmpSuffix):
+			tmpObjects[name] = true
+		case compression.FindDecompressor(path.Ext(name)) != nil:
+			objectNames = append(objectNames, name)
+		}
+	}
+	if dated > 0 {
+		tracelog.WarningLogger.Printf("Skipping %d binlogs and sentinels, they are dated by their modification time", dated)
+	}
+	tracelog.InfoLogger.Printf("Re-encrypting %d objects by prefix %q", len(objectNames), prefix)
+
+	stats := new(reencryptStats)
+	namesCh := make(chan string)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < utility.Max(cfg.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range namesCh {
+				reencrypted, err := reencryptObject(ctx, folder, name, tmpObjects[name+ReencryptTmpSuffix], cfg)
+
+				stats.mutex.Lock()
+				switch {
+				case err != nil:
+					tracelog.ErrorLogger.Printf("Failed to re-encrypt %q: %v", name, err)
+					stats.failed++
+				case reencrypted:
+					tracelog.DebugLogger.Printf("Re-encrypted %q", name)
+					stats.reencrypted++
+				default:
+					tracelog.DebugLogger.Printf("Skipped %q, it is already re-encrypted", name)
+					stats.skipped++
+				}
+				stats.mutex.Unlock()
+			}
+		}()
+	}
+	for _, name := range objectNames {
+		if ctx.Err() != nil {
+			break
+		}
+		namesCh <- name
+	}
+	close(namesCh)
+	wg.Wait()
+
+	tracelog.InfoLogger.Printf("Re-encrypted objects: %d, already re-encrypted: %d, failed: %d",
+		stats.reencrypted, stats.skipped, stats.failed)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if stats.failed > 0 {
+		return fmt.Errorf("failed to re-encrypt %d of %d objects", stats.failed, len(objectNames))
+	}
+	return nil
+}
+
+// BackupDataPrefix returns the prefix of the backup data objects, the backup name may be LATEST
+func BackupDataPrefix(folder storage.Folder, backupName string) (string, error) {
+	backup, err := internal.GetBackupByName(backupName, utility.BaseBackupPath, folder)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(utility.BaseBackupPath, backup.Name) + "/", nil
+}
+
+// reencryptObject replaces the object with its re-encrypted copy and returns false if it is already re-encrypted
+func reencryptObject(
+	ctx context.Context,
+	folder storage.Folder,
+	name string,
+	hasTmpObject bool,
+	cfg ReencryptConfig,
+) (bool, error) {
+	tmpName := name + ReencryptTmpSuffix
+
+	done, err := canDecrypt(folder, name, cfg.NewCrypter)
+	if err != nil {
+		return false, err
+	}
+	if done {
+		if hasTmpObject {
+			// the previous run was interrupted after the copy
+			return false, folder.DeleteObjects([]string{tmpName})
+		}
+		return false, nil
+	}
+
+	objReadCloser, err := folder.ReadObject(name)
+	if err != nil {
+		return false, err
+	}
+	defer utility.LoggedClose(objReadCloser, "")
+	decryptedReader, err := cfg.OldCrypter.Decrypt(objReadCloser)
+	if err != nil {
+		return false, fmt.Errorf("decrypt with the old crypter: %w", err)
+	}
+
+	encryptedReader, encryptedWriter := io.Pipe()
+	go func() {
+		_ = encryptedWriter.CloseWithError(encrypt(encryptedWriter, decryptedReader, cfg.NewCrypter))
+	}()
+	err = folder.PutObjectWithContext(ctx, tmpName, encryptedReader)
+	_ = encryptedReader.CloseWithError(err)
+	if err != nil {
+		return false, fmt.Errorf("upload re-encrypted object: %w", err)
+	}
+	err = folder.CopyObject(tmpName, name)
+	if err != nil {
+		return false, fmt.Errorf("replace the object with the re-encrypted one: %w", err)
+	}
+	err = folder.DeleteObjects([]string{tmpName})
+	if err != nil {
+		return false, fmt.Errorf("delete temporary object: %w", err)
+	}
+	return true, nil
+}
+
+func encrypt(dst io.Writer, src io.Reader, crypter crypto.Crypter) error {
+	writer, err := crypter.Encrypt(dst)
+	if err != nil {
+		return fmt.Errorf("encrypt with the new crypter: %w", err)
+	}
+	_, err = utility.FastCopy(writer, src)
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// canDecrypt checks whether the crypter decrypts the beginning of the object
+func canDecrypt(folder storage.Folder, name string, crypter crypto.Crypter) (bool, error) {
+	objReadCloser, err := folder.ReadObject(name)
+	if err != nil {
+		return false, err
+	}
+	defer utility.LoggedClose(objReadCloser, "")
+
+	decryptedReader, err := crypter.Decrypt(objReadCloser)
+	if err != nil {
+		return false, nil
+	}
+	_, err = io.ReadFull(decryptedReader, make([]byte, 1))
+	return err == nil || err == io.EOF, nil
+}
+
+func checkCrypterCycle(crypter crypto.Crypter) error {
+	const probe = "wal-g"
+	encrypted := new(bytes.Buffer)
+	writer, err := crypter.Encrypt(encrypted)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write([]byte(probe))
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	reader, err := crypter.Decrypt(encrypted)
+	if err != nil {
+		return err
+	}
+	decrypted, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if string(decrypted) != probe {
+		return errors.New("decrypted data doesn't match the encrypted one")
+	}
+	return nil
+}
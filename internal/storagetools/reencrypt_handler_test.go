@@ -0,0 +1,116 @@
+package storagetools
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/crypto"
+	agecrypter "github.com/wal-g/wal-g/internal/crypto/age"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+func newAgeCrypter(t *testing.T) crypto.Crypter {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return agecrypter.NewCrypter(identity.Recipient().String(), "", identity.String(), "")
+}
+
+func putEncrypted(t *testing.T, folder storage.Folder, name, content string, crypter crypto.Crypter) {
+	encrypted := new(bytes.Buffer)
+	writer, err := crypter.Encrypt(encrypted)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, folder.PutObject(name, encrypted))
+}
+
+func readDecrypted(t *testing.T, folder storage.Folder, name string, crypter crypto.Crypter) string {
+	reader, err := folder.ReadObject(name)
+	require.NoError(t, err)
+	defer reader.Close()
+	decrypted, err := crypter.Decrypt(reader)
+	require.NoError(t, err)
+	content, err := io.ReadAll(decrypted)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestHandleReencrypt(t *testing.T) {
+	oldCrypter, newCrypter := newAgeCrypter(t), newAgeCrypter(t)
+	folder := memory.NewFolder("test/", memory.NewKVS())
+
+	putEncrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.lz4", "part 1", oldCrypter)
+	putEncrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_2.tar.lz4", "part 2", oldCrypter)
+	// re-encrypted and copied, but the temporary object is left by the interrupted run
+	putEncrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_3.tar.lz4", "part 3", newCrypter)
+	putEncrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_3.tar.lz4"+ReencryptTmpSuffix, "part 3", newCrypter)
+	// uploaded partially by the interrupted run
+	require.NoError(t, folder.PutObject("basebackups_005/base_1/tar_partitions/part_2.tar.lz4"+ReencryptTmpSuffix,
+		bytes.NewBufferString("garbage")))
+	require.NoError(t, folder.PutObject("basebackups_005/base_1_backup_stop_sentinel.json", bytes.NewBufferString("{}")))
+	putEncrypted(t, folder, "wal_005/000000010000000000000001.lz4", "wal", oldCrypter)
+	putEncrypted(t, folder, "basebackups_005/binlog_005/mysql-bin.000001.lz4", "binlog", oldCrypter)
+
+	cfg := ReencryptConfig{Concurrency: 2, OldCrypter: oldCrypter, NewCrypter: newCrypter}
+	require.NoError(t, HandleReencrypt(context.Background(), "basebackups_005/", folder, cfg))
+	assert.Error(t, HandleReencrypt(context.Background(), "basebackups_005/binlog_005/", folder, cfg))
+
+	assert.Equal(t, "part 1", readDecrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.lz4", newCrypter))
+	assert.Equal(t, "part 2", readDecrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_2.tar.lz4", newCrypter))
+	assert.Equal(t, "part 3", readDecrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_3.tar.lz4", newCrypter))
+	assert.Equal(t, "wal", readDecrypted(t, folder, "wal_005/000000010000000000000001.lz4", oldCrypter))
+	// the binlogs are dated by their modification time
+	assert.Equal(t, "binlog", readDecrypted(t, folder, "basebackups_005/binlog_005/mysql-bin.000001.lz4", oldCrypter))
+
+	objects, err := storage.ListFolderRecursively(folder)
+	require.NoError(t, err)
+	var names []string
+	for _, object := range objects {
+		names = append(names, object.GetName())
+	}
+	assert.ElementsMatch(t, []string{
+		"basebackups_005/base_1/tar_partitions/part_1.tar.lz4",
+		"basebackups_005/base_1/tar_partitions/part_2.tar.lz4",
+		"basebackups_005/base_1/tar_partitions/part_3.tar.lz4",
+		"basebackups_005/base_1_backup_stop_sentinel.json",
+		"wal_005/000000010000000000000001.lz4",
+		"basebackups_005/binlog_005/mysql-bin.000001.lz4",
+	}, names)
+
+	// the second run has nothing to do
+	require.NoError(t, HandleReencrypt(context.Background(), "basebackups_005/", folder, cfg))
+	assert.Equal(t, "part 1", readDecrypted(t, folder, "basebackups_005/base_1/tar_partitions/part_1.tar.lz4", newCrypter))
+}
+
+func TestHandleReencrypt_UnknownKey(t *testing.T) {
+	oldCrypter, newCrypter := newAgeCrypter(t), newAgeCrypter(t)
+	folder := memory.NewFolder("test/", memory.NewKVS())
+	putEncrypted(t, folder, "wal_005/000000010000000000000001.lz4", "wal", newAgeCrypter(t))
+	putEncrypted(t, folder, "wal_005/000000010000000000000002.lz4", "wal", oldCrypter)
+
+	cfg := ReencryptConfig{Concurrency: 1, OldCrypter: oldCrypter, NewCrypter: newCrypter}
+	err := HandleReencrypt(context.Background(), "wal_005/", folder, cfg)
+
+	assert.Error(t, err)
+	assert.Equal(t, "wal", readDecrypted(t, folder, "wal_005/000000010000000000000002.lz4", newCrypter))
+}
+
+func TestHandleReencrypt_NewCrypterCantDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	cfg := ReencryptConfig{
+		OldCrypter: newAgeCrypter(t),
+		NewCrypter: agecrypter.NewCrypter(identity.Recipient().String(), "", "", ""),
+	}
+
+	err = HandleReencrypt(context.Background(), "", memory.NewFolder("test/", memory.NewKVS()), cfg)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package storagetools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/limiters"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+	"golang.org/x/time/rate"
+)
+
+type VerifyConfig struct {
+	Concurrency int
+	// RateLimit limits the total download speed in bytes per second, 0 means no limit
+	RateLimit int64
+	// Crypter is used to decrypt compressed objects, nil means the objects aren't encrypted
+	Crypter crypto.Crypter
+}
+
+type VerifyReport struct {
+	Prefix         string                `json:"prefix"`
+	CheckedObjects int                   `json:"checked_objects"`
+	CheckedBytes   int64                 `json:"checked_bytes"`
+	BrokenObjects  []VerifyObjectProblem `json:"broken_objects"`
+}
+
+type VerifyObjectProblem struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Error string `json:"error"`
+}
+
+// HandleVerify reads every object by the prefix end to end: objects with a known compression extension are
+// decrypted and decompressed, all the others are just downloaded. Objects that are unreadable or truncated
+// are listed in the JSON report written to the output.
+func HandleVerify(prefix string, folder storage.Folder, cfg VerifyConfig, output io.Writer) error {
+	objects, err := storage.ListFolderRecursivelyWithPrefix(folder, prefix)
+	if err != nil {
+		return fmt.Errorf("list files by prefix: %w", err)
+	}
+	tracelog.InfoLogger.Printf("Verifying %d objects by prefix %q", len(objects), prefix)
+
+	var limiter *rate.Limiter
+	if cfg.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit), int(cfg.RateLimit))
+	}
+
+	report := &VerifyReport{
+		Prefix:        prefix,
+		BrokenObjects: []VerifyObjectProblem{},
+	}
+	reportMu := new(sync.Mutex)
+
+	objectsCh := make(chan storage.Object)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < utility.Max(cfg.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range objectsCh {
+				readBytes, err := verifyObject(folder, object, cfg.Crypter, limiter)
+
+				reportMu.Lock()
+				report.CheckedObjects++
+				report.CheckedBytes += readBytes
+				if err != nil {
+					tracelog.WarningLogger.Printf("Object %q is broken: %v", object.GetName(), err)
+					report.BrokenObjects = append(report.BrokenObjects, VerifyObjectProblem{
+						Path:  object.GetName(),
+						Size:  object.GetSize(),
+						Error: err.Error(),
+					})
+				}
+				reportMu.Unlock()
+			}
+		}()
+	}
+	for _, object := range objects {
+		objectsCh <- object
+	}
+	close(objectsCh)
+	wg.Wait()
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "    ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("encode report to JSON: %w", err)
+	}
+
+	if len(report.BrokenObjects) > 0 {
+		return fmt.Errorf("%d of %d objects are broken", len(report.BrokenObjects), report.CheckedObjects)
+	}
+	return nil
+}
+
+// verifyObject reads the object till the end and returns the number of bytes downloaded from the storage
+func verifyObject(
+	folder storage.Folder,
+	object storage.Object,
+	crypter crypto.Crypter,
+	limiter *rate.Limiter,
+) (int64, error) {
+	objReadCloser, err := folder.ReadObject(object.GetName())
+	if err != nil {
+		return 0, err
+	}
+	defer utility.LoggedClose(objReadCloser, "")
+
+	var objReader io.Reader = objReadCloser
+	if limiter != nil {
+		objReader = limiters.NewReader(context.Background(), objReader, limiter)
+	}
+	readSize := new(int64)
+	rawReader := utility.NewWithSizeReader(objReader, readSize)
+	objReader = rawReader
+
+	decompressor := compression.FindDecompressor(path.Ext(object.GetName()))
+	if decompressor != nil {
+		if crypter != nil {
+			objReader, err = crypter.Decrypt(objReader)
+			if err != nil {
+				return *readSize, fmt.Errorf("init decryption: %w", err)
+			}
+		}
+
+		decompressedReader, err := decompressor.Decompress(objReader)
+		if err != nil {
+			return *readSize, fmt.Errorf("init decompression: %w", err)
+		}
+		defer utility.LoggedClose(decompressedReader, "")
+		objReader = decompressedReader
+	}
+
+	_, err = io.Copy(io.Discard, objReader)
+	if err != nil {
+		return *readSize, err
+	}
+	// decompressors may stop before the end of the raw data, so read the rest of it to check the size
+	_, err = io.Copy(io.Discard, rawReader)
+	if err != nil {
+		return *readSize, err
+	}
+
+	if *readSize != object.GetSize() {
+		return *readSize, fmt.Errorf("object is truncated: read %d bytes, expected %d",
+			*readSize, object.GetSize())
+	}
+	return *readSize, nil
+}
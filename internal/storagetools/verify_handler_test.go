@@ -0,0 +1,51 @@
+package storagetools
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestHandleVerify(t *testing.T) {
+	folder := memory.NewFolder("test/", memory.NewKVS())
+
+	compressed := new(bytes.Buffer)
+	writer := compression.Compressors[lz4.AlgorithmName].NewWriter(compressed)
+	_, err := writer.Write(bytes.Repeat([]byte("wal-g"), 1000))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	require.NoError(t, folder.PutObject("wal/good.lz4", bytes.NewReader(compressed.Bytes())))
+	require.NoError(t, folder.PutObject("wal/truncated.lz4", bytes.NewReader(compressed.Bytes()[:compressed.Len()/2])))
+	require.NoError(t, folder.PutObject("wal/plain.json", bytes.NewBufferString("{}")))
+	require.NoError(t, folder.PutObject("other/good.lz4", bytes.NewReader(compressed.Bytes())))
+
+	t.Run("report broken objects", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		err := HandleVerify("wal/", folder, VerifyConfig{Concurrency: 2}, output)
+		assert.Error(t, err)
+
+		report := VerifyReport{}
+		require.NoError(t, json.Unmarshal(output.Bytes(), &report))
+		assert.Equal(t, 3, report.CheckedObjects)
+		require.Len(t, report.BrokenObjects, 1)
+		assert.Equal(t, "wal/truncated.lz4", report.BrokenObjects[0].Path)
+	})
+
+	t.Run("no broken objects", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		err := HandleVerify("other/", folder, VerifyConfig{Concurrency: 1, RateLimit: 1 << 20}, output)
+		assert.NoError(t, err)
+
+		report := VerifyReport{}
+		require.NoError(t, json.Unmarshal(output.Bytes(), &report))
+		assert.Equal(t, 1, report.CheckedObjects)
+		assert.Empty(t, report.BrokenObjects)
+	})
+}
@@ -13,6 +13,7 @@ import (
 const (
 	SplitMergeStreamBackup   = "SPLIT_MERGE_STREAM_BACKUP"
 	SingleStreamStreamBackup = "STREAM_BACKUP"
+	DedupStreamBackup        = "DEDUP_STREAM_BACKUP"
 )
 
 type BackupStreamMetadata struct {
@@ -41,6 +42,12 @@ func GetBackupStreamFetcher(backup Backup) (StreamFetcher, error) {
 		return func(backup Backup, writer io.WriteCloser) error {
 			return DownloadAndDecompressSplittedStream(backup, int(blockSize), compression, writer, maxDownloadRetry)
 		}, nil
+	case DedupStreamBackup:
+		var compression = metadata.Compression
+		var concurrency = viper.GetInt(conf.DownloadConcurrencySetting)
+		return func(backup Backup, writer io.WriteCloser) error {
+			return DownloadAndDecompressDedupStream(backup, compression, writer, concurrency)
+		}, nil
 	case SingleStreamStreamBackup, "":
 		return DownloadAndDecompressStream, nil
 	}
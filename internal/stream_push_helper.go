@@ -95,7 +95,7 @@ func (uploader *RegularUploader) PushStreamToDestination(ctx context.Context, st
 	if uploader.dataSize != nil {
 		stream = utility.NewWithSizeReader(stream, uploader.dataSize)
 	}
-	compressed := CompressAndEncrypt(stream, uploader.Compressor, ConfigureCrypter())
+	compressed := CompressAndEncryptNamed(stream, uploader.Compressor, ConfigureCrypter(), path.Base(dstPath))
 	err := uploader.Upload(ctx, dstPath, compressed)
 	tracelog.InfoLogger.Println("FILE PATH:", dstPath)
 
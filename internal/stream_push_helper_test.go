@@ -13,6 +13,7 @@ import (
 
 	. "github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
 	functests "github.com/wal-g/wal-g/internal/testutils"
 	"github.com/wal-g/wal-g/pkg/storages/fs"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
@@ -167,6 +168,23 @@ func TestSplitBackup_Retry_NetworkError(t *testing.T) {
 	checkPushAndFetchBackup(t, 3, 1009, 100*1000, 30*1000, 5, 1000*1000)
 }
 
+func TestPushStreamToDestination_AdaptiveCompressionLevels(t *testing.T) {
+	storageFolder, clear, err := GetFolder(0)
+	assert.NoError(t, err)
+	defer clear()
+	uploader := NewRegularUploader(zstd.NewAdaptiveCompressor(), storageFolder)
+
+	for _, dstPath := range []string{"stream_1/part_0000.zst", "stream_1/part_0001.zst"} {
+		err = uploader.PushStreamToDestination(context.Background(), bytes.NewReader(getByteSampleArray(1000)), dstPath)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, map[string]string{"part_0000.zst": "default", "part_0001.zst": "default"},
+		AdaptiveCompressionLevels(uploader))
+
+	ResetAdaptiveCompressionLevels(uploader)
+	assert.Empty(t, AdaptiveCompressionLevels(uploader))
+}
+
 func GetS3Folder(networkErrorAfterByteSize int) (storage.Folder, func() error, error) {
 	cwd, err := filepath.Abs("./")
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wal-g/wal-g/internal/abool"
 	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/tracing"
 )
 
 // TarBallQueue is used to process multiple tarballs concurrently
@@ -65,6 +66,9 @@ func (tarQueue *TarBallQueue) DequeCtx(ctx context.Context) (TarBall, error) {
 	if tarQueue.started.IsNotSet() {
 		panic("Trying to deque from not started Queue")
 	}
+	// the span shows the time spent waiting for a tarball to fill
+	_, span := tracing.Start(ctx, "tar_ball_queue.deque")
+	defer span.End()
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -116,11 +120,15 @@ func (tarQueue *TarBallQueue) EnqueueBack(tarBall TarBall) {
 	tarQueue.tarsToFillQueue <- tarBall
 }
 
-func (tarQueue *TarBallQueue) FinishTarBall(tarBall TarBall) error {
+func (tarQueue *TarBallQueue) FinishTarBall(tarBall TarBall) (err error) {
+	_, span := tracing.Start(context.Background(), "tar_ball_queue.finish_tar_ball",
+		tracing.ObjectName(tarBall.Name()), tracing.Bytes(tarBall.Size()))
+	defer func() { tracing.End(span, err) }()
+
 	tarQueue.mutex.Lock()
 	defer tarQueue.mutex.Unlock()
 
-	err := tarQueue.CloseTarball(tarBall)
+	err = tarQueue.CloseTarball(tarBall)
 	if err != nil {
 		return errors.Wrap(err, "HandleWalkedFSObject: failed to close tarball")
 	}
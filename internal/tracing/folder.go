@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Folder starts a span for every storage request
+type Folder struct {
+	storage.Folder
+}
+
+func NewFolder(folder storage.Folder) *Folder {
+	return &Folder{Folder: folder}
+}
+
+func (tf *Folder) GetSubFolder(subFolderRelativePath string) storage.Folder {
+	return NewFolder(tf.Folder.GetSubFolder(subFolderRelativePath))
+}
+
+func (tf *Folder) ListFolder() (objects []storage.Object, subFolders []storage.Folder, err error) {
+	_, span := Start(context.Background(), "storage.list_folder", ObjectName(tf.GetPath()))
+	objects, subFolders, err = tf.Folder.ListFolder()
+	End(span, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range subFolders {
+		subFolders[i] = NewFolder(subFolders[i])
+	}
+	return objects, subFolders, nil
+}
+
+func (tf *Folder) DeleteObjects(objectRelativePaths []string) error {
+	_, span := Start(context.Background(), "storage.delete_objects", FilesCountKey.Int(len(objectRelativePaths)))
+	err := tf.Folder.DeleteObjects(objectRelativePaths)
+	End(span, err)
+	return err
+}
+
+func (tf *Folder) Exists(objectRelativePath string) (bool, error) {
+	_, span := Start(context.Background(), "storage.exists", ObjectName(tf.GetPath()+objectRelativePath))
+	exists, err := tf.Folder.Exists(objectRelativePath)
+	End(span, err)
+	return exists, err
+}
+
+// ReadObject starts the span which is ended when the object reader is closed
+func (tf *Folder) ReadObject(objectRelativePath string) (io.ReadCloser, error) {
+	_, span := Start(context.Background(), "storage.read_object", ObjectName(tf.GetPath()+objectRelativePath))
+	readCloser, err := tf.Folder.ReadObject(objectRelativePath)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	return &spanReadCloser{ReadCloser: readCloser, span: span}, nil
+}
+
+func (tf *Folder) PutObject(name string, content io.Reader) error {
+	return tf.PutObjectWithContext(context.Background(), name, content)
+}
+
+func (tf *Folder) PutObjectWithContext(ctx context.Context, name string, content io.Reader) error {
+	ctx, span := Start(ctx, "storage.put_object", ObjectName(tf.GetPath()+name))
+	reader := &countingReader{Reader: content}
+	err := tf.Folder.PutObjectWithContext(ctx, name, reader)
+	span.SetAttributes(Bytes(reader.bytes))
+	End(span, err)
+	return err
+}
+
+func (tf *Folder) CopyObject(srcPath string, dstPath string) error {
+	_, span := Start(context.Background(), "storage.copy_object", ObjectName(tf.GetPath()+dstPath))
+	err := tf.Folder.CopyObject(srcPath, dstPath)
+	End(span, err)
+	return err
+}
+
+type countingReader struct {
+	io.Reader
+	bytes int64
+}
+
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	reader.bytes += int64(n)
+	return n, err
+}
+
+type spanReadCloser struct {
+	io.ReadCloser
+	span  trace.Span
+	bytes int64
+	once  sync.Once
+}
+
+func (reader *spanReadCloser) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	reader.bytes += int64(n)
+	return n, err
+}
+
+func (reader *spanReadCloser) Close() error {
+	err := reader.ReadCloser.Close()
+	reader.once.Do(func() {
+		reader.span.SetAttributes(Bytes(reader.bytes))
+		End(reader.span, err)
+	})
+	return err
+}
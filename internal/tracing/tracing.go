@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	OTLPExporter = "otlp"
+	FileExporter = "file"
+
+	tracerName      = "github.com/wal-g/wal-g"
+	shutdownTimeout = 10 * time.Second
+)
+
+// The span attributes of the instrumented pipelines
+const (
+	ObjectNameKey        = attribute.Key("walg.object.name")
+	BytesKey             = attribute.Key("walg.bytes")
+	CompressionMethodKey = attribute.Key("walg.compression.method")
+	EncryptionKey        = attribute.Key("walg.encryption")
+	FilesCountKey        = attribute.Key("walg.files.count")
+)
+
+var (
+	rootMutex sync.Mutex
+	// root is the span of the running command, it is the parent of the spans started without a parent
+	root trace.Span
+
+	enabled bool
+)
+
+// Configure sets up the span exporter from the settings and returns the function
+// which flushes the spans left and shuts the exporter down.
+// The tracing is off when WALG_TRACING_EXPORTER is not set.
+func Configure() (shutdown func(), err error) {
+	exporterType := viper.GetString(conf.TracingExporterSetting)
+	if exporterType == "" {
+		return func() {}, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var closeOutput func() error
+	switch exporterType {
+	case OTLPExporter:
+		exporter, err = newOTLPExporter()
+	case FileExporter:
+		exporter, closeOutput, err = newFileExporter()
+	default:
+		err = fmt.Errorf("unknown %s value: %s", conf.TracingExporterSetting, exporterType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "wal-g"))),
+	)
+	otel.SetTracerProvider(provider)
+	enabled = true
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			tracelog.WarningLogger.Printf("Failed to export the trace spans: %v", err)
+		}
+		if closeOutput != nil {
+			if err := closeOutput(); err != nil {
+				tracelog.WarningLogger.Printf("Failed to close the trace file: %v", err)
+			}
+		}
+	}, nil
+}
+
+func newOTLPExporter() (sdktrace.SpanExporter, error) {
+	var options []otlptracehttp.Option
+	if endpoint := viper.GetString(conf.TracingOTLPEndpointSetting); endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if viper.GetBool(conf.TracingOTLPInsecureSetting) {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+func newFileExporter() (sdktrace.SpanExporter, func() error, error) {
+	path := viper.GetString(conf.TracingFileSetting)
+	if path == "" {
+		return nil, nil, fmt.Errorf("%s is required for the %s tracing exporter", conf.TracingFileSetting, FileExporter)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	return exporter, file.Close, nil
+}
+
+// Enabled reports whether the span exporter is configured
+func Enabled() bool {
+	return enabled
+}
+
+// StartRoot starts the span of the running command and returns the function which ends it
+// with the error the command failed with, if any
+func StartRoot(name string) (end func(err error)) {
+	_, span := otel.Tracer(tracerName).Start(context.Background(), name)
+	rootMutex.Lock()
+	root = span
+	rootMutex.Unlock()
+	return func(err error) {
+		rootMutex.Lock()
+		root = nil
+		rootMutex.Unlock()
+		End(span, err)
+	}
+}
+
+// Start starts the span. The span of the running command is used as the parent if the context has no span.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		rootMutex.Lock()
+		if root != nil {
+			ctx = trace.ContextWithSpan(ctx, root)
+		}
+		rootMutex.Unlock()
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error, if any, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func ObjectName(name string) attribute.KeyValue {
+	return ObjectNameKey.String(name)
+}
+
+func Bytes(bytes int64) attribute.KeyValue {
+	return BytesKey.Int64(bytes)
+}
+
+func CompressionMethod(method string) attribute.KeyValue {
+	return CompressionMethodKey.String(method)
+}
+
+func Encryption(crypterName string) attribute.KeyValue {
+	return EncryptionKey.String(crypterName)
+}
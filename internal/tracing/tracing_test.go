@@ -0,0 +1,107 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/tracing"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupTestExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "span %s is missing", name)
+	return tracetest.SpanStub{}
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestStart_RootParent(t *testing.T) {
+	exporter := setupTestExporter(t)
+
+	endRoot := tracing.StartRoot("wal-g backup-push")
+	_, span := tracing.Start(context.Background(), "child")
+	tracing.End(span, errors.New("failed"))
+	endRoot(errors.New("fatal"))
+	_, orphan := tracing.Start(context.Background(), "orphan")
+	orphan.End()
+
+	spans := exporter.GetSpans()
+	root := findSpan(t, spans, "wal-g backup-push")
+	child := findSpan(t, spans, "child")
+	assert.Equal(t, root.SpanContext.SpanID(), child.Parent.SpanID())
+	assert.Equal(t, codes.Error, child.Status.Code)
+	assert.Equal(t, codes.Error, root.Status.Code)
+	assert.False(t, findSpan(t, spans, "orphan").Parent.IsValid())
+}
+
+func TestFolder(t *testing.T) {
+	exporter := setupTestExporter(t)
+	folder := tracing.NewFolder(memory.NewFolder("", memory.NewKVS())).GetSubFolder("basebackups_005")
+
+	require.NoError(t, folder.PutObject("base_1/part_1.tar.lz4", bytes.NewReader(make([]byte, 100))))
+	readCloser, err := folder.ReadObject("base_1/part_1.tar.lz4")
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, readCloser)
+	require.NoError(t, err)
+	require.NoError(t, readCloser.Close())
+	_, err = folder.ReadObject("missing")
+	assert.Error(t, err)
+	_, subFolders, err := folder.ListFolder()
+	require.NoError(t, err)
+	require.Len(t, subFolders, 1)
+	assert.IsType(t, &tracing.Folder{}, subFolders[0])
+
+	spans := exporter.GetSpans()
+	put := findSpan(t, spans, "storage.put_object")
+	assert.Equal(t, "basebackups_005/base_1/part_1.tar.lz4", spanAttribute(put, tracing.ObjectNameKey).AsString())
+	assert.Equal(t, int64(100), spanAttribute(put, tracing.BytesKey).AsInt64())
+
+	var reads []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == "storage.read_object" {
+			reads = append(reads, span)
+		}
+	}
+	require.Len(t, reads, 2)
+	assert.Equal(t, int64(100), spanAttribute(reads[0], tracing.BytesKey).AsInt64())
+	assert.Equal(t, codes.Error, reads[1].Status.Code)
+	findSpan(t, spans, "storage.list_folder")
+}
+
+func TestConfigure_Disabled(t *testing.T) {
+	shutdown, err := tracing.Configure()
+	require.NoError(t, err)
+	shutdown()
+	assert.False(t, tracing.Enabled())
+}
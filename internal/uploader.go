@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/wal-g/wal-g/internal/abool"
 	"github.com/wal-g/wal-g/internal/statistics"
@@ -14,6 +15,7 @@ import (
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/ioextensions"
+	"github.com/wal-g/wal-g/internal/tracing"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
@@ -140,8 +142,11 @@ func (uploader *RegularUploader) Clone() Uploader {
 
 // TODO : unit tests
 // UploadFile compresses a file and uploads it.
-func (uploader *RegularUploader) UploadFile(ctx context.Context, file ioextensions.NamedReader) error {
+func (uploader *RegularUploader) UploadFile(ctx context.Context, file ioextensions.NamedReader) (err error) {
 	filename := file.Name()
+	ctx, span := tracing.Start(ctx, "uploader.upload_file", tracing.ObjectName(filename),
+		tracing.CompressionMethod(uploader.Compressor.FileExtension()))
+	defer func() { tracing.End(span, err) }()
 
 	fileReader := file.(io.Reader)
 	if uploader.dataSize != nil {
@@ -150,7 +155,7 @@ func (uploader *RegularUploader) UploadFile(ctx context.Context, file ioextensio
 	compressedFile := CompressAndEncrypt(fileReader, uploader.Compressor, ConfigureCrypter())
 	dstPath := utility.SanitizePath(filepath.Base(filename) + "." + uploader.Compressor.FileExtension())
 
-	err := uploader.Upload(ctx, dstPath, compressedFile)
+	err = uploader.Upload(ctx, dstPath, compressedFile)
 	tracelog.InfoLogger.Println("FILE PATH:", dstPath)
 	return err
 }
@@ -171,20 +176,49 @@ func (uploader *RegularUploader) Upload(ctx context.Context, path string, conten
 	uploader.waitGroup.Add(1)
 	defer uploader.waitGroup.Done()
 
+	ctx, span := tracing.Start(ctx, "uploader.upload", tracing.ObjectName(path))
+	adaptive, isAdaptive := uploader.Compressor.(compression.AdaptiveCompressor)
+	dataWait := &timedReader{Reader: content}
+	if isAdaptive {
+		content = dataWait
+	}
+	var uploadedSize int64
+	content = utility.NewWithSizeReader(content, &uploadedSize)
+
 	statistics.WalgMetrics.UploadedFilesTotal.Inc()
 	if uploader.tarSize != nil {
 		content = utility.NewWithSizeReader(content, uploader.tarSize)
 	}
+	start := time.Now()
 	err := uploader.UploadingFolder.PutObjectWithContext(ctx, path, content)
+	span.SetAttributes(tracing.Bytes(atomic.LoadInt64(&uploadedSize)))
+	tracing.End(span, err)
 	if err != nil {
 		statistics.WalgMetrics.UploadedFilesFailedTotal.Inc()
 		uploader.failed.Set()
 		tracelog.ErrorLogger.Printf(tracelog.GetErrorFormatter()+"\n", err)
 		return err
 	}
+	if isAdaptive {
+		// the time spent waiting for the compressed data is the compression bottleneck, not the upload one
+		adaptive.ReportUpload(atomic.LoadInt64(&uploadedSize), time.Since(start)-dataWait.elapsed)
+	}
 	return nil
 }
 
+// timedReader measures the time spent waiting for the underlying reader
+type timedReader struct {
+	io.Reader
+	elapsed time.Duration
+}
+
+func (reader *timedReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := reader.Reader.Read(p)
+	reader.elapsed += time.Since(start)
+	return n, err
+}
+
 // UploadMultiple uploads multiple objects from the start of the slice,
 // returning the first error if any. Note that this operation is not atomic
 // TODO : unit tests / is it used?
@@ -0,0 +1,57 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/tracing"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+type namedTestReader struct {
+	*bytes.Reader
+	name string
+}
+
+func (reader namedTestReader) Name() string {
+	return reader.name
+}
+
+func TestRegularUploader_UploadFileSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	folder := tracing.NewFolder(memory.NewFolder("", memory.NewKVS()))
+	uploader := internal.NewRegularUploader(compression.Compressors[lz4.AlgorithmName], folder)
+	file := namedTestReader{Reader: bytes.NewReader(make([]byte, 1<<16)), name: "/pgdata/pg_wal/000000010000000000000001"}
+	require.NoError(t, uploader.UploadFile(context.Background(), file))
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	require.Contains(t, spans, "uploader.upload_file")
+	require.Contains(t, spans, "uploader.upload")
+	require.Contains(t, spans, "storage.put_object")
+	require.Contains(t, spans, "compress_and_encrypt")
+
+	uploadFile, upload, put := spans["uploader.upload_file"], spans["uploader.upload"], spans["storage.put_object"]
+	assert.Equal(t, uploadFile.SpanContext.SpanID(), upload.Parent.SpanID())
+	assert.Equal(t, upload.SpanContext.SpanID(), put.Parent.SpanID())
+	assert.Contains(t, uploadFile.Attributes, tracing.CompressionMethod(lz4.FileExtension))
+	assert.Contains(t, spans["compress_and_encrypt"].Attributes, tracing.Bytes(1<<16))
+	assert.Contains(t, upload.Attributes, tracing.ObjectName("000000010000000000000001.lz4"))
+	assert.Contains(t, put.Attributes, tracing.ObjectName("000000010000000000000001.lz4"))
+}
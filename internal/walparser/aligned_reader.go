@@ -31,3 +31,8 @@ func (reader *AlignedReader) ReadToAlignment() error {
 	_, err := reader.Read(padding)
 	return errors.WithStack(err)
 }
+
+// alignedOffset returns the offset the next aligned read starts from
+func (reader *AlignedReader) alignedOffset() int {
+	return (reader.alreadyRead + reader.alignment - 1) / reader.alignment * reader.alignment
+}
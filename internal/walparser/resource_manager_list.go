@@ -30,3 +30,29 @@ const (
 
 	RmNextFreeID
 )
+
+// ResourceManagerNames are the names of the resource managers as pg_waldump prints them
+var ResourceManagerNames = [RmNextFreeID]string{
+	RmXlogID:       "XLOG",
+	RmXactID:       "Transaction",
+	RmSmgrID:       "Storage",
+	RmClogID:       "CLOG",
+	RmDBaseID:      "Database",
+	RmTblSpcID:     "Tablespace",
+	RmMultiXactID:  "MultiXact",
+	RmRelMapID:     "RelMap",
+	RmStandbyID:    "Standby",
+	RmHeap2ID:      "Heap2",
+	RmHeapID:       "Heap",
+	RmBTreeID:      "Btree",
+	RmHashID:       "Hash",
+	RmGinID:        "Gin",
+	RmGistID:       "Gist",
+	RmSeqID:        "Sequence",
+	RmSPGistID:     "SPGist",
+	RmBrinID:       "BRIN",
+	RmCommitTSID:   "CommitTs",
+	RmReplOriginID: "ReplicationOrigin",
+	RmGenericID:    "Generic",
+	RmLogicalMsgID: "LogicalMessage",
+}
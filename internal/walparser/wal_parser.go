@@ -56,10 +56,11 @@ func (err PartialPageError) Error() string {
 type WalParser struct {
 	currentRecordData         []byte
 	hasCurrentRecordBeginning bool
+	currentRecordLsn          XLogRecordPtr
 }
 
 func NewWalParser() *WalParser {
-	return &WalParser{make([]byte, 0), false}
+	return &WalParser{currentRecordData: make([]byte, 0)}
 }
 
 func (parser *WalParser) setCurrentRecordData(data []byte) {
@@ -89,6 +90,7 @@ func (parser *WalParser) ParseRecordsFromPage(reader io.Reader) (prevRecordTail
 	currentRecordData := concatByteSlices(parser.currentRecordData, page.PrevRecordTrailingData)
 	if !parser.hasCurrentRecordBeginning {
 		parser.setCurrentRecordData(page.NextRecordHeadingData)
+		parser.currentRecordLsn = page.NextRecordHeadingLsn
 		return currentRecordData, page.Records, pageParsingErr
 	}
 	header, err := readXLogRecordHeader(bytes.NewReader(currentRecordData))
@@ -102,10 +104,12 @@ func (parser *WalParser) ParseRecordsFromPage(reader io.Reader) (prevRecordTail
 	if err != nil {
 		return nil, nil, err
 	}
+	currentRecord.Lsn = parser.currentRecordLsn
 	records := make([]XLogRecord, len(page.Records)+1)
 	records[0] = *currentRecord
 	copy(records[1:], page.Records)
 	parser.setCurrentRecordData(page.NextRecordHeadingData)
+	parser.currentRecordLsn = page.NextRecordHeadingLsn
 	return nil, records, pageParsingErr
 }
 
@@ -152,6 +156,7 @@ func (parser *WalParser) parsePage(reader io.Reader) (*XLogPage, error) {
 func readXLogPage(alignedReader *AlignedReader, pageHeader *XLogPageHeader, remainingData []byte) (*XLogPage, error) {
 	pageRecords := make([]XLogRecord, 0)
 	for {
+		recordLsn := pageHeader.PageAddress + XLogRecordPtr(alignedReader.alignedOffset())
 		recordData, wholeRecord, err := tryReadXLogRecordData(alignedReader)
 		if err != nil {
 			return checkPartialPage(alignedReader,
@@ -164,13 +169,15 @@ func readXLogPage(alignedReader *AlignedReader, pageHeader *XLogPageHeader, rema
 				return checkPartialPage(alignedReader,
 					&XLogPage{Header: *pageHeader, PrevRecordTrailingData: remainingData, Records: pageRecords}, err)
 			}
+			record.Lsn = recordLsn
 			pageRecords = append(pageRecords, *record)
 			if record.isWALSwitch() {
 				return &XLogPage{Header: *pageHeader, PrevRecordTrailingData: remainingData, Records: pageRecords}, nil
 			}
 			continue
 		}
-		return &XLogPage{*pageHeader, remainingData, pageRecords, recordData}, nil
+		return &XLogPage{Header: *pageHeader, PrevRecordTrailingData: remainingData, Records: pageRecords,
+			NextRecordHeadingData: recordData, NextRecordHeadingLsn: recordLsn}, nil
 	}
 }
 
@@ -216,9 +223,9 @@ func LoadWalParser(reader io.Reader) (*WalParser, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &WalParser{data, len(data) > 0}, nil
+	return &WalParser{currentRecordData: data, hasCurrentRecordBeginning: len(data) > 0}, nil
 }
 
 func LoadWalParserFromCurrentRecordHead(currentRecordHead []byte) *WalParser {
-	return &WalParser{currentRecordHead, true}
+	return &WalParser{currentRecordData: currentRecordHead, hasCurrentRecordBeginning: true}
 }
@@ -111,3 +111,29 @@ func TestSaveLoadWalParser(t *testing.T) {
 
 	assert.Equal(t, walParser, loadedWalParser)
 }
+
+func TestParsing_RecordLsn(t *testing.T) {
+	walFile, err := os.Open(LongRecordTestPath)
+	assert.NoError(t, err)
+	defer utility.LoggedClose(walFile, "")
+	pageReader := NewWalPageReader(walFile)
+	parser := NewWalParser()
+
+	var records []XLogRecord
+	for {
+		page, err := pageReader.ReadPageData()
+		if err != nil {
+			break
+		}
+		_, pageRecords, err := parser.ParseRecordsFromPage(bytes.NewReader(page))
+		if err != nil {
+			break
+		}
+		records = append(records, pageRecords...)
+	}
+	assert.Greater(t, len(records), 1)
+	for i := 1; i < len(records); i++ {
+		assert.NotZero(t, records[i-1].Lsn)
+		assert.Equal(t, records[i].Header.PrevRecordPtr, records[i-1].Lsn)
+	}
+}
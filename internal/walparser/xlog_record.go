@@ -6,6 +6,8 @@ const (
 )
 
 type XLogRecord struct {
+	// Lsn is the WAL position of the record, it is zero if the record position is unknown
+	Lsn         XLogRecordPtr
 	Header      XLogRecordHeader
 	MainDataLen uint32
 	Origin      uint16
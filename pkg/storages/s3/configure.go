@@ -36,6 +36,9 @@ const (
 	rangeBatchEnabledSetting        = "S3_RANGE_BATCH_ENABLED"
 	rangeQueriesMaxRetriesSetting   = "S3_RANGE_MAX_RETRIES"
 	requestAdditionalHeadersSetting = "S3_REQUEST_ADDITIONAL_HEADERS"
+	objectLockModeSetting           = "S3_OBJECT_LOCK_MODE"
+	objectLockRetentionSetting      = "S3_OBJECT_LOCK_RETENTION"
+	objectLockLegalHoldSetting      = "S3_OBJECT_LOCK_LEGAL_HOLD"
 	// limiters for retry policy during interaction with S3
 	maxRetriesSetting              = "S3_MAX_RETRIES"
 	minThrottlingRetryDelaySetting = "S3_MIN_THROTTLING_RETRY_DELAY"
@@ -71,6 +74,9 @@ var SettingList = []string{
 	requestAdditionalHeadersSetting,
 	minThrottlingRetryDelaySetting,
 	maxThrottlingRetryDelaySetting,
+	objectLockModeSetting,
+	objectLockRetentionSetting,
+	objectLockLegalHoldSetting,
 }
 
 const (
@@ -84,6 +90,7 @@ const (
 	defaultStorageClass            = "STANDARD"
 	defaultRangeBatchEnabled       = false
 	defaultRangeMaxRetries         = 10
+	defaultObjectLockLegalHold     = false
 )
 
 // TODO: Unit tests
@@ -141,6 +148,17 @@ func ConfigureStorage(
 	if err != nil {
 		return nil, err
 	}
+	objectLockLegalHold, err := setting.BoolOptional(settings, objectLockLegalHoldSetting, defaultObjectLockLegalHold)
+	if err != nil {
+		return nil, err
+	}
+	var objectLockRetention time.Duration
+	if retention, ok := settings[objectLockRetentionSetting]; ok {
+		objectLockRetention, err = time.ParseDuration(retention)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", objectLockRetentionSetting, err)
+		}
+	}
 
 	config := &Config{
 		Secrets: &Secrets{
@@ -170,6 +188,11 @@ func ConfigureStorage(
 			ServerSideEncryption:         settings[sseSetting],
 			ServerSideEncryptionCustomer: settings[sseCSetting],
 			ServerSideEncryptionKMSID:    settings[sseKmsIDSetting],
+			ObjectLock: ObjectLockConfig{
+				Mode:            settings[objectLockModeSetting],
+				RetentionPeriod: objectLockRetention,
+				LegalHold:       objectLockLegalHold,
+			},
 		},
 		RangeBatchEnabled:       rangeBatchEnabled,
 		RangeMaxRetries:         rangeMaxRetries,
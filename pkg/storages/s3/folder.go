@@ -2,9 +2,13 @@ package s3
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -14,11 +18,15 @@ import (
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/statistics"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
 	NotFoundAWSErrorCode  = "NotFound"
 	NoSuchKeyAWSErrorCode = "NoSuchKey"
+
+	// lockCheckConcurrency is the number of parallel HEAD requests checking Object Lock of the deleted objects
+	lockCheckConcurrency = 16
 )
 
 // TODO: Unit tests
@@ -87,6 +95,13 @@ func (folder *Folder) CopyObject(srcPath string, dstPath string) error {
 	source := path.Join(*folder.bucket, folder.path, srcPath)
 	dst := path.Join(folder.path, dstPath)
 	input := &s3.CopyObjectInput{CopySource: &source, Bucket: folder.bucket, Key: &dst}
+	if folder.uploader.ObjectLock.Mode != "" {
+		input.ObjectLockMode = aws.String(folder.uploader.ObjectLock.Mode)
+		input.ObjectLockRetainUntilDate = aws.Time(folder.uploader.retainUntilDate())
+	}
+	if folder.uploader.ObjectLock.LegalHold {
+		input.ObjectLockLegalHoldStatus = aws.String(s3.ObjectLockLegalHoldStatusOn)
+	}
 	_, err := folder.s3API.CopyObject(input)
 	if err != nil {
 		tracelog.DebugLogger.Printf("expected HTTP Status Code: %v", err)
@@ -214,7 +229,22 @@ func (folder *Folder) listObjectsPagesV2(prefix *string, delimiter *string,
 	return err
 }
 
+// DeleteObjects deletes the objects. If Object Lock is configured and some objects are still retained or under
+// legal hold, nothing is deleted and storage.ObjectsLockedError listing the locked objects is returned.
 func (folder *Folder) DeleteObjects(objectRelativePaths []string) error {
+	if folder.uploader.ObjectLock.Enabled() {
+		lockedObjects, err := folder.findLockedObjects(objectRelativePaths)
+		if err != nil {
+			return err
+		}
+		if len(lockedObjects) > 0 {
+			return storage.NewObjectsLockedError(lockedObjects)
+		}
+	}
+	return folder.deleteObjects(objectRelativePaths)
+}
+
+func (folder *Folder) deleteObjects(objectRelativePaths []string) error {
 	parts := partitionStrings(objectRelativePaths, 1000)
 	for _, part := range parts {
 		input := &s3.DeleteObjectsInput{Bucket: folder.bucket, Delete: &s3.Delete{
@@ -232,6 +262,67 @@ func (folder *Folder) DeleteObjects(objectRelativePaths []string) error {
 	return nil
 }
 
+// findLockedObjects returns the objects that are protected by Object Lock
+func (folder *Folder) findLockedObjects(objectRelativePaths []string) ([]storage.LockedObject, error) {
+	now := time.Now()
+	lockedObjects := make([]storage.LockedObject, 0)
+	lockedObjectsMutex := sync.Mutex{}
+	errGroup := errgroup.Group{}
+	errGroup.SetLimit(lockCheckConcurrency)
+	for _, objectRelativePath := range objectRelativePaths {
+		objectRelativePath := objectRelativePath
+		errGroup.Go(func() error {
+			lockedObject, locked, err := folder.checkObjectLock(objectRelativePath, now)
+			if err != nil || !locked {
+				return err
+			}
+			lockedObjectsMutex.Lock()
+			defer lockedObjectsMutex.Unlock()
+			lockedObjects = append(lockedObjects, lockedObject)
+			return nil
+		})
+	}
+	if err := errGroup.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(lockedObjects, func(i, j int) bool {
+		return lockedObjects[i].Path < lockedObjects[j].Path
+	})
+	return lockedObjects, nil
+}
+
+func (folder *Folder) checkObjectLock(objectRelativePath string, now time.Time) (storage.LockedObject, bool, error) {
+	objectPath := folder.path + objectRelativePath
+	head, err := folder.s3API.HeadObject(&s3.HeadObjectInput{
+		Bucket: folder.bucket,
+		Key:    aws.String(objectPath),
+	})
+	if err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); ok {
+			statistics.WriteStatusCodeMetric(reqErr.StatusCode())
+		}
+		if isAwsNotExist(err) {
+			// it may be a folder or an already deleted object, S3 doesn't fail to delete them
+			return storage.LockedObject{}, false, nil
+		}
+		return storage.LockedObject{}, false, errors.Wrapf(err, "failed to check object lock of '%s'", objectPath)
+	}
+	statistics.WriteStatusCodeMetric(200)
+
+	switch {
+	case aws.StringValue(head.ObjectLockLegalHoldStatus) == s3.ObjectLockLegalHoldStatusOn:
+		return storage.LockedObject{Path: objectRelativePath, Reason: "legal hold"}, true, nil
+	case head.ObjectLockRetainUntilDate != nil && head.ObjectLockRetainUntilDate.After(now):
+		return storage.LockedObject{
+			Path: objectRelativePath,
+			Reason: fmt.Sprintf("%s retention until %s", aws.StringValue(head.ObjectLockMode),
+				head.ObjectLockRetainUntilDate.Format(time.RFC3339)),
+		}, true, nil
+	default:
+		return storage.LockedObject{}, false, nil
+	}
+}
+
 func (folder *Folder) partitionToObjects(keys []string) []*s3.ObjectIdentifier {
 	objects := make([]*s3.ObjectIdentifier, len(keys))
 	for id, key := range keys {
@@ -1,9 +1,15 @@
 package s3
 
 import (
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
@@ -73,3 +79,55 @@ func TestS3FolderEndpointSource(t *testing.T) {
 
 	storage.RunFolderTest(st.RootFolder(), t)
 }
+
+type objectLockS3API struct {
+	s3iface.S3API
+	mutex     sync.Mutex
+	locked    map[string]*s3.HeadObjectOutput
+	deleted   []string
+	headCalls int
+}
+
+func (api *objectLockS3API) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	api.headCalls++
+	if head, ok := api.locked[aws.StringValue(input.Key)]; ok {
+		return head, nil
+	}
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (api *objectLockS3API) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	for _, object := range input.Delete.Objects {
+		api.deleted = append(api.deleted, aws.StringValue(object.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func TestS3FolderDeleteObjects_ObjectLock(t *testing.T) {
+	api := &objectLockS3API{locked: map[string]*s3.HeadObjectOutput{
+		"prefix/base_2/part_1": {ObjectLockLegalHoldStatus: aws.String(s3.ObjectLockLegalHoldStatusOn)},
+		"prefix/base_2/part_2": {
+			ObjectLockMode:            aws.String(s3.ObjectLockModeGovernance),
+			ObjectLockRetainUntilDate: aws.Time(time.Now().Add(time.Hour)),
+		},
+		"prefix/base_1/part_1": {
+			ObjectLockMode:            aws.String(s3.ObjectLockModeGovernance),
+			ObjectLockRetainUntilDate: aws.Time(time.Now().Add(-time.Hour)),
+		},
+	}}
+	uploader := &Uploader{ObjectLock: ObjectLockConfig{Mode: s3.ObjectLockModeGovernance, RetentionPeriod: time.Hour}}
+	folder := NewFolder(api, uploader, "prefix", &Config{Bucket: "bucket"})
+
+	err := folder.DeleteObjects([]string{"base_1/part_1", "base_2/part_2", "base_2/part_1", "base_2/part_3"})
+	var lockedErr storage.ObjectsLockedError
+	require.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, []string{"base_2/part_1", "base_2/part_2"},
+		[]string{lockedErr.LockedObjects[0].Path, lockedErr.LockedObjects[1].Path})
+	assert.Empty(t, api.deleted)
+	assert.Equal(t, 4, api.headCalls)
+
+	require.NoError(t, folder.DeleteObjects([]string{"base_1/part_1"}))
+	assert.Equal(t, []string{"prefix/base_1/part_1"}, api.deleted)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -24,6 +25,35 @@ type UploaderConfig struct {
 	ServerSideEncryption         string
 	ServerSideEncryptionCustomer string
 	ServerSideEncryptionKMSID    string
+	ObjectLock                   ObjectLockConfig
+}
+
+// ObjectLockConfig describes the S3 Object Lock (WORM) protection applied to the uploaded objects
+type ObjectLockConfig struct {
+	// Mode is a retention mode: GOVERNANCE or COMPLIANCE. Empty mode means that no retention is set.
+	Mode string
+	// RetentionPeriod is added to the upload time to get the date until which the object is retained.
+	RetentionPeriod time.Duration
+	// LegalHold places a legal hold on the uploaded objects, that prevents deletion until it is removed.
+	LegalHold bool
+}
+
+func (config ObjectLockConfig) Enabled() bool {
+	return config.Mode != "" || config.LegalHold
+}
+
+func (config ObjectLockConfig) validate() error {
+	if config.Mode == "" {
+		return nil
+	}
+	if config.Mode != s3.ObjectLockModeGovernance && config.Mode != s3.ObjectLockModeCompliance {
+		return fmt.Errorf("object lock mode must be %s or %s, got %q",
+			s3.ObjectLockModeGovernance, s3.ObjectLockModeCompliance, config.Mode)
+	}
+	if config.RetentionPeriod <= 0 {
+		return fmt.Errorf("object lock retention period must be positive if object lock mode is set")
+	}
+	return nil
 }
 
 func createUploader(s3Client *s3.S3, config *UploaderConfig) (*Uploader, error) {
@@ -32,13 +62,18 @@ func createUploader(s3Client *s3.S3, config *UploaderConfig) (*Uploader, error)
 	if (config.ServerSideEncryption == "aws:kms") == (config.ServerSideEncryptionKMSID == "") {
 		return nil, fmt.Errorf("server-side encryption KMS key ID must be set if 'aws:kms' encryption is used")
 	}
-	return NewUploader(
+	if err := config.ObjectLock.validate(); err != nil {
+		return nil, err
+	}
+	uploader := NewUploader(
 		uploaderAPI,
 		config.ServerSideEncryption,
 		config.ServerSideEncryptionCustomer,
 		config.ServerSideEncryptionKMSID,
 		config.StorageClass,
-	), nil
+	)
+	uploader.ObjectLock = config.ObjectLock
+	return uploader, nil
 }
 
 type Uploader struct {
@@ -47,10 +82,17 @@ type Uploader struct {
 	SSECustomerKey       string
 	SSEKMSKeyID          string
 	StorageClass         string
+	ObjectLock           ObjectLockConfig
 }
 
 func NewUploader(uploaderAPI s3manageriface.UploaderAPI, serverSideEncryption, sseCustomerKey, sseKmsKeyID, storageClass string) *Uploader {
-	return &Uploader{uploaderAPI, serverSideEncryption, sseCustomerKey, sseKmsKeyID, storageClass}
+	return &Uploader{
+		uploaderAPI:          uploaderAPI,
+		serverSideEncryption: serverSideEncryption,
+		SSECustomerKey:       sseCustomerKey,
+		SSEKMSKeyID:          sseKmsKeyID,
+		StorageClass:         storageClass,
+	}
 }
 
 // TODO : unit tests
@@ -79,9 +121,21 @@ func (uploader *Uploader) createUploadInput(bucket, path string, content io.Read
 		}
 	}
 
+	if uploader.ObjectLock.Mode != "" {
+		uploadInput.ObjectLockMode = aws.String(uploader.ObjectLock.Mode)
+		uploadInput.ObjectLockRetainUntilDate = aws.Time(uploader.retainUntilDate())
+	}
+	if uploader.ObjectLock.LegalHold {
+		uploadInput.ObjectLockLegalHoldStatus = aws.String(s3.ObjectLockLegalHoldStatusOn)
+	}
+
 	return uploadInput
 }
 
+func (uploader *Uploader) retainUntilDate() time.Time {
+	return time.Now().Add(uploader.ObjectLock.RetentionPeriod).UTC()
+}
+
 func (uploader *Uploader) upload(ctx context.Context, bucket, path string, content io.Reader) error {
 	input := uploader.createUploadInput(bucket, path, content)
 	_, err := uploader.uploaderAPI.UploadWithContext(ctx, input)
@@ -2,8 +2,12 @@ package s3
 
 import (
 	"fmt"
-	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPartitionStrings(t *testing.T) {
@@ -60,3 +64,45 @@ func TestPartitionStrings(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateUploadInput_ObjectLock(t *testing.T) {
+	uploader := NewUploader(nil, "", "", "", "STANDARD")
+	uploader.ObjectLock = ObjectLockConfig{
+		Mode:            s3.ObjectLockModeCompliance,
+		RetentionPeriod: time.Hour,
+		LegalHold:       true,
+	}
+
+	input := uploader.createUploadInput("bucket", "path", nil)
+	assert.Equal(t, s3.ObjectLockModeCompliance, aws.StringValue(input.ObjectLockMode))
+	assert.Equal(t, s3.ObjectLockLegalHoldStatusOn, aws.StringValue(input.ObjectLockLegalHoldStatus))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), aws.TimeValue(input.ObjectLockRetainUntilDate), time.Minute)
+}
+
+func TestCreateUploadInput_NoObjectLock(t *testing.T) {
+	uploader := NewUploader(nil, "", "", "", "STANDARD")
+
+	input := uploader.createUploadInput("bucket", "path", nil)
+	assert.Nil(t, input.ObjectLockMode)
+	assert.Nil(t, input.ObjectLockRetainUntilDate)
+	assert.Nil(t, input.ObjectLockLegalHoldStatus)
+}
+
+func TestObjectLockConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		config  ObjectLockConfig
+		isValid bool
+	}{
+		{ObjectLockConfig{}, true},
+		{ObjectLockConfig{LegalHold: true}, true},
+		{ObjectLockConfig{Mode: s3.ObjectLockModeGovernance, RetentionPeriod: time.Hour}, true},
+		{ObjectLockConfig{Mode: s3.ObjectLockModeCompliance}, false},
+		{ObjectLockConfig{Mode: "UNKNOWN", RetentionPeriod: time.Hour}, false},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			err := tc.config.validate()
+			assert.Equal(t, tc.isValid, err == nil)
+		})
+	}
+}
@@ -30,3 +30,27 @@ func NewError(err error, storageName, format string, args ...interface{}) Error
 func (err Error) Error() string {
 	return fmt.Sprintf(tracelog.GetErrorFormatter(), err.error)
 }
+
+// LockedObject is an object that can't be deleted at the moment, e.g. because of S3 Object Lock
+type LockedObject struct {
+	Path   string
+	Reason string
+}
+
+// ObjectsLockedError is returned from Folder.DeleteObjects if some objects are protected from deletion,
+// in this case none of the objects are deleted
+type ObjectsLockedError struct {
+	error
+	LockedObjects []LockedObject
+}
+
+func NewObjectsLockedError(lockedObjects []LockedObject) ObjectsLockedError {
+	return ObjectsLockedError{
+		error:         errors.Errorf("%d objects are locked and can't be deleted", len(lockedObjects)),
+		LockedObjects: lockedObjects,
+	}
+}
+
+func (err ObjectsLockedError) Error() string {
+	return fmt.Sprintf(tracelog.GetErrorFormatter(), err.error)
+}
@@ -0,0 +1,309 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>` +
+	`<D:propfind xmlns:D="DAV:"><D:prop>` +
+	`<D:resourcetype/><D:getcontentlength/><D:getlastmodified/>` +
+	`</D:prop></D:propfind>`
+
+// Client is a minimal WebDAV (RFC 4918) client supporting just the methods required by the storage Folder.
+type Client struct {
+	rootURL    *url.URL
+	httpClient *http.Client
+	user       string
+	password   string
+
+	// knownDirs caches the collections that are known to exist, so we don't send MKCOL before every PUT
+	knownDirs sync.Map
+}
+
+func NewClient(rootURL *url.URL, httpClient *http.Client, user, password string) *Client {
+	return &Client{
+		rootURL:    rootURL,
+		httpClient: httpClient,
+		user:       user,
+		password:   password,
+	}
+}
+
+type resourceInfo struct {
+	name         string
+	isCollection bool
+	size         int64
+	lastModified time.Time
+}
+
+type multiStatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		PropStats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength int64  `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// URL builds an absolute URL of the resource located by the path relative to the storage root.
+func (c *Client) URL(relativePath string) *url.URL {
+	resURL := *c.rootURL
+	resURL.Path = path.Join("/", c.rootURL.Path, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(resURL.Path, "/") {
+		resURL.Path += "/"
+	}
+	resURL.RawPath = ""
+	return &resURL
+}
+
+// List returns the direct children of the collection. It returns nil without any error if the collection
+// doesn't exist.
+func (c *Client) List(relativePath string) ([]resourceInfo, error) {
+	collectionURL := c.URL(storageDirPath(relativePath))
+	req, err := c.newRequest(context.Background(), "PROPFIND", collectionURL, strings.NewReader(propfindBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list WebDAV collection %q: %w", collectionURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, newUnexpectedStatusError(req, resp)
+	}
+
+	var status multiStatus
+	err = xml.NewDecoder(resp.Body).Decode(&status)
+	if err != nil {
+		return nil, fmt.Errorf("decode PROPFIND response for %q: %w", collectionURL, err)
+	}
+
+	selfPath := path.Clean(collectionURL.Path)
+	var resources []resourceInfo
+	for _, response := range status.Responses {
+		hrefURL, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, fmt.Errorf("parse href %q in PROPFIND response: %w", response.Href, err)
+		}
+		hrefPath := path.Clean(hrefURL.Path)
+		if hrefPath == selfPath {
+			continue
+		}
+
+		info := resourceInfo{name: path.Base(hrefPath)}
+		for _, propStat := range response.PropStats {
+			if !strings.Contains(propStat.Status, " 200 ") {
+				continue
+			}
+			if propStat.Prop.ResourceType.Collection != nil {
+				info.isCollection = true
+			}
+			if propStat.Prop.ContentLength != 0 {
+				info.size = propStat.Prop.ContentLength
+			}
+			if propStat.Prop.LastModified != "" {
+				info.lastModified, _ = http.ParseTime(propStat.Prop.LastModified)
+			}
+		}
+		resources = append(resources, info)
+	}
+	return resources, nil
+}
+
+// Exists checks if the resource exists.
+func (c *Client) Exists(relativePath string) (bool, error) {
+	resp, req, err := c.do(context.Background(), http.MethodHead, c.URL(relativePath), nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case isSuccess(resp.StatusCode):
+		return true, nil
+	default:
+		return false, newUnexpectedStatusError(req, resp)
+	}
+}
+
+// Get downloads the resource. It returns errNotFound if the resource doesn't exist.
+func (c *Client) Get(relativePath string) (io.ReadCloser, error) {
+	resp, req, err := c.do(context.Background(), http.MethodGet, c.URL(relativePath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newUnexpectedStatusError(req, resp)
+	}
+	return resp.Body, nil
+}
+
+// Put uploads the content creating all the missing parent collections.
+func (c *Client) Put(ctx context.Context, relativePath string, content io.Reader) error {
+	err := c.MkdirAll(ctx, path.Dir(relativePath))
+	if err != nil {
+		return err
+	}
+
+	resp, req, err := c.do(ctx, http.MethodPut, c.URL(relativePath), content)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp.StatusCode) {
+		return newUnexpectedStatusError(req, resp)
+	}
+	return nil
+}
+
+// Copy copies the resource with the server-side COPY method, overwriting the destination if it exists.
+// It returns errNotFound if the source resource doesn't exist.
+func (c *Client) Copy(srcRelativePath, dstRelativePath string) error {
+	err := c.MkdirAll(context.Background(), path.Dir(dstRelativePath))
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(context.Background(), "COPY", c.URL(srcRelativePath), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", c.URL(dstRelativePath).String())
+	req.Header.Set("Overwrite", "T")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send WebDAV COPY request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if !isSuccess(resp.StatusCode) {
+		return newUnexpectedStatusError(req, resp)
+	}
+	return nil
+}
+
+// Delete removes the resource. A collection is removed with all its content. A nonexistent resource is ignored.
+func (c *Client) Delete(relativePath string) error {
+	resp, req, err := c.do(context.Background(), http.MethodDelete, c.URL(relativePath), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	c.forgetDirs(relativePath)
+
+	if resp.StatusCode != http.StatusNotFound && !isSuccess(resp.StatusCode) {
+		return newUnexpectedStatusError(req, resp)
+	}
+	return nil
+}
+
+// MkdirAll creates the collection and all its missing parents with MKCOL requests.
+func (c *Client) MkdirAll(ctx context.Context, relativePath string) error {
+	relativePath = strings.Trim(path.Clean("/"+relativePath), "/")
+
+	var segments []string
+	if relativePath != "" {
+		segments = strings.Split(relativePath, "/")
+	}
+	for i := 0; i <= len(segments); i++ {
+		dirPath := storageDirPath(strings.Join(segments[:i], "/"))
+		if _, ok := c.knownDirs.Load(dirPath); ok {
+			continue
+		}
+
+		resp, req, err := c.do(ctx, "MKCOL", c.URL(dirPath), nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		// 405 Method Not Allowed means the collection already exists
+		if resp.StatusCode != http.StatusMethodNotAllowed && !isSuccess(resp.StatusCode) {
+			return newUnexpectedStatusError(req, resp)
+		}
+		c.knownDirs.Store(dirPath, struct{}{})
+	}
+	return nil
+}
+
+func (c *Client) forgetDirs(relativePath string) {
+	prefix := storageDirPath(strings.Trim(relativePath, "/"))
+	c.knownDirs.Range(func(key, _ any) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			c.knownDirs.Delete(key)
+		}
+		return true
+	})
+}
+
+func (c *Client) do(ctx context.Context, method string, resURL *url.URL, body io.Reader) (*http.Response, *http.Request, error) {
+	req, err := c.newRequest(ctx, method, resURL, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("send WebDAV %s request: %w", method, err)
+	}
+	return resp, req, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, resURL *url.URL, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, resURL.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("create WebDAV %s request for %q: %w", method, resURL, err)
+	}
+	if c.user != "" || c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	return req, nil
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+func storageDirPath(relativePath string) string {
+	if relativePath == "" || strings.HasSuffix(relativePath, "/") {
+		return relativePath
+	}
+	return relativePath + "/"
+}
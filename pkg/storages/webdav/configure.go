@@ -0,0 +1,69 @@
+package webdav
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// TODO: Merge the settings and their default values with ones defined in internal/config.go
+
+const (
+	usernameSetting   = "WEBDAV_USERNAME"
+	passwordSetting   = "WEBDAV_PASSWORD"
+	caCertFileSetting = "WEBDAV_CA_CERT_FILE"
+	timeoutSetting    = "WEBDAV_TIMEOUT"
+)
+
+var SettingList = []string{
+	usernameSetting,
+	passwordSetting,
+	caCertFileSetting,
+	timeoutSetting,
+}
+
+const defaultTimeout = 0 // no timeout, big objects may be uploaded for a long time
+
+func ConfigureStorage(
+	prefix string,
+	settings map[string]string,
+	rootWraps ...storage.WrapRootFolder,
+) (storage.HashableStorage, error) {
+	rootURL, err := url.Parse(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("parse WebDAV storage prefix %q: %w", prefix, err)
+	}
+	if rootURL.Scheme != "http" && rootURL.Scheme != "https" {
+		return nil, fmt.Errorf("WebDAV storage prefix %q must have http or https scheme", prefix)
+	}
+	if rootURL.Host == "" {
+		return nil, fmt.Errorf("WebDAV storage prefix %q has no host", prefix)
+	}
+
+	timeout := time.Duration(defaultTimeout)
+	if t, ok := settings[timeoutSetting]; ok {
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", timeoutSetting, err)
+		}
+	}
+
+	config := &Config{
+		Secrets: &Secrets{
+			Password: settings[passwordSetting],
+		},
+		URL:        rootURL.Scheme + "://" + rootURL.Host,
+		RootPath:   rootURL.Path,
+		User:       settings[usernameSetting],
+		CACertFile: settings[caCertFileSetting],
+		Timeout:    timeout,
+	}
+
+	st, err := NewStorage(config, rootWraps...)
+	if err != nil {
+		return nil, fmt.Errorf("create WebDAV storage: %w", err)
+	}
+	return st, nil
+}
@@ -0,0 +1,22 @@
+package webdav
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var errNotFound = errors.New("resource not found")
+
+const maxErrorBodySize = 512
+
+func newUnexpectedStatusError(req *http.Request, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return fmt.Errorf("unexpected WebDAV response to %s %q: %s", req.Method, req.URL, resp.Status)
+	}
+	return fmt.Errorf("unexpected WebDAV response to %s %q: %s: %s", req.Method, req.URL, resp.Status, message)
+}
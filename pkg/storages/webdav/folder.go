@@ -0,0 +1,110 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// Folder represents a WebDAV collection
+type Folder struct {
+	client *Client
+	path   string
+}
+
+func NewFolder(client *Client, path string) *Folder {
+	// Trim leading slash because all paths are relative to the storage root.
+	path = strings.TrimPrefix(path, "/")
+	return &Folder{
+		client: client,
+		path:   path,
+	}
+}
+
+func (folder *Folder) GetPath() string {
+	return folder.path
+}
+
+func (folder *Folder) ListFolder() (objects []storage.Object, subFolders []storage.Folder, err error) {
+	resources, err := folder.client.List(folder.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list WebDAV folder %q: %w", folder.path, err)
+	}
+
+	for _, res := range resources {
+		if res.isCollection {
+			subFolders = append(subFolders, NewFolder(folder.client, storageDirPath(path.Join(folder.path, res.name))))
+			// Folder is not object, just skip it
+			continue
+		}
+		objects = append(objects, storage.NewLocalObject(res.name, res.lastModified, res.size))
+	}
+	return objects, subFolders, nil
+}
+
+func (folder *Folder) DeleteObjects(objectRelativePaths []string) error {
+	for _, relativePath := range objectRelativePaths {
+		objPath := path.Join(folder.path, relativePath)
+		err := folder.client.Delete(objPath)
+		if err != nil {
+			return fmt.Errorf("delete object %q via WebDAV: %w", objPath, err)
+		}
+	}
+	return nil
+}
+
+func (folder *Folder) Exists(objectRelativePath string) (bool, error) {
+	objPath := path.Join(folder.path, objectRelativePath)
+	exists, err := folder.client.Exists(objPath)
+	if err != nil {
+		return false, fmt.Errorf("check object %q existence via WebDAV: %w", objPath, err)
+	}
+	return exists, nil
+}
+
+func (folder *Folder) GetSubFolder(subFolderRelativePath string) storage.Folder {
+	return NewFolder(folder.client, storageDirPath(path.Join(folder.path, subFolderRelativePath)))
+}
+
+func (folder *Folder) ReadObject(objectRelativePath string) (io.ReadCloser, error) {
+	objPath := path.Join(folder.path, objectRelativePath)
+	reader, err := folder.client.Get(objPath)
+	if errors.Is(err, errNotFound) {
+		return nil, storage.NewObjectNotFoundError(objPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read object %q via WebDAV: %w", objPath, err)
+	}
+	return reader, nil
+}
+
+func (folder *Folder) PutObject(name string, content io.Reader) error {
+	return folder.PutObjectWithContext(context.Background(), name, content)
+}
+
+func (folder *Folder) PutObjectWithContext(ctx context.Context, name string, content io.Reader) error {
+	objPath := path.Join(folder.path, name)
+	err := folder.client.Put(ctx, objPath, content)
+	if err != nil {
+		return fmt.Errorf("put object %q via WebDAV: %w", objPath, err)
+	}
+	return nil
+}
+
+func (folder *Folder) CopyObject(srcPath string, dstPath string) error {
+	srcObjPath := path.Join(folder.path, srcPath)
+	dstObjPath := path.Join(folder.path, dstPath)
+	err := folder.client.Copy(srcObjPath, dstObjPath)
+	if errors.Is(err, errNotFound) {
+		return storage.NewObjectNotFoundError(srcObjPath)
+	}
+	if err != nil {
+		return fmt.Errorf("copy object %q to %q via WebDAV: %w", srcObjPath, dstObjPath, err)
+	}
+	return nil
+}
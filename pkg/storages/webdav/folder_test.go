@@ -0,0 +1,47 @@
+package webdav
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"golang.org/x/net/webdav"
+)
+
+func TestWebDAVFolder(t *testing.T) {
+	server := httptest.NewServer(&webdav.Handler{
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	})
+	defer server.Close()
+
+	st, err := ConfigureStorage(server.URL+"/walg-test", nil)
+	require.NoError(t, err)
+	defer st.Close()
+
+	storage.RunFolderTest(st.RootFolder(), t)
+}
+
+func TestWebDAVFolder_ListNonexistent(t *testing.T) {
+	server := httptest.NewServer(&webdav.Handler{
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	})
+	defer server.Close()
+
+	st, err := ConfigureStorage(server.URL+"/walg", nil)
+	require.NoError(t, err)
+	defer st.Close()
+
+	objects, subFolders, err := st.RootFolder().GetSubFolder("nonexistent").ListFolder()
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+	assert.Empty(t, subFolders)
+}
+
+func TestConfigureStorage_WrongScheme(t *testing.T) {
+	_, err := ConfigureStorage("ssh://localhost/walg", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package webdav
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+var _ storage.HashableStorage = &Storage{}
+
+type Storage struct {
+	httpClient *http.Client
+	rootFolder storage.Folder
+	hash       string
+}
+
+type Config struct {
+	Secrets    *Secrets `json:"-"`
+	URL        string
+	RootPath   string
+	User       string
+	CACertFile string
+	Timeout    time.Duration
+}
+
+type Secrets struct {
+	Password string
+}
+
+func NewStorage(config *Config, rootWraps ...storage.WrapRootFolder) (*Storage, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CACertFile != "" {
+		caCert, err := os.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("read WebDAV CA certificate: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in %q", config.CACertFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+	}
+	httpClient := &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+	}
+
+	rootURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse WebDAV URL %q: %w", config.URL, err)
+	}
+	rootURL.Path = storage.AddDelimiterToPath(config.RootPath)
+	client := NewClient(rootURL, httpClient, config.User, config.Secrets.Password)
+
+	var folder storage.Folder = NewFolder(client, "")
+
+	for _, wrap := range rootWraps {
+		folder = wrap(folder)
+	}
+
+	hash, err := storage.ComputeConfigHash("webdav", config)
+	if err != nil {
+		return nil, fmt.Errorf("compute config hash: %w", err)
+	}
+
+	return &Storage{httpClient, folder, hash}, nil
+}
+
+func (s *Storage) RootFolder() storage.Folder {
+	return s.rootFolder
+}
+
+func (s *Storage) ConfigHash() string {
+	return s.hash
+}
+
+func (s *Storage) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
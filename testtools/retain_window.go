@@ -0,0 +1,46 @@
+package testtools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// RetainWindowTestBackupLogNos are the logs which the backups of MakeRetainWindowTestFolder start at:
+// the backups are made 30, 20 and 6 days ago
+var RetainWindowTestBackupLogNos = []int{1, 6, 13}
+
+// MakeRetainWindowTestFolder creates the folder for the retain window tests: the logs 1-15, e.g. WAL segments or binlogs,
+// uploaded every 2 days since 30 days ago except the skipped ones, and the backups started along with the logs
+// RetainWindowTestBackupLogNos and finished an hour later. The objects are put by the database specific callbacks
+// and get the upload time as their modification time.
+func MakeRetainWindowTestFolder(t *testing.T, skipLogs map[int]bool,
+	putBackup func(folder storage.Folder, logNo int, startTime, finishTime time.Time) error,
+	putLog func(folder storage.Folder, logNo int) error) storage.Folder {
+	var objectsTime time.Time
+	folder := memory.NewFolder("", memory.NewKVS(memory.WithCustomTime(func() time.Time {
+		return objectsTime
+	})))
+	now := utility.TimeNowCrossPlatformUTC()
+	logTime := func(logNo int) time.Time {
+		return now.Add(-time.Duration(32-2*logNo) * 24 * time.Hour)
+	}
+	for _, logNo := range RetainWindowTestBackupLogNos {
+		startTime := logTime(logNo)
+		objectsTime = startTime.Add(time.Hour)
+		require.NoError(t, putBackup(folder, logNo, startTime, objectsTime))
+	}
+	for logNo := 1; logNo <= 15; logNo++ {
+		if skipLogs[logNo] {
+			continue
+		}
+		objectsTime = logTime(logNo)
+		require.NoError(t, putLog(folder, logNo))
+	}
+	objectsTime = now
+	return folder
+}